@@ -0,0 +1,222 @@
+package winapi
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/go-ole"
+)
+
+// DWMWINDOWATTRIBUTE
+const (
+	DWMWA_NCRENDERING_ENABLED         uint32 = 1
+	DWMWA_NCRENDERING_POLICY          uint32 = 2
+	DWMWA_TRANSITIONS_FORCEDISABLED   uint32 = 3
+	DWMWA_ALLOW_NCPAINT               uint32 = 4
+	DWMWA_CAPTION_BUTTON_BOUNDS       uint32 = 5
+	DWMWA_NONCLIENT_RTL_LAYOUT        uint32 = 6
+	DWMWA_FORCE_ICONIC_REPRESENTATION uint32 = 7
+	DWMWA_FLIP3D_POLICY               uint32 = 8
+	DWMWA_EXTENDED_FRAME_BOUNDS       uint32 = 9
+	DWMWA_HAS_ICONIC_BITMAP           uint32 = 10
+	DWMWA_DISALLOW_PEEK               uint32 = 11
+	DWMWA_EXCLUDED_FROM_PEEK          uint32 = 12
+	DWMWA_CLOAK                       uint32 = 13
+	DWMWA_CLOAKED                     uint32 = 14
+	DWMWA_FREEZE_REPRESENTATION       uint32 = 15
+	DWMWA_PASSIVE_UPDATE_MODE         uint32 = 16
+	DWMWA_USE_HOSTBACKDROPBRUSH       uint32 = 17
+	// 18 and 19 are unused, builds before 20H1 took dark mode as 19.
+	DWMWA_USE_IMMERSIVE_DARK_MODE uint32 = 20
+	// 21 to 32 are undocumented.
+	DWMWA_WINDOW_CORNER_PREFERENCE       uint32 = 33
+	DWMWA_BORDER_COLOR                   uint32 = 34
+	DWMWA_CAPTION_COLOR                  uint32 = 35
+	DWMWA_TEXT_COLOR                     uint32 = 36
+	DWMWA_VISIBLE_FRAME_BORDER_THICKNESS uint32 = 37
+	DWMWA_SYSTEMBACKDROP_TYPE            uint32 = 38
+
+	dwmwaUseImmersiveDarkModeBefore20H1 uint32 = 19
+)
+
+// DWMWA_CLOAKED reasons
+const (
+	DWM_CLOAKED_APP       uint32 = 0x1
+	DWM_CLOAKED_SHELL     uint32 = 0x2
+	DWM_CLOAKED_INHERITED uint32 = 0x4
+)
+
+// DWM_WINDOW_CORNER_PREFERENCE
+const (
+	DWMWCP_DEFAULT uint32 = iota
+	DWMWCP_DONOTROUND
+	DWMWCP_ROUND
+	DWMWCP_ROUNDSMALL
+)
+
+// Special values for DWMWA_BORDER_COLOR, DWMWA_CAPTION_COLOR and DWMWA_TEXT_COLOR
+const (
+	DWMWA_COLOR_DEFAULT win.COLORREF = 0xFFFFFFFF
+	DWMWA_COLOR_NONE    win.COLORREF = 0xFFFFFFFE
+)
+
+// dwmAttributes names each attribute and gives the size of its value.
+var dwmAttributes = map[uint32]struct {
+	name string
+	size uintptr
+}{
+	DWMWA_NCRENDERING_ENABLED:            {"DWMWA_NCRENDERING_ENABLED", 4},
+	DWMWA_NCRENDERING_POLICY:             {"DWMWA_NCRENDERING_POLICY", 4},
+	DWMWA_TRANSITIONS_FORCEDISABLED:      {"DWMWA_TRANSITIONS_FORCEDISABLED", 4},
+	DWMWA_ALLOW_NCPAINT:                  {"DWMWA_ALLOW_NCPAINT", 4},
+	DWMWA_CAPTION_BUTTON_BOUNDS:          {"DWMWA_CAPTION_BUTTON_BOUNDS", unsafe.Sizeof(win.RECT{})},
+	DWMWA_NONCLIENT_RTL_LAYOUT:           {"DWMWA_NONCLIENT_RTL_LAYOUT", 4},
+	DWMWA_FORCE_ICONIC_REPRESENTATION:    {"DWMWA_FORCE_ICONIC_REPRESENTATION", 4},
+	DWMWA_FLIP3D_POLICY:                  {"DWMWA_FLIP3D_POLICY", 4},
+	DWMWA_EXTENDED_FRAME_BOUNDS:          {"DWMWA_EXTENDED_FRAME_BOUNDS", unsafe.Sizeof(win.RECT{})},
+	DWMWA_HAS_ICONIC_BITMAP:              {"DWMWA_HAS_ICONIC_BITMAP", 4},
+	DWMWA_DISALLOW_PEEK:                  {"DWMWA_DISALLOW_PEEK", 4},
+	DWMWA_EXCLUDED_FROM_PEEK:             {"DWMWA_EXCLUDED_FROM_PEEK", 4},
+	DWMWA_CLOAK:                          {"DWMWA_CLOAK", 4},
+	DWMWA_CLOAKED:                        {"DWMWA_CLOAKED", 4},
+	DWMWA_FREEZE_REPRESENTATION:          {"DWMWA_FREEZE_REPRESENTATION", 4},
+	DWMWA_PASSIVE_UPDATE_MODE:            {"DWMWA_PASSIVE_UPDATE_MODE", 4},
+	DWMWA_USE_HOSTBACKDROPBRUSH:          {"DWMWA_USE_HOSTBACKDROPBRUSH", 4},
+	dwmwaUseImmersiveDarkModeBefore20H1:  {"DWMWA_USE_IMMERSIVE_DARK_MODE", 4},
+	DWMWA_USE_IMMERSIVE_DARK_MODE:        {"DWMWA_USE_IMMERSIVE_DARK_MODE", 4},
+	DWMWA_WINDOW_CORNER_PREFERENCE:       {"DWMWA_WINDOW_CORNER_PREFERENCE", 4},
+	DWMWA_BORDER_COLOR:                   {"DWMWA_BORDER_COLOR", 4},
+	DWMWA_CAPTION_COLOR:                  {"DWMWA_CAPTION_COLOR", 4},
+	DWMWA_TEXT_COLOR:                     {"DWMWA_TEXT_COLOR", 4},
+	DWMWA_VISIBLE_FRAME_BORDER_THICKNESS: {"DWMWA_VISIBLE_FRAME_BORDER_THICKNESS", 4},
+	DWMWA_SYSTEMBACKDROP_TYPE:            {"DWMWA_SYSTEMBACKDROP_TYPE", 4},
+}
+
+// DwmAttributeName returns the constant name of attr.
+func DwmAttributeName(attr uint32) string {
+	if a, ok := dwmAttributes[attr]; ok {
+		return a.name
+	}
+	return fmt.Sprintf("DWMWINDOWATTRIBUTE(%d)", attr)
+}
+
+func checkDwmAttribute(attr uint32, size uintptr) error {
+	a, ok := dwmAttributes[attr]
+	if !ok {
+		return errors.Errorf("unknown DWM attribute %d", attr)
+	}
+	if a.size != size {
+		return errors.Errorf("%s takes %d bytes, got %d", a.name, a.size, size)
+	}
+	return nil
+}
+
+// DwmGetWindowAttribute reads attr of hwnd into the value pointed to by pv,
+// which must have the size of the attribute.
+func DwmGetWindowAttribute(hwnd win.HWND, attr uint32, pv unsafe.Pointer, size uintptr) error {
+	if err := checkDwmAttribute(attr, size); err != nil {
+		return err
+	}
+	if err := procDwmGetWindowAttribute.Find(); err != nil {
+		return err
+	}
+	if hr := dwmGetWindowAttribute(uintptr(hwnd), attr, pv, uint32(size)); hr != win.S_OK {
+		return errors.Wrap(ole.NewError(uintptr(uint32(hr))), DwmAttributeName(attr))
+	}
+	return nil
+}
+
+// DwmSetWindowAttribute sets attr of hwnd to the value pointed to by pv.
+func DwmSetWindowAttribute(hwnd win.HWND, attr uint32, pv unsafe.Pointer, size uintptr) error {
+	if err := checkDwmAttribute(attr, size); err != nil {
+		return err
+	}
+	if err := procDwmSetWindowAttribute.Find(); err != nil {
+		return err
+	}
+	if hr := dwmSetWindowAttribute(uintptr(hwnd), attr, pv, uint32(size)); hr != win.S_OK {
+		return errors.Wrap(ole.NewError(uintptr(uint32(hr))), DwmAttributeName(attr))
+	}
+	return nil
+}
+
+func dwmGetBool(hwnd win.HWND, attr uint32) (bool, error) {
+	var v win.BOOL
+	err := DwmGetWindowAttribute(hwnd, attr, unsafe.Pointer(&v), unsafe.Sizeof(v))
+	return v != 0, err
+}
+
+func dwmSetBool(hwnd win.HWND, attr uint32, value bool) error {
+	var v win.BOOL
+	if value {
+		v = win.TRUE
+	}
+	return DwmSetWindowAttribute(hwnd, attr, unsafe.Pointer(&v), unsafe.Sizeof(v))
+}
+
+func dwmSetUint32(hwnd win.HWND, attr uint32, v uint32) error {
+	return DwmSetWindowAttribute(hwnd, attr, unsafe.Pointer(&v), unsafe.Sizeof(v))
+}
+
+// DwmCloaked returns the DWM_CLOAKED_* reasons hwnd is cloaked for, 0 when
+// it is not. Cloaked windows are visible to user32 but not drawn, like
+// suspended UWP apps and windows on other virtual desktops.
+func DwmCloaked(hwnd win.HWND) (uint32, error) {
+	var v uint32
+	err := DwmGetWindowAttribute(hwnd, DWMWA_CLOAKED, unsafe.Pointer(&v), unsafe.Sizeof(v))
+	return v, err
+}
+
+// DwmExtendedFrameBounds returns the window rectangle without the invisible
+// resize borders GetWindowRect includes. The rectangle is in physical
+// pixels regardless of the DPI awareness of the caller.
+func DwmExtendedFrameBounds(hwnd win.HWND) (win.RECT, error) {
+	var r win.RECT
+	err := DwmGetWindowAttribute(hwnd, DWMWA_EXTENDED_FRAME_BOUNDS, unsafe.Pointer(&r), unsafe.Sizeof(r))
+	return r, err
+}
+
+// DwmDarkMode reports whether the title bar of hwnd is drawn dark.
+func DwmDarkMode(hwnd win.HWND) (bool, error) {
+	dark, err := dwmGetBool(hwnd, DWMWA_USE_IMMERSIVE_DARK_MODE)
+	if err != nil {
+		return dwmGetBool(hwnd, dwmwaUseImmersiveDarkModeBefore20H1)
+	}
+	return dark, nil
+}
+
+// DwmSetDarkMode switches the title bar of hwnd between dark and light.
+func DwmSetDarkMode(hwnd win.HWND, dark bool) error {
+	if err := dwmSetBool(hwnd, DWMWA_USE_IMMERSIVE_DARK_MODE, dark); err != nil {
+		return dwmSetBool(hwnd, dwmwaUseImmersiveDarkModeBefore20H1, dark)
+	}
+	return nil
+}
+
+// DwmSetCornerPreference sets the DWMWCP_* rounding of hwnd, Windows 11 only.
+func DwmSetCornerPreference(hwnd win.HWND, pref uint32) error {
+	return dwmSetUint32(hwnd, DWMWA_WINDOW_CORNER_PREFERENCE, pref)
+}
+
+// DwmSetBorderColor sets the border color of hwnd, Windows 11 only. Pass
+// DWMWA_COLOR_NONE to hide the border or DWMWA_COLOR_DEFAULT to reset it.
+func DwmSetBorderColor(hwnd win.HWND, color win.COLORREF) error {
+	return dwmSetUint32(hwnd, DWMWA_BORDER_COLOR, uint32(color))
+}
+
+// DwmSetCaptionColor sets the title bar color of hwnd, Windows 11 only.
+func DwmSetCaptionColor(hwnd win.HWND, color win.COLORREF) error {
+	return dwmSetUint32(hwnd, DWMWA_CAPTION_COLOR, uint32(color))
+}
+
+// DwmSetTextColor sets the title text color of hwnd, Windows 11 only.
+func DwmSetTextColor(hwnd win.HWND, color win.COLORREF) error {
+	return dwmSetUint32(hwnd, DWMWA_TEXT_COLOR, uint32(color))
+}
+
+// DwmSetExcludedFromPeek keeps hwnd visible while Aero Peek shows the desktop.
+func DwmSetExcludedFromPeek(hwnd win.HWND, excluded bool) error {
+	return dwmSetBool(hwnd, DWMWA_EXCLUDED_FROM_PEEK, excluded)
+}
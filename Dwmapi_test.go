@@ -0,0 +1,70 @@
+package winapi
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/lxn/win"
+)
+
+// the values of dwmapi.h
+func TestDwmAttributes(t *testing.T) {
+	tests := []struct {
+		attr  uint32
+		value uint32
+		name  string
+		size  uintptr
+	}{
+		{DWMWA_NCRENDERING_ENABLED, 1, "DWMWA_NCRENDERING_ENABLED", 4},
+		{DWMWA_NCRENDERING_POLICY, 2, "DWMWA_NCRENDERING_POLICY", 4},
+		{DWMWA_TRANSITIONS_FORCEDISABLED, 3, "DWMWA_TRANSITIONS_FORCEDISABLED", 4},
+		{DWMWA_ALLOW_NCPAINT, 4, "DWMWA_ALLOW_NCPAINT", 4},
+		{DWMWA_CAPTION_BUTTON_BOUNDS, 5, "DWMWA_CAPTION_BUTTON_BOUNDS", unsafe.Sizeof(win.RECT{})},
+		{DWMWA_NONCLIENT_RTL_LAYOUT, 6, "DWMWA_NONCLIENT_RTL_LAYOUT", 4},
+		{DWMWA_FORCE_ICONIC_REPRESENTATION, 7, "DWMWA_FORCE_ICONIC_REPRESENTATION", 4},
+		{DWMWA_FLIP3D_POLICY, 8, "DWMWA_FLIP3D_POLICY", 4},
+		{DWMWA_EXTENDED_FRAME_BOUNDS, 9, "DWMWA_EXTENDED_FRAME_BOUNDS", unsafe.Sizeof(win.RECT{})},
+		{DWMWA_HAS_ICONIC_BITMAP, 10, "DWMWA_HAS_ICONIC_BITMAP", 4},
+		{DWMWA_DISALLOW_PEEK, 11, "DWMWA_DISALLOW_PEEK", 4},
+		{DWMWA_EXCLUDED_FROM_PEEK, 12, "DWMWA_EXCLUDED_FROM_PEEK", 4},
+		{DWMWA_CLOAK, 13, "DWMWA_CLOAK", 4},
+		{DWMWA_CLOAKED, 14, "DWMWA_CLOAKED", 4},
+		{DWMWA_FREEZE_REPRESENTATION, 15, "DWMWA_FREEZE_REPRESENTATION", 4},
+		{DWMWA_PASSIVE_UPDATE_MODE, 16, "DWMWA_PASSIVE_UPDATE_MODE", 4},
+		{DWMWA_USE_HOSTBACKDROPBRUSH, 17, "DWMWA_USE_HOSTBACKDROPBRUSH", 4},
+		{dwmwaUseImmersiveDarkModeBefore20H1, 19, "DWMWA_USE_IMMERSIVE_DARK_MODE", 4},
+		{DWMWA_USE_IMMERSIVE_DARK_MODE, 20, "DWMWA_USE_IMMERSIVE_DARK_MODE", 4},
+		{DWMWA_WINDOW_CORNER_PREFERENCE, 33, "DWMWA_WINDOW_CORNER_PREFERENCE", 4},
+		{DWMWA_BORDER_COLOR, 34, "DWMWA_BORDER_COLOR", 4},
+		{DWMWA_CAPTION_COLOR, 35, "DWMWA_CAPTION_COLOR", 4},
+		{DWMWA_TEXT_COLOR, 36, "DWMWA_TEXT_COLOR", 4},
+		{DWMWA_VISIBLE_FRAME_BORDER_THICKNESS, 37, "DWMWA_VISIBLE_FRAME_BORDER_THICKNESS", 4},
+		{DWMWA_SYSTEMBACKDROP_TYPE, 38, "DWMWA_SYSTEMBACKDROP_TYPE", 4},
+	}
+	if len(tests) != len(dwmAttributes) {
+		t.Errorf("%d attributes in the table, want %d", len(dwmAttributes), len(tests))
+	}
+	for _, tt := range tests {
+		if tt.attr != tt.value {
+			t.Errorf("%s = %d, want %d", tt.name, tt.attr, tt.value)
+		}
+		if got := DwmAttributeName(tt.attr); got != tt.name {
+			t.Errorf("DwmAttributeName(%d) = %q, want %q", tt.attr, got, tt.name)
+		}
+		if err := checkDwmAttribute(tt.attr, tt.size); err != nil {
+			t.Errorf("checkDwmAttribute(%s, %d): %v", tt.name, tt.size, err)
+		}
+		if err := checkDwmAttribute(tt.attr, tt.size+1); err == nil {
+			t.Errorf("checkDwmAttribute(%s) accepted %d bytes", tt.name, tt.size+1)
+		}
+	}
+
+	for _, attr := range []uint32{0, 18, 21, 32, 39} {
+		if got, want := DwmAttributeName(attr), "DWMWINDOWATTRIBUTE("; len(got) < len(want) || got[:len(want)] != want {
+			t.Errorf("DwmAttributeName(%d) = %q", attr, got)
+		}
+		if err := checkDwmAttribute(attr, 4); err == nil {
+			t.Errorf("checkDwmAttribute accepted unknown attribute %d", attr)
+		}
+	}
+}
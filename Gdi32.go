@@ -1,9 +1,11 @@
 package winapi
 
 import (
+	"syscall"
 	"unsafe"
 
 	"github.com/lxn/win"
+	"github.com/whiteboxsolutions/winapi/gdileak"
 )
 
 const (
@@ -11,26 +13,187 @@ const (
 	FLOODFILLSURFACE
 )
 
+// SetTextAlign flags
+const (
+	TA_NOUPDATECP uint32 = 0
+	TA_UPDATECP   uint32 = 1
+	TA_LEFT       uint32 = 0
+	TA_RIGHT      uint32 = 2
+	TA_CENTER     uint32 = 6
+	TA_TOP        uint32 = 0
+	TA_BOTTOM     uint32 = 8
+	TA_BASELINE   uint32 = 24
+	TA_RTLREADING uint32 = 256
+)
+
+const GDI_ERROR uint32 = 0xFFFFFFFF
+
 func CreateRectRgnIndirect(rect win.RECT) win.HRGN {
-	return win.HRGN(createRectRgnIndirect(uintptr(unsafe.Pointer(&rect.Left))))
+	rgn := createRectRgnIndirect(uintptr(unsafe.Pointer(&rect.Left)))
+	trackGDI(rgn, gdileak.Region)
+	return win.HRGN(rgn)
+}
+
+// ExtCreateRegion creates a region from an RGNDATA structure.
+func ExtCreateRegion(data []byte) (win.HRGN, error) {
+	if len(data) == 0 {
+		return 0, syscall.EINVAL
+	}
+	rgn, err := extCreateRegion(0, uint32(len(data)), &data[0])
+	trackGDI(rgn, gdileak.Region)
+	return win.HRGN(rgn), err
+}
+
+// GetRegionData returns the RGNDATA structure describing hrgn.
+func GetRegionData(hrgn win.HRGN) ([]byte, error) {
+	n, err := getRegionData(uintptr(hrgn), 0, nil)
+	if n == 0 {
+		return nil, err
+	}
+	data := make([]byte, n)
+	if n, err = getRegionData(uintptr(hrgn), n, &data[0]); n == 0 {
+		return nil, err
+	}
+	return data, nil
+}
+
+// GetEnhMetaFileBits returns the contents of the enhanced metafile hemf.
+func GetEnhMetaFileBits(hemf win.HENHMETAFILE) ([]byte, error) {
+	n := getEnhMetaFileBits(uintptr(hemf), 0, nil)
+	if n == 0 {
+		return nil, syscall.EINVAL
+	}
+	data := make([]byte, n)
+	if getEnhMetaFileBits(uintptr(hemf), n, &data[0]) == 0 {
+		return nil, syscall.EINVAL
+	}
+	return data, nil
+}
+
+// SetEnhMetaFileBits creates an enhanced metafile in memory from its
+// contents. The caller deletes it with win.DeleteEnhMetaFile.
+func SetEnhMetaFileBits(data []byte) (win.HENHMETAFILE, error) {
+	if len(data) == 0 {
+		return 0, syscall.EINVAL
+	}
+	hemf, err := setEnhMetaFileBits(uint32(len(data)), &data[0])
+	return win.HENHMETAFILE(hemf), err
 }
 
+// ExtFloodFill fills the area around (x, y) with the current brush;
+// opType is FLOODFILLBORDER or FLOODFILLSURFACE.
 func ExtFloodFill(hdc win.HDC, x int, y int, color uint32, opType uint32) error {
 	return extFloodFill(uintptr(hdc), x, y, color, opType)
 }
 
 func CreateSolidBrush(color uint32) win.HGDIOBJ {
-	return win.HGDIOBJ(createSolidBrush(color))
+	brush := createSolidBrush(color)
+	trackGDI(brush, gdileak.Brush)
+	return win.HGDIOBJ(brush)
 }
 
 func CreatePen(iStyle int, cWidth int, color uint32) win.HPEN {
-	return win.HPEN(createPen(iStyle, cWidth, color))
+	pen := createPen(iStyle, cWidth, color)
+	trackGDI(pen, gdileak.Pen)
+	return win.HPEN(pen)
+}
+
+// PolyDraw draws lines and Bezier curves; aj holds the gdi.PT_* type of each point of apt.
+func PolyDraw(hdc win.HDC, apt []win.POINT, aj []byte) error {
+	if len(apt) == 0 || len(apt) != len(aj) {
+		return syscall.EINVAL
+	}
+	return polyDraw(uintptr(hdc), uintptr(unsafe.Pointer(&apt[0])), uintptr(unsafe.Pointer(&aj[0])), len(apt))
+}
+
+// BeginPath starts recording the drawing calls on hdc into a path instead of drawing them.
+func BeginPath(hdc win.HDC) error {
+	return beginPath(uintptr(hdc))
+}
+
+func EndPath(hdc win.HDC) error {
+	return endPath(uintptr(hdc))
+}
+
+func AbortPath(hdc win.HDC) error {
+	return abortPath(uintptr(hdc))
+}
+
+func StrokePath(hdc win.HDC) error {
+	return strokePath(uintptr(hdc))
+}
+
+func FillPath(hdc win.HDC) error {
+	return fillPath(uintptr(hdc))
+}
+
+func StrokeAndFillPath(hdc win.HDC) error {
+	return strokeAndFillPath(uintptr(hdc))
+}
+
+// PathToRegion converts the closed path of hdc into a region and discards the path.
+func PathToRegion(hdc win.HDC) (win.HRGN, error) {
+	rgn, err := pathToRegion(uintptr(hdc))
+	trackGDI(rgn, gdileak.Region)
+	return win.HRGN(rgn), err
+}
+
+// EnumFontFamiliesEx calls lpProc, a callback created by syscall.NewCallback,
+// for each font matching the face name and character set of lf. It returns
+// the last value returned by lpProc.
+func EnumFontFamiliesEx(hdc win.HDC, lf *win.LOGFONT, lpProc uintptr, lParam uintptr) int32 {
+	return enumFontFamiliesEx(uintptr(hdc), uintptr(unsafe.Pointer(lf)), lpProc, lParam, 0)
+}
+
+// EnumFontFamiliesEx FontType
+const (
+	RASTER_FONTTYPE   uint32 = 0x0001
+	DEVICE_FONTTYPE   uint32 = 0x0002
+	TRUETYPE_FONTTYPE uint32 = 0x0004
+)
+
+// SetPolyFillMode sets gdi.ALTERNATE or gdi.WINDING and returns the
+// previous mode or zero on failure.
+func SetPolyFillMode(hdc win.HDC, mode int) int {
+	return setPolyFillMode(uintptr(hdc), mode)
+}
+
+func Polygon(hdc win.HDC, apt []win.POINT) error {
+	if len(apt) == 0 {
+		return syscall.EINVAL
+	}
+	return polygon(uintptr(hdc), uintptr(unsafe.Pointer(&apt[0])), len(apt))
+}
+
+// PolyBezier draws cubic Bezier curves; apt holds a start point followed
+// by two control points and an end point per curve.
+func PolyBezier(hdc win.HDC, apt []win.POINT) error {
+	if len(apt) == 0 || (len(apt)-1)%3 != 0 {
+		return syscall.EINVAL
+	}
+	return polyBezier(uintptr(hdc), uintptr(unsafe.Pointer(&apt[0])), uint32(len(apt)))
+}
+
+// Arc draws the part of the ellipse bounded by left, top, right and bottom
+// from the radial through (xStart, yStart) counterclockwise to the one
+// through (xEnd, yEnd).
+func Arc(hdc win.HDC, left, top, right, bottom, xStart, yStart, xEnd, yEnd int) error {
+	return arc(uintptr(hdc), left, top, right, bottom, xStart, yStart, xEnd, yEnd)
+}
+
+// SetTextAlign returns the previous alignment or GDI_ERROR.
+func SetTextAlign(hdc win.HDC, align uint32) uint32 {
+	return setTextAlign(uintptr(hdc), align)
 }
 
-func PolyDraw(hdc win.HDC, apt win.POINT, aj byte, cpt int) error {
-	return polyDraw(uintptr(hdc), uintptr(unsafe.Pointer(&apt.X)), uintptr(aj), cpt)
+// SelectClipRgn replaces the clipping region with a copy of hrgn, zero
+// removes clipping. It returns the region type or zero on failure.
+func SelectClipRgn(hdc win.HDC, hrgn win.HRGN) int {
+	return selectClipRgn(uintptr(hdc), uintptr(hrgn))
 }
 
 func CreateDIBSection(hdc win.HDC, pbmi *win.BITMAPINFO, usage uint, ppvBits uintptr, hSection win.HANDLE, offset uint32) win.HBITMAP {
-	return win.HBITMAP(createDIBSection(uintptr(hdc), uintptr(unsafe.Pointer(&pbmi.BmiHeader.BiSize)), usage, ppvBits, uintptr(hSection), offset))
+	hbm := createDIBSection(uintptr(hdc), uintptr(unsafe.Pointer(&pbmi.BmiHeader.BiSize)), usage, ppvBits, uintptr(hSection), offset)
+	trackGDI(hbm, gdileak.Bitmap)
+	return win.HBITMAP(hbm)
 }
@@ -0,0 +1,18 @@
+package winapi
+
+import (
+	"github.com/lxn/win"
+	"golang.org/x/sys/windows"
+)
+
+func GlobalSize(hMem win.HGLOBAL) uintptr {
+	return globalSize(uintptr(hMem))
+}
+
+func VirtualAllocEx(process windows.Handle, address uintptr, size uintptr, allocType uint32, protect uint32) (uintptr, error) {
+	return virtualAllocEx(uintptr(process), address, size, allocType, protect)
+}
+
+func VirtualFreeEx(process windows.Handle, address uintptr, size uintptr, freeType uint32) error {
+	return virtualFreeEx(uintptr(process), address, size, freeType)
+}
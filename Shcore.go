@@ -0,0 +1,24 @@
+package winapi
+
+import (
+	"github.com/lxn/win"
+	"github.com/whiteboxsolutions/go-ole"
+)
+
+// MONITOR_DPI_TYPE
+const (
+	MDT_EFFECTIVE_DPI uint32 = iota
+	MDT_ANGULAR_DPI
+	MDT_RAW_DPI
+)
+
+// GetDpiForMonitor is available from Windows 8.1, earlier versions return an error.
+func GetDpiForMonitor(hmonitor win.HMONITOR, dpiType uint32) (dpiX uint32, dpiY uint32, err error) {
+	if err := procGetDpiForMonitor.Find(); err != nil {
+		return 0, 0, err
+	}
+	if hr := getDpiForMonitor(uintptr(hmonitor), dpiType, &dpiX, &dpiY); hr != win.S_OK {
+		return 0, 0, ole.NewError(uintptr(uint32(hr)))
+	}
+	return dpiX, dpiY, nil
+}
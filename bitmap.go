@@ -0,0 +1,137 @@
+package winapi
+
+import (
+	"image"
+	"image/draw"
+	"unsafe"
+
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi/dib"
+	"github.com/whiteboxsolutions/winapi/gdileak"
+	"golang.org/x/sys/windows"
+)
+
+// DIB is a top-down 32 bpp DIB section. The embedded BGRA image is backed
+// by the memory of the section, so pixels written through draw.Draw are
+// seen by GDI and the other way round. Call win.GdiFlush before reading
+// pixels that GDI has drawn.
+type DIB struct {
+	win.HBITMAP
+	*dib.BGRA
+}
+
+var _ draw.Image = (*DIB)(nil)
+
+// NewDIB creates a width x height DIB section with all pixels transparent black.
+func NewDIB(width, height int) (*DIB, error) {
+	if width <= 0 || height <= 0 {
+		return nil, errors.Errorf("dib section of %dx%d", width, height)
+	}
+	bih := win.BITMAPINFOHEADER{
+		BiSize:        uint32(unsafe.Sizeof(win.BITMAPINFOHEADER{})),
+		BiWidth:       int32(width),
+		BiHeight:      -int32(height),
+		BiPlanes:      1,
+		BiBitCount:    32,
+		BiCompression: win.BI_RGB,
+	}
+	var bits unsafe.Pointer
+	hbm := win.CreateDIBSection(0, &bih, win.DIB_RGB_COLORS, &bits, 0, 0)
+	if hbm == 0 {
+		return nil, errors.Wrap(windows.GetLastError(), "CreateDIBSection")
+	}
+	trackGDI(uintptr(hbm), gdileak.Bitmap)
+	return &DIB{
+		HBITMAP: hbm,
+		BGRA: &dib.BGRA{
+			Pix:    unsafe.Slice((*byte)(bits), width*height*4),
+			Stride: width * 4,
+			Rect:   image.Rect(0, 0, width, height),
+		},
+	}, nil
+}
+
+// Close deletes the bitmap, the pixels must not be used afterwards.
+func (d *DIB) Close() error {
+	if d.HBITMAP == 0 {
+		return nil
+	}
+	if !DeleteObject(win.HGDIOBJ(d.HBITMAP)) {
+		return errors.New("DeleteObject failed")
+	}
+	d.HBITMAP = 0
+	d.BGRA = &dib.BGRA{}
+	return nil
+}
+
+// HBITMAPToImage reads the pixels of a device dependent bitmap or DIB
+// section, which must not be selected into a device context. Bitmaps of
+// up to 8 bpp decode to *image.Paletted. 32 bpp bitmaps hold premultiplied
+// alpha like the DIB sections of NewDIB and decode to *image.RGBA, unless
+// their alpha is all zero, then they are opaque. Other bitmaps decode to
+// *image.NRGBA.
+func HBITMAPToImage(hbm win.HBITMAP) (image.Image, error) {
+	hdc := win.GetDC(0)
+	if hdc == 0 {
+		return nil, errors.Wrap(windows.GetLastError(), "GetDC")
+	}
+	defer win.ReleaseDC(0, hdc)
+
+	// room for the header followed by 3 masks or a 256 color table
+	info := make([]byte, dib.BITMAPINFOHEADER_SIZE+256*4)
+	bi := (*win.BITMAPINFO)(unsafe.Pointer(&info[0]))
+	bi.BmiHeader.BiSize = dib.BITMAPINFOHEADER_SIZE
+
+	// with a zero bit count GetDIBits only fills in the header
+	if win.GetDIBits(hdc, hbm, 0, 0, nil, bi, win.DIB_RGB_COLORS) == 0 {
+		return nil, errors.New("GetDIBits failed")
+	}
+	h, err := dib.ParseHeader(info)
+	if err != nil {
+		return nil, err
+	}
+	bits := make([]byte, h.Stride()*h.Dy())
+	// the second call fills in the masks or the color table
+	if win.GetDIBits(hdc, hbm, 0, uint32(h.Dy()), &bits[0], bi, win.DIB_RGB_COLORS) == 0 {
+		return nil, errors.New("GetDIBits failed")
+	}
+	if h, err = dib.ParseHeader(info); err != nil {
+		return nil, err
+	}
+	h.Premultiplied = h.BitCount == 32
+	palette := dib.DecodePalette(info[h.BitsOffset()-h.PaletteLen()*4 : h.BitsOffset()])
+	return dib.DecodeBits(&h, palette, bits)
+}
+
+// ImageToHBITMAP creates a DIB section holding img in the layout described
+// by o: 1, 4 or 8 bpp with a color table, or 16, 24 and 32 bpp with
+// optional bit field masks. 32 bpp colors are always premultiplied by
+// alpha, as AlphaBlend expects and HBITMAPToImage reads them. The caller
+// deletes the bitmap with DeleteObject.
+func ImageToHBITMAP(img image.Image, o dib.Options) (win.HBITMAP, error) {
+	if o.BitCount == 0 || o.BitCount == 32 {
+		o.Premultiplied = true
+	}
+	b := img.Bounds()
+	if b.Empty() {
+		return 0, errors.Errorf("image of %dx%d", b.Dx(), b.Dy())
+	}
+	h, pal, err := dib.NewHeader(b.Dx(), b.Dy(), img, o)
+	if err != nil {
+		return 0, err
+	}
+	info := append(h.Bytes(), dib.EncodePalette(pal)...)
+
+	var bits unsafe.Pointer
+	hbm := win.CreateDIBSection(0, (*win.BITMAPINFOHEADER)(unsafe.Pointer(&info[0])), win.DIB_RGB_COLORS, &bits, 0, 0)
+	if hbm == 0 {
+		return 0, errors.Wrap(windows.GetLastError(), "CreateDIBSection")
+	}
+	trackGDI(uintptr(hbm), gdileak.Bitmap)
+	if err := dib.PutBits(&h, pal, img, unsafe.Slice((*byte)(bits), h.Stride()*h.Dy())); err != nil {
+		DeleteObject(win.HGDIOBJ(hbm))
+		return 0, err
+	}
+	return hbm, nil
+}
@@ -0,0 +1,155 @@
+// Package capture grabs window and monitor contents with GDI, for systems
+// where Windows.Graphics.Capture is not supported.
+package capture
+
+import (
+	"image"
+	"runtime"
+	"unsafe"
+
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi"
+	"github.com/whiteboxsolutions/winapi/dib"
+	"github.com/whiteboxsolutions/winapi/display"
+	"github.com/whiteboxsolutions/winapi/gdileak"
+	"golang.org/x/sys/windows"
+)
+
+// dpiAware makes the calling thread per-monitor DPI aware while fn runs, so
+// window rectangles and the screen DC are in physical pixels even when the
+// process is DPI unaware and would see virtualized coordinates. The
+// goroutine stays on the thread until the awareness is restored.
+func dpiAware(fn func() error) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	old := winapi.SetThreadDpiAwarenessContext(winapi.DPI_AWARENESS_CONTEXT_PER_MONITOR_AWARE_V2)
+	if old != 0 {
+		defer winapi.SetThreadDpiAwarenessContext(old)
+	}
+	return fn()
+}
+
+// surface is a top-down 32 bpp DIB section selected into a memory DC.
+type surface struct {
+	header dib.Header
+	dc     win.HDC
+	bitmap win.HBITMAP
+	old    win.HGDIOBJ
+	bits   unsafe.Pointer
+}
+
+func newSurface(width, height int32) (*surface, error) {
+	if width <= 0 || height <= 0 {
+		return nil, errors.Errorf("capture: empty area %dx%d", width, height)
+	}
+	s := &surface{}
+	s.header, _, _ = dib.NewHeader(int(width), int(height), nil, dib.Options{TopDown: true})
+
+	s.dc = win.CreateCompatibleDC(0)
+	if s.dc == 0 {
+		return nil, errors.Wrap(windows.GetLastError(), "CreateCompatibleDC")
+	}
+	bih := win.BITMAPINFOHEADER{
+		BiSize:        uint32(unsafe.Sizeof(win.BITMAPINFOHEADER{})),
+		BiWidth:       s.header.Width,
+		BiHeight:      s.header.Height,
+		BiPlanes:      1,
+		BiBitCount:    32,
+		BiCompression: win.BI_RGB,
+	}
+	s.bitmap = win.CreateDIBSection(s.dc, &bih, win.DIB_RGB_COLORS, &s.bits, 0, 0)
+	if s.bitmap == 0 {
+		win.DeleteDC(s.dc)
+		return nil, errors.Wrap(windows.GetLastError(), "CreateDIBSection")
+	}
+	winapi.TrackGDIObject(uintptr(s.bitmap), gdileak.Bitmap)
+	s.old = win.SelectObject(s.dc, win.HGDIOBJ(s.bitmap))
+	return s, nil
+}
+
+func (s *surface) image() (*image.RGBA, error) {
+	win.GdiFlush()
+	size := s.header.Stride() * s.header.Dy()
+	return dib.ToRGBA(&s.header, unsafe.Slice((*byte)(s.bits), size))
+}
+
+func (s *surface) close() {
+	win.SelectObject(s.dc, s.old)
+	winapi.DeleteObject(win.HGDIOBJ(s.bitmap))
+	win.DeleteDC(s.dc)
+}
+
+// Window captures hwnd including its non-client area but without the
+// invisible resize borders. The image bounds start at (0, 0). PrintWindow
+// with PW_RENDERFULLCONTENT also renders DirectComposition content and
+// works for covered windows; when it fails the window area is copied from
+// the screen.
+func Window(hwnd win.HWND) (img *image.RGBA, err error) {
+	err = dpiAware(func() error {
+		var r win.RECT
+		if !win.GetWindowRect(hwnd, &r) {
+			return errors.Wrap(windows.GetLastError(), "GetWindowRect")
+		}
+		s, err := newSurface(r.Right-r.Left, r.Bottom-r.Top)
+		if err != nil {
+			return err
+		}
+		defer s.close()
+
+		if winapi.PrintWindow(hwnd, s.dc, winapi.PW_RENDERFULLCONTENT) != nil {
+			if err := blitScreen(s, display.Rect(r)); err != nil {
+				return err
+			}
+		}
+		if img, err = s.image(); err != nil {
+			return err
+		}
+		if frame, err := winapi.DwmExtendedFrameBounds(hwnd); err == nil {
+			crop := image.Rect(int(frame.Left-r.Left), int(frame.Top-r.Top), int(frame.Right-r.Left), int(frame.Bottom-r.Top))
+			if crop = crop.Intersect(img.Bounds()); !crop.Empty() {
+				sub := img.SubImage(crop).(*image.RGBA)
+				img = &image.RGBA{Pix: sub.Pix, Stride: sub.Stride, Rect: image.Rect(0, 0, crop.Dx(), crop.Dy())}
+			}
+		}
+		return nil
+	})
+	return img, err
+}
+
+// Rect captures the screen area r in virtual desktop coordinates.
+func Rect(r display.Rect) (img *image.RGBA, err error) {
+	err = dpiAware(func() error {
+		s, err := newSurface(r.Dx(), r.Dy())
+		if err != nil {
+			return err
+		}
+		defer s.close()
+
+		if err := blitScreen(s, r); err != nil {
+			return err
+		}
+		img, err = s.image()
+		return err
+	})
+	return img, err
+}
+
+// Monitor captures the whole area of m.
+func Monitor(m display.Monitor) (*image.RGBA, error) {
+	return Rect(m.Bounds)
+}
+
+func blitScreen(s *surface, r display.Rect) error {
+	screen := win.GetDC(0)
+	if screen == 0 {
+		return errors.Wrap(windows.GetLastError(), "GetDC")
+	}
+	defer win.ReleaseDC(0, screen)
+
+	// CAPTUREBLT includes layered windows
+	if !win.BitBlt(s.dc, 0, 0, r.Dx(), r.Dy(), screen, r.Left, r.Top, win.SRCCOPY|win.CAPTUREBLT) {
+		return errors.Wrap(windows.GetLastError(), "BitBlt")
+	}
+	return nil
+}
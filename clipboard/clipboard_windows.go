@@ -0,0 +1,281 @@
+package clipboard
+
+import (
+	"context"
+	"image"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi"
+	"github.com/whiteboxsolutions/winapi/dib"
+	"golang.org/x/sys/windows"
+)
+
+var ErrUnavailable = errors.New("clipboard: format not available")
+
+// another process may hold the clipboard open for a moment
+const (
+	openRetries = 10
+	openDelay   = 10 * time.Millisecond
+)
+
+var owner struct {
+	sync.Mutex
+	w *winapi.MessageWindow
+}
+
+// ownerWindow returns the window that owns the clipboard after Write,
+// SetClipboardData fails while the clipboard has no owner.
+func ownerWindow() (*winapi.MessageWindow, error) {
+	owner.Lock()
+	defer owner.Unlock()
+	if owner.w == nil {
+		w, err := winapi.NewMessageWindow("winapi.clipboard.owner", "", false, nil)
+		if err != nil {
+			return nil, err
+		}
+		owner.w = w
+	}
+	return owner.w, nil
+}
+
+// withClipboard opens the clipboard, runs fn and closes it again. All of
+// it runs on the locked thread of the owner window, since the clipboard
+// is opened by and must be closed from the same thread.
+func withClipboard(fn func() error) error {
+	w, err := ownerWindow()
+	if err != nil {
+		return err
+	}
+	if doErr := w.Do(func() {
+		for i := 0; ; i++ {
+			if win.OpenClipboard(w.HWND) {
+				break
+			}
+			if i == openRetries {
+				err = errors.Wrap(windows.GetLastError(), "OpenClipboard")
+				return
+			}
+			time.Sleep(openDelay)
+		}
+		defer win.CloseClipboard()
+		err = fn()
+	}); doErr != nil {
+		return doErr
+	}
+	return err
+}
+
+// RegisterFormat returns the id of the registered format name.
+func RegisterFormat(name string) (uint32, error) {
+	f, err := winapi.RegisterClipboardFormat(name)
+	return f, errors.Wrapf(err, "RegisterClipboardFormat %q", name)
+}
+
+// FormatName returns the name of a registered format.
+func FormatName(format uint32) string {
+	return winapi.GetClipboardFormatName(format)
+}
+
+// IsAvailable reports whether the clipboard holds data in format.
+func IsAvailable(format uint32) bool {
+	return win.IsClipboardFormatAvailable(format)
+}
+
+// SequenceNumber changes every time the clipboard content changes.
+func SequenceNumber() uint32 {
+	return winapi.GetClipboardSequenceNumber()
+}
+
+// Formats lists the formats of the current clipboard content.
+func Formats() ([]uint32, error) {
+	var formats []uint32
+	err := withClipboard(func() error {
+		for f := winapi.EnumClipboardFormats(0); f != 0; f = winapi.EnumClipboardFormats(f) {
+			formats = append(formats, f)
+		}
+		return nil
+	})
+	return formats, err
+}
+
+// Read returns a copy of the clipboard data in format.
+func Read(format uint32) ([]byte, error) {
+	var data []byte
+	err := withClipboard(func() error {
+		h := win.GetClipboardData(format)
+		if h == 0 {
+			return errors.Wrapf(ErrUnavailable, "format %d", format)
+		}
+		p := win.GlobalLock(win.HGLOBAL(h))
+		if p == nil {
+			return errors.Wrap(windows.GetLastError(), "GlobalLock")
+		}
+		defer win.GlobalUnlock(win.HGLOBAL(h))
+
+		size := winapi.GlobalSize(win.HGLOBAL(h))
+		data = make([]byte, size)
+		copy(data, unsafe.Slice((*byte)(p), size))
+		return nil
+	})
+	return data, err
+}
+
+// ReadNamed returns the clipboard data of a registered format.
+func ReadNamed(name string) ([]byte, error) {
+	f, err := RegisterFormat(name)
+	if err != nil {
+		return nil, err
+	}
+	return Read(f)
+}
+
+// Write replaces the clipboard content with items, one per format.
+func Write(items ...Item) error {
+	// resolve registered names without touching the caller's items
+	items = append([]Item(nil), items...)
+	for i := range items {
+		if items[i].Name == "" {
+			continue
+		}
+		f, err := RegisterFormat(items[i].Name)
+		if err != nil {
+			return err
+		}
+		items[i].Format = f
+	}
+
+	return withClipboard(func() error {
+		if !win.EmptyClipboard() {
+			return errors.Wrap(windows.GetLastError(), "EmptyClipboard")
+		}
+		for _, item := range items {
+			if err := setData(item.Format, item.Data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func setData(format uint32, data []byte) error {
+	// GlobalLock fails on a zero sized block
+	size := len(data)
+	if size == 0 {
+		size = 1
+	}
+	h := win.GlobalAlloc(win.GMEM_MOVEABLE|win.GMEM_ZEROINIT, uintptr(size))
+	if h == 0 {
+		return errors.Wrap(windows.GetLastError(), "GlobalAlloc")
+	}
+	p := win.GlobalLock(h)
+	if p == nil {
+		win.GlobalFree(h)
+		return errors.Wrap(windows.GetLastError(), "GlobalLock")
+	}
+	copy(unsafe.Slice((*byte)(p), size), data)
+	win.GlobalUnlock(h)
+
+	// the system owns the memory once SetClipboardData succeeds
+	if win.SetClipboardData(format, win.HANDLE(h)) == 0 {
+		win.GlobalFree(h)
+		return errors.Wrapf(windows.GetLastError(), "SetClipboardData %d", format)
+	}
+	return nil
+}
+
+func ReadText() (string, error) {
+	b, err := Read(CF_UNICODETEXT)
+	if err != nil {
+		return "", err
+	}
+	return DecodeText(b), nil
+}
+
+func WriteText(s string) error {
+	return Write(TextItem(s))
+}
+
+// ReadImage reads CF_DIBV5, which keeps alpha, or else CF_DIB.
+func ReadImage() (image.Image, error) {
+	format := CF_DIBV5
+	if !IsAvailable(format) {
+		format = CF_DIB
+	}
+	b, err := Read(format)
+	if err != nil {
+		return nil, err
+	}
+	return dib.Decode(b)
+}
+
+func WriteImage(img image.Image) error {
+	return Write(ImageItem(img))
+}
+
+func ReadFiles() ([]string, error) {
+	b, err := Read(CF_HDROP)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeFiles(b)
+}
+
+func WriteFiles(paths []string) error {
+	return Write(FilesItem(paths))
+}
+
+func ReadHTML() (HTML, error) {
+	b, err := ReadNamed(HTMLFormatName)
+	if err != nil {
+		return HTML{}, err
+	}
+	return DecodeHTML(b)
+}
+
+// WriteHTML writes fragment as HTML Format together with a plain text alternative.
+func WriteHTML(fragment, sourceURL, text string) error {
+	return Write(HTMLItem(fragment, sourceURL), TextItem(text))
+}
+
+// Watch sends on the returned channel whenever the clipboard content changes,
+// until ctx is done. Notifications are coalesced when the receiver lags behind.
+func Watch(ctx context.Context) (<-chan struct{}, error) {
+	ch := make(chan struct{}, 1)
+	w, err := winapi.NewMessageWindow("winapi.clipboard", "", false, func(hwnd win.HWND, msg uint32, wParam, lParam uintptr) (uintptr, bool) {
+		if msg != win.WM_CLIPBOARDUPDATE {
+			return 0, false
+		}
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+		return 0, true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if doErr := w.Do(func() {
+		err = winapi.AddClipboardFormatListener(w.HWND)
+	}); doErr != nil {
+		err = doErr
+	}
+	if err != nil {
+		w.Close()
+		return nil, errors.Wrap(err, "AddClipboardFormatListener")
+	}
+
+	go func() {
+		<-ctx.Done()
+		w.Do(func() {
+			winapi.RemoveClipboardFormatListener(w.HWND)
+		})
+		w.Close()
+		close(ch)
+	}()
+	return ch, nil
+}
@@ -0,0 +1,136 @@
+// Package clipboard reads and writes the Windows clipboard.
+package clipboard
+
+import (
+	"encoding/binary"
+	"image"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi/dib"
+)
+
+// Standard Clipboard Formats
+// https://learn.microsoft.com/en-us/windows/win32/dataxchg/standard-clipboard-formats
+const (
+	CF_TEXT        uint32 = 1
+	CF_BITMAP      uint32 = 2
+	CF_DIB         uint32 = 8
+	CF_UNICODETEXT uint32 = 13
+	CF_HDROP       uint32 = 15
+	CF_DIBV5       uint32 = 17
+)
+
+// HTMLFormatName is the registered name of the HTML clipboard format.
+const HTMLFormatName = "HTML Format"
+
+// Item is one representation of the clipboard content. Registered formats
+// may be given by Name instead of Format, they are registered on write.
+type Item struct {
+	Format uint32
+	Name   string
+	Data   []byte
+}
+
+func TextItem(s string) Item {
+	return Item{Format: CF_UNICODETEXT, Data: EncodeText(s)}
+}
+
+// ImageItem stores img as CF_DIBV5 to keep its alpha channel, Windows
+// synthesizes CF_DIB and CF_BITMAP from it.
+func ImageItem(img image.Image) Item {
+	return Item{Format: CF_DIBV5, Data: dib.EncodeV5(img)}
+}
+
+func FilesItem(paths []string) Item {
+	return Item{Format: CF_HDROP, Data: EncodeFiles(paths)}
+}
+
+func HTMLItem(fragment, sourceURL string) Item {
+	return Item{Name: HTMLFormatName, Data: EncodeHTML(fragment, sourceURL)}
+}
+
+// EncodeText encodes s as null terminated UTF-16 for CF_UNICODETEXT.
+func EncodeText(s string) []byte {
+	u := utf16.Encode([]rune(s))
+	b := make([]byte, (len(u)+1)*2)
+	for i, c := range u {
+		binary.LittleEndian.PutUint16(b[i*2:], c)
+	}
+	return b
+}
+
+// DecodeText decodes CF_UNICODETEXT data up to the first null character.
+func DecodeText(b []byte) string {
+	u := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		c := binary.LittleEndian.Uint16(b[i:])
+		if c == 0 {
+			break
+		}
+		u = append(u, c)
+	}
+	return string(utf16.Decode(u))
+}
+
+// DROPFILES structure (shlobj_core.h)
+// https://learn.microsoft.com/en-us/windows/win32/api/shlobj_core/ns-shlobj_core-dropfiles
+const sizeofDROPFILES = 20
+
+// EncodeFiles encodes paths as a DROPFILES structure for CF_HDROP.
+func EncodeFiles(paths []string) []byte {
+	b := make([]byte, sizeofDROPFILES)
+	binary.LittleEndian.PutUint32(b[0:], sizeofDROPFILES) // pFiles
+	binary.LittleEndian.PutUint32(b[16:], 1)              // fWide
+	for _, p := range paths {
+		for _, c := range utf16.Encode([]rune(p)) {
+			b = append(b, byte(c), byte(c>>8))
+		}
+		b = append(b, 0, 0)
+	}
+	// the list ends with an empty string
+	return append(b, 0, 0)
+}
+
+// DecodeFiles decodes the file list of a DROPFILES structure.
+func DecodeFiles(b []byte) ([]string, error) {
+	if len(b) < sizeofDROPFILES {
+		return nil, errors.New("clipboard: short DROPFILES")
+	}
+	offset := int(binary.LittleEndian.Uint32(b[0:]))
+	wide := binary.LittleEndian.Uint32(b[16:]) != 0
+	if offset < sizeofDROPFILES || offset > len(b) {
+		return nil, errors.Errorf("clipboard: DROPFILES file list at %d", offset)
+	}
+
+	var paths []string
+	if !wide {
+		start := offset
+		for i := offset; i < len(b); i++ {
+			if b[i] != 0 {
+				continue
+			}
+			if i == start {
+				break
+			}
+			paths = append(paths, string(b[start:i]))
+			start = i + 1
+		}
+		return paths, nil
+	}
+
+	var cur []uint16
+	for i := offset; i+1 < len(b); i += 2 {
+		c := binary.LittleEndian.Uint16(b[i:])
+		if c != 0 {
+			cur = append(cur, c)
+			continue
+		}
+		if len(cur) == 0 {
+			break
+		}
+		paths = append(paths, string(utf16.Decode(cur)))
+		cur = cur[:0]
+	}
+	return paths, nil
+}
@@ -0,0 +1,93 @@
+package clipboard
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func TestText(t *testing.T) {
+	for _, s := range []string{"", "hello", "äöü\r\nzwei Zeilen", "emoji 😀 needs a surrogate pair"} {
+		b := EncodeText(s)
+		if len(b)%2 != 0 || !bytes.HasSuffix(b, []byte{0, 0}) {
+			t.Errorf("EncodeText(%q) = % x is not null terminated UTF-16", s, b)
+		}
+		if got := DecodeText(b); got != s {
+			t.Errorf("DecodeText(EncodeText(%q)) = %q", s, got)
+		}
+	}
+	if got := EncodeText("A😀"); !bytes.Equal(got, []byte{'A', 0, 0x3D, 0xD8, 0x00, 0xDE, 0, 0}) {
+		t.Errorf("EncodeText = % x", got)
+	}
+
+	tests := []struct {
+		b    []byte
+		want string
+	}{
+		{nil, ""},
+		// text stops at the first null, odd trailing bytes are ignored
+		{[]byte{'a', 0, 0, 0, 'b', 0}, "a"},
+		{[]byte{'a', 0, 'b'}, "a"},
+		{[]byte{'a', 0, 'b', 0}, "ab"},
+	}
+	for _, tt := range tests {
+		if got := DecodeText(tt.b); got != tt.want {
+			t.Errorf("DecodeText(% x) = %q, want %q", tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestFiles(t *testing.T) {
+	paths := []string{`C:\Users\me\a.txt`, `D:\Bilder\Über.png`, `\\server\share\x`}
+	b := EncodeFiles(paths)
+	le := binary.LittleEndian
+	if le.Uint32(b) != sizeofDROPFILES || le.Uint32(b[16:]) != 1 {
+		t.Errorf("DROPFILES header % x", b[:sizeofDROPFILES])
+	}
+	// the list ends with two nulls
+	if !bytes.HasSuffix(b, []byte{'x', 0, 0, 0, 0, 0}) {
+		t.Errorf("file list ends with % x", b[len(b)-6:])
+	}
+	got, err := DecodeFiles(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, paths) {
+		t.Errorf("DecodeFiles = %q, want %q", got, paths)
+	}
+
+	if got, err := DecodeFiles(EncodeFiles(nil)); err != nil || len(got) != 0 {
+		t.Errorf("DecodeFiles of an empty list = %q, %v", got, err)
+	}
+}
+
+func TestDecodeFilesANSI(t *testing.T) {
+	// fWide is zero and the list starts behind 4 bytes of padding
+	b := make([]byte, sizeofDROPFILES+4)
+	binary.LittleEndian.PutUint32(b, sizeofDROPFILES+4)
+	b = append(b, "C:\\a\x00C:\\b\x00\x00junk"...)
+	got, err := DecodeFiles(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{`C:\a`, `C:\b`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeFiles = %q, want %q", got, want)
+	}
+}
+
+func TestDecodeFilesErrors(t *testing.T) {
+	beyond := EncodeFiles([]string{"x"})
+	binary.LittleEndian.PutUint32(beyond, uint32(len(beyond)+1))
+	inside := EncodeFiles([]string{"x"})
+	binary.LittleEndian.PutUint32(inside, 8)
+	for name, b := range map[string][]byte{
+		"short":              make([]byte, sizeofDROPFILES-1),
+		"list beyond data":   beyond,
+		"list inside header": inside,
+	} {
+		if _, err := DecodeFiles(b); err == nil {
+			t.Errorf("%s: DecodeFiles succeeded", name)
+		}
+	}
+}
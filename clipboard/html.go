@@ -0,0 +1,124 @@
+package clipboard
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// HTML Clipboard Format
+// https://learn.microsoft.com/en-us/windows/win32/dataxchg/html-clipboard-format
+
+const (
+	startFragmentComment = "<!--StartFragment-->"
+	endFragmentComment   = "<!--EndFragment-->"
+)
+
+// HTML is decoded "HTML Format" clipboard data.
+type HTML struct {
+	Version   string
+	SourceURL string
+
+	// Document is the context between StartHTML and EndHTML,
+	// Fragment the selected part between StartFragment and EndFragment.
+	Document string
+	Fragment string
+}
+
+// EncodeHTML wraps fragment in a document and prefixes the description
+// header whose byte offsets locate the document and the fragment.
+func EncodeHTML(fragment, sourceURL string) []byte {
+	// every offset is printed with ten digits, so the header length is
+	// known up front; only the offsets go through fmt, the URL may hold
+	// percent signs
+	const offsets = "Version:0.9\r\n" +
+		"StartHTML:%010d\r\n" +
+		"EndHTML:%010d\r\n" +
+		"StartFragment:%010d\r\n" +
+		"EndFragment:%010d\r\n"
+	var source string
+	if sourceURL != "" {
+		source = "SourceURL:" + sourceURL + "\r\n"
+	}
+	prefix := "<html><body>\r\n" + startFragmentComment
+	suffix := endFragmentComment + "\r\n</body></html>"
+
+	startHTML := len(fmt.Sprintf(offsets, 0, 0, 0, 0)) + len(source)
+	startFragment := startHTML + len(prefix)
+	endFragment := startFragment + len(fragment)
+	endHTML := endFragment + len(suffix)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, offsets, startHTML, endHTML, startFragment, endFragment)
+	b.WriteString(source)
+	b.WriteString(prefix)
+	b.WriteString(fragment)
+	b.WriteString(suffix)
+	return b.Bytes()
+}
+
+// DecodeHTML parses "HTML Format" data. Producers that miscalculate the
+// offsets are common, so the fragment comments are used when the offsets
+// do not point inside the data.
+func DecodeHTML(b []byte) (HTML, error) {
+	var h HTML
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+
+	offsets := map[string]int{"StartHTML": -1, "EndHTML": -1, "StartFragment": -1, "EndFragment": -1}
+	rest := string(b)
+	for rest != "" && !strings.HasPrefix(rest, "<") {
+		line := rest
+		if i := strings.IndexAny(rest, "\r\n"); i >= 0 {
+			line = rest[:i]
+			rest = strings.TrimLeft(rest[i:], "\r\n")
+		} else {
+			rest = ""
+		}
+		i := strings.IndexByte(line, ':')
+		if i < 0 {
+			break
+		}
+		key, value := line[:i], strings.TrimSpace(line[i+1:])
+		switch key {
+		case "Version":
+			h.Version = value
+		case "SourceURL":
+			h.SourceURL = value
+		case "StartHTML", "EndHTML", "StartFragment", "EndFragment":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return h, errors.Wrapf(err, "clipboard: HTML Format %s", key)
+			}
+			offsets[key] = n
+		}
+	}
+	if h.Version == "" {
+		return h, errors.New("clipboard: HTML Format without Version")
+	}
+
+	valid := func(start, end int) bool {
+		return start >= 0 && start <= end && end <= len(b)
+	}
+	if start, end := offsets["StartHTML"], offsets["EndHTML"]; valid(start, end) {
+		h.Document = string(b[start:end])
+	} else {
+		h.Document = rest
+	}
+
+	if start, end := offsets["StartFragment"], offsets["EndFragment"]; valid(start, end) {
+		h.Fragment = string(b[start:end])
+		return h, nil
+	}
+	start := strings.Index(h.Document, startFragmentComment)
+	end := strings.LastIndex(h.Document, endFragmentComment)
+	if start < 0 || end < start+len(startFragmentComment) {
+		return h, errors.New("clipboard: HTML Format without fragment")
+	}
+	h.Fragment = h.Document[start+len(startFragmentComment) : end]
+	return h, nil
+}
@@ -0,0 +1,124 @@
+package clipboard
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// headerOffset returns the value of the offset key in the header of b.
+func headerOffset(t *testing.T, b []byte, key string) int {
+	t.Helper()
+	m := regexp.MustCompile(`(?m)^` + key + `:(\d{10})\r$`).FindSubmatch(b)
+	if m == nil {
+		t.Fatalf("no %s in\n%s", key, b)
+	}
+	n, _ := strconv.Atoi(string(m[1]))
+	return n
+}
+
+func TestEncodeHTMLOffsets(t *testing.T) {
+	for _, tt := range []struct {
+		fragment, sourceURL string
+	}{
+		{"<b>x</b>", ""},
+		{"<b>x</b>", "https://example.com/page"},
+		// percent signs are not format verbs
+		{"<b>x</b>", "https://e.com/a%20b?q=100%25&d=%d%s"},
+		{"", ""},
+		{"<p>äöü – 日本語</p>", "file:///C:/x%20y.html"},
+	} {
+		b := EncodeHTML(tt.fragment, tt.sourceURL)
+		startHTML := headerOffset(t, b, "StartHTML")
+		endHTML := headerOffset(t, b, "EndHTML")
+		startFragment := headerOffset(t, b, "StartFragment")
+		endFragment := headerOffset(t, b, "EndFragment")
+
+		if endHTML != len(b) {
+			t.Errorf("%q: EndHTML %d, want the length %d", tt.fragment, endHTML, len(b))
+		}
+		if doc := string(b[startHTML:endHTML]); !strings.HasPrefix(doc, "<html>") || !strings.HasSuffix(doc, "</html>") {
+			t.Errorf("%q: document %q", tt.fragment, doc)
+		}
+		if got := string(b[startFragment:endFragment]); got != tt.fragment {
+			t.Errorf("%q: fragment at the offsets is %q", tt.fragment, got)
+		}
+		header := string(b[:startHTML])
+		if strings.Contains(header, "%!") {
+			t.Errorf("%q: header went through fmt:\n%s", tt.sourceURL, header)
+		}
+		if tt.sourceURL == "" {
+			if strings.Contains(header, "SourceURL") {
+				t.Errorf("header with an empty SourceURL:\n%s", header)
+			}
+		} else if !strings.Contains(header, "\r\nSourceURL:"+tt.sourceURL+"\r\n") {
+			t.Errorf("header lacks SourceURL %q:\n%s", tt.sourceURL, header)
+		}
+
+		h, err := DecodeHTML(b)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.fragment, err)
+		}
+		want := HTML{
+			Version:   "0.9",
+			SourceURL: tt.sourceURL,
+			Document:  string(b[startHTML:endHTML]),
+			Fragment:  tt.fragment,
+		}
+		if h != want {
+			t.Errorf("DecodeHTML = %+v, want %+v", h, want)
+		}
+	}
+}
+
+func TestDecodeHTMLFallback(t *testing.T) {
+	doc := "<html><body>\r\n<!--StartFragment--><i>hi</i><!--EndFragment-->\r\n</body></html>"
+	tests := []struct {
+		name string
+		data string
+		want HTML
+	}{
+		{
+			"offsets beyond the data",
+			"Version:1.0\r\nStartHTML:0000000090\r\nEndHTML:0000099999\r\n" +
+				"StartFragment:0000099000\r\nEndFragment:0000099999\r\n" + doc,
+			HTML{Version: "1.0", Document: doc, Fragment: "<i>hi</i>"},
+		},
+		{
+			"unset offsets",
+			"Version:0.9\r\nStartHTML:-1\r\nEndHTML:-1\r\nStartFragment:-1\r\nEndFragment:-1\r\n" +
+				"SourceURL:about:blank\r\n" + doc,
+			HTML{Version: "0.9", SourceURL: "about:blank", Document: doc, Fragment: "<i>hi</i>"},
+		},
+		{
+			"no offsets, LF line ends and a NUL terminator",
+			"Version:0.9\n" + doc + "\x00garbage",
+			HTML{Version: "0.9", Document: doc, Fragment: "<i>hi</i>"},
+		},
+	}
+	for _, tt := range tests {
+		h, err := DecodeHTML([]byte(tt.data))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if h != tt.want {
+			t.Errorf("%s: DecodeHTML = %+v, want %+v", tt.name, h, tt.want)
+		}
+	}
+}
+
+func TestDecodeHTMLErrors(t *testing.T) {
+	for name, data := range map[string]string{
+		"empty":           "",
+		"no version":      "StartHTML:0000000000\r\n<html></html>",
+		"bad offset":      "Version:0.9\r\nStartHTML:12x\r\n<html></html>",
+		"no fragment":     "Version:0.9\r\n<html><body>x</body></html>",
+		"reversed marker": "Version:0.9\r\n<!--EndFragment--><!--StartFragment-->",
+	} {
+		if _, err := DecodeHTML([]byte(data)); err == nil {
+			t.Errorf("%s: DecodeHTML succeeded", name)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package controls
+
+import (
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi"
+	"golang.org/x/sys/windows"
+)
+
+// Timeout bounds how long a message to a control may take.
+var Timeout = 5 * time.Second
+
+var ErrNotFound = errors.New("controls: control not found")
+
+// Class names of the controls.
+const (
+	ButtonClass   = "Button"
+	EditClass     = "Edit"
+	ComboBoxClass = "ComboBox"
+	ListBoxClass  = "ListBox"
+	ListViewClass = "SysListView32"
+	TreeViewClass = "SysTreeView32"
+	TabClass      = "SysTabControl32"
+)
+
+func send(hwnd win.HWND, msg uint32, wParam, lParam uintptr) (uintptr, error) {
+	res, err := winapi.SendMessageTimeout(hwnd, msg, wParam, lParam, winapi.SMTO_ABORTIFHUNG, uint32(Timeout/time.Millisecond))
+	if err != nil {
+		return 0, errors.Wrapf(err, "message %#x to hwnd %#x", msg, hwnd)
+	}
+	return res, nil
+}
+
+// notify sends the WM_COMMAND notification code the control would send its
+// parent after a change made by the user.
+func notify(hwnd win.HWND, code uint16) error {
+	id := uint16(win.GetWindowLong(hwnd, win.GWL_ID))
+	_, err := send(win.GetParent(hwnd), win.WM_COMMAND, uintptr(id)|uintptr(code)<<16, uintptr(hwnd))
+	return err
+}
+
+var enumChildren struct {
+	sync.Mutex
+	hwnds []win.HWND
+}
+
+var enumChildrenProc = syscall.NewCallback(func(hwnd win.HWND, lParam uintptr) uintptr {
+	enumChildren.hwnds = append(enumChildren.hwnds, hwnd)
+	return 1
+})
+
+// Find returns the index-th descendant of parent with class, in z-order.
+// Class names compare ignoring case, an empty text matches any window text.
+func Find(parent win.HWND, class, text string, index int) (win.HWND, error) {
+	enumChildren.Lock()
+	enumChildren.hwnds = nil
+	windows.EnumChildWindows(windows.HWND(parent), enumChildrenProc, nil)
+	hwnds := enumChildren.hwnds
+	enumChildren.Unlock()
+
+	for _, hwnd := range hwnds {
+		if c, err := winapi.ClassName(hwnd); err != nil || !strings.EqualFold(c, class) {
+			continue
+		}
+		if text != "" {
+			if t, err := winapi.WindowText(hwnd); err != nil || t != text {
+				continue
+			}
+		}
+		if index == 0 {
+			return hwnd, nil
+		}
+		index--
+	}
+	return 0, errors.Wrapf(ErrNotFound, "%s %q", class, text)
+}
+
+// Button is a push button, check box or radio button.
+type Button struct {
+	HWND win.HWND
+}
+
+// FindButton returns the button of parent labeled text.
+func FindButton(parent win.HWND, text string) (Button, error) {
+	hwnd, err := Find(parent, ButtonClass, text, 0)
+	return Button{hwnd}, err
+}
+
+// Click posts a click and returns without waiting, since the click handler
+// may open a modal dialog.
+func (b Button) Click() error {
+	if win.PostMessage(b.HWND, win.BM_CLICK, 0, 0) == 0 {
+		return errors.Wrap(windows.GetLastError(), "PostMessage")
+	}
+	return nil
+}
+
+// Check returns the BST_* state of a check box or radio button.
+func (b Button) Check() (uint32, error) {
+	res, err := send(b.HWND, win.BM_GETCHECK, 0, 0)
+	return uint32(res), err
+}
+
+// Checked reports whether a check box or radio button is checked.
+func (b Button) Checked() (bool, error) {
+	state, err := b.Check()
+	return state == win.BST_CHECKED, err
+}
+
+// SetCheck sets the BST_* state without notifying the dialog, Click to
+// toggle it like the user does.
+func (b Button) SetCheck(state uint32) error {
+	_, err := send(b.HWND, win.BM_SETCHECK, uintptr(state), 0)
+	return err
+}
+
+// Edit is a single or multi line edit control.
+type Edit struct {
+	HWND win.HWND
+}
+
+// FindEdit returns the index-th edit control of parent.
+func FindEdit(parent win.HWND, index int) (Edit, error) {
+	hwnd, err := Find(parent, EditClass, "", index)
+	return Edit{hwnd}, err
+}
+
+func (e Edit) Text() (string, error) {
+	return winapi.WindowTextTimeout(e.HWND, Timeout)
+}
+
+// SetText replaces the contents and notifies the dialog with EN_CHANGE.
+func (e Edit) SetText(text string) error {
+	if _, err := send(e.HWND, win.WM_SETTEXT, 0, uintptr(unsafe.Pointer(winapi.MustUTF16PtrFromString(text)))); err != nil {
+		return err
+	}
+	return notify(e.HWND, win.EN_CHANGE)
+}
+
+// Selection returns the selected character range, start == end is the
+// caret position.
+func (e Edit) Selection() (start, end int, err error) {
+	var s, t uint32
+	if _, err := send(e.HWND, win.EM_GETSEL, uintptr(unsafe.Pointer(&s)), uintptr(unsafe.Pointer(&t))); err != nil {
+		return 0, 0, err
+	}
+	return int(s), int(t), nil
+}
+
+// Select selects the characters from start to end, -1 as start deselects.
+func (e Edit) Select(start, end int) error {
+	_, err := send(e.HWND, win.EM_SETSEL, uintptr(start), uintptr(end))
+	return err
+}
+
+// ReplaceSelection replaces the selection with text, inserting at the caret
+// when nothing is selected.
+func (e Edit) ReplaceSelection(text string) error {
+	_, err := send(e.HWND, win.EM_REPLACESEL, 1, uintptr(unsafe.Pointer(winapi.MustUTF16PtrFromString(text))))
+	return err
+}
+
+// listMessages are the messages that differ between combo and list boxes.
+type listMessages struct {
+	count, getText, getTextLen, getCurSel, setCurSel, findExact uint32
+	selChange                                                   uint16
+}
+
+var (
+	comboBoxMessages = listMessages{win.CB_GETCOUNT, win.CB_GETLBTEXT, win.CB_GETLBTEXTLEN, win.CB_GETCURSEL, win.CB_SETCURSEL, win.CB_FINDSTRINGEXACT, win.CBN_SELCHANGE}
+	listBoxMessages  = listMessages{win.LB_GETCOUNT, win.LB_GETTEXT, win.LB_GETTEXTLEN, win.LB_GETCURSEL, win.LB_SETCURSEL, win.LB_FINDSTRINGEXACT, win.LBN_SELCHANGE}
+)
+
+// itemList implements the items of combo and list boxes.
+type itemList struct {
+	hwnd win.HWND
+	msgs *listMessages
+}
+
+const listErr = ^uintptr(0) // CB_ERR, LB_ERR
+
+func (l itemList) count() (int, error) {
+	n, err := send(l.hwnd, l.msgs.count, 0, 0)
+	if err == nil && n == listErr {
+		err = errors.New("controls: cannot count items")
+	}
+	return int(n), err
+}
+
+func (l itemList) item(i int) (string, error) {
+	n, err := send(l.hwnd, l.msgs.getTextLen, uintptr(i), 0)
+	if err != nil {
+		return "", err
+	}
+	if n == listErr {
+		return "", errors.Errorf("controls: no item %d", i)
+	}
+	buf := make([]uint16, n+1)
+	if _, err := send(l.hwnd, l.msgs.getText, uintptr(i), uintptr(unsafe.Pointer(&buf[0]))); err != nil {
+		return "", err
+	}
+	return windows.UTF16ToString(buf), nil
+}
+
+func (l itemList) items() ([]string, error) {
+	n, err := l.count()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]string, n)
+	for i := range res {
+		if res[i], err = l.item(i); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (l itemList) selected() (int, error) {
+	i, err := send(l.hwnd, l.msgs.getCurSel, 0, 0)
+	if i == listErr {
+		return -1, err
+	}
+	return int(i), err
+}
+
+func (l itemList) selectIndex(i int) error {
+	if res, err := send(l.hwnd, l.msgs.setCurSel, uintptr(i), 0); err != nil {
+		return err
+	} else if res == listErr && i >= 0 {
+		return errors.Errorf("controls: no item %d", i)
+	}
+	return notify(l.hwnd, l.msgs.selChange)
+}
+
+func (l itemList) selectText(text string) error {
+	i, err := send(l.hwnd, l.msgs.findExact, listErr, uintptr(unsafe.Pointer(winapi.MustUTF16PtrFromString(text))))
+	if err != nil {
+		return err
+	}
+	if i == listErr {
+		return errors.Wrapf(ErrNotFound, "item %q", text)
+	}
+	return l.selectIndex(int(i))
+}
+
+// ComboBox is a drop down list.
+type ComboBox struct {
+	HWND win.HWND
+}
+
+// FindComboBox returns the index-th combo box of parent.
+func FindComboBox(parent win.HWND, index int) (ComboBox, error) {
+	hwnd, err := Find(parent, ComboBoxClass, "", index)
+	return ComboBox{hwnd}, err
+}
+
+func (c ComboBox) list() itemList {
+	return itemList{c.HWND, &comboBoxMessages}
+}
+
+func (c ComboBox) Count() (int, error) {
+	return c.list().count()
+}
+
+func (c ComboBox) Item(i int) (string, error) {
+	return c.list().item(i)
+}
+
+func (c ComboBox) Items() ([]string, error) {
+	return c.list().items()
+}
+
+// Selected returns the index of the selected item, -1 for none.
+func (c ComboBox) Selected() (int, error) {
+	return c.list().selected()
+}
+
+// Select selects item i and notifies the dialog with CBN_SELCHANGE.
+func (c ComboBox) Select(i int) error {
+	return c.list().selectIndex(i)
+}
+
+// SelectText selects the item equal to text, ignoring case.
+func (c ComboBox) SelectText(text string) error {
+	return c.list().selectText(text)
+}
+
+// ListBox is a single selection list box.
+type ListBox struct {
+	HWND win.HWND
+}
+
+// FindListBox returns the index-th list box of parent.
+func FindListBox(parent win.HWND, index int) (ListBox, error) {
+	hwnd, err := Find(parent, ListBoxClass, "", index)
+	return ListBox{hwnd}, err
+}
+
+func (l ListBox) list() itemList {
+	return itemList{l.HWND, &listBoxMessages}
+}
+
+func (l ListBox) Count() (int, error) {
+	return l.list().count()
+}
+
+func (l ListBox) Item(i int) (string, error) {
+	return l.list().item(i)
+}
+
+func (l ListBox) Items() ([]string, error) {
+	return l.list().items()
+}
+
+// Selected returns the index of the selected item, -1 for none.
+func (l ListBox) Selected() (int, error) {
+	return l.list().selected()
+}
+
+// Select selects item i and notifies the dialog with LBN_SELCHANGE.
+func (l ListBox) Select(i int) error {
+	return l.list().selectIndex(i)
+}
+
+// SelectText selects the item equal to text, ignoring case.
+func (l ListBox) SelectText(text string) error {
+	return l.list().selectText(text)
+}
@@ -0,0 +1,201 @@
+// Package controls drives the classic Win32 common controls of other
+// processes: buttons, edits, combo and list boxes, list views, tree views
+// and tabs.
+//
+// Messages of the newer common controls take pointers to structures that
+// Windows does not marshal between processes, so those structures are
+// written into memory allocated in the target. The target may have another
+// pointer size than the caller, the layouts here encode them for either.
+package controls
+
+import (
+	"encoding/binary"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// RemotePtr is a pointer-sized field holding an address in the target
+// process.
+type RemotePtr uint64
+
+var remotePtrType = reflect.TypeOf(RemotePtr(0))
+
+// fieldLayout returns the offset of each field of the struct type t and the
+// padded size of the struct for targets with ptrSize byte pointers. Fields
+// are int32, uint32 or RemotePtr.
+func fieldLayout(t reflect.Type, ptrSize int) (offsets []int, size int) {
+	offsets = make([]int, t.NumField())
+	align := 4
+	for i := 0; i < t.NumField(); i++ {
+		n := 4
+		if t.Field(i).Type == remotePtrType {
+			n = ptrSize
+		}
+		if n > align {
+			align = n
+		}
+		size = (size + n - 1) / n * n
+		offsets[i] = size
+		size += n
+	}
+	size = (size + align - 1) / align * align
+	return offsets, size
+}
+
+// Sizeof returns the size of the structure v points to in a target with
+// ptrSize byte pointers.
+func Sizeof(v interface{}, ptrSize int) int {
+	_, size := fieldLayout(reflect.TypeOf(v).Elem(), ptrSize)
+	return size
+}
+
+// Marshal encodes the structure v points to for a target with ptrSize byte
+// pointers.
+func Marshal(v interface{}, ptrSize int) ([]byte, error) {
+	if ptrSize != 4 && ptrSize != 8 {
+		return nil, errors.Errorf("controls: pointer size %d", ptrSize)
+	}
+	rv := reflect.ValueOf(v).Elem()
+	offsets, size := fieldLayout(rv.Type(), ptrSize)
+	b := make([]byte, size)
+	for i, off := range offsets {
+		f := rv.Field(i)
+		switch {
+		case f.Type() == remotePtrType && ptrSize == 8:
+			binary.LittleEndian.PutUint64(b[off:], f.Uint())
+		case f.Type() == remotePtrType:
+			binary.LittleEndian.PutUint32(b[off:], uint32(f.Uint()))
+		case f.Kind() == reflect.Int32:
+			binary.LittleEndian.PutUint32(b[off:], uint32(f.Int()))
+		default:
+			binary.LittleEndian.PutUint32(b[off:], uint32(f.Uint()))
+		}
+	}
+	return b, nil
+}
+
+// Unmarshal decodes b, encoded for a target with ptrSize byte pointers,
+// into the structure v points to.
+func Unmarshal(b []byte, v interface{}, ptrSize int) error {
+	if ptrSize != 4 && ptrSize != 8 {
+		return errors.Errorf("controls: pointer size %d", ptrSize)
+	}
+	rv := reflect.ValueOf(v).Elem()
+	offsets, size := fieldLayout(rv.Type(), ptrSize)
+	if len(b) < size {
+		return errors.Errorf("controls: %s needs %d bytes, got %d", rv.Type().Name(), size, len(b))
+	}
+	for i, off := range offsets {
+		f := rv.Field(i)
+		switch {
+		case f.Type() == remotePtrType && ptrSize == 8:
+			f.SetUint(binary.LittleEndian.Uint64(b[off:]))
+		case f.Type() == remotePtrType:
+			f.SetUint(uint64(binary.LittleEndian.Uint32(b[off:])))
+		case f.Kind() == reflect.Int32:
+			f.SetInt(int64(int32(binary.LittleEndian.Uint32(b[off:]))))
+		default:
+			f.SetUint(uint64(binary.LittleEndian.Uint32(b[off:])))
+		}
+	}
+	return nil
+}
+
+// LVITEM is LVITEMW.
+type LVITEM struct {
+	Mask      uint32
+	Item      int32
+	SubItem   int32
+	State     uint32
+	StateMask uint32
+	Text      RemotePtr
+	TextMax   int32
+	Image     int32
+	Param     RemotePtr
+	Indent    int32
+	GroupId   int32
+	Columns   uint32
+	PuColumns RemotePtr
+	PiColFmt  RemotePtr
+	Group     int32
+}
+
+// LVCOLUMN is LVCOLUMNW.
+type LVCOLUMN struct {
+	Mask      uint32
+	Fmt       int32
+	Cx        int32
+	Text      RemotePtr
+	TextMax   int32
+	SubItem   int32
+	Image     int32
+	Order     int32
+	CxMin     int32
+	CxDefault int32
+	CxIdeal   int32
+}
+
+// TVITEM is TVITEMW.
+type TVITEM struct {
+	Mask          uint32
+	Item          RemotePtr
+	State         uint32
+	StateMask     uint32
+	Text          RemotePtr
+	TextMax       int32
+	Image         int32
+	SelectedImage int32
+	Children      int32
+	Param         RemotePtr
+}
+
+// TCITEM is TCITEMW.
+type TCITEM struct {
+	Mask      uint32
+	State     uint32
+	StateMask uint32
+	Text      RemotePtr
+	TextMax   int32
+	Image     int32
+	Param     RemotePtr
+}
+
+// Message ids and flags of the controls.
+const (
+	LVM_FIRST         = 0x1000
+	LVM_GETITEMCOUNT  = LVM_FIRST + 4
+	LVM_GETNEXTITEM   = LVM_FIRST + 12
+	LVM_GETHEADER     = LVM_FIRST + 31
+	LVM_SETITEMSTATE  = LVM_FIRST + 43
+	LVM_ENSUREVISIBLE = LVM_FIRST + 19
+	LVM_GETCOLUMNW    = LVM_FIRST + 95
+	LVM_GETITEMTEXTW  = LVM_FIRST + 115
+	HDM_FIRST         = 0x1200
+	HDM_GETITEMCOUNT  = HDM_FIRST + 0
+	LVCF_TEXT         = 0x0004
+	LVIF_STATE        = 0x0008
+	LVIS_FOCUSED      = 0x0001
+	LVIS_SELECTED     = 0x0002
+	LVNI_ALL          = 0x0000
+	LVNI_SELECTED     = 0x0002
+	TV_FIRST          = 0x1100
+	TVM_EXPAND        = TV_FIRST + 2
+	TVM_GETNEXTITEM   = TV_FIRST + 10
+	TVM_SELECTITEM    = TV_FIRST + 11
+	TVM_GETITEMW      = TV_FIRST + 62
+	TVGN_ROOT         = 0x0000
+	TVGN_NEXT         = 0x0001
+	TVGN_CHILD        = 0x0004
+	TVGN_CARET        = 0x0009
+	TVE_COLLAPSE      = 0x0001
+	TVE_EXPAND        = 0x0002
+	TVIF_TEXT         = 0x0001
+	TVIF_HANDLE       = 0x0010
+	TCM_FIRST         = 0x1300
+	TCM_GETITEMCOUNT  = TCM_FIRST + 4
+	TCM_GETCURSEL     = TCM_FIRST + 11
+	TCM_SETCURFOCUS   = TCM_FIRST + 48
+	TCM_GETITEMW      = TCM_FIRST + 60
+	TCIF_TEXT         = 0x0001
+)
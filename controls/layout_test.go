@@ -0,0 +1,103 @@
+package controls
+
+import (
+	"reflect"
+	"testing"
+)
+
+// sizes and offsets of commctrl.h for Windows Vista and later
+func TestFieldLayout(t *testing.T) {
+	tests := []struct {
+		v       interface{}
+		ptrSize int
+		size    int
+		offsets []int
+	}{
+		{&LVITEM{}, 4, 60, []int{0, 4, 8, 12, 16, 20, 24, 28, 32, 36, 40, 44, 48, 52, 56}},
+		{&LVITEM{}, 8, 88, []int{0, 4, 8, 12, 16, 24, 32, 36, 40, 48, 52, 56, 64, 72, 80}},
+		{&LVCOLUMN{}, 4, 44, []int{0, 4, 8, 12, 16, 20, 24, 28, 32, 36, 40}},
+		{&LVCOLUMN{}, 8, 56, []int{0, 4, 8, 16, 24, 28, 32, 36, 40, 44, 48}},
+		{&TVITEM{}, 4, 40, []int{0, 4, 8, 12, 16, 20, 24, 28, 32, 36}},
+		{&TVITEM{}, 8, 56, []int{0, 8, 16, 20, 24, 32, 36, 40, 44, 48}},
+		{&TCITEM{}, 4, 28, []int{0, 4, 8, 12, 16, 20, 24}},
+		{&TCITEM{}, 8, 40, []int{0, 4, 8, 16, 24, 28, 32}},
+	}
+	for _, tt := range tests {
+		typ := reflect.TypeOf(tt.v).Elem()
+		offsets, size := fieldLayout(typ, tt.ptrSize)
+		if size != tt.size {
+			t.Errorf("%s with %d byte pointers is %d bytes, want %d", typ.Name(), tt.ptrSize, size, tt.size)
+		}
+		if got := Sizeof(tt.v, tt.ptrSize); got != tt.size {
+			t.Errorf("Sizeof(%s, %d) = %d, want %d", typ.Name(), tt.ptrSize, got, tt.size)
+		}
+		if !reflect.DeepEqual(offsets, tt.offsets) {
+			t.Errorf("%s with %d byte pointers has offsets %v, want %v", typ.Name(), tt.ptrSize, offsets, tt.offsets)
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	in := TVITEM{
+		Mask:      TVIF_TEXT | TVIF_HANDLE,
+		Item:      0x1122334455667788,
+		State:     3,
+		StateMask: 0xFFFFFFFF,
+		Text:      0x7FF0_0000_1000,
+		TextMax:   260,
+		Image:     -1,
+		Children:  1,
+		Param:     42,
+	}
+
+	b, err := Marshal(&in, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 56 {
+		t.Fatalf("%d bytes, want 56", len(b))
+	}
+	if b[8] != 0x88 || b[15] != 0x11 {
+		t.Errorf("hItem encoded as % x", b[8:16])
+	}
+	if b[36] != 0xFF || b[39] != 0xFF {
+		t.Errorf("iImage -1 encoded as % x", b[36:40])
+	}
+	var out TVITEM
+	if err := Unmarshal(b, &out, 8); err != nil {
+		t.Fatal(err)
+	}
+	if out != in {
+		t.Errorf("64-bit round trip = %+v, want %+v", out, in)
+	}
+
+	// 32-bit targets keep the low half of pointers
+	b, err = Marshal(&in, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 40 {
+		t.Fatalf("%d bytes, want 40", len(b))
+	}
+	out = TVITEM{}
+	if err := Unmarshal(b, &out, 4); err != nil {
+		t.Fatal(err)
+	}
+	want := in
+	want.Item, want.Text = 0x55667788, 0x1000
+	if out != want {
+		t.Errorf("32-bit round trip = %+v, want %+v", out, want)
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	if _, err := Marshal(&TCITEM{}, 2); err == nil {
+		t.Error("Marshal accepted 2 byte pointers")
+	}
+	if err := Unmarshal(make([]byte, 40), &TCITEM{}, 16); err == nil {
+		t.Error("Unmarshal accepted 16 byte pointers")
+	}
+	if err := Unmarshal(make([]byte, 39), &TCITEM{}, 8); err == nil {
+		t.Error("Unmarshal accepted a short buffer")
+	}
+}
@@ -0,0 +1,138 @@
+package controls
+
+import (
+	"unsafe"
+
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi"
+	"golang.org/x/sys/windows"
+)
+
+// remoteProcess is the process owning a control, with memory access.
+type remoteProcess struct {
+	handle  windows.Handle
+	ptrSize int
+}
+
+func openRemote(hwnd win.HWND) (*remoteProcess, error) {
+	var pid uint32
+	win.GetWindowThreadProcessId(hwnd, &pid)
+	if pid == 0 {
+		return nil, errors.Wrapf(winapi.ErrInvalidWindow, "hwnd %#x", hwnd)
+	}
+	h, err := windows.OpenProcess(windows.PROCESS_VM_OPERATION|windows.PROCESS_VM_READ|windows.PROCESS_VM_WRITE|windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return nil, errors.Wrap(err, "OpenProcess")
+	}
+	p := &remoteProcess{handle: h, ptrSize: targetPointerSize(h)}
+	return p, nil
+}
+
+// targetPointerSize tells 32-bit processes running under WOW64 apart from
+// native ones.
+func targetPointerSize(h windows.Handle) int {
+	var wow64 bool
+	if windows.IsWow64Process(h, &wow64) == nil && wow64 {
+		return 4
+	}
+	if unsafe.Sizeof(uintptr(0)) == 4 {
+		// a 32-bit caller on a 64-bit system sees native processes as 64-bit
+		var self bool
+		if windows.IsWow64Process(windows.CurrentProcess(), &self) == nil && self {
+			return 8
+		}
+		return 4
+	}
+	return 8
+}
+
+func (p *remoteProcess) close() {
+	windows.CloseHandle(p.handle)
+}
+
+func (p *remoteProcess) alloc(size int) (uintptr, error) {
+	addr, err := winapi.VirtualAllocEx(p.handle, 0, uintptr(size), windows.MEM_COMMIT|windows.MEM_RESERVE, windows.PAGE_READWRITE)
+	if err != nil {
+		return 0, errors.Wrap(err, "VirtualAllocEx")
+	}
+	return addr, nil
+}
+
+func (p *remoteProcess) free(addr uintptr) {
+	winapi.VirtualFreeEx(p.handle, addr, 0, windows.MEM_RELEASE)
+}
+
+func (p *remoteProcess) write(addr uintptr, b []byte) error {
+	if err := windows.WriteProcessMemory(p.handle, addr, &b[0], uintptr(len(b)), nil); err != nil {
+		return errors.Wrap(err, "WriteProcessMemory")
+	}
+	return nil
+}
+
+func (p *remoteProcess) read(addr uintptr, b []byte) error {
+	if err := windows.ReadProcessMemory(p.handle, addr, &b[0], uintptr(len(b)), nil); err != nil {
+		return errors.Wrap(err, "ReadProcessMemory")
+	}
+	return nil
+}
+
+// textField points a structure at a text buffer of the exchange.
+type textField struct {
+	ptr *RemotePtr
+	max *int32
+	len int // in UTF-16 units, zero for no buffer
+}
+
+// exchange writes v followed by an optional text buffer into the target,
+// sends msg with the address of v as lParam and reads both back.
+func (p *remoteProcess) exchange(hwnd win.HWND, msg uint32, wParam uintptr, v interface{}, text textField) (uintptr, string, error) {
+	size := Sizeof(v, p.ptrSize)
+	addr, err := p.alloc(size + 2*text.len)
+	if err != nil {
+		return 0, "", err
+	}
+	defer p.free(addr)
+
+	if text.len > 0 {
+		*text.ptr = RemotePtr(addr) + RemotePtr(size)
+		*text.max = int32(text.len)
+	}
+	b, err := Marshal(v, p.ptrSize)
+	if err != nil {
+		return 0, "", err
+	}
+	if err := p.write(addr, b); err != nil {
+		return 0, "", err
+	}
+	res, err := send(hwnd, msg, wParam, addr)
+	if err != nil {
+		return 0, "", err
+	}
+
+	buf := make([]byte, size+2*text.len)
+	if err := p.read(addr, buf); err != nil {
+		return 0, "", err
+	}
+	if err := Unmarshal(buf, v, p.ptrSize); err != nil {
+		return 0, "", err
+	}
+	if text.len == 0 {
+		return res, "", nil
+	}
+	u := make([]uint16, text.len)
+	for i := range u {
+		u[i] = uint16(buf[size+2*i]) | uint16(buf[size+2*i+1])<<8
+	}
+	return res, windows.UTF16ToString(u), nil
+}
+
+// readItemText reads text with a buffer grown until the text fits.
+func readItemText(read func(max int) (string, error)) (string, error) {
+	for max := 256; ; max *= 4 {
+		s, err := read(max)
+		if err != nil || len(windows.StringToUTF16(s)) < max || max >= 1<<16 {
+			return s, err
+		}
+	}
+}
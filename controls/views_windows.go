@@ -0,0 +1,320 @@
+package controls
+
+import (
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+)
+
+// ListView is a report view list, rows and columns are read through memory
+// in the owning process.
+type ListView struct {
+	HWND win.HWND
+}
+
+// FindListView returns the index-th list view of parent.
+func FindListView(parent win.HWND, index int) (ListView, error) {
+	hwnd, err := Find(parent, ListViewClass, "", index)
+	return ListView{hwnd}, err
+}
+
+func (l ListView) RowCount() (int, error) {
+	n, err := send(l.HWND, LVM_GETITEMCOUNT, 0, 0)
+	return int(n), err
+}
+
+// ColumnCount returns the number of columns of the header, 0 outside
+// report view.
+func (l ListView) ColumnCount() (int, error) {
+	header, err := send(l.HWND, LVM_GETHEADER, 0, 0)
+	if err != nil || header == 0 {
+		return 0, err
+	}
+	n, err := send(win.HWND(header), HDM_GETITEMCOUNT, 0, 0)
+	return int(int32(n)), err
+}
+
+// Columns returns the column titles.
+func (l ListView) Columns() ([]string, error) {
+	n, err := l.ColumnCount()
+	if err != nil {
+		return nil, err
+	}
+	p, err := openRemote(l.HWND)
+	if err != nil {
+		return nil, err
+	}
+	defer p.close()
+
+	res := make([]string, n)
+	for i := range res {
+		res[i], err = readItemText(func(max int) (string, error) {
+			col := LVCOLUMN{Mask: LVCF_TEXT}
+			ok, text, err := p.exchange(l.HWND, LVM_GETCOLUMNW, uintptr(i), &col, textField{&col.Text, &col.TextMax, max})
+			if err == nil && ok == 0 {
+				err = errors.Errorf("controls: no column %d", i)
+			}
+			return text, err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (l ListView) cell(p *remoteProcess, row, col int) (string, error) {
+	return readItemText(func(max int) (string, error) {
+		item := LVITEM{SubItem: int32(col)}
+		_, text, err := p.exchange(l.HWND, LVM_GETITEMTEXTW, uintptr(row), &item, textField{&item.Text, &item.TextMax, max})
+		return text, err
+	})
+}
+
+// Cell returns the text of row in column col, column 0 is the item itself.
+func (l ListView) Cell(row, col int) (string, error) {
+	p, err := openRemote(l.HWND)
+	if err != nil {
+		return "", err
+	}
+	defer p.close()
+	return l.cell(p, row, col)
+}
+
+// Rows returns the text of every cell. Outside report view each row has
+// the item text only.
+func (l ListView) Rows() ([][]string, error) {
+	rows, err := l.RowCount()
+	if err != nil {
+		return nil, err
+	}
+	cols, err := l.ColumnCount()
+	if err != nil {
+		return nil, err
+	}
+	if cols == 0 {
+		cols = 1
+	}
+	p, err := openRemote(l.HWND)
+	if err != nil {
+		return nil, err
+	}
+	defer p.close()
+
+	res := make([][]string, rows)
+	for r := range res {
+		res[r] = make([]string, cols)
+		for c := range res[r] {
+			if res[r][c], err = l.cell(p, r, c); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return res, nil
+}
+
+// Selected returns the indexes of the selected rows.
+func (l ListView) Selected() ([]int, error) {
+	var res []int
+	for i := -1; ; {
+		next, err := send(l.HWND, LVM_GETNEXTITEM, uintptr(i), LVNI_SELECTED)
+		if err != nil {
+			return nil, err
+		}
+		if int32(next) < 0 {
+			return res, nil
+		}
+		i = int(int32(next))
+		res = append(res, i)
+	}
+}
+
+// Select selects and focuses row, deselecting the others when only is set.
+func (l ListView) Select(row int, only bool) error {
+	p, err := openRemote(l.HWND)
+	if err != nil {
+		return err
+	}
+	defer p.close()
+
+	if only {
+		none := LVITEM{StateMask: LVIS_SELECTED}
+		// row -1 applies the state to every item
+		if _, _, err := p.exchange(l.HWND, LVM_SETITEMSTATE, ^uintptr(0), &none, textField{}); err != nil {
+			return err
+		}
+	}
+	item := LVITEM{State: LVIS_SELECTED | LVIS_FOCUSED, StateMask: LVIS_SELECTED | LVIS_FOCUSED}
+	if _, _, err := p.exchange(l.HWND, LVM_SETITEMSTATE, uintptr(row), &item, textField{}); err != nil {
+		return err
+	}
+	_, err = send(l.HWND, LVM_ENSUREVISIBLE, uintptr(row), 0)
+	return err
+}
+
+// TreeView is a tree of items.
+type TreeView struct {
+	HWND win.HWND
+}
+
+// TreeItem is an item of a TreeView, Handle is its HTREEITEM in the owning
+// process.
+type TreeItem struct {
+	Tree   TreeView
+	Handle uintptr
+}
+
+// FindTreeView returns the index-th tree view of parent.
+func FindTreeView(parent win.HWND, index int) (TreeView, error) {
+	hwnd, err := Find(parent, TreeViewClass, "", index)
+	return TreeView{hwnd}, err
+}
+
+func (t TreeView) next(item uintptr, flag uintptr) ([]TreeItem, error) {
+	var res []TreeItem
+	for {
+		h, err := send(t.HWND, TVM_GETNEXTITEM, flag, item)
+		if err != nil {
+			return nil, err
+		}
+		if h == 0 {
+			return res, nil
+		}
+		res = append(res, TreeItem{t, h})
+		item, flag = h, TVGN_NEXT
+	}
+}
+
+// Roots returns the top level items.
+func (t TreeView) Roots() ([]TreeItem, error) {
+	return t.next(0, TVGN_ROOT)
+}
+
+// Selected returns the selected item, ok is false when there is none.
+func (t TreeView) Selected() (item TreeItem, ok bool, err error) {
+	h, err := send(t.HWND, TVM_GETNEXTITEM, TVGN_CARET, 0)
+	return TreeItem{t, h}, h != 0 && err == nil, err
+}
+
+// Walk visits the items depth first, returning false from fn skips the
+// children of an item. Children of collapsed items that were never expanded
+// may not exist yet.
+func (t TreeView) Walk(fn func(item TreeItem, depth int) bool) error {
+	roots, err := t.Roots()
+	if err != nil {
+		return err
+	}
+	return walkTree(roots, 0, fn)
+}
+
+func walkTree(items []TreeItem, depth int, fn func(item TreeItem, depth int) bool) error {
+	for _, it := range items {
+		if !fn(it, depth) {
+			continue
+		}
+		children, err := it.Children()
+		if err != nil {
+			return err
+		}
+		if err := walkTree(children, depth+1, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Children returns the direct children of the item.
+func (i TreeItem) Children() ([]TreeItem, error) {
+	return i.Tree.next(i.Handle, TVGN_CHILD)
+}
+
+// Text returns the label of the item.
+func (i TreeItem) Text() (string, error) {
+	p, err := openRemote(i.Tree.HWND)
+	if err != nil {
+		return "", err
+	}
+	defer p.close()
+
+	return readItemText(func(max int) (string, error) {
+		item := TVITEM{Mask: TVIF_TEXT | TVIF_HANDLE, Item: RemotePtr(i.Handle)}
+		ok, text, err := p.exchange(i.Tree.HWND, TVM_GETITEMW, 0, &item, textField{&item.Text, &item.TextMax, max})
+		if err == nil && ok == 0 {
+			err = errors.Errorf("controls: no tree item %#x", i.Handle)
+		}
+		return text, err
+	})
+}
+
+// Select makes the item the selection.
+func (i TreeItem) Select() error {
+	_, err := send(i.Tree.HWND, TVM_SELECTITEM, TVGN_CARET, i.Handle)
+	return err
+}
+
+// Expand expands or collapses the item.
+func (i TreeItem) Expand(expand bool) error {
+	action := uintptr(TVE_COLLAPSE)
+	if expand {
+		action = TVE_EXPAND
+	}
+	_, err := send(i.Tree.HWND, TVM_EXPAND, action, i.Handle)
+	return err
+}
+
+// Tab is a tab control.
+type Tab struct {
+	HWND win.HWND
+}
+
+// FindTab returns the index-th tab control of parent.
+func FindTab(parent win.HWND, index int) (Tab, error) {
+	hwnd, err := Find(parent, TabClass, "", index)
+	return Tab{hwnd}, err
+}
+
+func (t Tab) Count() (int, error) {
+	n, err := send(t.HWND, TCM_GETITEMCOUNT, 0, 0)
+	return int(n), err
+}
+
+// Titles returns the labels of the tabs.
+func (t Tab) Titles() ([]string, error) {
+	n, err := t.Count()
+	if err != nil {
+		return nil, err
+	}
+	p, err := openRemote(t.HWND)
+	if err != nil {
+		return nil, err
+	}
+	defer p.close()
+
+	res := make([]string, n)
+	for i := range res {
+		res[i], err = readItemText(func(max int) (string, error) {
+			item := TCITEM{Mask: TCIF_TEXT}
+			ok, text, err := p.exchange(t.HWND, TCM_GETITEMW, uintptr(i), &item, textField{&item.Text, &item.TextMax, max})
+			if err == nil && ok == 0 {
+				err = errors.Errorf("controls: no tab %d", i)
+			}
+			return text, err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// Selected returns the index of the selected tab, -1 for none.
+func (t Tab) Selected() (int, error) {
+	i, err := send(t.HWND, TCM_GETCURSEL, 0, 0)
+	return int(int32(i)), err
+}
+
+// Select switches to tab i. Unlike TCM_SETCURSEL, TCM_SETCURFOCUS sends the
+// notifications that make the dialog show the page.
+func (t Tab) Select(i int) error {
+	_, err := send(t.HWND, TCM_SETCURFOCUS, uintptr(i), 0)
+	return err
+}
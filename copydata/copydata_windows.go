@@ -0,0 +1,200 @@
+package copydata
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi"
+	"golang.org/x/sys/windows"
+)
+
+// DefaultTimeout bounds how long a send waits for the receiving window
+// procedure to accept the message.
+const DefaultTimeout = 5 * time.Second
+
+// Handler serves a message. For requests the returned data or error is sent
+// back to the requesting Receiver; for one-way messages it is discarded.
+// Handlers run on their own goroutine.
+type Handler func(m *Message) ([]byte, error)
+
+// Receiver owns a message-only window that accepts WM_COPYDATA messages. It
+// also acts as the return address for requests it sends.
+type Receiver struct {
+	Name    string
+	Timeout time.Duration
+
+	handler Handler
+	window  *winapi.MessageWindow
+	pending Pending
+}
+
+// Find returns the window of the receiver called name.
+func Find(name string) (win.HWND, error) {
+	hwnd := winapi.FindWindowEx(win.HWND_MESSAGE, 0, winapi.MustUTF16PtrFromString(ClassName), winapi.MustUTF16PtrFromString(name))
+	if hwnd == 0 {
+		return 0, errors.Wrap(ErrNotFound, name)
+	}
+	return hwnd, nil
+}
+
+// listenMu and the session wide listenMutex make looking for a receiver of
+// the same name and creating the window one step. The kernel mutex belongs
+// to a thread, listenMu keeps goroutines sharing a thread apart.
+var listenMu sync.Mutex
+
+const listenMutex = `Local\winapi.copydata.listen`
+
+func lockListen() (unlock func(), err error) {
+	listenMu.Lock()
+	runtime.LockOSThread()
+	unlock = func() {
+		runtime.UnlockOSThread()
+		listenMu.Unlock()
+	}
+	// an existing mutex is opened with ERROR_ALREADY_EXISTS
+	h, err := windows.CreateMutex(nil, false, windows.StringToUTF16Ptr(listenMutex))
+	if h == 0 {
+		unlock()
+		return nil, errors.Wrap(err, "CreateMutex")
+	}
+	// WAIT_ABANDONED still hands over ownership
+	if _, err := windows.WaitForSingleObject(h, windows.INFINITE); err != nil {
+		windows.CloseHandle(h)
+		unlock()
+		return nil, errors.Wrap(err, "WaitForSingleObject")
+	}
+	return func() {
+		windows.ReleaseMutex(h)
+		windows.CloseHandle(h)
+		unlock()
+	}, nil
+}
+
+// Listen creates a receiver called name that passes incoming messages to h.
+// An empty name creates an anonymous receiver that can only be used to send
+// requests. Names must be unique within the desktop session.
+func Listen(name string, h Handler) (*Receiver, error) {
+	if name != "" {
+		unlock, err := lockListen()
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+		if _, err := Find(name); err == nil {
+			return nil, errors.Errorf("copydata: receiver %q already exists", name)
+		}
+	}
+	r := &Receiver{Name: name, Timeout: DefaultTimeout, handler: h}
+	w, err := winapi.NewMessageWindow(ClassName, name, false, r.wndProc)
+	if err != nil {
+		return nil, err
+	}
+	r.window = w
+	return r, nil
+}
+
+// HWND returns the receiver window.
+func (r *Receiver) HWND() win.HWND {
+	return r.window.HWND
+}
+
+// Close destroys the receiver window. Outstanding requests fail with their
+// context.
+func (r *Receiver) Close() error {
+	return r.window.Close()
+}
+
+func (r *Receiver) wndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) (uintptr, bool) {
+	if msg != win.WM_COPYDATA {
+		return 0, false
+	}
+	cds := *(**winapi.COPYDATASTRUCT)(unsafe.Pointer(&lParam))
+	var b []byte
+	if cds.CbData > 0 {
+		b = unsafe.Slice(*(**byte)(unsafe.Pointer(&cds.LpData)), cds.CbData)
+	}
+	m, err := Decode(uint32(cds.DwData), b)
+	if err != nil {
+		return 0, true
+	}
+	if r.pending.Resolve(m) || m.ReplyTo != 0 {
+		return 1, true
+	}
+	if r.handler == nil {
+		return 0, true
+	}
+
+	from := win.HWND(wParam)
+	go func() {
+		data, err := r.handler(m)
+		if m.ID == 0 || from == 0 {
+			return
+		}
+		// the requester gave up when its window is gone
+		send(from, r.window.HWND, m.Reply(data, err), r.timeout())
+	}()
+	return 1, true
+}
+
+func (r *Receiver) timeout() time.Duration {
+	if r.Timeout <= 0 {
+		return DefaultTimeout
+	}
+	return r.Timeout
+}
+
+// Request sends data to the receiver called to and waits for the response
+// of its handler.
+func (r *Receiver) Request(ctx context.Context, to string, typ uint32, data []byte) ([]byte, error) {
+	hwnd, err := Find(to)
+	if err != nil {
+		return nil, err
+	}
+	id, ch := r.pending.Add()
+	defer r.pending.Cancel(id)
+
+	if err := send(hwnd, r.window.HWND, &Message{Type: typ, ID: id, Data: data}, r.timeout()); err != nil {
+		return nil, err
+	}
+	select {
+	case m := <-ch:
+		return m.Result()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-r.window.Done():
+		return nil, errors.New("copydata: receiver closed")
+	}
+}
+
+// Send delivers a one-way message to the receiver called to.
+func Send(to string, typ uint32, data []byte) error {
+	hwnd, err := Find(to)
+	if err != nil {
+		return err
+	}
+	return send(hwnd, 0, &Message{Type: typ, Data: data}, DefaultTimeout)
+}
+
+func send(to, from win.HWND, m *Message, timeout time.Duration) error {
+	b := m.Encode()
+	cds := winapi.COPYDATASTRUCT{
+		DwData: uintptr(m.Type),
+		CbData: uint32(len(b)),
+		LpData: uintptr(unsafe.Pointer(&b[0])),
+	}
+	res, err := winapi.SendMessageTimeout(to, win.WM_COPYDATA, uintptr(from), uintptr(unsafe.Pointer(&cds)),
+		winapi.SMTO_ABORTIFHUNG|winapi.SMTO_ERRORONEXIT, uint32(timeout/time.Millisecond))
+	runtime.KeepAlive(b)
+	if err != nil {
+		return errors.Wrap(err, "SendMessageTimeout")
+	}
+	if res == 0 {
+		return errors.New("copydata: message rejected")
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+// Package copydata exchanges messages between processes of the same desktop
+// session with WM_COPYDATA.
+//
+// A Receiver owns a message-only window whose title is its name, so senders
+// find it with FindWindowEx and no further setup. The type tag of a Message
+// travels in COPYDATASTRUCT.dwData and the payload carries a small envelope
+// used to correlate responses with requests.
+package copydata
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ClassName is the window class of every receiver window.
+const ClassName = "winapi.copydata"
+
+// envelope layout, little endian:
+//
+//	magic   uint32
+//	id      uint32 // non-zero when the sender waits for a response
+//	replyTo uint32 // id of the request this message answers
+//	flags   uint32
+//	data    []byte
+const (
+	envelopeMagic = 0x31444357 // "WCD1"
+	envelopeSize  = 16
+
+	flagError = 1
+
+	// unnamedError stands in for an empty error message, which could not
+	// be told apart from a success
+	unnamedError = "error"
+)
+
+var (
+	ErrFormat   = errors.New("copydata: malformed message")
+	ErrNotFound = errors.New("copydata: receiver not found")
+)
+
+// RemoteError is returned by a request when the receiving handler failed.
+type RemoteError string
+
+func (e RemoteError) Error() string {
+	return "copydata: remote: " + string(e)
+}
+
+// Message is a single WM_COPYDATA payload.
+type Message struct {
+	Type    uint32
+	ID      uint32
+	ReplyTo uint32
+	// Err is the message of a failed handler, it is never empty for a
+	// failure.
+	Err  string
+	Data []byte
+}
+
+// Encode returns the envelope and payload of m. The type tag is not part of
+// the bytes, it is carried separately in dwData.
+func (m *Message) Encode() []byte {
+	data, flags := m.Data, uint32(0)
+	if m.Err != "" {
+		data, flags = []byte(m.Err), flagError
+	}
+	b := make([]byte, envelopeSize+len(data))
+	binary.LittleEndian.PutUint32(b[0:], envelopeMagic)
+	binary.LittleEndian.PutUint32(b[4:], m.ID)
+	binary.LittleEndian.PutUint32(b[8:], m.ReplyTo)
+	binary.LittleEndian.PutUint32(b[12:], flags)
+	copy(b[envelopeSize:], data)
+	return b
+}
+
+// Decode parses b as produced by Encode. Data is copied out of b, which for
+// WM_COPYDATA is only valid while the message is being handled.
+func Decode(typ uint32, b []byte) (*Message, error) {
+	if len(b) < envelopeSize || binary.LittleEndian.Uint32(b) != envelopeMagic {
+		return nil, ErrFormat
+	}
+	m := &Message{
+		Type:    typ,
+		ID:      binary.LittleEndian.Uint32(b[4:]),
+		ReplyTo: binary.LittleEndian.Uint32(b[8:]),
+	}
+	data := append([]byte(nil), b[envelopeSize:]...)
+	if binary.LittleEndian.Uint32(b[12:])&flagError != 0 {
+		m.Err = string(data)
+		if m.Err == "" {
+			m.Err = unnamedError
+		}
+	} else {
+		m.Data = data
+	}
+	return m, nil
+}
+
+// Reply returns the response to m carrying data, or err when it is not nil.
+func (m *Message) Reply(data []byte, err error) *Message {
+	r := &Message{Type: m.Type, ReplyTo: m.ID, Data: data}
+	if err != nil {
+		r.Err, r.Data = err.Error(), nil
+		if r.Err == "" {
+			r.Err = unnamedError
+		}
+	}
+	return r
+}
+
+// Result returns the payload of a response or its error.
+func (m *Message) Result() ([]byte, error) {
+	if m.Err != "" {
+		return nil, RemoteError(m.Err)
+	}
+	return m.Data, nil
+}
+
+// Pending correlates responses with outstanding requests.
+type Pending struct {
+	mu      sync.Mutex
+	next    uint32
+	waiting map[uint32]chan *Message
+}
+
+// Add allocates a request id and the channel its response is delivered on.
+func (p *Pending) Add() (uint32, <-chan *Message) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.waiting == nil {
+		p.waiting = map[uint32]chan *Message{}
+	}
+	for {
+		p.next++
+		if _, ok := p.waiting[p.next]; p.next != 0 && !ok {
+			break
+		}
+	}
+	ch := make(chan *Message, 1)
+	p.waiting[p.next] = ch
+	return p.next, ch
+}
+
+// Cancel forgets the request id, a late response is dropped.
+func (p *Pending) Cancel(id uint32) {
+	p.mu.Lock()
+	delete(p.waiting, id)
+	p.mu.Unlock()
+}
+
+// Resolve delivers m to the request it answers. It reports false when m is
+// not a response or nobody waits for it anymore.
+func (p *Pending) Resolve(m *Message) bool {
+	if m.ReplyTo == 0 {
+		return false
+	}
+	p.mu.Lock()
+	ch, ok := p.waiting[m.ReplyTo]
+	delete(p.waiting, m.ReplyTo)
+	p.mu.Unlock()
+	if ok {
+		ch <- m
+	}
+	return ok
+}
+
+// Len returns the number of outstanding requests.
+func (p *Pending) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.waiting)
+}
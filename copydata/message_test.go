@@ -0,0 +1,170 @@
+package copydata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	messages := []*Message{
+		{Type: 1},
+		{Type: 2, ID: 7, Data: []byte("hello")},
+		{Type: 3, ReplyTo: 7, Data: []byte{0, 1, 2}},
+		{Type: 4, ReplyTo: 8, Err: "boom"},
+	}
+	for _, m := range messages {
+		b := m.Encode()
+		got, err := Decode(m.Type, b)
+		if err != nil {
+			t.Errorf("Decode(%+v): %v", m, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, m) {
+			t.Errorf("Decode(Encode(%+v)) = %+v", m, got)
+		}
+	}
+}
+
+func TestEncodeLayout(t *testing.T) {
+	b := (&Message{Type: 9, ID: 0x01020304, ReplyTo: 5, Data: []byte("x")}).Encode()
+	want := []byte{
+		'W', 'C', 'D', '1',
+		0x04, 0x03, 0x02, 0x01,
+		5, 0, 0, 0,
+		0, 0, 0, 0,
+		'x',
+	}
+	if !bytes.Equal(b, want) {
+		t.Errorf("Encode = % x, want % x", b, want)
+	}
+}
+
+func TestDecodeCopies(t *testing.T) {
+	b := (&Message{Data: []byte("abc")}).Encode()
+	m, err := Decode(0, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b[envelopeSize] = 'z'
+	if string(m.Data) != "abc" {
+		t.Errorf("Data = %q shares memory with the input", m.Data)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	valid := (&Message{}).Encode()
+	for _, b := range [][]byte{nil, valid[:envelopeSize-1], append([]byte{'X'}, valid[1:]...)} {
+		if _, err := Decode(0, b); err != ErrFormat {
+			t.Errorf("Decode(% x) = %v, want ErrFormat", b, err)
+		}
+	}
+}
+
+func TestReply(t *testing.T) {
+	req := &Message{Type: 3, ID: 11, Data: []byte("q")}
+
+	r := req.Reply([]byte("a"), nil)
+	if r.Type != 3 || r.ReplyTo != 11 || r.ID != 0 {
+		t.Errorf("Reply = %+v", r)
+	}
+	if data, err := r.Result(); err != nil || string(data) != "a" {
+		t.Errorf("Result = %q, %v", data, err)
+	}
+
+	r = req.Reply([]byte("ignored"), errors.New("failed"))
+	if r.Data != nil {
+		t.Errorf("error reply carries data %q", r.Data)
+	}
+	decoded, err := Decode(r.Type, r.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decoded.Result(); err != RemoteError("failed") {
+		t.Errorf("Result = %v, want RemoteError", err)
+	}
+
+	// an error without a message still fails
+	r = req.Reply(nil, errors.New(""))
+	if decoded, err = Decode(r.Type, r.Encode()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decoded.Result(); err != RemoteError(unnamedError) {
+		t.Errorf("Result of an empty error = %v", err)
+	}
+	b := (&Message{ReplyTo: 11}).Encode()
+	binary.LittleEndian.PutUint32(b[12:], flagError)
+	if decoded, err = Decode(3, b); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decoded.Result(); err == nil {
+		t.Error("an error flag without a message decodes as a success")
+	}
+}
+
+func TestPending(t *testing.T) {
+	var p Pending
+	id1, ch1 := p.Add()
+	id2, _ := p.Add()
+	if id1 == 0 || id2 == 0 || id1 == id2 {
+		t.Fatalf("ids %d and %d", id1, id2)
+	}
+	if p.Len() != 2 {
+		t.Fatalf("Len = %d", p.Len())
+	}
+
+	if p.Resolve(&Message{ID: id1}) {
+		t.Error("Resolve accepted a message that is not a response")
+	}
+	if !p.Resolve(&Message{ReplyTo: id1, Data: []byte("r")}) {
+		t.Fatal("Resolve dropped a response")
+	}
+	if m := <-ch1; string(m.Data) != "r" {
+		t.Errorf("response %q", m.Data)
+	}
+	if p.Resolve(&Message{ReplyTo: id1}) {
+		t.Error("Resolve delivered a second response")
+	}
+
+	p.Cancel(id2)
+	if p.Resolve(&Message{ReplyTo: id2}) {
+		t.Error("Resolve delivered to a cancelled request")
+	}
+	if p.Len() != 0 {
+		t.Errorf("Len = %d after all requests finished", p.Len())
+	}
+}
+
+func TestPendingSkipsZeroAndBusyIDs(t *testing.T) {
+	var p Pending
+	p.next = ^uint32(0) - 1
+	busy, _ := p.Add() // 0xFFFFFFFF
+	p.next = busy - 1
+	id, _ := p.Add()
+	if id == busy || id == 0 {
+		t.Errorf("Add = %#x after wrapping", id)
+	}
+}
+
+func TestPendingConcurrent(t *testing.T) {
+	var p Pending
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id, ch := p.Add()
+			go p.Resolve(&Message{ReplyTo: id})
+			if m := <-ch; m.ReplyTo != id {
+				t.Errorf("request %d got response to %d", id, m.ReplyTo)
+			}
+		}()
+	}
+	wg.Wait()
+	if p.Len() != 0 {
+		t.Errorf("Len = %d", p.Len())
+	}
+}
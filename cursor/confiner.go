@@ -0,0 +1,226 @@
+// Package cursor keeps the mouse cursor confined and hidden across the
+// focus and display changes that reset the global ClipCursor setting, and
+// replaces the system cursors or the cursor of single windows.
+package cursor
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi/display"
+)
+
+// Backend is the global cursor state a CursorConfiner drives.
+type Backend interface {
+	// ClipCursor confines the cursor to r, nil releases it.
+	ClipCursor(r *display.Rect) error
+
+	// ShowCursor increments or decrements the display counter and returns
+	// its new value, the cursor is shown while the counter is not negative.
+	ShowCursor(show bool) int
+
+	// ClientRect returns the client area of hwnd in screen coordinates.
+	ClientRect(hwnd uintptr) (display.Rect, error)
+
+	// Foreground returns the foreground window.
+	Foreground() uintptr
+}
+
+// CursorConfiner stacks confinement requests, the most recent one that can be
+// resolved is applied, and tracks its changes of the cursor display counter.
+type CursorConfiner struct {
+	// OnlyWhenForeground releases a window confinement while the window is
+	// not in the foreground, so the user can switch away from it.
+	OnlyWhenForeground bool
+
+	mu      sync.Mutex
+	backend Backend
+	stack   []*Confinement
+	clipped bool
+	applied display.Rect
+	shown   int // net ShowCursor increments
+	holds   []*CursorHold
+}
+
+// Confinement is a release handle of one confinement request.
+type Confinement struct {
+	c    *CursorConfiner
+	hwnd uintptr
+	rect display.Rect
+}
+
+// CursorHold is a release handle of one HideCursor call.
+type CursorHold struct {
+	c        *CursorConfiner
+	released bool
+}
+
+func NewCursorConfiner(b Backend) *CursorConfiner {
+	return &CursorConfiner{backend: b}
+}
+
+// ConfineToWindow confines the cursor to the client area of hwnd, following
+// the window when it moves.
+func (c *CursorConfiner) ConfineToWindow(hwnd uintptr) (*Confinement, error) {
+	return c.push(&Confinement{c: c, hwnd: hwnd})
+}
+
+// ConfineToRect confines the cursor to the screen rectangle r.
+func (c *CursorConfiner) ConfineToRect(r display.Rect) (*Confinement, error) {
+	if r.Empty() {
+		return nil, errors.New("cursor: empty confinement rectangle")
+	}
+	return c.push(&Confinement{c: c, rect: r})
+}
+
+func (c *CursorConfiner) push(cf *Confinement) (*Confinement, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stack = append(c.stack, cf)
+	if err := c.apply(); err != nil {
+		c.stack = c.stack[:len(c.stack)-1]
+		return nil, err
+	}
+	return cf, nil
+}
+
+// Release removes the request, the previous one takes effect again.
+// Requests may be released in any order; releasing twice is a no-op.
+func (cf *Confinement) Release() error {
+	c := cf.c
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, other := range c.stack {
+		if other == cf {
+			c.stack = append(c.stack[:i], c.stack[i+1:]...)
+			return c.apply()
+		}
+	}
+	return nil
+}
+
+// Reapply confines the cursor again. Call it after events that reset
+// ClipCursor, such as WM_DISPLAYCHANGE, focus changes or window moves.
+func (c *CursorConfiner) Reapply() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.apply()
+}
+
+// Watches reports whether hwnd is the window of a confinement.
+func (c *CursorConfiner) Watches(hwnd uintptr) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, cf := range c.stack {
+		if cf.hwnd == hwnd {
+			return true
+		}
+	}
+	return false
+}
+
+// Active returns the rectangle the cursor is confined to.
+func (c *CursorConfiner) Active() (display.Rect, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.applied, c.clipped
+}
+
+// target resolves the most recent confinement, skipping destroyed windows
+// and, with OnlyWhenForeground, windows in the background.
+func (c *CursorConfiner) target() (display.Rect, bool) {
+	for i := len(c.stack) - 1; i >= 0; i-- {
+		cf := c.stack[i]
+		if cf.hwnd == 0 {
+			return cf.rect, true
+		}
+		if c.OnlyWhenForeground && c.backend.Foreground() != cf.hwnd {
+			continue
+		}
+		if r, err := c.backend.ClientRect(cf.hwnd); err == nil && !r.Empty() {
+			return r, true
+		}
+	}
+	return display.Rect{}, false
+}
+
+func (c *CursorConfiner) apply() error {
+	r, ok := c.target()
+	switch {
+	case ok:
+		if err := c.backend.ClipCursor(&r); err != nil {
+			return errors.Wrap(err, "ClipCursor")
+		}
+		c.applied, c.clipped = r, true
+	case c.clipped:
+		if err := c.backend.ClipCursor(nil); err != nil {
+			return errors.Wrap(err, "ClipCursor")
+		}
+		c.applied, c.clipped = display.Rect{}, false
+	}
+	return nil
+}
+
+// HideCursor decrements the display counter until the hold is released.
+// The backend is called without holding the lock, it may wait for a thread
+// that calls back into c.
+func (c *CursorConfiner) HideCursor() *CursorHold {
+	c.mu.Lock()
+	c.shown--
+	h := &CursorHold{c: c}
+	c.holds = append(c.holds, h)
+	c.mu.Unlock()
+
+	c.backend.ShowCursor(false)
+	return h
+}
+
+// Release increments the display counter again, releasing twice is a no-op.
+func (h *CursorHold) Release() {
+	c := h.c
+	c.mu.Lock()
+	if h.released {
+		c.mu.Unlock()
+		return
+	}
+	h.released = true
+	c.shown++
+	for i, other := range c.holds {
+		if other == h {
+			c.holds = append(c.holds[:i], c.holds[i+1:]...)
+			break
+		}
+	}
+	c.mu.Unlock()
+
+	c.backend.ShowCursor(true)
+}
+
+// RestoreCursor undoes every display counter change made through c,
+// leaving the counter exactly where it was, and invalidates all holds.
+func (c *CursorConfiner) RestoreCursor() {
+	c.mu.Lock()
+	shown := c.shown
+	c.shown = 0
+	for _, h := range c.holds {
+		h.released = true
+	}
+	c.holds = nil
+	c.mu.Unlock()
+
+	for ; shown < 0; shown++ {
+		c.backend.ShowCursor(true)
+	}
+	for ; shown > 0; shown-- {
+		c.backend.ShowCursor(false)
+	}
+}
+
+// ReleaseAll drops every confinement and restores the cursor.
+func (c *CursorConfiner) ReleaseAll() error {
+	c.RestoreCursor()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stack = nil
+	return c.apply()
+}
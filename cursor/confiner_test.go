@@ -0,0 +1,194 @@
+package cursor
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi/display"
+)
+
+type fakeBackend struct {
+	clip       *display.Rect
+	counter    int
+	windows    map[uintptr]display.Rect
+	foreground uintptr
+
+	// onShow runs inside ShowCursor, like a hook on the thread the
+	// Windows backend waits for
+	onShow func()
+}
+
+func (b *fakeBackend) ClipCursor(r *display.Rect) error {
+	if r == nil {
+		b.clip = nil
+		return nil
+	}
+	clip := *r
+	b.clip = &clip
+	return nil
+}
+
+func (b *fakeBackend) ShowCursor(show bool) int {
+	if b.onShow != nil {
+		b.onShow()
+	}
+	if show {
+		b.counter++
+	} else {
+		b.counter--
+	}
+	return b.counter
+}
+
+func (b *fakeBackend) ClientRect(hwnd uintptr) (display.Rect, error) {
+	r, ok := b.windows[hwnd]
+	if !ok {
+		return display.Rect{}, errors.New("no window")
+	}
+	return r, nil
+}
+
+func (b *fakeBackend) Foreground() uintptr {
+	return b.foreground
+}
+
+func rc(left, top, right, bottom int32) display.Rect {
+	return display.Rect{Left: left, Top: top, Right: right, Bottom: bottom}
+}
+
+func checkClip(t *testing.T, b *fakeBackend, want *display.Rect) {
+	t.Helper()
+	switch {
+	case want == nil && b.clip != nil:
+		t.Errorf("clipped to %+v, want released", *b.clip)
+	case want != nil && b.clip == nil:
+		t.Errorf("released, want clipped to %+v", *want)
+	case want != nil && *b.clip != *want:
+		t.Errorf("clipped to %+v, want %+v", *b.clip, *want)
+	}
+}
+
+func TestConfinementStack(t *testing.T) {
+	b := &fakeBackend{windows: map[uintptr]display.Rect{1: rc(10, 10, 110, 110)}}
+	c := NewCursorConfiner(b)
+
+	screen := rc(0, 0, 1920, 1080)
+	outer, err := c.ConfineToRect(screen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	window, err := c.ConfineToWindow(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := rc(10, 10, 110, 110)
+	checkClip(t, b, &client)
+	if !c.Watches(1) || c.Watches(2) {
+		t.Error("Watches does not match the confined windows")
+	}
+
+	// the window moved
+	b.windows[1] = rc(20, 20, 120, 120)
+	if err := c.Reapply(); err != nil {
+		t.Fatal(err)
+	}
+	moved := rc(20, 20, 120, 120)
+	checkClip(t, b, &moved)
+
+	// a destroyed window falls back to the request below it
+	delete(b.windows, 1)
+	c.Reapply()
+	checkClip(t, b, &screen)
+	if r, ok := c.Active(); !ok || r != screen {
+		t.Errorf("Active = %+v, %v", r, ok)
+	}
+
+	outer.Release()
+	checkClip(t, b, nil)
+	window.Release()
+	window.Release()
+	checkClip(t, b, nil)
+
+	if _, err := c.ConfineToRect(display.Rect{}); err == nil {
+		t.Error("ConfineToRect accepted an empty rectangle")
+	}
+}
+
+func TestOnlyWhenForeground(t *testing.T) {
+	b := &fakeBackend{windows: map[uintptr]display.Rect{
+		1: rc(0, 0, 100, 100),
+		2: rc(200, 0, 300, 100),
+	}}
+	c := NewCursorConfiner(b)
+	c.OnlyWhenForeground = true
+
+	screen := rc(0, 0, 1920, 1080)
+	c.ConfineToRect(screen)
+	c.ConfineToWindow(1)
+	c.ConfineToWindow(2)
+
+	b.foreground = 2
+	c.Reapply()
+	want := b.windows[2]
+	checkClip(t, b, &want)
+
+	// a background window falls back to the lower requests
+	b.foreground = 1
+	c.Reapply()
+	want = b.windows[1]
+	checkClip(t, b, &want)
+
+	b.foreground = 3
+	c.Reapply()
+	checkClip(t, b, &screen)
+}
+
+func TestCursorHolds(t *testing.T) {
+	b := &fakeBackend{}
+	c := NewCursorConfiner(b)
+
+	h1 := c.HideCursor()
+	h2 := c.HideCursor()
+	if b.counter != -2 {
+		t.Fatalf("counter = %d after two holds", b.counter)
+	}
+	h1.Release()
+	h1.Release()
+	if b.counter != -1 {
+		t.Fatalf("counter = %d after releasing one hold twice", b.counter)
+	}
+	c.HideCursor()
+	c.RestoreCursor()
+	if b.counter != 0 {
+		t.Fatalf("counter = %d after RestoreCursor", b.counter)
+	}
+	h2.Release()
+	if b.counter != 0 {
+		t.Fatalf("counter = %d after releasing an invalidated hold", b.counter)
+	}
+}
+
+// The Windows backend waits for a thread whose event hook calls Watches,
+// the confiner must not hold its lock while calling ShowCursor.
+func TestShowCursorWithoutLock(t *testing.T) {
+	b := &fakeBackend{}
+	c := NewCursorConfiner(b)
+	b.onShow = func() {
+		c.Watches(1)
+		c.Active()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h := c.HideCursor()
+		h.Release()
+		c.HideCursor()
+		c.RestoreCursor()
+		c.ReleaseAll()
+	}()
+	<-done
+	if b.counter != 0 {
+		t.Errorf("counter = %d", b.counter)
+	}
+}
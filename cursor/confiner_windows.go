@@ -0,0 +1,145 @@
+package cursor
+
+import (
+	"sync"
+
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi"
+	"github.com/whiteboxsolutions/winapi/display"
+	"golang.org/x/sys/windows"
+)
+
+// windowsBackend runs ShowCursor through ui, the display counter belongs
+// to the calling thread and hides the cursor only over windows of that
+// thread.
+type windowsBackend struct {
+	ui func(fn func())
+}
+
+func (b *windowsBackend) ClipCursor(r *display.Rect) error {
+	if r == nil {
+		_, err := winapi.ClipCursor(nil)
+		return err
+	}
+	rect := win.RECT(*r)
+	_, err := winapi.ClipCursor(&rect)
+	return err
+}
+
+func (b *windowsBackend) ShowCursor(show bool) int {
+	if b.ui == nil {
+		return winapi.ShowCursor(show)
+	}
+	var counter int
+	b.ui(func() {
+		counter = winapi.ShowCursor(show)
+	})
+	return counter
+}
+
+func (b *windowsBackend) ClientRect(hwnd uintptr) (display.Rect, error) {
+	var r win.RECT
+	if !win.GetClientRect(win.HWND(hwnd), &r) {
+		return display.Rect{}, errors.Wrap(windows.GetLastError(), "GetClientRect")
+	}
+	tl := win.POINT{X: r.Left, Y: r.Top}
+	br := win.POINT{X: r.Right, Y: r.Bottom}
+	win.ClientToScreen(win.HWND(hwnd), &tl)
+	win.ClientToScreen(win.HWND(hwnd), &br)
+	return display.Rect{Left: tl.X, Top: tl.Y, Right: br.X, Bottom: br.Y}, nil
+}
+
+func (b *windowsBackend) Foreground() uintptr {
+	return uintptr(win.GetForegroundWindow())
+}
+
+// confiners receive the WinEvents of their message window thread. Handlers
+// take the confiner lock on another goroutine, so the hook never blocks the
+// thread on a lock held by a caller waiting for it.
+var confiners sync.Map // thread id -> *CursorConfiner
+
+func winEventProc(hook win.HWINEVENTHOOK, event uint32, hwnd win.HWND, idObject int32, idChild int32, idEventThread uint32, dwmsEventTime uint32) uintptr {
+	v, ok := confiners.Load(windows.GetCurrentThreadId())
+	if !ok {
+		return 0
+	}
+	c := v.(*CursorConfiner)
+	switch event {
+	case win.EVENT_SYSTEM_FOREGROUND:
+		go c.Reapply()
+	case win.EVENT_OBJECT_LOCATIONCHANGE:
+		if idObject == win.OBJID_WINDOW && idChild == 0 {
+			go func() {
+				if c.Watches(uintptr(hwnd)) {
+					c.Reapply()
+				}
+			}()
+		}
+	}
+	return 0
+}
+
+// Confiner is a CursorConfiner that re-applies itself on display changes,
+// foreground changes and moves of confining windows.
+type Confiner struct {
+	*CursorConfiner
+
+	w     *winapi.MessageWindow
+	hooks []win.HWINEVENTHOOK
+}
+
+// New creates a Confiner. ui runs fn on the thread owning the windows the
+// cursor is to be hidden over and returns when fn has run, for example Do
+// of a winapi.MessageWindow that owns them or the synchronous invoke of a
+// UI toolkit. A nil ui calls ShowCursor directly, HideCursor, Release,
+// RestoreCursor and Close must then be called on that thread.
+func New(ui func(fn func())) (*Confiner, error) {
+	c := &Confiner{}
+	c.CursorConfiner = NewCursorConfiner(&windowsBackend{ui: ui})
+
+	// a top-level window, message-only windows miss the WM_DISPLAYCHANGE broadcast
+	w, err := winapi.NewMessageWindow("winapi.cursor", "", true, func(hwnd win.HWND, msg uint32, wParam, lParam uintptr) (uintptr, bool) {
+		if msg == win.WM_DISPLAYCHANGE {
+			go c.Reapply()
+		}
+		return 0, false
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.w = w
+	confiners.Store(w.ThreadID, c.CursorConfiner)
+
+	if doErr := w.Do(func() {
+		for _, event := range []uint32{win.EVENT_SYSTEM_FOREGROUND, win.EVENT_OBJECT_LOCATIONCHANGE} {
+			var h win.HWINEVENTHOOK
+			h, err = win.SetWinEventHook(event, event, 0, winEventProc, 0, 0, win.WINEVENT_OUTOFCONTEXT)
+			if err != nil {
+				return
+			}
+			c.hooks = append(c.hooks, h)
+		}
+	}); doErr != nil {
+		err = doErr
+	}
+	if err != nil {
+		c.Close()
+		return nil, errors.Wrap(err, "SetWinEventHook")
+	}
+	return c, nil
+}
+
+// Close releases every confinement, restores the cursor display counter and
+// stops watching events.
+func (c *Confiner) Close() error {
+	err := c.ReleaseAll()
+	c.w.Do(func() {
+		for _, h := range c.hooks {
+			win.UnhookWinEvent(h)
+		}
+	})
+	confiners.Delete(c.w.ThreadID)
+	c.w.Close()
+	return err
+}
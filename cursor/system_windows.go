@@ -0,0 +1,142 @@
+package cursor
+
+import (
+	"sync"
+	"syscall"
+
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi"
+)
+
+// SystemCursors replaces system cursors and puts back the ones it
+// replaced. Replacements last beyond the process, call Restore before
+// exiting.
+type SystemCursors struct {
+	mu       sync.Mutex
+	original map[uint32]win.HCURSOR // copies of the replaced cursors
+}
+
+// Set replaces the system cursor id, one of winapi.OCR_*, with a copy of
+// h. The caller keeps ownership of h.
+func (s *SystemCursors) Set(id uint32, h win.HCURSOR) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.original[id]; !ok {
+		current := win.LoadCursor(0, win.MAKEINTRESOURCE(uintptr(id)))
+		if current == 0 {
+			return errors.Errorf("no system cursor %d", id)
+		}
+		saved, err := winapi.CopyIcon(win.HICON(current))
+		if err != nil {
+			return errors.Wrap(err, "CopyIcon")
+		}
+		if s.original == nil {
+			s.original = map[uint32]win.HCURSOR{}
+		}
+		s.original[id] = win.HCURSOR(saved)
+	}
+	c, err := winapi.CopyIcon(win.HICON(h))
+	if err != nil {
+		return errors.Wrap(err, "CopyIcon")
+	}
+	if err := winapi.SetSystemCursor(win.HCURSOR(c), id); err != nil {
+		win.DestroyIcon(c)
+		return errors.Wrap(err, "SetSystemCursor")
+	}
+	return nil
+}
+
+// Restore puts back the system cursors replaced through s.
+func (s *SystemCursors) Restore() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err error
+	for id, h := range s.original {
+		// the system takes ownership of the saved copy
+		if e := winapi.SetSystemCursor(h, id); e != nil {
+			win.DestroyIcon(win.HICON(h))
+			if err == nil {
+				err = errors.Wrap(e, "SetSystemCursor")
+			}
+		}
+		delete(s.original, id)
+	}
+	return err
+}
+
+// windowCursor is a window subclassed to answer WM_SETCURSOR.
+type windowCursor struct {
+	prev   uintptr
+	cursor win.HCURSOR
+}
+
+var windowCursors = struct {
+	sync.Mutex
+	m map[win.HWND]*windowCursor
+}{m: map[win.HWND]*windowCursor{}}
+
+var windowCursorProc = syscall.NewCallback(func(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	// copy the fields under the lock, SetWindowCursor changes them from
+	// other threads
+	windowCursors.Lock()
+	p := windowCursors.m[hwnd]
+	var wc windowCursor
+	if p != nil {
+		wc = *p
+	}
+	if msg == win.WM_NCDESTROY {
+		delete(windowCursors.m, hwnd)
+	}
+	windowCursors.Unlock()
+	if p == nil {
+		return win.DefWindowProc(hwnd, msg, wParam, lParam)
+	}
+	switch msg {
+	case win.WM_SETCURSOR:
+		if wc.cursor != 0 && win.LOWORD(uint32(lParam)) == win.HTCLIENT {
+			win.SetCursor(wc.cursor)
+			return 1
+		}
+	case win.WM_NCDESTROY:
+		win.SetWindowLongPtr(hwnd, win.GWLP_WNDPROC, wc.prev)
+	}
+	return win.CallWindowProc(wc.prev, hwnd, msg, wParam, lParam)
+})
+
+// SetWindowCursor shows h while the mouse is over the client area of
+// hwnd, a window of this process, replacing the class cursor and whatever
+// the window sets itself. A zero h restores the cursor of the window. The
+// caller keeps ownership of h and must not destroy it while it is set.
+func SetWindowCursor(hwnd win.HWND, h win.HCURSOR) error {
+	windowCursors.Lock()
+	defer windowCursors.Unlock()
+
+	wc := windowCursors.m[hwnd]
+	switch {
+	case wc == nil && h == 0:
+		return nil
+	case wc == nil:
+		wc = &windowCursor{cursor: h}
+		windowCursors.m[hwnd] = wc
+		prev := win.SetWindowLongPtr(hwnd, win.GWLP_WNDPROC, windowCursorProc)
+		if prev == 0 {
+			delete(windowCursors.m, hwnd)
+			return errors.Errorf("cannot subclass window %#x", hwnd)
+		}
+		wc.prev = prev
+	case h != 0:
+		wc.cursor = h
+	default:
+		// unsubclass unless another subclass was installed on top of ours,
+		// then keep passing messages through
+		wc.cursor = 0
+		if win.GetWindowLongPtr(hwnd, win.GWLP_WNDPROC) == windowCursorProc {
+			win.SetWindowLongPtr(hwnd, win.GWLP_WNDPROC, wc.prev)
+			delete(windowCursors.m, hwnd)
+		}
+	}
+	return nil
+}
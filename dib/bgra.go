@@ -0,0 +1,93 @@
+package dib
+
+import (
+	"image"
+	"image/color"
+)
+
+// BGRA is an in-memory image in the byte order of a 32 bpp BI_RGB DIB:
+// blue, green, red and alpha. Like image.RGBA the colors are alpha
+// premultiplied, which is what AlphaBlend and UpdateLayeredWindow expect.
+type BGRA struct {
+	// Pix holds the pixels in top-down row order, 4 bytes per pixel.
+	Pix []uint8
+	// Stride is the distance in bytes between vertically adjacent pixels.
+	Stride int
+	Rect   image.Rectangle
+}
+
+// NewBGRA returns a BGRA image with its own pixel memory.
+func NewBGRA(r image.Rectangle) *BGRA {
+	return &BGRA{
+		Pix:    make([]uint8, 4*r.Dx()*r.Dy()),
+		Stride: 4 * r.Dx(),
+		Rect:   r,
+	}
+}
+
+func (p *BGRA) ColorModel() color.Model { return color.RGBAModel }
+
+func (p *BGRA) Bounds() image.Rectangle { return p.Rect }
+
+func (p *BGRA) At(x, y int) color.Color {
+	return p.BGRAAt(x, y)
+}
+
+// BGRAAt returns the color at (x, y) as a premultiplied color.RGBA.
+func (p *BGRA) BGRAAt(x, y int) color.RGBA {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return color.RGBA{}
+	}
+	s := p.Pix[p.PixOffset(x, y):]
+	return color.RGBA{R: s[2], G: s[1], B: s[0], A: s[3]}
+}
+
+// PixOffset returns the index of the first byte of the pixel at (x, y) in Pix.
+func (p *BGRA) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x-p.Rect.Min.X)*4
+}
+
+func (p *BGRA) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.SetBGRA(x, y, color.RGBAModel.Convert(c).(color.RGBA))
+}
+
+// SetBGRA sets the pixel at (x, y) to the premultiplied color c.
+func (p *BGRA) SetBGRA(x, y int, c color.RGBA) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	d := p.Pix[p.PixOffset(x, y):]
+	d[0], d[1], d[2], d[3] = c.B, c.G, c.R, c.A
+}
+
+// SubImage returns the part of p visible through r. The pixels are shared.
+func (p *BGRA) SubImage(r image.Rectangle) image.Image {
+	r = r.Intersect(p.Rect)
+	if r.Empty() {
+		return &BGRA{}
+	}
+	return &BGRA{
+		Pix:    p.Pix[p.PixOffset(r.Min.X, r.Min.Y):],
+		Stride: p.Stride,
+		Rect:   r,
+	}
+}
+
+// Opaque reports whether every pixel has an alpha of 0xFF.
+func (p *BGRA) Opaque() bool {
+	if p.Rect.Empty() {
+		return true
+	}
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		row := p.Pix[p.PixOffset(p.Rect.Min.X, y):]
+		for i := 3; i < p.Rect.Dx()*4; i += 4 {
+			if row[i] != 0xFF {
+				return false
+			}
+		}
+	}
+	return true
+}
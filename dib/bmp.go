@@ -0,0 +1,59 @@
+package dib
+
+import (
+	"encoding/binary"
+	"image"
+
+	"github.com/pkg/errors"
+)
+
+// BITMAPFILEHEADER_SIZE is the length of the file header in front of the
+// packed DIB of a .bmp file.
+const BITMAPFILEHEADER_SIZE = 14
+
+// EncodeBMP encodes img as a .bmp file laid out according to o. A
+// BITMAPV5HEADER with an alpha mask keeps transparency for readers that
+// understand it.
+func EncodeBMP(img image.Image, o Options) ([]byte, error) {
+	h, pal, err := NewHeader(img.Bounds().Dx(), img.Bounds().Dy(), img, o)
+	if err != nil {
+		return nil, err
+	}
+	info := append(h.Bytes(), EncodePalette(pal)...)
+	bits, err := EncodeBits(&h, pal, img)
+	if err != nil {
+		return nil, err
+	}
+	offset := BITMAPFILEHEADER_SIZE + len(info)
+	out := make([]byte, BITMAPFILEHEADER_SIZE, offset+len(bits))
+	out[0], out[1] = 'B', 'M'
+	binary.LittleEndian.PutUint32(out[2:], uint32(offset+len(bits)))
+	binary.LittleEndian.PutUint32(out[10:], uint32(offset))
+	out = append(out, info...)
+	return append(out, bits...), nil
+}
+
+// DecodeBMP decodes a .bmp file. The pixels are read from the offset in
+// the file header, which some writers place behind padding or an ICC profile.
+func DecodeBMP(b []byte) (image.Image, error) {
+	if len(b) < BITMAPFILEHEADER_SIZE || b[0] != 'B' || b[1] != 'M' {
+		return nil, errors.Wrap(ErrFormat, "not a BMP file")
+	}
+	packed := b[BITMAPFILEHEADER_SIZE:]
+	h, err := ParseHeader(packed)
+	if err != nil {
+		return nil, err
+	}
+	offset := h.BitsOffset()
+	if len(packed) < offset {
+		return nil, errors.Wrap(ErrFormat, "short color table")
+	}
+	palette := DecodePalette(packed[h.Size+uint32(h.maskBytes()) : offset])
+	if off := int(binary.LittleEndian.Uint32(b[10:])); off != 0 {
+		if off < BITMAPFILEHEADER_SIZE+int(h.Size) || off > len(b) {
+			return nil, errors.Wrapf(ErrFormat, "pixel offset %d", off)
+		}
+		offset = off - BITMAPFILEHEADER_SIZE
+	}
+	return DecodeBits(&h, palette, packed[offset:])
+}
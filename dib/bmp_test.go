@@ -0,0 +1,65 @@
+package dib
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// checkGolden compares b with the file testdata/name, or writes it with -update.
+func checkGolden(t *testing.T, name string, b []byte) []byte {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, want) {
+		t.Errorf("%s differs from the golden file, run go test -update to accept it", name)
+	}
+	return want
+}
+
+func TestBMPGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		o    Options
+	}{
+		{"1bpp.bmp", Options{BitCount: 1}},
+		{"4bpp.bmp", Options{BitCount: 4}},
+		{"8bpp.bmp", Options{BitCount: 8}},
+		{"rgb555.bmp", Options{BitCount: 16}},
+		{"rgb565.bmp", Options{BitCount: 16, RedMask: 0xF800, GreenMask: 0x07E0, BlueMask: 0x001F}},
+		{"24bpp.bmp", Options{BitCount: 24}},
+		{"24bpp-topdown.bmp", Options{BitCount: 24, TopDown: true}},
+		{"32bpp.bmp", Options{BitCount: 32}},
+		{"argb-v5.bmp", Options{
+			BitCount:   32,
+			HeaderSize: BITMAPV5HEADER_SIZE,
+			RedMask:    0x00FF0000, GreenMask: 0x0000FF00, BlueMask: 0x000000FF, AlphaMask: 0xFF000000,
+		}},
+	}
+	for _, tt := range tests {
+		// an odd width exercises the scan line padding
+		img := testImage(7, 5, tt.o)
+		b, err := EncodeBMP(img, tt.o)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		golden := checkGolden(t, tt.name, b)
+		got, err := DecodeBMP(golden)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		checkSame(t, tt.name, got, img)
+	}
+}
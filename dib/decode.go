@@ -0,0 +1,150 @@
+package dib
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/pkg/errors"
+)
+
+// Decode decodes a packed DIB: a header, optional bit field masks, the
+// color table and the pixels, as stored in CF_DIB, CF_DIBV5 and BMP files.
+// Palette based images decode to *image.Paletted, all others to *image.NRGBA.
+// The colors are taken to be straight alpha, see Options.Premultiplied.
+func Decode(packed []byte) (image.Image, error) {
+	h, err := ParseHeader(packed)
+	if err != nil {
+		return nil, err
+	}
+	offset := h.BitsOffset()
+	if len(packed) < offset {
+		return nil, errors.Wrap(ErrFormat, "short color table")
+	}
+	palette := DecodePalette(packed[h.Size+uint32(h.maskBytes()) : offset])
+	return DecodeBits(&h, palette, packed[offset:])
+}
+
+// DecodePalette decodes a table of RGBQUAD entries.
+func DecodePalette(b []byte) color.Palette {
+	p := make(color.Palette, len(b)/4)
+	for i := range p {
+		p[i] = color.RGBA{R: b[i*4+2], G: b[i*4+1], B: b[i*4], A: 0xFF}
+	}
+	return p
+}
+
+// DecodeBits decodes the pixel data described by h. 16 and 32 bpp pixels
+// decode to *image.RGBA when h.Premultiplied is set.
+func DecodeBits(h *Header, palette color.Palette, bits []byte) (image.Image, error) {
+	switch h.Compression {
+	case BI_PNG:
+		return png.Decode(bytes.NewReader(bits))
+	case BI_JPEG:
+		return jpeg.Decode(bytes.NewReader(bits))
+	case BI_RGB, BI_BITFIELDS, BI_ALPHABITFIELDS:
+	default:
+		return nil, errors.Wrapf(ErrUnsupported, "compression %d", h.Compression)
+	}
+
+	w, ht, stride := h.Dx(), h.Dy(), h.Stride()
+	if len(bits) < stride*ht {
+		return nil, errors.Wrapf(ErrFormat, "%d bytes of pixels, need %d", len(bits), stride*ht)
+	}
+	row := func(y int) []byte {
+		if !h.TopDown() {
+			y = ht - 1 - y
+		}
+		return bits[y*stride : (y+1)*stride]
+	}
+
+	switch h.BitCount {
+	case 1, 2, 4, 8:
+		if len(palette) == 0 {
+			return nil, errors.Wrap(ErrFormat, "missing color table")
+		}
+		img := image.NewPaletted(image.Rect(0, 0, w, ht), palette)
+		bpp := uint(h.BitCount)
+		mask := byte(1<<bpp - 1)
+		for y := 0; y < ht; y++ {
+			src := row(y)
+			dst := img.Pix[y*img.Stride:]
+			for x := 0; x < w; x++ {
+				bit := uint(x) * bpp
+				idx := src[bit/8] >> (8 - bpp - bit%8) & mask
+				if int(idx) >= len(palette) {
+					idx = 0
+				}
+				dst[x] = idx
+			}
+		}
+		return img, nil
+	case 24:
+		img := image.NewNRGBA(image.Rect(0, 0, w, ht))
+		for y := 0; y < ht; y++ {
+			src := row(y)
+			dst := img.Pix[y*img.Stride:]
+			for x := 0; x < w; x++ {
+				dst[x*4+0] = src[x*3+2]
+				dst[x*4+1] = src[x*3+1]
+				dst[x*4+2] = src[x*3+0]
+				dst[x*4+3] = 0xFF
+			}
+		}
+		return img, nil
+	case 16, 32:
+		return decodeMasked(h, w, ht, row), nil
+	}
+	return nil, errors.Wrapf(ErrUnsupported, "%d bits per pixel", h.BitCount)
+}
+
+func decodeMasked(h *Header, w, ht int, row func(y int) []byte) image.Image {
+	rm, gm, bm, am := h.masks()
+	r, g, b, a := newChannel(rm), newChannel(gm), newChannel(bm), newChannel(am)
+	stride := 4 * w
+	pix := make([]byte, stride*ht)
+	hasAlpha := false
+
+	for y := 0; y < ht; y++ {
+		src := row(y)
+		dst := pix[y*stride:]
+		for x := 0; x < w; x++ {
+			var v uint32
+			if h.BitCount == 16 {
+				v = uint32(binary.LittleEndian.Uint16(src[x*2:]))
+			} else {
+				v = binary.LittleEndian.Uint32(src[x*4:])
+			}
+			dst[x*4+0] = r.get(v)
+			dst[x*4+1] = g.get(v)
+			dst[x*4+2] = b.get(v)
+			dst[x*4+3] = a.get(v)
+			if dst[x*4+3] != 0 {
+				hasAlpha = true
+			}
+		}
+	}
+
+	// many producers leave the alpha channel zeroed, treat that as opaque
+	if !hasAlpha {
+		for i := 3; i < len(pix); i += 4 {
+			pix[i] = 0xFF
+		}
+	}
+	rect := image.Rect(0, 0, w, ht)
+	if !h.Premultiplied {
+		return &image.NRGBA{Pix: pix, Stride: stride, Rect: rect}
+	}
+	// colors above their alpha are invalid premultiplied values
+	for i := 0; i < len(pix); i += 4 {
+		for c := i; c < i+3; c++ {
+			if pix[c] > pix[i+3] {
+				pix[c] = pix[i+3]
+			}
+		}
+	}
+	return &image.RGBA{Pix: pix, Stride: stride, Rect: rect}
+}
@@ -0,0 +1,591 @@
+package dib
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestChannel(t *testing.T) {
+	for width := 1; width <= 8; width++ {
+		for _, shift := range []uint{0, 3, 24 - uint(width), 32 - uint(width)} {
+			mask := uint32(1<<width-1) << shift
+			c := newChannel(mask)
+			if got := c.get(mask); got != 0xFF {
+				t.Errorf("mask %#x: get(mask) = %#x", mask, got)
+			}
+			if got := c.put(0xFF); got != mask {
+				t.Errorf("mask %#x: put(0xFF) = %#x", mask, got)
+			}
+			prev := -1
+			for k := uint32(0); k <= mask>>shift; k++ {
+				v := c.get(k << shift)
+				if int(v) <= prev {
+					t.Errorf("mask %#x: get(%d) = %d is not above %d", mask, k, v, prev)
+				}
+				prev = int(v)
+				if got := c.put(v); got != k<<shift {
+					t.Errorf("mask %#x: put(get(%d)) = %#x", mask, k, got>>shift)
+				}
+			}
+			// bits outside the mask are ignored
+			if got := c.get(^mask); got != 0 {
+				t.Errorf("mask %#x: get(^mask) = %d", mask, got)
+			}
+		}
+	}
+	var none channel
+	if none.get(0xFFFFFFFF) != 0 || none.put(0xFF) != 0 {
+		t.Error("empty mask carries a value")
+	}
+}
+
+// testImage returns a w x h image with deterministic pixels that survive
+// the layout o without loss.
+func testImage(w, h int, o Options) image.Image {
+	rnd := rand.New(rand.NewSource(int64(w*100 + h + int(o.BitCount))))
+	r := image.Rect(0, 0, w, h)
+	switch o.BitCount {
+	case 1, 2, 4, 8:
+		pal := DefaultPalette(o.BitCount)
+		if len(pal) > 1<<o.BitCount {
+			pal = pal[:1<<o.BitCount]
+		}
+		img := image.NewPaletted(r, pal)
+		for i := range img.Pix {
+			img.Pix[i] = uint8(rnd.Intn(len(pal)))
+		}
+		return img
+	}
+
+	img := image.NewNRGBA(r)
+	rnd.Read(img.Pix)
+	rm, gm, bm, am := o.RedMask, o.GreenMask, o.BlueMask, o.AlphaMask
+	if rm|gm|bm|am == 0 {
+		h := Header{BitCount: o.BitCount}
+		rm, gm, bm, am = h.masks()
+	}
+	for i := 0; i < len(img.Pix); i += 4 {
+		if o.BitCount == 24 {
+			img.Pix[i+3] = 0xFF
+			continue
+		}
+		// quantize to the precision of the masks
+		for j, m := range []uint32{rm, gm, bm, am} {
+			c := newChannel(m)
+			img.Pix[i+j] = c.get(c.put(img.Pix[i+j]))
+		}
+		if am == 0 {
+			img.Pix[i+3] = 0xFF
+		}
+	}
+	return img
+}
+
+func checkSame(t *testing.T, name string, got, want image.Image) {
+	t.Helper()
+	if got.Bounds() != want.Bounds() {
+		t.Fatalf("%s: bounds %v, want %v", name, got.Bounds(), want.Bounds())
+	}
+	if p, ok := want.(*image.Paletted); ok {
+		q, ok := got.(*image.Paletted)
+		if !ok {
+			t.Fatalf("%s: decoded to %T", name, got)
+		}
+		if !reflect.DeepEqual(q.Palette, p.Palette) || !bytes.Equal(q.Pix, p.Pix) {
+			t.Errorf("%s: paletted image differs", name)
+		}
+		return
+	}
+	b := want.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			g := color.NRGBAModel.Convert(got.At(x, y))
+			w := color.NRGBAModel.Convert(want.At(x, y))
+			if g != w {
+				t.Fatalf("%s: pixel (%d, %d) = %v, want %v", name, x, y, g, w)
+			}
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	layouts := []Options{
+		{BitCount: 1},
+		{BitCount: 2},
+		{BitCount: 4},
+		{BitCount: 8},
+		{BitCount: 16},
+		{BitCount: 16, RedMask: 0xF800, GreenMask: 0x07E0, BlueMask: 0x001F},
+		{BitCount: 16, RedMask: 0x0F00, GreenMask: 0x00F0, BlueMask: 0x000F, AlphaMask: 0xF000},
+		{BitCount: 24},
+		{BitCount: 32},
+		{BitCount: 32, RedMask: 0x000000FF, GreenMask: 0x0000FF00, BlueMask: 0x00FF0000},
+		{BitCount: 32, RedMask: 0xFF000000, GreenMask: 0x00FF0000, BlueMask: 0x0000FF00, AlphaMask: 0x000000FF},
+		{BitCount: 32, RedMask: 0x3FF00000, GreenMask: 0x000FFC00, BlueMask: 0x000003FF},
+	}
+	for _, o := range layouts {
+		for _, size := range []uint32{BITMAPINFOHEADER_SIZE, BITMAPV4HEADER_SIZE, BITMAPV5HEADER_SIZE} {
+			for _, topDown := range []bool{false, true} {
+				// odd widths exercise the scan line padding
+				for _, w := range []int{1, 3, 5, 17} {
+					o := o
+					o.HeaderSize, o.TopDown = size, topDown
+					img := testImage(w, 3, o)
+					name := layoutName(o, w)
+
+					packed, err := EncodeWith(img, o)
+					if err != nil {
+						t.Fatalf("%s: %v", name, err)
+					}
+					h, err := ParseHeader(packed)
+					if err != nil {
+						t.Fatalf("%s: %v", name, err)
+					}
+					if h.TopDown() != topDown || h.Dx() != w || h.Dy() != 3 || h.Size != size {
+						t.Errorf("%s: header %+v", name, h)
+					}
+					if len(packed) != h.BitsOffset()+h.Stride()*3 || int(h.SizeImage) != h.Stride()*3 {
+						t.Errorf("%s: %d bytes with header %+v", name, len(packed), h)
+					}
+					got, err := Decode(packed)
+					if err != nil {
+						t.Fatalf("%s: %v", name, err)
+					}
+					checkSame(t, name, got, img)
+				}
+			}
+		}
+	}
+}
+
+func layoutName(o Options, w int) string {
+	return fmt.Sprintf("%d bpp, header %d, masks %#x %#x %#x %#x, top-down %v, width %d",
+		o.BitCount, o.HeaderSize, o.RedMask, o.GreenMask, o.BlueMask, o.AlphaMask, o.TopDown, w)
+}
+
+func TestCompression(t *testing.T) {
+	tests := []struct {
+		o    Options
+		want uint32
+	}{
+		{Options{}, BI_RGB},
+		{Options{BitCount: 16}, BI_RGB},
+		{Options{BitCount: 16, RedMask: 0xF800, GreenMask: 0x07E0, BlueMask: 0x001F}, BI_BITFIELDS},
+		{Options{BitCount: 32, AlphaMask: 0xFF000000, RedMask: 0xFF0000}, BI_ALPHABITFIELDS},
+		{Options{BitCount: 32, HeaderSize: BITMAPV5HEADER_SIZE, AlphaMask: 0xFF000000, RedMask: 0xFF0000}, BI_BITFIELDS},
+	}
+	for _, tt := range tests {
+		h, _, err := NewHeader(1, 1, nil, tt.o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h.Compression != tt.want {
+			t.Errorf("NewHeader(%+v).Compression = %d, want %d", tt.o, h.Compression, tt.want)
+		}
+	}
+
+	h, _, _ := NewHeader(1, 1, nil, Options{BitCount: 32, HeaderSize: BITMAPV4HEADER_SIZE})
+	if h.RedMask != 0xFF0000 || h.AlphaMask != 0xFF000000 || h.CSType != LCS_sRGB {
+		t.Errorf("V4 header %+v does not state the default masks", h)
+	}
+	h, _, _ = NewHeader(1, 1, nil, Options{BitCount: 16, HeaderSize: BITMAPV5HEADER_SIZE})
+	if h.RedMask != 0x7C00 || h.AlphaMask != 0 || h.Intent != LCS_GM_IMAGES {
+		t.Errorf("V5 header %+v", h)
+	}
+
+	for _, o := range []Options{{BitCount: 3}, {BitCount: 64}, {BitCount: 1, Palette: DefaultPalette(4)}} {
+		if _, _, err := NewHeader(1, 1, nil, o); err == nil {
+			t.Errorf("NewHeader accepted %+v", o)
+		}
+	}
+}
+
+func TestScanLineOrder(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 2))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 1, G: 2, B: 3, A: 4})
+	img.SetNRGBA(0, 1, color.NRGBA{R: 5, G: 6, B: 7, A: 8})
+
+	bottomUp := Encode(img)
+	if got, want := bottomUp[BITMAPINFOHEADER_SIZE:], []byte{7, 6, 5, 8, 3, 2, 1, 4}; !bytes.Equal(got, want) {
+		t.Errorf("bottom-up pixels % x, want % x", got, want)
+	}
+	if h, _ := ParseHeader(bottomUp); h.Height != 2 {
+		t.Errorf("bottom-up height %d", h.Height)
+	}
+
+	topDown, _ := EncodeWith(img, Options{TopDown: true})
+	if got, want := topDown[BITMAPINFOHEADER_SIZE:], []byte{3, 2, 1, 4, 7, 6, 5, 8}; !bytes.Equal(got, want) {
+		t.Errorf("top-down pixels % x, want % x", got, want)
+	}
+	if h, _ := ParseHeader(topDown); h.Height != -2 {
+		t.Errorf("top-down height %d", h.Height)
+	}
+
+	// 1 bpp packs the first pixel into the high bit, padded to a DWORD
+	p := image.NewPaletted(image.Rect(0, 0, 3, 1), DefaultPalette(1))
+	p.Pix = []uint8{1, 0, 1}
+	packed, _ := EncodeWith(p, Options{BitCount: 1})
+	if got, want := packed[BITMAPINFOHEADER_SIZE+8:], []byte{0xA0, 0, 0, 0}; !bytes.Equal(got, want) {
+		t.Errorf("1 bpp pixels % x, want % x", got, want)
+	}
+}
+
+func TestPalette(t *testing.T) {
+	pal := color.Palette{color.RGBA{0x10, 0x20, 0x30, 0xFF}, color.RGBA{0x40, 0x50, 0x60, 0xFF}}
+	b := EncodePalette(pal)
+	if want := []byte{0x30, 0x20, 0x10, 0, 0x60, 0x50, 0x40, 0}; !bytes.Equal(b, want) {
+		t.Errorf("EncodePalette = % x, want % x", b, want)
+	}
+	if got := DecodePalette(b); !reflect.DeepEqual(got, pal) {
+		t.Errorf("DecodePalette = %v, want %v", got, pal)
+	}
+
+	// a non-paletted image is mapped to the closest entries
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{0x41, 0x51, 0x61, 0xFF})
+	img.SetNRGBA(1, 0, color.NRGBA{0x11, 0x21, 0x31, 0xFF})
+	packed, err := EncodeWith(img, Options{BitCount: 8, Palette: pal})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Decode(packed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := got.(*image.Paletted); !bytes.Equal(p.Pix, []uint8{1, 0}) || len(p.Palette) != 2 {
+		t.Errorf("indices %v of %d colors", p.Pix, len(p.Palette))
+	}
+
+	if n := len(DefaultPalette(4)); n != 16 {
+		t.Errorf("DefaultPalette(4) has %d colors", n)
+	}
+	if n := len(DefaultPalette(8)); n != 256 {
+		t.Errorf("DefaultPalette(8) has %d colors", n)
+	}
+}
+
+func TestPremultiplied(t *testing.T) {
+	straight := color.NRGBA{R: 200, G: 100, B: 50, A: 128}
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	img.SetNRGBA(0, 0, straight)
+	pre := color.RGBAModel.Convert(straight).(color.RGBA)
+
+	h, _, err := NewHeader(1, 1, nil, Options{Premultiplied: true, TopDown: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !h.Premultiplied {
+		t.Fatal("NewHeader dropped Premultiplied")
+	}
+	bits, err := EncodeBits(&h, nil, img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{pre.B, pre.G, pre.R, pre.A}; !bytes.Equal(bits, want) {
+		t.Errorf("premultiplied pixel % x, want % x", bits, want)
+	}
+
+	got, err := DecodeBits(&h, nil, bits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rgba, ok := got.(*image.RGBA)
+	if !ok {
+		t.Fatalf("premultiplied pixels decode to %T", got)
+	}
+	if c := rgba.RGBAAt(0, 0); c != pre {
+		t.Errorf("decoded %v, want %v", c, pre)
+	}
+
+	// the same bytes read as straight alpha keep their values
+	h.Premultiplied = false
+	got, _ = DecodeBits(&h, nil, bits)
+	if c := got.(*image.NRGBA).NRGBAAt(0, 0); c != (color.NRGBA{pre.R, pre.G, pre.B, pre.A}) {
+		t.Errorf("straight decode %v", c)
+	}
+	bits, _ = EncodeBits(&h, nil, img)
+	if want := []byte{straight.B, straight.G, straight.R, straight.A}; !bytes.Equal(bits, want) {
+		t.Errorf("straight pixel % x, want % x", bits, want)
+	}
+
+	// a premultiplied bitmap round trips through every 32 bpp layout
+	src := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	for i := 0; i < len(src.Pix); i += 4 {
+		a := uint8(i / 4)
+		src.Pix[i], src.Pix[i+1], src.Pix[i+2], src.Pix[i+3] = a, a/2, a/3, a
+	}
+	for _, o := range []Options{
+		{Premultiplied: true},
+		{Premultiplied: true, HeaderSize: BITMAPV5HEADER_SIZE, RedMask: 0xFF0000, GreenMask: 0xFF00, BlueMask: 0xFF, AlphaMask: 0xFF000000},
+	} {
+		h, _, _ := NewHeader(16, 16, nil, o)
+		bits, err := EncodeBits(&h, nil, src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := DecodeBits(&h, nil, bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rgba, ok := got.(*image.RGBA); !ok || !bytes.Equal(rgba.Pix, src.Pix) {
+			t.Errorf("%+v: premultiplied round trip differs", o)
+		}
+	}
+}
+
+func TestPremultipliedClamp(t *testing.T) {
+	h := Header{Size: BITMAPINFOHEADER_SIZE, Width: 1, Height: 1, Planes: 1, BitCount: 32, Premultiplied: true}
+	got, err := DecodeBits(&h, nil, []byte{0xFF, 0x20, 0x90, 0x40})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, want := got.(*image.RGBA).RGBAAt(0, 0), (color.RGBA{R: 0x40, G: 0x20, B: 0x40, A: 0x40}); c != want {
+		t.Errorf("decoded %v, want %v", c, want)
+	}
+}
+
+func TestZeroAlphaIsOpaque(t *testing.T) {
+	for _, premultiplied := range []bool{false, true} {
+		h := Header{Size: BITMAPINFOHEADER_SIZE, Width: 2, Height: 1, Planes: 1, BitCount: 32, Premultiplied: premultiplied}
+		got, err := DecodeBits(&h, nil, []byte{1, 2, 3, 0, 4, 5, 6, 0})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.(interface{ Opaque() bool }).Opaque() {
+			t.Errorf("premultiplied %v: all zero alpha is not opaque", premultiplied)
+		}
+		if _, _, b, _ := got.At(1, 0).RGBA(); b != 0x0404 {
+			t.Errorf("premultiplied %v: blue %#x", premultiplied, b)
+		}
+
+		// a single non-zero alpha makes the channel count
+		got, _ = DecodeBits(&h, nil, []byte{1, 2, 3, 0, 4, 5, 6, 7})
+		if _, _, _, a := got.At(0, 0).RGBA(); a != 0 {
+			t.Errorf("premultiplied %v: alpha %#x, want transparent", premultiplied, a)
+		}
+	}
+}
+
+func TestParseHeaderErrors(t *testing.T) {
+	valid := func() []byte {
+		h, _, _ := NewHeader(2, 2, nil, Options{})
+		return h.Bytes()
+	}
+	put := func(off int, v uint32) []byte {
+		b := valid()
+		binary.LittleEndian.PutUint32(b[off:], v)
+		return b
+	}
+	tests := []struct {
+		name string
+		b    []byte
+		want error
+	}{
+		{"empty", nil, ErrFormat},
+		{"short", valid()[:20], ErrFormat},
+		{"core header", put(0, 12), ErrUnsupported},
+		{"zero width", put(4, 0), ErrFormat},
+		{"negative width", put(4, 0xFFFFFFFF), ErrFormat},
+		{"zero height", put(8, 0), ErrFormat},
+		{"huge width", put(4, 1<<16+1), ErrFormat},
+		{"huge height", put(8, uint32(-(1<<16+1)&0xFFFFFFFF)), ErrFormat},
+		{"huge palette", put(32, 257), ErrFormat},
+		{"missing masks", put(16, BI_BITFIELDS), ErrFormat},
+	}
+	for _, tt := range tests {
+		if _, err := ParseHeader(tt.b); errors.Cause(err) != tt.want {
+			t.Errorf("%s: ParseHeader = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	if _, err := Decode(append(put(14, 12), make([]byte, 16)...)); errors.Cause(err) != ErrUnsupported {
+		t.Errorf("12 bpp: %v", err)
+	}
+	if _, err := Decode(append(put(16, 1), make([]byte, 16)...)); errors.Cause(err) != ErrUnsupported {
+		t.Errorf("BI_RLE8: %v", err)
+	}
+	if _, err := Decode(valid()); errors.Cause(err) != ErrFormat {
+		t.Errorf("missing pixels: %v", err)
+	}
+	h, _, _ := NewHeader(2, 2, nil, Options{BitCount: 8})
+	if _, err := Decode(h.Bytes()); errors.Cause(err) != ErrFormat {
+		t.Errorf("missing color table: %v", err)
+	}
+}
+
+func TestHeaderBytes(t *testing.T) {
+	for _, o := range []Options{
+		{},
+		{BitCount: 16, RedMask: 0xF800, GreenMask: 0x07E0, BlueMask: 0x001F},
+		{AlphaMask: 0xFF000000, RedMask: 0xFF0000, GreenMask: 0xFF00, BlueMask: 0xFF},
+		{HeaderSize: BITMAPV4HEADER_SIZE, TopDown: true},
+		{HeaderSize: BITMAPV5HEADER_SIZE, BitCount: 8},
+	} {
+		h, _, err := NewHeader(7, 5, nil, o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ParseHeader(h.Bytes())
+		if err != nil {
+			t.Fatalf("%+v: %v", o, err)
+		}
+		if !reflect.DeepEqual(got, h) {
+			t.Errorf("ParseHeader(%+v.Bytes()) = %+v", h, got)
+		}
+	}
+}
+
+func TestStride(t *testing.T) {
+	tests := []struct{ width, bitCount, want int }{
+		{1, 1, 4}, {32, 1, 4}, {33, 1, 8},
+		{7, 4, 4}, {9, 4, 8},
+		{3, 8, 4}, {5, 8, 8},
+		{1, 16, 4}, {3, 16, 8},
+		{1, 24, 4}, {2, 24, 8}, {4, 24, 12},
+		{3, 32, 12},
+	}
+	for _, tt := range tests {
+		if got := Stride(tt.width, tt.bitCount); got != tt.want {
+			t.Errorf("Stride(%d, %d) = %d, want %d", tt.width, tt.bitCount, got, tt.want)
+		}
+	}
+}
+
+func TestBMP(t *testing.T) {
+	img := testImage(5, 4, Options{BitCount: 32, HeaderSize: BITMAPV5HEADER_SIZE})
+	o := Options{HeaderSize: BITMAPV5HEADER_SIZE, RedMask: 0xFF0000, GreenMask: 0xFF00, BlueMask: 0xFF, AlphaMask: 0xFF000000}
+	b, err := EncodeBMP(img, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b[:2]) != "BM" || int(binary.LittleEndian.Uint32(b[2:])) != len(b) {
+		t.Errorf("file header % x", b[:BITMAPFILEHEADER_SIZE])
+	}
+	if off := binary.LittleEndian.Uint32(b[10:]); off != BITMAPFILEHEADER_SIZE+BITMAPV5HEADER_SIZE {
+		t.Errorf("pixel offset %d", off)
+	}
+	got, err := DecodeBMP(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSame(t, "BMP", got, img)
+
+	// pixels behind padding are found through the offset
+	padded := append([]byte(nil), b[:BITMAPFILEHEADER_SIZE+BITMAPV5HEADER_SIZE]...)
+	padded = append(padded, 0, 0, 0, 0)
+	padded = append(padded, b[BITMAPFILEHEADER_SIZE+BITMAPV5HEADER_SIZE:]...)
+	binary.LittleEndian.PutUint32(padded[10:], BITMAPFILEHEADER_SIZE+BITMAPV5HEADER_SIZE+4)
+	if got, err = DecodeBMP(padded); err != nil {
+		t.Fatal(err)
+	}
+	checkSame(t, "padded BMP", got, img)
+
+	binary.LittleEndian.PutUint32(padded[10:], uint32(len(padded)+1))
+	if _, err := DecodeBMP(padded); errors.Cause(err) != ErrFormat {
+		t.Errorf("offset beyond the file: %v", err)
+	}
+	if _, err := DecodeBMP([]byte("GIF89a..............")); errors.Cause(err) != ErrFormat {
+		t.Errorf("not a BMP: %v", err)
+	}
+}
+
+func TestToRGBA(t *testing.T) {
+	for _, bitCount := range []uint16{24, 32} {
+		for _, topDown := range []bool{false, true} {
+			src := testImage(3, 2, Options{BitCount: 24})
+			h, _, _ := NewHeader(3, 2, nil, Options{BitCount: bitCount, TopDown: topDown})
+			bits, _ := EncodeBits(&h, nil, src)
+			if bitCount == 32 {
+				// GDI leaves garbage in the reserved byte
+				for i := 3; i < len(bits); i += 4 {
+					bits[i] = 0x12
+				}
+			}
+			got, err := ToRGBA(&h, bits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Opaque() {
+				t.Errorf("%d bpp: not opaque", bitCount)
+			}
+			checkSame(t, "ToRGBA", got, src)
+		}
+	}
+
+	h, _, _ := NewHeader(1, 1, nil, Options{BitCount: 16})
+	if _, err := ToRGBA(&h, make([]byte, 4)); errors.Cause(err) != ErrUnsupported {
+		t.Errorf("16 bpp: %v", err)
+	}
+	h, _, _ = NewHeader(2, 2, nil, Options{})
+	if _, err := ToRGBA(&h, make([]byte, 8)); errors.Cause(err) != ErrFormat {
+		t.Errorf("short pixels: %v", err)
+	}
+}
+
+func TestBGRA(t *testing.T) {
+	p := NewBGRA(image.Rect(10, 20, 14, 23))
+	if len(p.Pix) != 4*4*3 || p.Stride != 16 {
+		t.Fatalf("NewBGRA: %d bytes, stride %d", len(p.Pix), p.Stride)
+	}
+	if p.Opaque() {
+		t.Error("a new image is opaque")
+	}
+
+	p.Set(11, 21, color.NRGBA{R: 200, G: 100, B: 50, A: 128})
+	want := color.RGBA{R: 100, G: 50, B: 25, A: 128}
+	if c := p.BGRAAt(11, 21); c != want {
+		t.Errorf("BGRAAt = %v, want %v", c, want)
+	}
+	if off := p.PixOffset(11, 21); !bytes.Equal(p.Pix[off:off+4], []byte{25, 50, 100, 128}) {
+		t.Errorf("pixel bytes % x", p.Pix[off:off+4])
+	}
+	if c := p.At(11, 21); c != want {
+		t.Errorf("At = %v", c)
+	}
+
+	// outside the bounds nothing is read or written
+	p.Set(9, 20, color.White)
+	p.SetBGRA(14, 20, want)
+	if c := p.BGRAAt(0, 0); c != (color.RGBA{}) {
+		t.Errorf("BGRAAt outside = %v", c)
+	}
+
+	sub := p.SubImage(image.Rect(11, 21, 20, 30)).(*BGRA)
+	if sub.Bounds() != image.Rect(11, 21, 14, 23) {
+		t.Errorf("SubImage bounds %v", sub.Bounds())
+	}
+	sub.SetBGRA(13, 22, color.RGBA{1, 2, 3, 4})
+	if c := p.BGRAAt(13, 22); c != (color.RGBA{1, 2, 3, 4}) {
+		t.Error("SubImage does not share the pixels")
+	}
+	if e := p.SubImage(image.Rect(0, 0, 5, 5)); !e.Bounds().Empty() {
+		t.Errorf("disjoint SubImage %v", e.Bounds())
+	}
+
+	for y := 21; y < 23; y++ {
+		for x := 11; x < 14; x++ {
+			sub.SetBGRA(x, y, color.RGBA{A: 0xFF})
+		}
+	}
+	if !sub.Opaque() {
+		t.Error("filled SubImage is not opaque")
+	}
+	if p.Opaque() {
+		t.Error("the pixels outside the SubImage are opaque")
+	}
+
+	// BGRA has the byte order of a 32 bpp BI_RGB DIB
+	h, _, _ := NewHeader(4, 3, nil, Options{TopDown: true, Premultiplied: true})
+	bits, _ := EncodeBits(&h, nil, p)
+	if !bytes.Equal(bits, p.Pix) {
+		t.Error("EncodeBits of a BGRA differs from its pixels")
+	}
+}
@@ -0,0 +1,262 @@
+package dib
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/color/palette"
+
+	"github.com/pkg/errors"
+)
+
+// Options controls the DIB layout produced by EncodeWith.
+type Options struct {
+	// BitCount is 1, 2, 4, 8, 16, 24 or 32, zero means 32.
+	BitCount uint16
+
+	// HeaderSize selects BITMAPINFOHEADER_SIZE (the default),
+	// BITMAPV4HEADER_SIZE or BITMAPV5HEADER_SIZE.
+	HeaderSize uint32
+
+	// Masks of 16 and 32 bpp images. All zero keeps BI_RGB with its default
+	// layout; anything else is written as BI_BITFIELDS.
+	RedMask, GreenMask, BlueMask, AlphaMask uint32
+
+	// Palette of images up to 8 bpp. Nil uses the palette of an
+	// *image.Paletted or a standard palette.
+	Palette color.Palette
+
+	// TopDown stores the first scan line first, with a negative height.
+	TopDown bool
+
+	// Premultiplied stores 16 and 32 bpp colors premultiplied by alpha,
+	// the layout of DIB sections drawn with AlphaBlend or
+	// UpdateLayeredWindow. Files and the clipboard use straight alpha.
+	// Decoding a header with Premultiplied set returns an *image.RGBA.
+	Premultiplied bool
+}
+
+// Encode encodes img as a 32 bpp BI_RGB packed DIB for CF_DIB, the alpha
+// channel is kept in the reserved byte.
+func Encode(img image.Image) []byte {
+	b, _ := EncodeWith(img, Options{})
+	return b
+}
+
+// EncodeV5 encodes img as a 32 bpp BITMAPV5HEADER packed DIB with an alpha
+// mask for CF_DIBV5.
+func EncodeV5(img image.Image) []byte {
+	b, _ := EncodeWith(img, Options{
+		HeaderSize: BITMAPV5HEADER_SIZE,
+		RedMask:    0x00FF0000,
+		GreenMask:  0x0000FF00,
+		BlueMask:   0x000000FF,
+		AlphaMask:  0xFF000000,
+	})
+	return b
+}
+
+// EncodeWith encodes img as a packed DIB laid out according to o.
+func EncodeWith(img image.Image, o Options) ([]byte, error) {
+	h, pal, err := NewHeader(img.Bounds().Dx(), img.Bounds().Dy(), img, o)
+	if err != nil {
+		return nil, err
+	}
+	out := h.Bytes()
+	out = append(out, EncodePalette(pal)...)
+	bits, err := EncodeBits(&h, pal, img)
+	if err != nil {
+		return nil, err
+	}
+	return append(out, bits...), nil
+}
+
+// NewHeader returns the header and color table for a width x height DIB
+// laid out according to o. img may be nil, it only supplies a palette.
+func NewHeader(width, height int, img image.Image, o Options) (Header, color.Palette, error) {
+	h := Header{
+		Size:     o.HeaderSize,
+		Width:    int32(width),
+		Height:   int32(height),
+		Planes:   1,
+		BitCount: o.BitCount,
+
+		Premultiplied: o.Premultiplied,
+	}
+	if h.Size == 0 {
+		h.Size = BITMAPINFOHEADER_SIZE
+	}
+	if h.BitCount == 0 {
+		h.BitCount = 32
+	}
+	if o.TopDown {
+		h.Height = -h.Height
+	}
+	if h.Size >= BITMAPV4HEADER_SIZE {
+		h.CSType = LCS_sRGB
+	}
+	if h.Size >= BITMAPV5HEADER_SIZE {
+		h.Intent = LCS_GM_IMAGES
+	}
+
+	var pal color.Palette
+	switch h.BitCount {
+	case 1, 2, 4, 8:
+		pal = o.Palette
+		if pal == nil {
+			if p, ok := img.(*image.Paletted); ok && len(p.Palette) <= 1<<h.BitCount {
+				pal = p.Palette
+			} else {
+				pal = DefaultPalette(h.BitCount)
+			}
+		}
+		if len(pal) > 1<<h.BitCount {
+			return h, nil, errors.Errorf("dib: %d colors do not fit %d bpp", len(pal), h.BitCount)
+		}
+		h.ClrUsed = uint32(len(pal))
+	case 16, 32:
+		if o.RedMask|o.GreenMask|o.BlueMask|o.AlphaMask != 0 {
+			h.Compression = BI_BITFIELDS
+			h.RedMask, h.GreenMask, h.BlueMask, h.AlphaMask = o.RedMask, o.GreenMask, o.BlueMask, o.AlphaMask
+			if h.Size == BITMAPINFOHEADER_SIZE && h.AlphaMask != 0 {
+				h.Compression = BI_ALPHABITFIELDS
+			}
+		} else if h.Size > BITMAPINFOHEADER_SIZE {
+			// V4 and V5 headers state the masks even for BI_RGB
+			h.RedMask, h.GreenMask, h.BlueMask, h.AlphaMask = h.masks()
+			if h.BitCount == 16 {
+				h.AlphaMask = 0
+			}
+		}
+	case 24:
+	default:
+		return h, nil, errors.Wrapf(ErrUnsupported, "%d bits per pixel", h.BitCount)
+	}
+	h.SizeImage = uint32(h.Stride() * h.Dy())
+	return h, pal, nil
+}
+
+// DefaultPalette returns the palette used for images that carry none.
+func DefaultPalette(bitCount uint16) color.Palette {
+	switch bitCount {
+	case 1:
+		return color.Palette{color.RGBA{0, 0, 0, 0xFF}, color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}}
+	case 2:
+		return color.Palette{
+			color.RGBA{0, 0, 0, 0xFF}, color.RGBA{0x55, 0x55, 0x55, 0xFF},
+			color.RGBA{0xAA, 0xAA, 0xAA, 0xFF}, color.RGBA{0xFF, 0xFF, 0xFF, 0xFF},
+		}
+	case 4:
+		// the 16 color VGA palette
+		var p color.Palette
+		for _, c := range []uint32{
+			0x000000, 0x800000, 0x008000, 0x808000, 0x000080, 0x800080, 0x008080, 0xC0C0C0,
+			0x808080, 0xFF0000, 0x00FF00, 0xFFFF00, 0x0000FF, 0xFF00FF, 0x00FFFF, 0xFFFFFF,
+		} {
+			p = append(p, color.RGBA{uint8(c >> 16), uint8(c >> 8), uint8(c), 0xFF})
+		}
+		return p
+	}
+	return palette.Plan9
+}
+
+// EncodePalette encodes p as RGBQUAD entries.
+func EncodePalette(p color.Palette) []byte {
+	b := make([]byte, len(p)*4)
+	for i, c := range p {
+		r, g, bl, _ := c.RGBA()
+		b[i*4+0] = uint8(bl >> 8)
+		b[i*4+1] = uint8(g >> 8)
+		b[i*4+2] = uint8(r >> 8)
+	}
+	return b
+}
+
+// EncodeBits encodes the pixels of img in the layout described by h.
+func EncodeBits(h *Header, pal color.Palette, img image.Image) ([]byte, error) {
+	bits := make([]byte, h.Stride()*h.Dy())
+	return bits, PutBits(h, pal, img, bits)
+}
+
+// PutBits writes the pixels of img into bits, which is laid out as described by h.
+func PutBits(h *Header, pal color.Palette, img image.Image, bits []byte) error {
+	w, ht, stride := h.Dx(), h.Dy(), h.Stride()
+	if len(bits) < stride*ht {
+		return errors.Wrapf(ErrFormat, "%d bytes of pixels, need %d", len(bits), stride*ht)
+	}
+	b := img.Bounds()
+	if b.Dx() < w || b.Dy() < ht {
+		return errors.Errorf("dib: image of %dx%d is smaller than %dx%d", b.Dx(), b.Dy(), w, ht)
+	}
+	row := func(y int) []byte {
+		if !h.TopDown() {
+			y = ht - 1 - y
+		}
+		return bits[y*stride : (y+1)*stride]
+	}
+
+	switch h.BitCount {
+	case 1, 2, 4, 8:
+		bpp := uint(h.BitCount)
+		paletted, samePalette := img.(*image.Paletted)
+		if samePalette {
+			samePalette = len(paletted.Palette) <= len(pal)
+			for i := 0; samePalette && i < len(paletted.Palette); i++ {
+				samePalette = paletted.Palette[i] == pal[i]
+			}
+		}
+		for y := 0; y < ht; y++ {
+			dst := row(y)
+			for i := range dst {
+				dst[i] = 0
+			}
+			for x := 0; x < w; x++ {
+				var idx int
+				if samePalette {
+					idx = int(paletted.ColorIndexAt(b.Min.X+x, b.Min.Y+y))
+				} else {
+					idx = pal.Index(img.At(b.Min.X+x, b.Min.Y+y))
+				}
+				bit := uint(x) * bpp
+				dst[bit/8] |= byte(idx) << (8 - bpp - bit%8)
+			}
+		}
+	case 24:
+		for y := 0; y < ht; y++ {
+			dst := row(y)
+			for x := 0; x < w; x++ {
+				c := color.NRGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+				dst[x*3+0], dst[x*3+1], dst[x*3+2] = c.B, c.G, c.R
+			}
+		}
+	case 16, 32:
+		rm, gm, bm, am := h.masks()
+		r, g, bl, a := newChannel(rm), newChannel(gm), newChannel(bm), newChannel(am)
+		for y := 0; y < ht; y++ {
+			dst := row(y)
+			for x := 0; x < w; x++ {
+				cr, cg, cb, ca := channels(img.At(b.Min.X+x, b.Min.Y+y), h.Premultiplied)
+				v := r.put(cr) | g.put(cg) | bl.put(cb) | a.put(ca)
+				if h.BitCount == 16 {
+					binary.LittleEndian.PutUint16(dst[x*2:], uint16(v))
+				} else {
+					binary.LittleEndian.PutUint32(dst[x*4:], v)
+				}
+			}
+		}
+	default:
+		return errors.Wrapf(ErrUnsupported, "%d bits per pixel", h.BitCount)
+	}
+	return nil
+}
+
+// channels returns the 8 bit channels of c with straight or premultiplied
+// alpha.
+func channels(c color.Color, premultiplied bool) (r, g, b, a uint8) {
+	if premultiplied {
+		p := color.RGBAModel.Convert(c).(color.RGBA)
+		return p.R, p.G, p.B, p.A
+	}
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return n.R, n.G, n.B, n.A
+}
@@ -0,0 +1,263 @@
+// Package dib encodes and decodes device independent bitmaps, the pixel
+// format behind CreateDIBSection, GetDIBits, CF_DIB/CF_DIBV5 and BMP files.
+package dib
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/pkg/errors"
+)
+
+// BITMAPINFOHEADER biCompression
+const (
+	BI_RGB            uint32 = 0
+	BI_RLE8           uint32 = 1
+	BI_RLE4           uint32 = 2
+	BI_BITFIELDS      uint32 = 3
+	BI_JPEG           uint32 = 4
+	BI_PNG            uint32 = 5
+	BI_ALPHABITFIELDS uint32 = 6
+)
+
+// Header sizes identifying the header version
+const (
+	BITMAPINFOHEADER_SIZE = 40
+	BITMAPV4HEADER_SIZE   = 108
+	BITMAPV5HEADER_SIZE   = 124
+)
+
+// LOGCOLORSPACE lcsCSType
+const (
+	LCS_CALIBRATED_RGB      uint32 = 0
+	LCS_sRGB                uint32 = 0x73524742
+	LCS_WINDOWS_COLOR_SPACE uint32 = 0x57696E20
+)
+
+const LCS_GM_IMAGES uint32 = 4
+
+var (
+	ErrFormat      = errors.New("dib: invalid format")
+	ErrUnsupported = errors.New("dib: unsupported format")
+)
+
+// Header is a BITMAPINFOHEADER, BITMAPV4HEADER or BITMAPV5HEADER; Size tells which.
+// The color masks are filled from the header for V4/V5 and from the DWORDs
+// following a BITMAPINFOHEADER with BI_BITFIELDS.
+type Header struct {
+	Size          uint32
+	Width         int32
+	Height        int32
+	Planes        uint16
+	BitCount      uint16
+	Compression   uint32
+	SizeImage     uint32
+	XPelsPerMeter int32
+	YPelsPerMeter int32
+	ClrUsed       uint32
+	ClrImportant  uint32
+
+	RedMask   uint32
+	GreenMask uint32
+	BlueMask  uint32
+	AlphaMask uint32
+	CSType    uint32
+	Intent    uint32
+
+	// Premultiplied is not part of the binary header, see Options.
+	Premultiplied bool
+}
+
+// TopDown reports whether the first scan line is the top one.
+func (h *Header) TopDown() bool {
+	return h.Height < 0
+}
+
+// Dx and Dy return the image size in pixels.
+func (h *Header) Dx() int {
+	return int(h.Width)
+}
+
+func (h *Header) Dy() int {
+	if h.Height < 0 {
+		return -int(h.Height)
+	}
+	return int(h.Height)
+}
+
+// Stride returns the length of a scan line, padded to a DWORD boundary.
+func (h *Header) Stride() int {
+	return Stride(h.Dx(), int(h.BitCount))
+}
+
+// Stride returns the DWORD aligned length of a scan line of width pixels.
+func Stride(width, bitCount int) int {
+	return (width*bitCount + 31) / 32 * 4
+}
+
+// PaletteLen returns the number of RGBQUAD entries of the color table.
+func (h *Header) PaletteLen() int {
+	if h.ClrUsed != 0 {
+		return int(h.ClrUsed)
+	}
+	if h.BitCount <= 8 {
+		return 1 << h.BitCount
+	}
+	return 0
+}
+
+// maskBytes returns the length of the masks stored after a BITMAPINFOHEADER.
+func (h *Header) maskBytes() int {
+	if h.Size != BITMAPINFOHEADER_SIZE {
+		return 0
+	}
+	switch h.Compression {
+	case BI_BITFIELDS:
+		return 12
+	case BI_ALPHABITFIELDS:
+		return 16
+	}
+	return 0
+}
+
+// BitsOffset returns the offset of the pixel data in a packed DIB.
+func (h *Header) BitsOffset() int {
+	return int(h.Size) + h.maskBytes() + h.PaletteLen()*4
+}
+
+// masks returns the effective channel masks of 16 and 32 bpp images.
+func (h *Header) masks() (r, g, b, a uint32) {
+	if h.Compression == BI_BITFIELDS || h.Compression == BI_ALPHABITFIELDS {
+		return h.RedMask, h.GreenMask, h.BlueMask, h.AlphaMask
+	}
+	switch h.BitCount {
+	case 16:
+		return 0x7C00, 0x03E0, 0x001F, 0
+	case 32:
+		// the high byte of BI_RGB is reserved, it holds alpha in practice
+		return 0x00FF0000, 0x0000FF00, 0x000000FF, 0xFF000000
+	}
+	return 0, 0, 0, 0
+}
+
+// ParseHeader reads the header and trailing bit field masks of a packed DIB.
+func ParseHeader(b []byte) (Header, error) {
+	var h Header
+	if len(b) < 4 {
+		return h, errors.Wrap(ErrFormat, "short header")
+	}
+	le := binary.LittleEndian
+	h.Size = le.Uint32(b)
+	if h.Size < BITMAPINFOHEADER_SIZE {
+		return h, errors.Wrapf(ErrUnsupported, "header size %d", h.Size)
+	}
+	if len(b) < int(h.Size) {
+		return h, errors.Wrap(ErrFormat, "short header")
+	}
+	h.Width = int32(le.Uint32(b[4:]))
+	h.Height = int32(le.Uint32(b[8:]))
+	h.Planes = le.Uint16(b[12:])
+	h.BitCount = le.Uint16(b[14:])
+	h.Compression = le.Uint32(b[16:])
+	h.SizeImage = le.Uint32(b[20:])
+	h.XPelsPerMeter = int32(le.Uint32(b[24:]))
+	h.YPelsPerMeter = int32(le.Uint32(b[28:]))
+	h.ClrUsed = le.Uint32(b[32:])
+	h.ClrImportant = le.Uint32(b[36:])
+
+	switch {
+	case h.Size >= 56:
+		// BITMAPV2INFOHEADER and later carry the masks in the header
+		h.RedMask = le.Uint32(b[40:])
+		h.GreenMask = le.Uint32(b[44:])
+		h.BlueMask = le.Uint32(b[48:])
+		h.AlphaMask = le.Uint32(b[52:])
+		if h.Size >= BITMAPV4HEADER_SIZE {
+			h.CSType = le.Uint32(b[56:])
+		}
+		if h.Size >= BITMAPV5HEADER_SIZE {
+			h.Intent = le.Uint32(b[108:])
+		}
+	case h.maskBytes() > 0:
+		if len(b) < int(h.Size)+h.maskBytes() {
+			return h, errors.Wrap(ErrFormat, "short bit field masks")
+		}
+		h.RedMask = le.Uint32(b[40:])
+		h.GreenMask = le.Uint32(b[44:])
+		h.BlueMask = le.Uint32(b[48:])
+		if h.maskBytes() == 16 {
+			h.AlphaMask = le.Uint32(b[52:])
+		}
+	}
+
+	if h.Width <= 0 || h.Height == 0 || h.Width > 1<<16 || h.Dy() > 1<<16 {
+		return h, errors.Wrapf(ErrFormat, "size %dx%d", h.Width, h.Height)
+	}
+	if h.PaletteLen() > 256 {
+		return h, errors.Wrapf(ErrFormat, "palette of %d colors", h.PaletteLen())
+	}
+	return h, nil
+}
+
+// Bytes encodes the header followed by the bit field masks a BITMAPINFOHEADER needs.
+func (h *Header) Bytes() []byte {
+	b := make([]byte, int(h.Size)+h.maskBytes())
+	le := binary.LittleEndian
+	le.PutUint32(b, h.Size)
+	le.PutUint32(b[4:], uint32(h.Width))
+	le.PutUint32(b[8:], uint32(h.Height))
+	le.PutUint16(b[12:], h.Planes)
+	le.PutUint16(b[14:], h.BitCount)
+	le.PutUint32(b[16:], h.Compression)
+	le.PutUint32(b[20:], h.SizeImage)
+	le.PutUint32(b[24:], uint32(h.XPelsPerMeter))
+	le.PutUint32(b[28:], uint32(h.YPelsPerMeter))
+	le.PutUint32(b[32:], h.ClrUsed)
+	le.PutUint32(b[36:], h.ClrImportant)
+	if h.Size >= 56 || h.maskBytes() > 0 {
+		le.PutUint32(b[40:], h.RedMask)
+		le.PutUint32(b[44:], h.GreenMask)
+		le.PutUint32(b[48:], h.BlueMask)
+		if h.Size >= 56 || h.maskBytes() == 16 {
+			le.PutUint32(b[52:], h.AlphaMask)
+		}
+	}
+	if h.Size >= BITMAPV4HEADER_SIZE {
+		le.PutUint32(b[56:], h.CSType)
+	}
+	if h.Size >= BITMAPV5HEADER_SIZE {
+		le.PutUint32(b[108:], h.Intent)
+	}
+	return b
+}
+
+// channel extracts and scales one color channel described by a mask.
+type channel struct {
+	mask  uint32
+	shift uint
+	max   uint32
+}
+
+func newChannel(mask uint32) channel {
+	if mask == 0 {
+		return channel{}
+	}
+	shift := uint(bits.TrailingZeros32(mask))
+	return channel{mask: mask, shift: shift, max: mask >> shift}
+}
+
+func (c channel) get(v uint32) uint8 {
+	if c.mask == 0 {
+		return 0
+	}
+	x := uint64((v & c.mask) >> c.shift)
+	return uint8((x*255 + uint64(c.max)/2) / uint64(c.max))
+}
+
+func (c channel) put(v uint8) uint32 {
+	if c.mask == 0 {
+		return 0
+	}
+	x := (uint64(v)*uint64(c.max) + 127) / 255
+	return (uint32(x) << c.shift) & c.mask
+}
@@ -0,0 +1,36 @@
+package dib
+
+import (
+	"image"
+
+	"github.com/pkg/errors"
+)
+
+// ToRGBA converts 24 or 32 bpp BI_RGB pixels as rendered by GDI into an
+// *image.RGBA. GDI leaves the reserved byte of 32 bpp pixels undefined,
+// so the result is opaque.
+func ToRGBA(h *Header, bits []byte) (*image.RGBA, error) {
+	if h.Compression != BI_RGB || h.BitCount != 24 && h.BitCount != 32 {
+		return nil, errors.Wrapf(ErrUnsupported, "%d bpp with compression %d", h.BitCount, h.Compression)
+	}
+	w, ht, stride := h.Dx(), h.Dy(), h.Stride()
+	if len(bits) < stride*ht {
+		return nil, errors.Wrapf(ErrFormat, "%d bytes of pixels, need %d", len(bits), stride*ht)
+	}
+
+	bpp := int(h.BitCount) / 8
+	img := image.NewRGBA(image.Rect(0, 0, w, ht))
+	for y := 0; y < ht; y++ {
+		sy := y
+		if !h.TopDown() {
+			sy = ht - 1 - y
+		}
+		src := bits[sy*stride:]
+		dst := img.Pix[y*img.Stride:]
+		for x := 0; x < w; x++ {
+			s, d := src[x*bpp:], dst[x*4:]
+			d[0], d[1], d[2], d[3] = s[2], s[1], s[0], 0xFF
+		}
+	}
+	return img, nil
+}
@@ -0,0 +1,89 @@
+package dib
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+// Pixels as GDI renders them into a DIB section: BGR order, rows padded to
+// 4 bytes, undefined reserved bytes.
+func TestToRGBAFixtures(t *testing.T) {
+	tests := []struct {
+		name   string
+		header Header
+		bits   []byte
+		// want holds the RGBA rows from the top
+		want []byte
+	}{
+		{
+			"24 bpp bottom-up",
+			Header{Size: BITMAPINFOHEADER_SIZE, Width: 3, Height: 2, Planes: 1, BitCount: 24},
+			[]byte{
+				// bottom row: white, gray, black, then 3 bytes of padding
+				0xFF, 0xFF, 0xFF, 0x80, 0x80, 0x80, 0x00, 0x00, 0x00, 0xEE, 0xEE, 0xEE,
+				// top row: blue, green, red
+				0xFF, 0x00, 0x00, 0x00, 0xFF, 0x00, 0x00, 0x00, 0xFF, 0xEE, 0xEE, 0xEE,
+			},
+			[]byte{
+				0x00, 0x00, 0xFF, 0xFF, 0x00, 0xFF, 0x00, 0xFF, 0xFF, 0x00, 0x00, 0xFF,
+				0xFF, 0xFF, 0xFF, 0xFF, 0x80, 0x80, 0x80, 0xFF, 0x00, 0x00, 0x00, 0xFF,
+			},
+		},
+		{
+			"32 bpp top-down",
+			Header{Size: BITMAPINFOHEADER_SIZE, Width: 2, Height: -2, Planes: 1, BitCount: 32},
+			[]byte{
+				0x10, 0x20, 0x30, 0x00, 0x40, 0x50, 0x60, 0x7F,
+				0x01, 0x02, 0x03, 0xFF, 0xAA, 0xBB, 0xCC, 0x12,
+			},
+			[]byte{
+				0x30, 0x20, 0x10, 0xFF, 0x60, 0x50, 0x40, 0xFF,
+				0x03, 0x02, 0x01, 0xFF, 0xCC, 0xBB, 0xAA, 0xFF,
+			},
+		},
+		{
+			"32 bpp bottom-up with trailing bytes",
+			Header{Size: BITMAPINFOHEADER_SIZE, Width: 1, Height: 2, Planes: 1, BitCount: 32},
+			[]byte{0x01, 0x02, 0x03, 0x00, 0x04, 0x05, 0x06, 0x00, 0x99, 0x99},
+			[]byte{0x06, 0x05, 0x04, 0xFF, 0x03, 0x02, 0x01, 0xFF},
+		},
+		{
+			"24 bpp single pixel",
+			Header{Size: BITMAPINFOHEADER_SIZE, Width: 1, Height: 1, Planes: 1, BitCount: 24},
+			[]byte{0x0C, 0x0B, 0x0A, 0x00},
+			[]byte{0x0A, 0x0B, 0x0C, 0xFF},
+		},
+	}
+	for _, tt := range tests {
+		img, err := ToRGBA(&tt.header, tt.bits)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		w, h := tt.header.Dx(), tt.header.Dy()
+		if img.Rect != image.Rect(0, 0, w, h) || img.Stride != 4*w {
+			t.Errorf("%s: bounds %v, stride %d", tt.name, img.Rect, img.Stride)
+			continue
+		}
+		if !bytes.Equal(img.Pix, tt.want) {
+			t.Errorf("%s: pixels\n% x\nwant\n% x", tt.name, img.Pix, tt.want)
+		}
+	}
+}
+
+func TestToRGBAShort(t *testing.T) {
+	// the last row needs its padding too
+	h := Header{Size: BITMAPINFOHEADER_SIZE, Width: 3, Height: 2, Planes: 1, BitCount: 24}
+	if _, err := ToRGBA(&h, make([]byte, 23)); err == nil {
+		t.Error("ToRGBA accepted 23 bytes for two padded rows of 12")
+	}
+	for _, h := range []Header{
+		{Size: BITMAPINFOHEADER_SIZE, Width: 1, Height: 1, Planes: 1, BitCount: 8},
+		{Size: BITMAPINFOHEADER_SIZE, Width: 1, Height: 1, Planes: 1, BitCount: 32, Compression: BI_BITFIELDS},
+	} {
+		if _, err := ToRGBA(&h, make([]byte, 16)); err == nil {
+			t.Errorf("ToRGBA accepted %d bpp with compression %d", h.BitCount, h.Compression)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package display
+
+import (
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi"
+)
+
+// HMONITOR returns the monitor handle, for example for
+// IGraphicsCaptureItemInterop.CreateForMonitor.
+func (m Monitor) HMONITOR() win.HMONITOR {
+	return win.HMONITOR(m.Handle)
+}
+
+func (r Rect) RECT() win.RECT {
+	return win.RECT(r)
+}
+
+// enumMonitors collects the handles passed to enumMonitorsProc.
+var enumMonitors struct {
+	sync.Mutex
+	handles []win.HMONITOR
+}
+
+var enumMonitorsProc = syscall.NewCallback(func(hmonitor win.HMONITOR, hdc win.HDC, rect *win.RECT, data uintptr) uintptr {
+	enumMonitors.handles = append(enumMonitors.handles, hmonitor)
+	return 1
+})
+
+// ListMonitors returns every monitor of the virtual desktop. The coordinates
+// are physical pixels when the process is per-monitor DPI aware.
+func ListMonitors() ([]Monitor, error) {
+	enumMonitors.Lock()
+	enumMonitors.handles = nil
+	err := winapi.EnumDisplayMonitors(0, nil, enumMonitorsProc, 0)
+	handles := enumMonitors.handles
+	enumMonitors.Unlock()
+	if err != nil {
+		return nil, errors.Wrap(err, "EnumDisplayMonitors")
+	}
+
+	monitors := make([]Monitor, 0, len(handles))
+	for _, h := range handles {
+		m, err := GetMonitor(h)
+		if err != nil {
+			return nil, err
+		}
+		monitors = append(monitors, m)
+	}
+	return monitors, nil
+}
+
+// GetMonitor describes the monitor h.
+func GetMonitor(h win.HMONITOR) (Monitor, error) {
+	var mi winapi.MONITORINFOEX
+	if err := winapi.GetMonitorInfo(h, &mi); err != nil {
+		return Monitor{}, errors.Wrap(err, "GetMonitorInfo")
+	}
+
+	m := Monitor{
+		Handle:     uintptr(h),
+		DeviceName: syscall.UTF16ToString(mi.SzDevice[:]),
+		Bounds:     Rect(mi.RcMonitor),
+		WorkArea:   Rect(mi.RcWork),
+		Primary:    mi.DwFlags&win.MONITORINFOF_PRIMARY != 0,
+	}
+
+	var err error
+	if m.DPIX, m.DPIY, err = winapi.GetDpiForMonitor(h, winapi.MDT_EFFECTIVE_DPI); err != nil {
+		m.DPIX, m.DPIY = systemDPI()
+	}
+	if m.RawDPIX, m.RawDPIY, err = winapi.GetDpiForMonitor(h, winapi.MDT_RAW_DPI); err != nil {
+		m.RawDPIX, m.RawDPIY = m.DPIX, m.DPIY
+	}
+
+	var dm win.DEVMODE
+	if winapi.EnumDisplaySettings(m.DeviceName, winapi.ENUM_CURRENT_SETTINGS, &dm) == nil {
+		// dmDisplayOrientation shares its offset with dmScale of the printer union
+		switch *(*uint32)(unsafe.Pointer(&dm.DmScale)) {
+		case winapi.DMDO_90:
+			m.Rotation = Rotate90
+		case winapi.DMDO_180:
+			m.Rotation = Rotate180
+		case winapi.DMDO_270:
+			m.Rotation = Rotate270
+		}
+	}
+	return m, nil
+}
+
+// systemDPI is the fallback before Windows 8.1, where all monitors share one DPI.
+func systemDPI() (uint32, uint32) {
+	hdc := win.GetDC(0)
+	defer win.ReleaseDC(0, hdc)
+	return uint32(win.GetDeviceCaps(hdc, win.LOGPIXELSX)), uint32(win.GetDeviceCaps(hdc, win.LOGPIXELSY))
+}
+
+// MonitorFromWindow returns the monitor the largest part of hwnd is on, or the nearest one.
+func MonitorFromWindow(hwnd win.HWND) (Monitor, error) {
+	return GetMonitor(win.MonitorFromWindow(hwnd, win.MONITOR_DEFAULTTONEAREST))
+}
+
+// MonitorFromPoint returns the monitor containing (x, y), or the nearest one.
+func MonitorFromPoint(x, y int32) (Monitor, error) {
+	return GetMonitor(winapi.MonitorFromPoint(win.POINT{X: x, Y: y}, win.MONITOR_DEFAULTTONEAREST))
+}
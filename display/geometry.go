@@ -0,0 +1,230 @@
+// Package display enumerates monitors and converts coordinates between them.
+package display
+
+// Rect is a rectangle in virtual desktop pixels, right and bottom exclusive.
+// It has the layout of win.RECT.
+type Rect struct {
+	Left, Top, Right, Bottom int32
+}
+
+func (r Rect) Dx() int32 {
+	return r.Right - r.Left
+}
+
+func (r Rect) Dy() int32 {
+	return r.Bottom - r.Top
+}
+
+func (r Rect) Empty() bool {
+	return r.Left >= r.Right || r.Top >= r.Bottom
+}
+
+// Contains reports whether the pixel at (x, y) lies inside r.
+func (r Rect) Contains(x, y int32) bool {
+	return x >= r.Left && x < r.Right && y >= r.Top && y < r.Bottom
+}
+
+// Intersect returns the largest rectangle contained by both r and s.
+func (r Rect) Intersect(s Rect) Rect {
+	if r.Left < s.Left {
+		r.Left = s.Left
+	}
+	if r.Top < s.Top {
+		r.Top = s.Top
+	}
+	if r.Right > s.Right {
+		r.Right = s.Right
+	}
+	if r.Bottom > s.Bottom {
+		r.Bottom = s.Bottom
+	}
+	if r.Empty() {
+		return Rect{}
+	}
+	return r
+}
+
+// Union returns the smallest rectangle containing both r and s.
+func (r Rect) Union(s Rect) Rect {
+	if r.Empty() {
+		return s
+	}
+	if s.Empty() {
+		return r
+	}
+	if r.Left > s.Left {
+		r.Left = s.Left
+	}
+	if r.Top > s.Top {
+		r.Top = s.Top
+	}
+	if r.Right < s.Right {
+		r.Right = s.Right
+	}
+	if r.Bottom < s.Bottom {
+		r.Bottom = s.Bottom
+	}
+	return r
+}
+
+// Offset returns r moved by (dx, dy).
+func (r Rect) Offset(dx, dy int32) Rect {
+	return Rect{r.Left + dx, r.Top + dy, r.Right + dx, r.Bottom + dy}
+}
+
+// Area returns the number of pixels of r.
+func (r Rect) Area() int64 {
+	if r.Empty() {
+		return 0
+	}
+	return int64(r.Dx()) * int64(r.Dy())
+}
+
+// MulDiv computes v * num / den rounded half away from zero, like the Win32 MulDiv.
+func MulDiv(v, num, den int32) int32 {
+	if den == 0 {
+		return -1
+	}
+	p := int64(v) * int64(num)
+	d := int64(den)
+	if (p < 0) != (d < 0) {
+		return int32((p - d/2) / d)
+	}
+	return int32((p + d/2) / d)
+}
+
+// ScaleDPI converts a length from one DPI to another.
+func ScaleDPI(v int32, fromDPI, toDPI uint32) int32 {
+	return MulDiv(v, int32(toDPI), int32(fromDPI))
+}
+
+// ScaleRect converts r from one DPI to another, scaling its edges.
+func ScaleRect(r Rect, fromDPI, toDPI uint32) Rect {
+	return Rect{
+		Left:   ScaleDPI(r.Left, fromDPI, toDPI),
+		Top:    ScaleDPI(r.Top, fromDPI, toDPI),
+		Right:  ScaleDPI(r.Right, fromDPI, toDPI),
+		Bottom: ScaleDPI(r.Bottom, fromDPI, toDPI),
+	}
+}
+
+// USER_DEFAULT_SCREEN_DPI is the DPI of 100% scaling.
+const USER_DEFAULT_SCREEN_DPI = 96
+
+// Rotation of a monitor in degrees clockwise.
+type Rotation int
+
+const (
+	Rotate0   Rotation = 0
+	Rotate90  Rotation = 90
+	Rotate180 Rotation = 180
+	Rotate270 Rotation = 270
+)
+
+// Monitor describes one display monitor.
+type Monitor struct {
+	// Handle is the HMONITOR.
+	Handle uintptr
+
+	// DeviceName is the GDI device name such as \\.\DISPLAY1.
+	DeviceName string
+
+	Bounds   Rect
+	WorkArea Rect
+	Primary  bool
+
+	// DPIX and DPIY are the effective DPI the system scales for,
+	// RawDPIX and RawDPIY the physical pixel density.
+	DPIX, DPIY       uint32
+	RawDPIX, RawDPIY uint32
+
+	Rotation Rotation
+}
+
+// Scale returns the scale factor, 1.0 meaning 100%.
+func (m Monitor) Scale() float64 {
+	if m.DPIX == 0 {
+		return 1
+	}
+	return float64(m.DPIX) / USER_DEFAULT_SCREEN_DPI
+}
+
+// ToLogical converts r in physical pixels on m to 96 DPI logical units relative to m.
+func (m Monitor) ToLogical(r Rect) Rect {
+	r = r.Offset(-m.Bounds.Left, -m.Bounds.Top)
+	return ScaleRect(r, m.DPIX, USER_DEFAULT_SCREEN_DPI)
+}
+
+// FromLogical converts r in 96 DPI logical units relative to m to physical pixels.
+func (m Monitor) FromLogical(r Rect) Rect {
+	r = ScaleRect(r, USER_DEFAULT_SCREEN_DPI, m.DPIX)
+	return r.Offset(m.Bounds.Left, m.Bounds.Top)
+}
+
+// VirtualDesktop returns the union of all monitor bounds.
+func VirtualDesktop(monitors []Monitor) Rect {
+	var r Rect
+	for _, m := range monitors {
+		r = r.Union(m.Bounds)
+	}
+	return r
+}
+
+// Primary returns the primary monitor.
+func Primary(monitors []Monitor) (Monitor, bool) {
+	for _, m := range monitors {
+		if m.Primary {
+			return m, true
+		}
+	}
+	return Monitor{}, false
+}
+
+// At returns the monitor containing the point (x, y).
+func At(monitors []Monitor, x, y int32) (Monitor, bool) {
+	for _, m := range monitors {
+		if m.Bounds.Contains(x, y) {
+			return m, true
+		}
+	}
+	return Monitor{}, false
+}
+
+// Nearest returns the monitor closest to (x, y), like MONITOR_DEFAULTTONEAREST.
+func Nearest(monitors []Monitor, x, y int32) (Monitor, bool) {
+	var best Monitor
+	bestDist := int64(-1)
+	for _, m := range monitors {
+		dx := distance(x, m.Bounds.Left, m.Bounds.Right-1)
+		dy := distance(y, m.Bounds.Top, m.Bounds.Bottom-1)
+		if d := dx*dx + dy*dy; bestDist < 0 || d < bestDist {
+			best, bestDist = m, d
+		}
+	}
+	return best, bestDist >= 0
+}
+
+func distance(v, lo, hi int32) int64 {
+	switch {
+	case v < lo:
+		return int64(lo) - int64(v)
+	case v > hi:
+		return int64(v) - int64(hi)
+	}
+	return 0
+}
+
+// Largest returns the monitor sharing the largest area with r, like MonitorFromRect.
+func Largest(monitors []Monitor, r Rect) (Monitor, bool) {
+	var best Monitor
+	var bestArea int64
+	for _, m := range monitors {
+		if a := m.Bounds.Intersect(r).Area(); a > bestArea {
+			best, bestArea = m, a
+		}
+	}
+	if bestArea == 0 {
+		return Nearest(monitors, r.Left+r.Dx()/2, r.Top+r.Dy()/2)
+	}
+	return best, true
+}
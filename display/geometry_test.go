@@ -0,0 +1,151 @@
+package display
+
+import "testing"
+
+func TestRect(t *testing.T) {
+	r := Rect{0, 0, 10, 10}
+	if !r.Contains(0, 0) || !r.Contains(9, 9) || r.Contains(10, 5) || r.Contains(5, -1) {
+		t.Errorf("Contains does not treat %+v as right and bottom exclusive", r)
+	}
+	if got, want := r.Intersect(Rect{5, 5, 20, 20}), (Rect{5, 5, 10, 10}); got != want {
+		t.Errorf("Intersect = %+v, want %+v", got, want)
+	}
+	if got := r.Intersect(Rect{10, 0, 20, 10}); got != (Rect{}) {
+		t.Errorf("Intersect of adjacent rects = %+v, want empty", got)
+	}
+	if got, want := r.Union(Rect{-5, 20, 0, 30}), (Rect{-5, 0, 10, 30}); got != want {
+		t.Errorf("Union = %+v, want %+v", got, want)
+	}
+	if got := (Rect{}).Union(r); got != r {
+		t.Errorf("Union with empty = %+v, want %+v", got, r)
+	}
+	if got := r.Union(Rect{3, 3, 3, 8}); got != r {
+		t.Errorf("Union of empty = %+v, want %+v", got, r)
+	}
+	if got, want := r.Offset(-3, 4), (Rect{-3, 4, 7, 14}); got != want {
+		t.Errorf("Offset = %+v, want %+v", got, want)
+	}
+	if got := r.Area(); got != 100 {
+		t.Errorf("Area = %d, want 100", got)
+	}
+	if got := (Rect{5, 5, 0, 10}).Area(); got != 0 {
+		t.Errorf("Area of inverted rect = %d, want 0", got)
+	}
+	if got := (Rect{0, 0, 1 << 30, 1 << 30}).Area(); got != 1<<60 {
+		t.Errorf("Area overflows: %d", got)
+	}
+}
+
+func TestMulDiv(t *testing.T) {
+	tests := []struct{ v, num, den, want int32 }{
+		{100, 144, 96, 150},
+		{5, 1, 2, 3},
+		{-5, 1, 2, -3},
+		{5, -1, 2, -3},
+		{-5, -1, 2, 3},
+		{4, 1, 3, 1},
+		{1 << 30, 4, 8, 1 << 29},
+		{1, 1, 0, -1},
+	}
+	for _, tt := range tests {
+		if got := MulDiv(tt.v, tt.num, tt.den); got != tt.want {
+			t.Errorf("MulDiv(%d, %d, %d) = %d, want %d", tt.v, tt.num, tt.den, got, tt.want)
+		}
+	}
+}
+
+func TestScale(t *testing.T) {
+	if got := ScaleDPI(150, 144, 96); got != 100 {
+		t.Errorf("ScaleDPI(150, 144, 96) = %d, want 100", got)
+	}
+	if got, want := ScaleRect(Rect{10, 20, 30, 40}, 96, 120), (Rect{13, 25, 38, 50}); got != want {
+		t.Errorf("ScaleRect = %+v, want %+v", got, want)
+	}
+	if got := (Monitor{DPIX: 144}).Scale(); got != 1.5 {
+		t.Errorf("Scale = %v, want 1.5", got)
+	}
+	if got := (Monitor{}).Scale(); got != 1 {
+		t.Errorf("Scale without DPI = %v, want 1", got)
+	}
+}
+
+var testMonitors = []Monitor{
+	{Handle: 1, Bounds: Rect{0, 0, 1920, 1080}, Primary: true, DPIX: 96, DPIY: 96},
+	{Handle: 2, Bounds: Rect{1920, -200, 4480, 1240}, DPIX: 144, DPIY: 144},
+}
+
+func TestLogical(t *testing.T) {
+	m := testMonitors[1]
+	physical := Rect{2070, 100, 2220, 250}
+	logical := Rect{100, 200, 200, 300}
+	if got := m.ToLogical(physical); got != logical {
+		t.Errorf("ToLogical = %+v, want %+v", got, logical)
+	}
+	if got := m.FromLogical(logical); got != physical {
+		t.Errorf("FromLogical = %+v, want %+v", got, physical)
+	}
+}
+
+func TestMonitors(t *testing.T) {
+	if got, want := VirtualDesktop(testMonitors), (Rect{0, -200, 4480, 1240}); got != want {
+		t.Errorf("VirtualDesktop = %+v, want %+v", got, want)
+	}
+	if m, ok := Primary(testMonitors); !ok || m.Handle != 1 {
+		t.Errorf("Primary = %d, %v", m.Handle, ok)
+	}
+	if _, ok := Primary(testMonitors[1:]); ok {
+		t.Error("Primary found a monitor without the flag")
+	}
+
+	at := []struct {
+		x, y int32
+		want uintptr
+	}{
+		{0, 0, 1},
+		{1919, 1079, 1},
+		{1920, 0, 2},
+		{2000, -200, 2},
+		{-1, 0, 0},
+		{100, 1080, 0},
+	}
+	for _, tt := range at {
+		m, ok := At(testMonitors, tt.x, tt.y)
+		if ok != (tt.want != 0) || m.Handle != tt.want {
+			t.Errorf("At(%d, %d) = %d, %v, want %d", tt.x, tt.y, m.Handle, ok, tt.want)
+		}
+	}
+
+	nearest := []struct {
+		x, y int32
+		want uintptr
+	}{
+		{-100, 500, 1},
+		{5000, 0, 2},
+		{1000, 2000, 1},
+		{1925, 1100, 2},
+		{100, 100, 1},
+	}
+	for _, tt := range nearest {
+		if m, ok := Nearest(testMonitors, tt.x, tt.y); !ok || m.Handle != tt.want {
+			t.Errorf("Nearest(%d, %d) = %d, %v, want %d", tt.x, tt.y, m.Handle, ok, tt.want)
+		}
+	}
+	if _, ok := Nearest(nil, 0, 0); ok {
+		t.Error("Nearest found a monitor in an empty list")
+	}
+
+	largest := []struct {
+		r    Rect
+		want uintptr
+	}{
+		{Rect{1800, 0, 2000, 100}, 1},
+		{Rect{1900, 0, 2100, 100}, 2},
+		{Rect{-500, -500, -400, -400}, 1},
+		{Rect{5000, 0, 5100, 100}, 2},
+	}
+	for _, tt := range largest {
+		if m, ok := Largest(testMonitors, tt.r); !ok || m.Handle != tt.want {
+			t.Errorf("Largest(%+v) = %d, %v, want %d", tt.r, m.Handle, ok, tt.want)
+		}
+	}
+}
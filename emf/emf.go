@@ -0,0 +1,488 @@
+// Package emf reads enhanced metafiles, the vector format of
+// CreateEnhMetaFile and CF_ENHMETAFILE. Parse decodes the header and the
+// common drawing and object records into Go values; all other records are
+// kept as Unknown. Rectangles are kept as stored: unlike image.Rectangle,
+// EMF rectangles include their right and bottom edges.
+package emf
+
+import (
+	"encoding/binary"
+	"image"
+	"math"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi/dib"
+	"github.com/whiteboxsolutions/winapi/gdi"
+)
+
+// Record types
+const (
+	EMR_HEADER                 uint32 = 1
+	EMR_POLYBEZIER             uint32 = 2
+	EMR_POLYGON                uint32 = 3
+	EMR_POLYLINE               uint32 = 4
+	EMR_POLYBEZIERTO           uint32 = 5
+	EMR_POLYLINETO             uint32 = 6
+	EMR_EOF                    uint32 = 14
+	EMR_SETTEXTCOLOR           uint32 = 24
+	EMR_SETBKCOLOR             uint32 = 25
+	EMR_MOVETOEX               uint32 = 27
+	EMR_SELECTOBJECT           uint32 = 37
+	EMR_CREATEPEN              uint32 = 38
+	EMR_CREATEBRUSHINDIRECT    uint32 = 39
+	EMR_DELETEOBJECT           uint32 = 40
+	EMR_ELLIPSE                uint32 = 42
+	EMR_RECTANGLE              uint32 = 43
+	EMR_LINETO                 uint32 = 54
+	EMR_STRETCHDIBITS          uint32 = 81
+	EMR_EXTCREATEFONTINDIRECTW uint32 = 82
+	EMR_EXTTEXTOUTW            uint32 = 84
+	EMR_POLYBEZIER16           uint32 = 85
+	EMR_POLYGON16              uint32 = 86
+	EMR_POLYLINE16             uint32 = 87
+	EMR_POLYBEZIERTO16         uint32 = 88
+	EMR_POLYLINETO16           uint32 = 89
+)
+
+// ENHMETA_SIGNATURE is " EMF" in the dSignature field of the header.
+const ENHMETA_SIGNATURE uint32 = 0x464D4520
+
+// ExtTextOut options
+const (
+	ETO_OPAQUE  uint32 = 0x0002
+	ETO_CLIPPED uint32 = 0x0004
+	ETO_PDY     uint32 = 0x2000
+)
+
+// stockObject marks the object index of a stock object in EMR_SELECTOBJECT.
+const stockObject uint32 = 0x80000000
+
+var ErrFormat = errors.New("emf: invalid format")
+
+// Record is a decoded metafile record.
+type Record interface {
+	// Type returns the EMR_* type.
+	Type() uint32
+}
+
+// Header is EMR_HEADER, the first record of every metafile.
+type Header struct {
+	// Bounds is the drawn area in device units.
+	Bounds image.Rectangle
+	// Frame is the picture area in 0.01 millimeters.
+	Frame   image.Rectangle
+	Version uint32
+	// Bytes and Records are the size of the file and its number of records.
+	Bytes   uint32
+	Records uint32
+	Handles uint16
+	// Description holds the application and picture names, if any.
+	Description []string
+	PalEntries  uint32
+	// Device is the size of the reference device in pixels and
+	// Millimeters in millimeters.
+	Device      image.Point
+	Millimeters image.Point
+}
+
+// Poly is one of the EMR_POLY* records.
+type Poly struct {
+	Kind   uint32
+	Bounds image.Rectangle
+	Points []image.Point
+}
+
+// ExtTextOut is EMR_EXTTEXTOUTW.
+type ExtTextOut struct {
+	Bounds       image.Rectangle
+	GraphicsMode uint32
+	XScale       float32
+	YScale       float32
+	Reference    image.Point
+	Text         string
+	Options      uint32
+	Rect         image.Rectangle
+	// Dx holds the distance from each character cell to the next, followed
+	// by the vertical distances when Options has ETO_PDY.
+	Dx []int32
+}
+
+// StretchDIBits is EMR_STRETCHDIBITS.
+type StretchDIBits struct {
+	Bounds image.Rectangle
+	Dest   image.Rectangle
+	Src    image.Rectangle
+	Usage  uint32
+	ROP    uint32
+	// BitmapInfo is the BITMAPINFO and Bits the pixels of the source DIB.
+	BitmapInfo []byte
+	Bits       []byte
+}
+
+// Image decodes the source bitmap.
+func (s *StretchDIBits) Image() (image.Image, error) {
+	h, err := dib.ParseHeader(s.BitmapInfo)
+	if err != nil {
+		return nil, err
+	}
+	offset := h.BitsOffset()
+	if offset > len(s.BitmapInfo) {
+		return nil, errors.Wrap(ErrFormat, "short color table")
+	}
+	palette := dib.DecodePalette(s.BitmapInfo[offset-h.PaletteLen()*4 : offset])
+	return dib.DecodeBits(&h, palette, s.Bits)
+}
+
+// CreatePen is EMR_CREATEPEN, creating the pen at Index of the handle table.
+type CreatePen struct {
+	Index uint32
+	Pen   gdi.Pen
+}
+
+// CreateBrush is EMR_CREATEBRUSHINDIRECT.
+type CreateBrush struct {
+	Index uint32
+	Brush gdi.Brush
+}
+
+// CreateFont is EMR_EXTCREATEFONTINDIRECTW.
+type CreateFont struct {
+	Index uint32
+	Font  gdi.Font
+}
+
+// SelectObject is EMR_SELECTOBJECT.
+type SelectObject struct {
+	Index uint32
+}
+
+// Stock returns the GetStockObject index when a stock object is selected.
+func (s SelectObject) Stock() (int, bool) {
+	return int(s.Index &^ stockObject), s.Index&stockObject != 0
+}
+
+// DeleteObject is EMR_DELETEOBJECT.
+type DeleteObject struct {
+	Index uint32
+}
+
+// SetColor is EMR_SETTEXTCOLOR or EMR_SETBKCOLOR.
+type SetColor struct {
+	Kind  uint32
+	Color gdi.Color
+}
+
+// MoveTo is EMR_MOVETOEX or EMR_LINETO.
+type MoveTo struct {
+	Kind  uint32
+	Point image.Point
+}
+
+// Box is EMR_RECTANGLE or EMR_ELLIPSE.
+type Box struct {
+	Kind uint32
+	Box  image.Rectangle
+}
+
+// EOF is EMR_EOF, the last record.
+type EOF struct{}
+
+// Unknown is a record Parse does not decode. Data excludes the type and size.
+type Unknown struct {
+	Kind uint32
+	Data []byte
+}
+
+func (*Header) Type() uint32        { return EMR_HEADER }
+func (p *Poly) Type() uint32        { return p.Kind }
+func (*ExtTextOut) Type() uint32    { return EMR_EXTTEXTOUTW }
+func (*StretchDIBits) Type() uint32 { return EMR_STRETCHDIBITS }
+func (*CreatePen) Type() uint32     { return EMR_CREATEPEN }
+func (*CreateBrush) Type() uint32   { return EMR_CREATEBRUSHINDIRECT }
+func (*CreateFont) Type() uint32    { return EMR_EXTCREATEFONTINDIRECTW }
+func (*SelectObject) Type() uint32  { return EMR_SELECTOBJECT }
+func (*DeleteObject) Type() uint32  { return EMR_DELETEOBJECT }
+func (c *SetColor) Type() uint32    { return c.Kind }
+func (m *MoveTo) Type() uint32      { return m.Kind }
+func (b *Box) Type() uint32         { return b.Kind }
+func (*EOF) Type() uint32           { return EMR_EOF }
+func (u *Unknown) Type() uint32     { return u.Kind }
+
+// File is a parsed metafile.
+type File struct {
+	Header  *Header
+	Records []Record
+}
+
+// Parse decodes a metafile as returned by GetEnhMetaFileBits or stored in
+// .emf files. Records after EMR_EOF are ignored.
+func Parse(b []byte) (*File, error) {
+	f := &File{}
+	for off := 0; off < len(b); {
+		if len(b)-off < 8 {
+			return f, errors.Wrapf(ErrFormat, "truncated record at %d", off)
+		}
+		typ := le.Uint32(b[off:])
+		size := le.Uint32(b[off+4:])
+		if size < 8 || size%4 != 0 || uint64(size) > uint64(len(b)-off) {
+			return f, errors.Wrapf(ErrFormat, "record of %d bytes at %d", size, off)
+		}
+		data := b[off : off+int(size)]
+		if off == 0 && typ != EMR_HEADER {
+			return f, errors.Wrap(ErrFormat, "missing header")
+		}
+		r, err := ParseRecord(data)
+		if err != nil {
+			return f, errors.Wrapf(err, "record %d at %d", typ, off)
+		}
+		if h, ok := r.(*Header); ok && off == 0 {
+			f.Header = h
+		}
+		f.Records = append(f.Records, r)
+		if typ == EMR_EOF {
+			return f, nil
+		}
+		off += int(size)
+	}
+	if f.Header == nil {
+		return f, errors.Wrap(ErrFormat, "empty metafile")
+	}
+	return f, errors.Wrap(ErrFormat, "missing EMR_EOF")
+}
+
+var le = binary.LittleEndian
+
+// reader decodes the fields of one record, remembering the first out of
+// bounds access.
+type reader struct {
+	b   []byte
+	bad bool
+}
+
+func (r *reader) u32(off int) uint32 {
+	if off < 0 || off+4 > len(r.b) {
+		r.bad = true
+		return 0
+	}
+	return le.Uint32(r.b[off:])
+}
+
+func (r *reader) i32(off int) int     { return int(int32(r.u32(off))) }
+func (r *reader) f32(off int) float32 { return math.Float32frombits(r.u32(off)) }
+
+func (r *reader) u16(off int) uint16 {
+	if off < 0 || off+2 > len(r.b) {
+		r.bad = true
+		return 0
+	}
+	return le.Uint16(r.b[off:])
+}
+
+func (r *reader) u8(off int) uint8 {
+	if off < 0 || off >= len(r.b) {
+		r.bad = true
+		return 0
+	}
+	return r.b[off]
+}
+
+// rect reads a RECTL.
+func (r *reader) rect(off int) image.Rectangle {
+	return image.Rectangle{
+		Min: image.Point{X: r.i32(off), Y: r.i32(off + 4)},
+		Max: image.Point{X: r.i32(off + 8), Y: r.i32(off + 12)},
+	}
+}
+
+func (r *reader) point(off int) image.Point {
+	return image.Point{X: r.i32(off), Y: r.i32(off + 4)}
+}
+
+// bytes returns n bytes at off.
+func (r *reader) bytes(off, n int) []byte {
+	if off < 0 || n < 0 || n > len(r.b) || off > len(r.b)-n {
+		r.bad = true
+		return nil
+	}
+	return r.b[off : off+n]
+}
+
+// utf16 decodes n UTF-16 code units at off.
+func (r *reader) utf16(off, n int) []uint16 {
+	if n < 0 || n > len(r.b)/2 {
+		r.bad = true
+		return nil
+	}
+	b := r.bytes(off, n*2)
+	s := make([]uint16, len(b)/2)
+	for i := range s {
+		s[i] = le.Uint16(b[i*2:])
+	}
+	return s
+}
+
+// count reads an element count at off and checks that count elements of
+// size bytes fit behind start.
+func (r *reader) count(off, start, size int) int {
+	n := r.u32(off)
+	if uint64(n)*uint64(size) > uint64(len(r.b)) || start+int(n)*size > len(r.b) {
+		r.bad = true
+		return 0
+	}
+	return int(n)
+}
+
+// ParseRecord decodes one record including its type and size fields.
+func ParseRecord(b []byte) (Record, error) {
+	r := &reader{b: b}
+	typ := r.u32(0)
+	var rec Record
+	switch typ {
+	case EMR_HEADER:
+		rec = parseHeader(r)
+	case EMR_POLYBEZIER, EMR_POLYGON, EMR_POLYLINE, EMR_POLYBEZIERTO, EMR_POLYLINETO:
+		p := &Poly{Kind: typ, Bounds: r.rect(8)}
+		n := r.count(24, 28, 8)
+		p.Points = make([]image.Point, n)
+		for i := range p.Points {
+			p.Points[i] = r.point(28 + i*8)
+		}
+		rec = p
+	case EMR_POLYBEZIER16, EMR_POLYGON16, EMR_POLYLINE16, EMR_POLYBEZIERTO16, EMR_POLYLINETO16:
+		p := &Poly{Kind: typ, Bounds: r.rect(8)}
+		n := r.count(24, 28, 4)
+		p.Points = make([]image.Point, n)
+		for i := range p.Points {
+			p.Points[i] = image.Point{X: int(int16(r.u16(28 + i*4))), Y: int(int16(r.u16(30 + i*4)))}
+		}
+		rec = p
+	case EMR_EXTTEXTOUTW:
+		rec = parseExtTextOut(r)
+	case EMR_STRETCHDIBITS:
+		s := &StretchDIBits{
+			Bounds: r.rect(8),
+			Dest:   image.Rect(r.i32(24), r.i32(28), r.i32(24)+r.i32(72), r.i32(28)+r.i32(76)),
+			Src:    image.Rect(r.i32(32), r.i32(36), r.i32(32)+r.i32(40), r.i32(36)+r.i32(44)),
+			Usage:  r.u32(64),
+			ROP:    r.u32(68),
+		}
+		s.BitmapInfo = r.bytes(int(r.u32(48)), int(r.u32(52)))
+		s.Bits = r.bytes(int(r.u32(56)), int(r.u32(60)))
+		rec = s
+	case EMR_CREATEPEN:
+		rec = &CreatePen{Index: r.u32(8), Pen: gdi.Pen{
+			Style: r.u32(12),
+			Width: r.i32(16),
+			Color: gdi.Color(r.u32(24)),
+		}}
+	case EMR_CREATEBRUSHINDIRECT:
+		rec = &CreateBrush{Index: r.u32(8), Brush: gdi.Brush{
+			Style: r.u32(12),
+			Color: gdi.Color(r.u32(16)),
+			Hatch: r.u32(20),
+		}}
+	case EMR_EXTCREATEFONTINDIRECTW:
+		// the LOGFONTW at 12 is followed by optional extensions
+		rec = &CreateFont{Index: r.u32(8), Font: gdi.Font{
+			Height:    r.i32(12),
+			Weight:    r.i32(28),
+			Italic:    r.u8(32) != 0,
+			Underline: r.u8(33) != 0,
+			StrikeOut: r.u8(34) != 0,
+			Quality:   r.u8(38),
+			Face:      cstring(r.utf16(40, 32)),
+		}}
+	case EMR_SELECTOBJECT:
+		rec = &SelectObject{Index: r.u32(8)}
+	case EMR_DELETEOBJECT:
+		rec = &DeleteObject{Index: r.u32(8)}
+	case EMR_SETTEXTCOLOR, EMR_SETBKCOLOR:
+		rec = &SetColor{Kind: typ, Color: gdi.Color(r.u32(8))}
+	case EMR_MOVETOEX, EMR_LINETO:
+		rec = &MoveTo{Kind: typ, Point: r.point(8)}
+	case EMR_RECTANGLE, EMR_ELLIPSE:
+		rec = &Box{Kind: typ, Box: r.rect(8)}
+	case EMR_EOF:
+		rec = &EOF{}
+	default:
+		data := r.bytes(8, len(b)-8)
+		rec = &Unknown{Kind: typ, Data: append([]byte(nil), data...)}
+	}
+	if r.bad {
+		return nil, errors.Wrapf(ErrFormat, "short record of type %d", typ)
+	}
+	return rec, nil
+}
+
+func parseHeader(r *reader) *Header {
+	h := &Header{
+		Bounds:     r.rect(8),
+		Frame:      r.rect(24),
+		Version:    r.u32(44),
+		Bytes:      r.u32(48),
+		Records:    r.u32(52),
+		Handles:    r.u16(56),
+		PalEntries: r.u32(68),
+		Device:     r.point(72),
+	}
+	h.Millimeters = r.point(80)
+	if r.u32(40) != ENHMETA_SIGNATURE {
+		r.bad = true
+	}
+	if n := int(r.u32(60)); n > 0 {
+		desc := r.utf16(int(r.u32(64)), n)
+		start := 0
+		for i, c := range desc {
+			if c == 0 {
+				if i > start {
+					h.Description = append(h.Description, string(utf16.Decode(desc[start:i])))
+				}
+				start = i + 1
+			}
+		}
+		if start < len(desc) {
+			h.Description = append(h.Description, string(utf16.Decode(desc[start:])))
+		}
+	}
+	return h
+}
+
+func parseExtTextOut(r *reader) *ExtTextOut {
+	t := &ExtTextOut{
+		Bounds:       r.rect(8),
+		GraphicsMode: r.u32(24),
+		XScale:       r.f32(28),
+		YScale:       r.f32(32),
+		Reference:    r.point(36),
+		Options:      r.u32(52),
+		Rect:         r.rect(56),
+	}
+	n := int(r.u32(44))
+	t.Text = string(utf16.Decode(r.utf16(int(r.u32(48)), n)))
+	if off := int(r.u32(72)); off != 0 && n > 0 {
+		if t.Options&ETO_PDY != 0 {
+			n *= 2
+		}
+		if n > len(r.b)/4 {
+			r.bad = true
+			return t
+		}
+		t.Dx = make([]int32, n)
+		for i := range t.Dx {
+			t.Dx[i] = int32(r.u32(off + i*4))
+		}
+	}
+	return t
+}
+
+// cstring decodes a NUL terminated UTF-16 string.
+func cstring(s []uint16) string {
+	for i, c := range s {
+		if c == 0 {
+			s = s[:i]
+			break
+		}
+	}
+	return string(utf16.Decode(s))
+}
@@ -0,0 +1,198 @@
+package emf
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi/gdi"
+)
+
+// offsets of records in testdata/sample.emf
+const (
+	polylineAt      = 0x15c
+	extTextOutAt    = 0x1f0
+	stretchDIBitsAt = 0x250
+)
+
+func readSample(t testing.TB) []byte {
+	b, err := os.ReadFile("testdata/sample.emf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestParse(t *testing.T) {
+	b := readSample(t)
+	f, err := Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := &Header{
+		Bounds:      image.Rect(0, 0, 99, 49),
+		Frame:       image.Rect(0, 0, 2646, 1323),
+		Version:     0x10000,
+		Bytes:       uint32(len(b)),
+		Records:     18,
+		Handles:     6,
+		Description: []string{"winapi", "sample"},
+		Device:      image.Pt(1920, 1080),
+		Millimeters: image.Pt(508, 286),
+	}
+	want := []Record{
+		header,
+		&CreateBrush{Index: 1, Brush: gdi.Brush{Color: gdi.RGB(0xFF, 0, 0)}},
+		&SelectObject{Index: 1},
+		&CreatePen{Index: 2, Pen: gdi.Pen{Width: 3, Color: gdi.RGB(0, 0xFF, 0)}},
+		&SelectObject{Index: 2},
+		&SetColor{Kind: EMR_SETTEXTCOLOR, Color: gdi.RGB(0x56, 0x34, 0x12)},
+		&MoveTo{Kind: EMR_MOVETOEX, Point: image.Pt(5, 6)},
+		&MoveTo{Kind: EMR_LINETO, Point: image.Pt(-7, 8)},
+		&Box{Kind: EMR_RECTANGLE, Box: image.Rect(10, 10, 40, 30)},
+		&Box{Kind: EMR_ELLIPSE, Box: image.Rect(50, 10, 90, 40)},
+		// 16 bit coordinates are signed
+		&Poly{Kind: EMR_POLYLINE16, Bounds: image.Rect(0, 0, 20, 40), Points: []image.Point{{0, 0}, {20, -10}, {-1, 40}}},
+		&Poly{Kind: EMR_POLYLINE, Bounds: image.Rect(1, 2, 70000, 4), Points: []image.Point{{1, 2}, {70000, 4}}},
+		&CreateFont{Index: 3, Font: gdi.Font{Face: "Arial", Height: -16, Weight: 700, Italic: true, StrikeOut: true, Quality: 5}},
+		&ExtTextOut{
+			Bounds:       image.Rect(10, 20, 40, 36),
+			GraphicsMode: 1,
+			XScale:       1,
+			YScale:       1,
+			Reference:    image.Pt(10, 20),
+			Text:         "Hé€",
+			Options:      ETO_CLIPPED,
+			Rect:         image.Rect(10, 20, 40, 36),
+			Dx:           []int32{8, 9, 10},
+		},
+		&StretchDIBits{
+			Bounds:     image.Rect(0, 0, 3, 1),
+			Dest:       image.Rect(0, 0, 4, 2),
+			Src:        image.Rect(0, 0, 2, 1),
+			ROP:        0x00CC0020,
+			BitmapInfo: b[stretchDIBitsAt+80 : stretchDIBitsAt+120],
+			Bits:       b[stretchDIBitsAt+120 : stretchDIBitsAt+128],
+		},
+		&DeleteObject{Index: 2},
+		&Unknown{Kind: 10, Data: make([]byte, 8)},
+		&EOF{},
+	}
+	if !reflect.DeepEqual(f.Header, header) {
+		t.Errorf("Header = %+v\nwant %+v", f.Header, header)
+	}
+	if len(f.Records) != len(want) {
+		t.Fatalf("%d records, want %d", len(f.Records), len(want))
+	}
+	for i, r := range f.Records {
+		if !reflect.DeepEqual(r, want[i]) {
+			t.Errorf("record %d = %+v\nwant %+v", i, r, want[i])
+		}
+		if r.Type() != want[i].Type() {
+			t.Errorf("record %d has type %d, want %d", i, r.Type(), want[i].Type())
+		}
+	}
+	if i, ok := f.Records[2].(*SelectObject).Stock(); ok || i != 1 {
+		t.Errorf("Stock() = %d, %v for a created object", i, ok)
+	}
+	if i, ok := (SelectObject{Index: stockObject | 5}).Stock(); !ok || i != 5 {
+		t.Errorf("Stock() = %d, %v for NULL_BRUSH", i, ok)
+	}
+}
+
+func TestStretchDIBitsImage(t *testing.T) {
+	f, err := Parse(readSample(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := f.Records[14].(*StretchDIBits)
+	m, err := s.Image()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Bounds() != image.Rect(0, 0, 2, 1) {
+		t.Fatalf("bounds %v", m.Bounds())
+	}
+	for x, want := range []color.Color{color.NRGBA{0, 0, 0xFF, 0xFF}, color.NRGBA{0, 0xFF, 0, 0xFF}} {
+		if got := color.NRGBAModel.Convert(m.At(x, 0)); got != want {
+			t.Errorf("pixel %d = %v, want %v", x, got, want)
+		}
+	}
+
+	s.BitmapInfo = s.BitmapInfo[:12]
+	if _, err := s.Image(); err == nil {
+		t.Error("Image of a short BITMAPINFO")
+	}
+}
+
+func TestParseTruncated(t *testing.T) {
+	b := readSample(t)
+	for n := 0; n < len(b); n++ {
+		f, err := Parse(b[:n])
+		if err == nil {
+			t.Fatalf("Parse of %d bytes succeeded", n)
+		}
+		if errors.Cause(err) != ErrFormat {
+			t.Fatalf("Parse of %d bytes: %v", n, err)
+		}
+		if f == nil {
+			t.Fatalf("Parse of %d bytes returned no file", n)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	sample := readSample(t)
+	put := func(off int, v uint32) []byte {
+		b := append([]byte(nil), sample...)
+		binary.LittleEndian.PutUint32(b[off:], v)
+		return b
+	}
+	tests := []struct {
+		name string
+		b    []byte
+	}{
+		{"signature", put(40, 0x12345678)},
+		{"missing header", put(0, EMR_EOF)},
+		{"odd size", put(4, 0x8a)},
+		{"short size", put(4, 4)},
+		{"size beyond the end", put(4, uint32(len(sample)+4))},
+		{"description beyond the record", put(60, 0x1000)},
+		{"point count", put(polylineAt+24, 0x40000000)},
+		{"text length", put(extTextOutAt+44, 0x7FFFFFFF)},
+		{"bits beyond the record", put(stretchDIBitsAt+56, 0xFFFFFFF0)},
+	}
+	for _, tt := range tests {
+		if _, err := Parse(tt.b); errors.Cause(err) != ErrFormat {
+			t.Errorf("%s: Parse = %v", tt.name, err)
+		}
+	}
+
+	// records after EMR_EOF are ignored
+	b := append(append([]byte(nil), sample...), 1, 2, 3)
+	if f, err := Parse(b); err != nil || len(f.Records) != 18 {
+		t.Errorf("Parse with trailing bytes = %v", err)
+	}
+}
+
+func TestParseRecordShort(t *testing.T) {
+	for _, typ := range []uint32{
+		EMR_HEADER, EMR_POLYLINE, EMR_POLYLINE16, EMR_EXTTEXTOUTW, EMR_STRETCHDIBITS,
+		EMR_CREATEPEN, EMR_CREATEBRUSHINDIRECT, EMR_EXTCREATEFONTINDIRECTW,
+		EMR_SELECTOBJECT, EMR_DELETEOBJECT, EMR_SETTEXTCOLOR, EMR_MOVETOEX, EMR_RECTANGLE,
+	} {
+		b := make([]byte, 8)
+		binary.LittleEndian.PutUint32(b, typ)
+		binary.LittleEndian.PutUint32(b[4:], 8)
+		if _, err := ParseRecord(b); errors.Cause(err) != ErrFormat {
+			t.Errorf("ParseRecord of an empty record %d = %v", typ, err)
+		}
+	}
+	if r, err := ParseRecord([]byte{99, 0, 0, 0, 8, 0, 0, 0}); err != nil || r.Type() != 99 {
+		t.Errorf("ParseRecord of an empty unknown record = %v, %v", r, err)
+	}
+}
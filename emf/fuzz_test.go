@@ -0,0 +1,27 @@
+//go:build go1.18
+// +build go1.18
+
+package emf
+
+import "testing"
+
+// FuzzParse needs testing.F from Go 1.18; the build constraint keeps the
+// package tests building with the go 1.17 directive in go.mod.
+func FuzzParse(f *testing.F) {
+	f.Add(readSample(f))
+	f.Add([]byte{1, 0, 0, 0, 8, 0, 0, 0})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		file, err := Parse(b)
+		if file == nil {
+			t.Fatal("Parse returned no file")
+		}
+		if err == nil && (file.Header == nil || len(file.Records) == 0) {
+			t.Fatal("Parse succeeded without a header")
+		}
+		for _, r := range file.Records {
+			if s, ok := r.(*StretchDIBits); ok {
+				s.Image()
+			}
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package emf
+
+import (
+	"image"
+	"strings"
+	"syscall"
+	"unicode/utf16"
+
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi"
+	"github.com/whiteboxsolutions/winapi/gdi"
+	"golang.org/x/sys/windows"
+)
+
+// Recording is an enhanced metafile being recorded through a gdi.Canvas.
+type Recording struct {
+	hdc    win.HDC
+	canvas *gdi.Canvas
+}
+
+// NewRecording starts recording a metafile whose picture covers bounds,
+// in pixels of the screen. The metafile is kept in memory when path is
+// empty and written to path otherwise. The description usually holds the
+// application name followed by the picture title.
+func NewRecording(path string, bounds image.Rectangle, description ...string) (*Recording, error) {
+	screen := win.GetDC(0)
+	if screen == 0 {
+		return nil, errors.Wrap(windows.GetLastError(), "GetDC")
+	}
+	defer win.ReleaseDC(0, screen)
+
+	var frame *win.RECT
+	if !bounds.Empty() {
+		// the frame is given in 0.01 mm of the reference device
+		mmX, pxX := win.GetDeviceCaps(screen, win.HORZSIZE), win.GetDeviceCaps(screen, win.HORZRES)
+		mmY, pxY := win.GetDeviceCaps(screen, win.VERTSIZE), win.GetDeviceCaps(screen, win.VERTRES)
+		if pxX == 0 || pxY == 0 {
+			return nil, errors.New("GetDeviceCaps failed")
+		}
+		frame = &win.RECT{
+			Left:   int32(bounds.Min.X) * mmX * 100 / pxX,
+			Top:    int32(bounds.Min.Y) * mmY * 100 / pxY,
+			Right:  int32(bounds.Max.X) * mmX * 100 / pxX,
+			Bottom: int32(bounds.Max.Y) * mmY * 100 / pxY,
+		}
+	}
+	var file, desc *uint16
+	var err error
+	if path != "" {
+		if file, err = syscall.UTF16PtrFromString(path); err != nil {
+			return nil, err
+		}
+	}
+	if len(description) > 0 {
+		// the strings are separated by NUL and end with two, which
+		// UTF16FromString rejects
+		u := utf16.Encode([]rune(strings.Join(description, "\x00")))
+		u = append(u, 0, 0)
+		desc = &u[0]
+	}
+	hdc := win.CreateEnhMetaFile(screen, file, frame, desc)
+	if hdc == 0 {
+		return nil, errors.Wrap(windows.GetLastError(), "CreateEnhMetaFile")
+	}
+	canvas, err := gdi.NewCanvas(hdc)
+	if err != nil {
+		win.DeleteEnhMetaFile(win.CloseEnhMetaFile(hdc))
+		return nil, err
+	}
+	return &Recording{hdc: hdc, canvas: canvas}, nil
+}
+
+// Canvas returns the canvas drawing into the metafile.
+func (r *Recording) Canvas() *gdi.Canvas {
+	return r.canvas
+}
+
+// HDC returns the metafile device context for drawing with plain GDI calls.
+func (r *Recording) HDC() win.HDC {
+	return r.hdc
+}
+
+// Close ends the recording and returns the contents of the metafile.
+func (r *Recording) Close() ([]byte, error) {
+	if r.hdc == 0 {
+		return nil, gdi.ErrClosed
+	}
+	err := r.canvas.Close()
+	hemf := win.CloseEnhMetaFile(r.hdc)
+	r.hdc = 0
+	if hemf == 0 {
+		return nil, errors.Wrap(windows.GetLastError(), "CloseEnhMetaFile")
+	}
+	defer win.DeleteEnhMetaFile(hemf)
+	if err != nil {
+		return nil, err
+	}
+	return winapi.GetEnhMetaFileBits(hemf)
+}
+
+// Read parses the metafile hemf, which is not deleted.
+func Read(hemf win.HENHMETAFILE) (*File, error) {
+	b, err := winapi.GetEnhMetaFileBits(hemf)
+	if err != nil {
+		return nil, errors.Wrap(err, "GetEnhMetaFileBits")
+	}
+	return Parse(b)
+}
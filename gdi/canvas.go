@@ -0,0 +1,555 @@
+// Package gdi draws on device contexts. A Canvas selects pens, brushes and
+// fonts into a DC and deletes every object it created, so drawing code
+// never calls SelectObject or DeleteObject itself. The Backend interface
+// separates the drawing logic from GDI: the Recorder backend runs anywhere
+// and logs the calls a Canvas makes.
+package gdi
+
+import (
+	"image"
+
+	"github.com/pkg/errors"
+)
+
+// Color is a COLORREF, 0x00BBGGRR.
+type Color uint32
+
+// RGB returns the COLORREF of r, g and b.
+func RGB(r, g, b uint8) Color {
+	return Color(r) | Color(g)<<8 | Color(b)<<16
+}
+
+func (c Color) R() uint8 { return uint8(c) }
+func (c Color) G() uint8 { return uint8(c >> 8) }
+func (c Color) B() uint8 { return uint8(c >> 16) }
+
+// RGBA implements color.Color, a COLORREF is always opaque.
+func (c Color) RGBA() (r, g, b, a uint32) {
+	r, g, b = uint32(c.R()), uint32(c.G()), uint32(c.B())
+	return r | r<<8, g | g<<8, b | b<<8, 0xFFFF
+}
+
+// Handle is a GDI object handle.
+type Handle uintptr
+
+// Pen styles
+const (
+	PS_SOLID       uint32 = 0
+	PS_DASH        uint32 = 1
+	PS_DOT         uint32 = 2
+	PS_DASHDOT     uint32 = 3
+	PS_DASHDOTDOT  uint32 = 4
+	PS_NULL        uint32 = 5
+	PS_INSIDEFRAME uint32 = 6
+)
+
+// Brush styles and hatches
+const (
+	BS_SOLID   uint32 = 0
+	BS_NULL    uint32 = 1
+	BS_HATCHED uint32 = 2
+
+	HS_HORIZONTAL uint32 = 0
+	HS_VERTICAL   uint32 = 1
+	HS_FDIAGONAL  uint32 = 2
+	HS_BDIAGONAL  uint32 = 3
+	HS_CROSS      uint32 = 4
+	HS_DIAGCROSS  uint32 = 5
+)
+
+// Pen describes the outline of shapes and lines.
+type Pen struct {
+	Style uint32
+	Width int
+	Color Color
+}
+
+// Brush describes the interior of shapes.
+type Brush struct {
+	Style uint32
+	Color Color
+	Hatch uint32
+}
+
+// NullPen and NullBrush draw nothing, for filled shapes without an
+// outline and outlines without a fill.
+var (
+	NullPen   = Pen{Style: PS_NULL}
+	NullBrush = Brush{Style: BS_NULL}
+)
+
+// Font weights
+const (
+	FW_NORMAL = 400
+	FW_BOLD   = 700
+)
+
+// Font describes a logical font. Height is in logical units with the sign
+// convention of LOGFONT: negative values are the character height,
+// positive values the cell height and zero selects a default size.
+type Font struct {
+	Face      string
+	Height    int
+	Weight    int
+	Italic    bool
+	Underline bool
+	StrikeOut bool
+	Quality   uint8
+}
+
+// Align places text relative to its reference point, a combination of
+// one horizontal and one vertical TA_* value.
+type Align uint32
+
+const (
+	AlignLeft     Align = 0
+	AlignRight    Align = 2
+	AlignCenter   Align = 6
+	AlignTop      Align = 0
+	AlignBottom   Align = 8
+	AlignBaseline Align = 24
+)
+
+// Backend performs the drawing operations of a Canvas.
+type Backend interface {
+	CreatePen(p Pen) (Handle, error)
+	CreateBrush(b Brush) (Handle, error)
+	CreateFont(f Font) (Handle, error)
+	// Select selects h and returns the object of the same type it replaces.
+	Select(h Handle) (Handle, error)
+	Delete(h Handle) error
+
+	// Save pushes the DC state and returns its level for Restore.
+	Save() (int, error)
+	Restore(level int) error
+
+	Polyline(pts []image.Point) error
+	Polygon(pts []image.Point) error
+	PolyBezier(pts []image.Point) error
+	Rectangle(r image.Rectangle) error
+	RoundRect(r image.Rectangle, corner image.Point) error
+	Ellipse(r image.Rectangle) error
+	Arc(r image.Rectangle, start, end image.Point) error
+	FillRect(r image.Rectangle, brush Handle) error
+
+	// PolyDraw draws lines and Bezier curves, types holds the PT_* type
+	// of each point.
+	PolyDraw(pts []image.Point, types []byte) error
+	SetPolyFillMode(mode int) error
+	// BeginPath records the following drawing calls into the path of the
+	// DC instead of drawing them, until EndPath.
+	BeginPath() error
+	EndPath() error
+	AbortPath() error
+	StrokePath() error
+	FillPath() error
+	StrokeAndFillPath() error
+	// PathToRegion converts the path into a region and discards it.
+	PathToRegion() (Handle, error)
+
+	SetTextColor(c Color) error
+	// SetBkColor sets the background of text, hatches and styled pens;
+	// transparent leaves the background untouched.
+	SetBkColor(c Color, transparent bool) error
+	SetTextAlign(a Align) error
+	TextOut(p image.Point, s string) error
+	// DrawText formats s in r. With DT_CALCRECT it only measures and
+	// returns the rectangle the text needs, otherwise the rectangle of
+	// the drawn text.
+	DrawText(s string, r image.Rectangle, flags uint32) (image.Rectangle, error)
+	// DPI returns the vertical resolution of the device.
+	DPI() int
+
+	IntersectClip(r image.Rectangle) error
+	ExcludeClip(r image.Rectangle) error
+	ResetClip() error
+}
+
+var ErrClosed = errors.New("gdi: canvas is closed")
+
+// level is one Save of a Canvas and the objects created while it was the
+// innermost one. Only the objects currently selected are kept, older ones
+// are deleted as soon as they are replaced.
+type level struct {
+	saved int
+	pen   Handle
+	brush Handle
+	font  Handle
+}
+
+// Canvas draws on a Backend and owns the objects it selects.
+type Canvas struct {
+	b      Backend
+	levels []level
+}
+
+// New saves the state of b, which Close restores.
+func New(b Backend) (*Canvas, error) {
+	c := &Canvas{b: b}
+	if err := c.Save(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Backend returns the backend c draws on.
+func (c *Canvas) Backend() Backend {
+	return c.b
+}
+
+func (c *Canvas) top() (*level, error) {
+	if len(c.levels) == 0 {
+		return nil, ErrClosed
+	}
+	return &c.levels[len(c.levels)-1], nil
+}
+
+// Save pushes the DC state: selected objects, colors, alignment and clipping.
+func (c *Canvas) Save() error {
+	if c.b == nil {
+		return ErrClosed
+	}
+	n, err := c.b.Save()
+	if err != nil {
+		return errors.Wrap(err, "SaveDC")
+	}
+	c.levels = append(c.levels, level{saved: n})
+	return nil
+}
+
+// Restore pops the state pushed by the last Save and deletes the objects
+// selected since. The state saved by New is only restored by Close.
+func (c *Canvas) Restore() error {
+	if len(c.levels) <= 1 {
+		return errors.New("gdi: Restore without Save")
+	}
+	return c.pop()
+}
+
+func (c *Canvas) pop() error {
+	l := c.levels[len(c.levels)-1]
+	c.levels = c.levels[:len(c.levels)-1]
+	err := c.b.Restore(l.saved)
+	if err != nil {
+		// the objects may still be selected, deleting them would fail
+		return errors.Wrap(err, "RestoreDC")
+	}
+	for _, h := range []Handle{l.pen, l.brush, l.font} {
+		if h == 0 {
+			continue
+		}
+		if e := c.b.Delete(h); e != nil && err == nil {
+			err = errors.Wrap(e, "DeleteObject")
+		}
+	}
+	return err
+}
+
+// Close restores the state the DC had when c was created and deletes all
+// objects c created. The canvas cannot be used afterwards.
+func (c *Canvas) Close() error {
+	var err error
+	for len(c.levels) > 0 {
+		if e := c.pop(); e != nil && err == nil {
+			err = e
+		}
+	}
+	c.b = nil
+	return err
+}
+
+// replace selects h and deletes old, the object it replaces, if the
+// current level created it.
+func (c *Canvas) replace(slot *Handle, h Handle) error {
+	if _, err := c.b.Select(h); err != nil {
+		c.b.Delete(h)
+		return errors.Wrap(err, "SelectObject")
+	}
+	old := *slot
+	*slot = h
+	if old != 0 {
+		if err := c.b.Delete(old); err != nil {
+			return errors.Wrap(err, "DeleteObject")
+		}
+	}
+	return nil
+}
+
+// SetPen selects a pen for lines and outlines.
+func (c *Canvas) SetPen(p Pen) error {
+	l, err := c.top()
+	if err != nil {
+		return err
+	}
+	h, err := c.b.CreatePen(p)
+	if err != nil {
+		return errors.Wrap(err, "CreatePen")
+	}
+	return c.replace(&l.pen, h)
+}
+
+// SetBrush selects a brush for the interior of shapes.
+func (c *Canvas) SetBrush(b Brush) error {
+	l, err := c.top()
+	if err != nil {
+		return err
+	}
+	h, err := c.b.CreateBrush(b)
+	if err != nil {
+		return errors.Wrap(err, "CreateBrush")
+	}
+	return c.replace(&l.brush, h)
+}
+
+// SetFont selects the font of Text.
+func (c *Canvas) SetFont(f Font) error {
+	l, err := c.top()
+	if err != nil {
+		return err
+	}
+	h, err := c.b.CreateFont(f)
+	if err != nil {
+		return errors.Wrap(err, "CreateFont")
+	}
+	return c.replace(&l.font, h)
+}
+
+// SetTextColor sets the color of Text.
+func (c *Canvas) SetTextColor(col Color) error {
+	if c.b == nil {
+		return ErrClosed
+	}
+	return c.b.SetTextColor(col)
+}
+
+// SetBackground fills the background of text, hatches and styled pens
+// with col.
+func (c *Canvas) SetBackground(col Color) error {
+	if c.b == nil {
+		return ErrClosed
+	}
+	return c.b.SetBkColor(col, false)
+}
+
+// SetTransparent leaves the background of text, hatches and styled pens
+// untouched.
+func (c *Canvas) SetTransparent() error {
+	if c.b == nil {
+		return ErrClosed
+	}
+	return c.b.SetBkColor(0, true)
+}
+
+// Line draws a line from a to b, excluding b.
+func (c *Canvas) Line(a, b image.Point) error {
+	return c.Polyline(a, b)
+}
+
+// Polyline draws connected line segments with the pen.
+func (c *Canvas) Polyline(pts ...image.Point) error {
+	if c.b == nil {
+		return ErrClosed
+	}
+	if err := checkPoints("Polyline", len(pts)); err != nil {
+		return err
+	}
+	return c.b.Polyline(pts)
+}
+
+// Polygon draws a closed polygon outlined with the pen and filled with the brush.
+func (c *Canvas) Polygon(pts ...image.Point) error {
+	if c.b == nil {
+		return ErrClosed
+	}
+	if err := checkPoints("Polygon", len(pts)); err != nil {
+		return err
+	}
+	return c.b.Polygon(pts)
+}
+
+// Bezier draws cubic Bezier curves with the pen: a start point followed
+// by two control points and an end point for each curve.
+func (c *Canvas) Bezier(pts ...image.Point) error {
+	if c.b == nil {
+		return ErrClosed
+	}
+	if err := checkPoints("PolyBezier", len(pts)); err != nil {
+		return err
+	}
+	return c.b.PolyBezier(pts)
+}
+
+// checkPoints rejects the point counts GDI rejects, and the empty slices
+// a Backend could not pass on.
+func checkPoints(method string, n int) error {
+	switch method {
+	case "PolyBezier":
+		if n < 4 || (n-1)%3 != 0 {
+			return errors.Errorf("gdi: %d Bezier points, need 3n+1", n)
+		}
+	default:
+		if n < 2 {
+			return errors.Errorf("gdi: %s of %d points", method, n)
+		}
+	}
+	return nil
+}
+
+// Rect draws r outlined with the pen and filled with the brush.
+func (c *Canvas) Rect(r image.Rectangle) error {
+	if c.b == nil {
+		return ErrClosed
+	}
+	return c.b.Rectangle(r)
+}
+
+// RoundRect draws r with corners rounded by ellipses of size corner.
+func (c *Canvas) RoundRect(r image.Rectangle, corner image.Point) error {
+	if c.b == nil {
+		return ErrClosed
+	}
+	return c.b.RoundRect(r, corner)
+}
+
+// Ellipse draws the ellipse bounded by r.
+func (c *Canvas) Ellipse(r image.Rectangle) error {
+	if c.b == nil {
+		return ErrClosed
+	}
+	return c.b.Ellipse(r)
+}
+
+// Arc draws the part of the ellipse bounded by r counterclockwise from the
+// radial through start to the radial through end.
+func (c *Canvas) Arc(r image.Rectangle, start, end image.Point) error {
+	if c.b == nil {
+		return ErrClosed
+	}
+	return c.b.Arc(r, start, end)
+}
+
+// Fill fills r with col without changing the selected brush or drawing an outline.
+func (c *Canvas) Fill(r image.Rectangle, col Color) error {
+	if c.b == nil {
+		return ErrClosed
+	}
+	h, err := c.b.CreateBrush(Brush{Color: col})
+	if err != nil {
+		return errors.Wrap(err, "CreateBrush")
+	}
+	err = c.b.FillRect(r, h)
+	if e := c.b.Delete(h); e != nil && err == nil {
+		err = errors.Wrap(e, "DeleteObject")
+	}
+	return err
+}
+
+// DrawPath draws the lines and curves of p with the pen. Unlike StrokePath
+// the figures are drawn one segment at a time, wide pens have no joins.
+func (c *Canvas) DrawPath(p *Path) error {
+	if c.b == nil {
+		return ErrClosed
+	}
+	pts, types := p.Points()
+	if len(pts) == 0 {
+		return nil
+	}
+	return c.b.PolyDraw(pts, types)
+}
+
+// definePath makes p the path of the DC.
+func (c *Canvas) definePath(p *Path) error {
+	if c.b == nil {
+		return ErrClosed
+	}
+	pts, types := p.Points()
+	if len(pts) == 0 {
+		return errors.New("gdi: empty path")
+	}
+	mode := ALTERNATE
+	if p.Winding {
+		mode = WINDING
+	}
+	if err := c.b.SetPolyFillMode(mode); err != nil {
+		return err
+	}
+	if err := c.b.BeginPath(); err != nil {
+		return err
+	}
+	err := c.b.PolyDraw(pts, types)
+	if err == nil {
+		err = c.b.EndPath()
+	}
+	if err != nil {
+		c.b.AbortPath()
+	}
+	return err
+}
+
+// StrokePath outlines p with the pen.
+func (c *Canvas) StrokePath(p *Path) error {
+	if err := c.definePath(p); err != nil {
+		return err
+	}
+	return c.b.StrokePath()
+}
+
+// FillPath fills the figures of p with the brush, open figures are closed.
+func (c *Canvas) FillPath(p *Path) error {
+	if err := c.definePath(p); err != nil {
+		return err
+	}
+	return c.b.FillPath()
+}
+
+// StrokeAndFillPath fills p with the brush and outlines it with the pen.
+func (c *Canvas) StrokeAndFillPath(p *Path) error {
+	if err := c.definePath(p); err != nil {
+		return err
+	}
+	return c.b.StrokeAndFillPath()
+}
+
+// PathRegion returns the area covered by filling p as a region in device
+// coordinates. The caller deletes it with DeleteObject.
+func (c *Canvas) PathRegion(p *Path) (Handle, error) {
+	if err := c.definePath(p); err != nil {
+		return 0, err
+	}
+	return c.b.PathToRegion()
+}
+
+// Text draws s with the font and text color, aligned to p as given by a.
+func (c *Canvas) Text(p image.Point, s string, a Align) error {
+	if c.b == nil {
+		return ErrClosed
+	}
+	if err := c.b.SetTextAlign(a); err != nil {
+		return err
+	}
+	return c.b.TextOut(p, s)
+}
+
+// Clip restricts drawing to the part of the current clipping area inside r.
+func (c *Canvas) Clip(r image.Rectangle) error {
+	if c.b == nil {
+		return ErrClosed
+	}
+	return c.b.IntersectClip(r)
+}
+
+// ExcludeClip removes r from the clipping area.
+func (c *Canvas) ExcludeClip(r image.Rectangle) error {
+	if c.b == nil {
+		return ErrClosed
+	}
+	return c.b.ExcludeClip(r)
+}
+
+// ResetClip removes all clipping, including clipping set before the last Save.
+func (c *Canvas) ResetClip() error {
+	if c.b == nil {
+		return ErrClosed
+	}
+	return c.b.ResetClip()
+}
@@ -0,0 +1,287 @@
+package gdi
+
+import (
+	"image"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func checkCalls(t *testing.T, r *Recorder, want ...string) {
+	t.Helper()
+	if got := r.Strings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("calls\n\t%s\nwant\n\t%s", strings.Join(got, "\n\t"), strings.Join(want, "\n\t"))
+	}
+	r.Reset()
+}
+
+func checkLive(t *testing.T, r *Recorder, want ...Handle) {
+	t.Helper()
+	if got := r.Live(); !reflect.DeepEqual(got, want) {
+		t.Errorf("live objects %v, want %v", got, want)
+	}
+}
+
+func TestColor(t *testing.T) {
+	c := RGB(0x12, 0x34, 0x56)
+	if c != 0x563412 || c.R() != 0x12 || c.G() != 0x34 || c.B() != 0x56 {
+		t.Errorf("RGB = %#x", uint32(c))
+	}
+	if r, g, b, a := c.RGBA(); r != 0x1212 || g != 0x3434 || b != 0x5656 || a != 0xFFFF {
+		t.Errorf("RGBA = %#x %#x %#x %#x", r, g, b, a)
+	}
+}
+
+func TestCanvasReplacesObjects(t *testing.T) {
+	r := NewRecorder()
+	c, err := New(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetPen(Pen{Width: 1})
+	c.SetPen(Pen{Width: 2})
+	c.SetBrush(Brush{Color: RGB(1, 2, 3)})
+	checkCalls(t, r,
+		"Save(1)",
+		"CreatePen({0 1 0}, 4)", "Select(4)",
+		"CreatePen({0 2 0}, 5)", "Select(5)", "Delete(4)",
+		"CreateBrush({0 197121 0}, 6)", "Select(6)",
+	)
+	checkLive(t, r, 5, 6)
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	checkCalls(t, r, "Restore(1)", "Delete(5)", "Delete(6)")
+	checkLive(t, r)
+}
+
+func TestCanvasSaveRestore(t *testing.T) {
+	r := NewRecorder()
+	c, _ := New(r)
+	c.SetPen(Pen{Width: 1})
+	c.SetFont(Font{Face: "Arial"})
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	// the outer pen stays selected in the saved state and must live on
+	c.SetPen(Pen{Width: 2})
+	c.SetPen(Pen{Width: 3})
+	c.SetBrush(NullBrush)
+	r.Reset()
+
+	if err := c.Restore(); err != nil {
+		t.Fatal(err)
+	}
+	checkCalls(t, r, "Restore(2)", "Delete(7)", "Delete(8)")
+	checkLive(t, r, 4, 5)
+	if got := r.state.selected; got != [3]Handle{4, 2, 5} {
+		t.Errorf("selected %v after Restore", got)
+	}
+
+	if err := c.Restore(); err == nil {
+		t.Error("Restore popped the state saved by New")
+	}
+	r.Reset()
+
+	c.Close()
+	checkCalls(t, r, "Restore(1)", "Delete(4)", "Delete(5)")
+	checkLive(t, r)
+	if got := r.state.selected; got != [3]Handle{1, 2, 3} {
+		t.Errorf("selected %v after Close, want the stock objects", got)
+	}
+}
+
+func TestCanvasNestedClose(t *testing.T) {
+	r := NewRecorder()
+	c, _ := New(r)
+	for i := 1; i <= 3; i++ {
+		c.SetPen(Pen{Width: i})
+		c.SetBrush(Brush{Hatch: uint32(i)})
+		c.Save()
+	}
+	c.SetFont(Font{})
+	if len(r.Live()) != 7 {
+		t.Fatalf("live objects %v", r.Live())
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	checkLive(t, r)
+	if len(r.saved) != 0 {
+		t.Errorf("%d saved states left", len(r.saved))
+	}
+}
+
+func TestCanvasFill(t *testing.T) {
+	r := NewRecorder()
+	c, _ := New(r)
+	r.Reset()
+	if err := c.Fill(image.Rect(0, 0, 10, 10), RGB(0xFF, 0, 0)); err != nil {
+		t.Fatal(err)
+	}
+	checkCalls(t, r, "CreateBrush({0 255 0}, 4)", "FillRect((0,0)-(10,10), 4)", "Delete(4)")
+	checkLive(t, r)
+}
+
+func TestCanvasClosed(t *testing.T) {
+	c, _ := New(NewRecorder())
+	c.Close()
+	for name, err := range map[string]error{
+		"SetPen":    c.SetPen(Pen{}),
+		"SetBrush":  c.SetBrush(Brush{}),
+		"SetFont":   c.SetFont(Font{}),
+		"Save":      c.Save(),
+		"Line":      c.Line(image.Point{}, image.Pt(1, 1)),
+		"Rect":      c.Rect(image.Rect(0, 0, 1, 1)),
+		"Fill":      c.Fill(image.Rect(0, 0, 1, 1), 0),
+		"Text":      c.Text(image.Point{}, "x", AlignLeft),
+		"Clip":      c.Clip(image.Rect(0, 0, 1, 1)),
+		"DrawPath":  c.DrawPath(new(Path).MoveTo(0, 0).LineTo(1, 1)),
+		"FillPath":  c.FillPath(new(Path).Rect(image.Rect(0, 0, 1, 1))),
+		"ResetClip": c.ResetClip(),
+	} {
+		if err != ErrClosed {
+			t.Errorf("%s on a closed canvas: %v", name, err)
+		}
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+}
+
+func TestPointCounts(t *testing.T) {
+	pts := []image.Point{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {2, 2}, {3, 3}, {4, 4}}
+	tests := []struct {
+		method string
+		ok     []int
+	}{
+		{"Polyline", []int{2, 3, 4, 5, 6, 7}},
+		{"Polygon", []int{2, 3, 4, 5, 6, 7}},
+		{"PolyBezier", []int{4, 7}},
+	}
+	for _, tt := range tests {
+		r := NewRecorder()
+		c, _ := New(r)
+		draw := map[string]func(...image.Point) error{
+			"Polyline":   c.Polyline,
+			"Polygon":    c.Polygon,
+			"PolyBezier": c.Bezier,
+		}[tt.method]
+		backend := map[string]func([]image.Point) error{
+			"Polyline":   r.Polyline,
+			"Polygon":    r.Polygon,
+			"PolyBezier": r.PolyBezier,
+		}[tt.method]
+		for n := 0; n <= len(pts); n++ {
+			ok := false
+			for _, m := range tt.ok {
+				ok = ok || m == n
+			}
+			if err := draw(pts[:n]...); (err == nil) != ok {
+				t.Errorf("Canvas %s of %d points: %v", tt.method, n, err)
+			}
+			// the backends reject what they cannot pass on by themselves
+			if err := backend(pts[:n]); (err == nil) != ok {
+				t.Errorf("Recorder %s of %d points: %v", tt.method, n, err)
+			}
+		}
+	}
+}
+
+func TestCanvasPath(t *testing.T) {
+	r := NewRecorder()
+	c, _ := New(r)
+	r.Reset()
+	p := new(Path).MoveTo(0, 0).LineTo(10, 0).LineTo(10, 10).Close()
+	if err := c.StrokePath(p); err != nil {
+		t.Fatal(err)
+	}
+	checkCalls(t, r,
+		"SetPolyFillMode(1)", "BeginPath()",
+		"PolyDraw([(0,0) (10,0) (10,10)], [6 2 3])",
+		"EndPath()", "StrokePath()",
+	)
+
+	p.Winding = true
+	h, err := c.PathRegion(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Calls[0].String() != "SetPolyFillMode(2)" {
+		t.Errorf("winding path starts with %v", r.Calls[0])
+	}
+	checkLive(t, r, h)
+
+	if err := c.FillPath(new(Path)); err == nil {
+		t.Error("FillPath accepted an empty path")
+	}
+	if err := c.DrawPath(new(Path)); err != nil {
+		t.Errorf("DrawPath of an empty path: %v", err)
+	}
+}
+
+// failingSelect is a Recorder whose Select fails, as SelectObject does for
+// objects of another device.
+type failingSelect struct {
+	*Recorder
+}
+
+func (f failingSelect) Select(h Handle) (Handle, error) {
+	f.record("Select", h)
+	return 0, errors.New("select failed")
+}
+
+func TestCanvasSelectFailure(t *testing.T) {
+	r := NewRecorder()
+	c, _ := New(failingSelect{r})
+	if err := c.SetPen(Pen{}); err == nil {
+		t.Fatal("SetPen ignored the failed Select")
+	}
+	checkLive(t, r)
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecorderChecks(t *testing.T) {
+	r := NewRecorder()
+	if _, err := r.Select(99); err == nil {
+		t.Error("Select of an unknown object")
+	}
+	if err := r.Delete(1); err == nil {
+		t.Error("Delete of the selected stock pen")
+	}
+	pen, _ := r.CreatePen(Pen{})
+	r.Select(pen)
+	r.Save()
+	r.Select(1)
+	if err := r.Delete(pen); err == nil {
+		t.Error("Delete of a pen RestoreDC would select again")
+	}
+	if err := r.Restore(2); err == nil {
+		t.Error("Restore of an unsaved level")
+	}
+	if err := r.Restore(-1); err != nil {
+		t.Errorf("Restore(-1): %v", err)
+	}
+	r.Select(1)
+	if err := r.Delete(pen); err != nil {
+		t.Errorf("Delete of a deselected pen: %v", err)
+	}
+	if err := r.Delete(pen); err == nil {
+		t.Error("second Delete")
+	}
+	if err := r.FillRect(image.Rect(0, 0, 1, 1), 1); err == nil {
+		t.Error("FillRect with a pen")
+	}
+	if err := r.StrokePath(); err == nil {
+		t.Error("StrokePath without a path")
+	}
+	if err := r.EndPath(); err == nil {
+		t.Error("EndPath without BeginPath")
+	}
+	checkLive(t, r)
+}
@@ -0,0 +1,290 @@
+package gdi
+
+import (
+	"image"
+	"syscall"
+	"unsafe"
+
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi"
+	"github.com/whiteboxsolutions/winapi/gdileak"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	hgdiError   = win.HGDIOBJ(^uintptr(0))
+	regionError = 0
+)
+
+// DC is the Backend drawing on a device context.
+type DC struct {
+	HDC win.HDC
+}
+
+// NewCanvas returns a Canvas drawing on hdc. Closing it restores the
+// state of hdc but does not release it.
+func NewCanvas(hdc win.HDC) (*Canvas, error) {
+	return New(DC{HDC: hdc})
+}
+
+func lastError(fn string) error {
+	return errors.Wrap(windows.GetLastError(), fn)
+}
+
+func rect(r image.Rectangle) (int32, int32, int32, int32) {
+	return int32(r.Min.X), int32(r.Min.Y), int32(r.Max.X), int32(r.Max.Y)
+}
+
+func points(pts []image.Point) []win.POINT {
+	p := make([]win.POINT, len(pts))
+	for i, pt := range pts {
+		p[i] = win.POINT{X: int32(pt.X), Y: int32(pt.Y)}
+	}
+	return p
+}
+
+func (d DC) CreatePen(p Pen) (Handle, error) {
+	h := winapi.CreatePen(int(p.Style), p.Width, uint32(p.Color))
+	if h == 0 {
+		return 0, lastError("CreatePen")
+	}
+	return Handle(h), nil
+}
+
+func (d DC) CreateBrush(b Brush) (Handle, error) {
+	lb := win.LOGBRUSH{LbStyle: b.Style, LbColor: win.COLORREF(b.Color), LbHatch: uintptr(b.Hatch)}
+	h := win.CreateBrushIndirect(&lb)
+	if h == 0 {
+		return 0, lastError("CreateBrushIndirect")
+	}
+	winapi.TrackGDIObject(uintptr(h), gdileak.Brush)
+	return Handle(h), nil
+}
+
+func (d DC) CreateFont(f Font) (Handle, error) {
+	lf := win.LOGFONT{
+		LfHeight:  int32(f.Height),
+		LfWeight:  int32(f.Weight),
+		LfCharSet: win.DEFAULT_CHARSET,
+		LfQuality: f.Quality,
+	}
+	if f.Italic {
+		lf.LfItalic = 1
+	}
+	if f.Underline {
+		lf.LfUnderline = 1
+	}
+	if f.StrikeOut {
+		lf.LfStrikeOut = 1
+	}
+	face, err := syscall.UTF16FromString(f.Face)
+	if err != nil {
+		return 0, err
+	}
+	if len(face) > len(lf.LfFaceName) {
+		return 0, errors.Errorf("gdi: font face %q is too long", f.Face)
+	}
+	copy(lf.LfFaceName[:], face)
+	h := win.CreateFontIndirect(&lf)
+	if h == 0 {
+		return 0, lastError("CreateFontIndirect")
+	}
+	winapi.TrackGDIObject(uintptr(h), gdileak.Font)
+	return Handle(h), nil
+}
+
+func (d DC) Select(h Handle) (Handle, error) {
+	old := win.SelectObject(d.HDC, win.HGDIOBJ(h))
+	if old == 0 || old == hgdiError {
+		return 0, lastError("SelectObject")
+	}
+	return Handle(old), nil
+}
+
+func (d DC) Delete(h Handle) error {
+	if !winapi.DeleteObject(win.HGDIOBJ(h)) {
+		return lastError("DeleteObject")
+	}
+	return nil
+}
+
+func (d DC) Save() (int, error) {
+	n := win.SaveDC(d.HDC)
+	if n == 0 {
+		return 0, lastError("SaveDC")
+	}
+	return int(n), nil
+}
+
+func (d DC) Restore(level int) error {
+	if !win.RestoreDC(d.HDC, int32(level)) {
+		return lastError("RestoreDC")
+	}
+	return nil
+}
+
+func (d DC) Polyline(pts []image.Point) error {
+	if err := checkPoints("Polyline", len(pts)); err != nil {
+		return err
+	}
+	p := points(pts)
+	if !win.Polyline(d.HDC, unsafe.Pointer(&p[0]), int32(len(p))) {
+		return lastError("Polyline")
+	}
+	return nil
+}
+
+func (d DC) Polygon(pts []image.Point) error {
+	if err := checkPoints("Polygon", len(pts)); err != nil {
+		return err
+	}
+	return winapi.Polygon(d.HDC, points(pts))
+}
+
+func (d DC) PolyBezier(pts []image.Point) error {
+	if err := checkPoints("PolyBezier", len(pts)); err != nil {
+		return err
+	}
+	return winapi.PolyBezier(d.HDC, points(pts))
+}
+
+func (d DC) Rectangle(r image.Rectangle) error {
+	left, top, right, bottom := rect(r)
+	if !win.Rectangle_(d.HDC, left, top, right, bottom) {
+		return lastError("Rectangle")
+	}
+	return nil
+}
+
+func (d DC) RoundRect(r image.Rectangle, corner image.Point) error {
+	left, top, right, bottom := rect(r)
+	if !win.RoundRect(d.HDC, left, top, right, bottom, int32(corner.X), int32(corner.Y)) {
+		return lastError("RoundRect")
+	}
+	return nil
+}
+
+func (d DC) Ellipse(r image.Rectangle) error {
+	left, top, right, bottom := rect(r)
+	if !win.Ellipse(d.HDC, left, top, right, bottom) {
+		return lastError("Ellipse")
+	}
+	return nil
+}
+
+func (d DC) Arc(r image.Rectangle, start, end image.Point) error {
+	return winapi.Arc(d.HDC, r.Min.X, r.Min.Y, r.Max.X, r.Max.Y, start.X, start.Y, end.X, end.Y)
+}
+
+func (d DC) FillRect(r image.Rectangle, brush Handle) error {
+	left, top, right, bottom := rect(r)
+	return winapi.FillRect(d.HDC, win.RECT{Left: left, Top: top, Right: right, Bottom: bottom}, win.HBRUSH(brush))
+}
+
+func (d DC) PolyDraw(pts []image.Point, types []byte) error {
+	return winapi.PolyDraw(d.HDC, points(pts), types)
+}
+
+func (d DC) SetPolyFillMode(mode int) error {
+	if winapi.SetPolyFillMode(d.HDC, mode) == 0 {
+		return lastError("SetPolyFillMode")
+	}
+	return nil
+}
+
+func (d DC) BeginPath() error         { return winapi.BeginPath(d.HDC) }
+func (d DC) EndPath() error           { return winapi.EndPath(d.HDC) }
+func (d DC) AbortPath() error         { return winapi.AbortPath(d.HDC) }
+func (d DC) StrokePath() error        { return winapi.StrokePath(d.HDC) }
+func (d DC) FillPath() error          { return winapi.FillPath(d.HDC) }
+func (d DC) StrokeAndFillPath() error { return winapi.StrokeAndFillPath(d.HDC) }
+
+func (d DC) PathToRegion() (Handle, error) {
+	rgn, err := winapi.PathToRegion(d.HDC)
+	return Handle(rgn), err
+}
+
+func (d DC) SetTextColor(c Color) error {
+	if win.SetTextColor(d.HDC, win.COLORREF(c)) == win.CLR_INVALID {
+		return lastError("SetTextColor")
+	}
+	return nil
+}
+
+func (d DC) SetBkColor(c Color, transparent bool) error {
+	mode := int32(win.OPAQUE)
+	if transparent {
+		mode = win.TRANSPARENT
+	} else if win.SetBkColor(d.HDC, win.COLORREF(c)) == win.CLR_INVALID {
+		return lastError("SetBkColor")
+	}
+	if win.SetBkMode(d.HDC, mode) == 0 {
+		return lastError("SetBkMode")
+	}
+	return nil
+}
+
+func (d DC) SetTextAlign(a Align) error {
+	if winapi.SetTextAlign(d.HDC, uint32(a)) == winapi.GDI_ERROR {
+		return lastError("SetTextAlign")
+	}
+	return nil
+}
+
+func (d DC) TextOut(p image.Point, s string) error {
+	text, err := syscall.UTF16FromString(s)
+	if err != nil {
+		return err
+	}
+	if !win.TextOut(d.HDC, int32(p.X), int32(p.Y), &text[0], int32(len(text)-1)) {
+		return lastError("TextOut")
+	}
+	return nil
+}
+
+func (d DC) DrawText(s string, r image.Rectangle, flags uint32) (image.Rectangle, error) {
+	text, err := syscall.UTF16FromString(s)
+	if err != nil {
+		return image.Rectangle{}, err
+	}
+	// DT_MODIFYSTRING would write past the end of text
+	flags &^= DT_MODIFYSTRING
+	left, top, right, bottom := rect(r)
+	rc := win.RECT{Left: left, Top: top, Right: right, Bottom: bottom}
+	height := win.DrawTextEx(d.HDC, &text[0], int32(len(text)-1), &rc, flags, nil)
+	if height == 0 && len(text) > 1 {
+		return image.Rectangle{}, lastError("DrawTextEx")
+	}
+	if flags&DT_CALCRECT != 0 {
+		return image.Rect(int(rc.Left), int(rc.Top), int(rc.Right), int(rc.Bottom)), nil
+	}
+	return image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+int(height)), nil
+}
+
+func (d DC) DPI() int {
+	return int(win.GetDeviceCaps(d.HDC, win.LOGPIXELSY))
+}
+
+func (d DC) IntersectClip(r image.Rectangle) error {
+	left, top, right, bottom := rect(r)
+	if win.IntersectClipRect(d.HDC, left, top, right, bottom) == regionError {
+		return lastError("IntersectClipRect")
+	}
+	return nil
+}
+
+func (d DC) ExcludeClip(r image.Rectangle) error {
+	left, top, right, bottom := rect(r)
+	if win.ExcludeClipRect(d.HDC, left, top, right, bottom) == regionError {
+		return lastError("ExcludeClipRect")
+	}
+	return nil
+}
+
+func (d DC) ResetClip() error {
+	if winapi.SelectClipRgn(d.HDC, 0) == 0 {
+		return lastError("SelectClipRgn")
+	}
+	return nil
+}
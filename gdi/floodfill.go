@@ -0,0 +1,153 @@
+package gdi
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// ExtFloodFill modes, the same values as winapi.FLOODFILLBORDER and
+// winapi.FLOODFILLSURFACE
+const (
+	// FLOODFILLBORDER fills up to pixels of the given color.
+	FLOODFILLBORDER uint32 = 0
+	// FLOODFILLSURFACE fills the pixels of the given color.
+	FLOODFILLSURFACE uint32 = 1
+)
+
+// FloodFill sets the pixels connected to p horizontally or vertically to
+// fill, as ExtFloodFill does on a device context. Pixels are compared to c
+// by their straight, not premultiplied, red, green and blue bytes; alpha
+// is ignored like GDI ignores the reserved byte of DIB pixels. It reports
+// false and fills nothing when p is outside img, or when p has the border
+// color in FLOODFILLBORDER mode or another color than c in
+// FLOODFILLSURFACE mode.
+//
+// FloodFill fills one scan line run at a time and is the one to use;
+// FloodFillNaive gives the same result pixel by pixel.
+func FloodFill(img draw.Image, p image.Point, c Color, mode uint32, fill color.Color) bool {
+	f := newFiller(img, c, mode, fill)
+	if !p.In(f.b) || !f.inside(p.X, p.Y) {
+		return false
+	}
+	stack := []image.Point{p}
+	for len(stack) > 0 {
+		q := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !f.inside(q.X, q.Y) {
+			continue
+		}
+		x0, x1 := q.X, q.X
+		for x0 > f.b.Min.X && f.inside(x0-1, q.Y) {
+			x0--
+		}
+		for x1 < f.b.Max.X-1 && f.inside(x1+1, q.Y) {
+			x1++
+		}
+		for x := x0; x <= x1; x++ {
+			f.set(x, q.Y)
+		}
+		// seed each run of fillable pixels in the lines above and below
+		for _, y := range [2]int{q.Y - 1, q.Y + 1} {
+			if y < f.b.Min.Y || y >= f.b.Max.Y {
+				continue
+			}
+			run := false
+			for x := x0; x <= x1; x++ {
+				in := f.inside(x, y)
+				if in && !run {
+					stack = append(stack, image.Point{X: x, Y: y})
+				}
+				run = in
+			}
+		}
+	}
+	return true
+}
+
+// FloodFillNaive is FloodFill visiting one pixel at a time in breadth
+// first order. It is kept as a reference to check FloodFill against.
+func FloodFillNaive(img draw.Image, p image.Point, c Color, mode uint32, fill color.Color) bool {
+	f := newFiller(img, c, mode, fill)
+	if !p.In(f.b) || !f.inside(p.X, p.Y) {
+		return false
+	}
+	f.set(p.X, p.Y)
+	queue := []image.Point{p}
+	for len(queue) > 0 {
+		q := queue[0]
+		queue = queue[1:]
+		for _, n := range [4]image.Point{{q.X - 1, q.Y}, {q.X + 1, q.Y}, {q.X, q.Y - 1}, {q.X, q.Y + 1}} {
+			if n.In(f.b) && f.inside(n.X, n.Y) {
+				f.set(n.X, n.Y)
+				queue = append(queue, n)
+			}
+		}
+	}
+	return true
+}
+
+// filler tracks the pixels a flood fill has set, so filling with the
+// color that is matched terminates, and caches which pixels match since
+// reading them through At is the expensive part.
+type filler struct {
+	img    draw.Image
+	b      image.Rectangle
+	color  Color
+	border bool
+	fill   color.Color
+	// visited is set for filled pixels, known for pixels read into match
+	visited, known, match bitmap
+}
+
+type bitmap []uint64
+
+func (m bitmap) get(i int) bool { return m[i/64]&(1<<uint(i%64)) != 0 }
+func (m bitmap) set(i int)      { m[i/64] |= 1 << uint(i%64) }
+
+func newFiller(img draw.Image, c Color, mode uint32, fill color.Color) *filler {
+	b := img.Bounds()
+	n := (b.Dx()*b.Dy() + 63) / 64
+	return &filler{
+		img:     img,
+		b:       b,
+		color:   c &^ 0xFF000000,
+		border:  mode == FLOODFILLBORDER,
+		fill:    fill,
+		visited: make(bitmap, n),
+		known:   make(bitmap, n),
+		match:   make(bitmap, n),
+	}
+}
+
+func (f *filler) index(x, y int) int {
+	return (y-f.b.Min.Y)*f.b.Dx() + x - f.b.Min.X
+}
+
+// inside reports whether the pixel at (x, y), which is in bounds, is yet
+// to be filled.
+func (f *filler) inside(x, y int) bool {
+	i := f.index(x, y)
+	if f.visited.get(i) {
+		return false
+	}
+	if !f.known.get(i) {
+		f.known.set(i)
+		if colorRef(f.img.At(x, y)) == f.color {
+			f.match.set(i)
+		}
+	}
+	return f.match.get(i) != f.border
+}
+
+func (f *filler) set(x, y int) {
+	f.visited.set(f.index(x, y))
+	f.img.Set(x, y, f.fill)
+}
+
+// colorRef returns the COLORREF of the straight red, green and blue
+// bytes of c, the values GDI compares in DIB pixels.
+func colorRef(c color.Color) Color {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return RGB(n.R, n.G, n.B)
+}
@@ -0,0 +1,174 @@
+package gdi
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+var (
+	black = color.NRGBA{0, 0, 0, 0xFF}
+	white = color.NRGBA{0xFF, 0xFF, 0xFF, 0xFF}
+	red   = color.NRGBA{0xFF, 0, 0, 0xFF}
+)
+
+// maze returns a w x h image at an offset from the origin whose pixels
+// are black with probability p and white otherwise.
+func maze(seed int64, w, h int, p float64) *image.NRGBA {
+	rnd := rand.New(rand.NewSource(seed))
+	img := image.NewNRGBA(image.Rect(-3, 5, w-3, h+5))
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			c := white
+			if rnd.Float64() < p {
+				c = black
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func cloneNRGBA(img *image.NRGBA) *image.NRGBA {
+	c := *img
+	c.Pix = append([]uint8(nil), img.Pix...)
+	return &c
+}
+
+func TestFloodFillEquivalence(t *testing.T) {
+	for seed := int64(0); seed < 40; seed++ {
+		for _, p := range []float64{0, 0.2, 0.4, 0.6} {
+			img := maze(seed, 31, 23, p)
+			rnd := rand.New(rand.NewSource(seed))
+			start := image.Pt(img.Rect.Min.X+rnd.Intn(31), img.Rect.Min.Y+rnd.Intn(23))
+			for _, tt := range []struct {
+				c    Color
+				mode uint32
+			}{
+				{RGB(0, 0, 0), FLOODFILLBORDER},
+				{RGB(0xFF, 0xFF, 0xFF), FLOODFILLSURFACE},
+				{RGB(0, 0, 0), FLOODFILLSURFACE},
+			} {
+				fast, naive := cloneNRGBA(img), cloneNRGBA(img)
+				ok := FloodFill(fast, start, tt.c, tt.mode, red)
+				okNaive := FloodFillNaive(naive, start, tt.c, tt.mode, red)
+				if ok != okNaive || !bytes.Equal(fast.Pix, naive.Pix) {
+					t.Fatalf("seed %d, density %v, mode %d from %v: FloodFill differs from FloodFillNaive",
+						seed, p, tt.mode, start)
+				}
+			}
+		}
+	}
+}
+
+func TestFloodFill(t *testing.T) {
+	// a white room with a black wall and a gap at the bottom
+	img := image.NewNRGBA(image.Rect(0, 0, 5, 4))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	for y := 0; y < 3; y++ {
+		img.SetNRGBA(2, y, black)
+	}
+	count := func(img *image.NRGBA, c color.NRGBA) int {
+		n := 0
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 5; x++ {
+				if img.NRGBAAt(x, y) == c {
+					n++
+				}
+			}
+		}
+		return n
+	}
+
+	border := cloneNRGBA(img)
+	if !FloodFill(border, image.Pt(0, 0), RGB(0, 0, 0), FLOODFILLBORDER, red) || count(border, red) != 17 {
+		t.Errorf("border fill set %d pixels", count(border, red))
+	}
+	surface := cloneNRGBA(img)
+	if !FloodFill(surface, image.Pt(2, 1), RGB(0, 0, 0), FLOODFILLSURFACE, red) || count(surface, red) != 3 {
+		t.Errorf("surface fill set %d pixels", count(surface, red))
+	}
+	// filling with the matched color terminates
+	same := cloneNRGBA(img)
+	if !FloodFill(same, image.Pt(0, 0), RGB(0xFF, 0xFF, 0xFF), FLOODFILLSURFACE, white) || !bytes.Equal(same.Pix, img.Pix) {
+		t.Error("filling white with white changed the image")
+	}
+
+	for _, tt := range []struct {
+		p    image.Point
+		c    Color
+		mode uint32
+	}{
+		{image.Pt(-1, 0), RGB(0, 0, 0), FLOODFILLBORDER},
+		{image.Pt(5, 0), RGB(0, 0, 0), FLOODFILLBORDER},
+		{image.Pt(2, 0), RGB(0, 0, 0), FLOODFILLBORDER},
+		{image.Pt(0, 0), RGB(0, 0, 0), FLOODFILLSURFACE},
+	} {
+		m := cloneNRGBA(img)
+		if FloodFill(m, tt.p, tt.c, tt.mode, red) || !bytes.Equal(m.Pix, img.Pix) {
+			t.Errorf("FloodFill from %v in mode %d filled", tt.p, tt.mode)
+		}
+	}
+}
+
+func TestFloodFillStraightColors(t *testing.T) {
+	// half transparent pixels of the same straight color match c
+	// whatever the layout of the image
+	straight := color.NRGBA{0xFF, 0, 0xFF, 0x80}
+	for _, img := range []interface {
+		image.Image
+		Set(x, y int, c color.Color)
+	}{
+		image.NewNRGBA(image.Rect(0, 0, 3, 1)),
+		image.NewRGBA(image.Rect(0, 0, 3, 1)),
+	} {
+		for x := 0; x < 3; x++ {
+			img.Set(x, 0, straight)
+		}
+		if !FloodFill(img, image.Pt(0, 0), RGB(0xFF, 0, 0xFF), FLOODFILLSURFACE, red) {
+			t.Errorf("%T: the seed does not match", img)
+			continue
+		}
+		for x := 0; x < 3; x++ {
+			if got := color.NRGBAModel.Convert(img.At(x, 0)); got != red {
+				t.Errorf("%T: pixel %d = %v", img, x, got)
+			}
+		}
+	}
+	if got := colorRef(color.RGBA{0x40, 0x20, 0x10, 0x80}); got != RGB(0x7F, 0x3F, 0x1F) {
+		t.Errorf("colorRef of a premultiplied color = %#x", uint32(got))
+	}
+	// alpha is ignored
+	if got := colorRef(color.NRGBA{1, 2, 3, 0x10}); got != RGB(1, 2, 3) {
+		t.Errorf("colorRef = %#x", uint32(got))
+	}
+}
+
+func benchmarkFloodFill(b *testing.B, fill func(img *image.NRGBA) bool) {
+	img := maze(1, 512, 512, 0.3)
+	img.SetNRGBA(img.Rect.Min.X, img.Rect.Min.Y, white)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		m := cloneNRGBA(img)
+		b.StartTimer()
+		fill(m)
+	}
+}
+
+func BenchmarkFloodFill(b *testing.B) {
+	benchmarkFloodFill(b, func(img *image.NRGBA) bool {
+		return FloodFill(img, img.Rect.Min, RGB(0, 0, 0), FLOODFILLBORDER, red)
+	})
+}
+
+func BenchmarkFloodFillNaive(b *testing.B) {
+	benchmarkFloodFill(b, func(img *image.NRGBA) bool {
+		return FloodFillNaive(img, img.Rect.Min, RGB(0, 0, 0), FLOODFILLBORDER, red)
+	})
+}
@@ -0,0 +1,239 @@
+package gdi
+
+import (
+	"image"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// LOGFONT lfQuality
+const (
+	DEFAULT_QUALITY           uint8 = 0
+	DRAFT_QUALITY             uint8 = 1
+	PROOF_QUALITY             uint8 = 2
+	NONANTIALIASED_QUALITY    uint8 = 3
+	ANTIALIASED_QUALITY       uint8 = 4
+	CLEARTYPE_QUALITY         uint8 = 5
+	CLEARTYPE_NATURAL_QUALITY uint8 = 6
+)
+
+// DefaultDPI is the resolution at which a point is 4/3 pixels.
+const DefaultDPI = 96
+
+// PointsToPixels converts a font size in points to pixels at dpi,
+// rounding like MulDiv.
+func PointsToPixels(points float64, dpi int) int {
+	px := points * float64(dpi) / 72
+	if px < 0 {
+		return -int(math.Floor(-px + 0.5))
+	}
+	return int(math.Floor(px + 0.5))
+}
+
+// PixelsToPoints converts a size in pixels at dpi to points.
+func PixelsToPoints(pixels int, dpi int) float64 {
+	if dpi == 0 {
+		return 0
+	}
+	return float64(pixels) * 72 / float64(dpi)
+}
+
+// FontSpec describes a font independent of the device resolution.
+type FontSpec struct {
+	Family string
+	// Size is the em height in points.
+	Size float64
+	// DPI is the resolution the size is converted at, zero uses the
+	// resolution of the device the font is selected into.
+	DPI       int
+	Weight    int
+	Italic    bool
+	Underline bool
+	StrikeOut bool
+	Quality   uint8
+}
+
+// Font returns the logical font of s at dpi, or at s.DPI when it is set.
+func (s FontSpec) Font(dpi int) Font {
+	if s.DPI != 0 {
+		dpi = s.DPI
+	}
+	if dpi == 0 {
+		dpi = DefaultDPI
+	}
+	return Font{
+		Face:      s.Family,
+		Height:    -PointsToPixels(s.Size, dpi),
+		Weight:    s.Weight,
+		Italic:    s.Italic,
+		Underline: s.Underline,
+		StrikeOut: s.StrikeOut,
+		Quality:   s.Quality,
+	}
+}
+
+// DrawText format flags
+const (
+	DT_TOP                  uint32 = 0x00000000
+	DT_LEFT                 uint32 = 0x00000000
+	DT_CENTER               uint32 = 0x00000001
+	DT_RIGHT                uint32 = 0x00000002
+	DT_VCENTER              uint32 = 0x00000004
+	DT_BOTTOM               uint32 = 0x00000008
+	DT_WORDBREAK            uint32 = 0x00000010
+	DT_SINGLELINE           uint32 = 0x00000020
+	DT_EXPANDTABS           uint32 = 0x00000040
+	DT_TABSTOP              uint32 = 0x00000080
+	DT_NOCLIP               uint32 = 0x00000100
+	DT_EXTERNALLEADING      uint32 = 0x00000200
+	DT_CALCRECT             uint32 = 0x00000400
+	DT_NOPREFIX             uint32 = 0x00000800
+	DT_INTERNAL             uint32 = 0x00001000
+	DT_EDITCONTROL          uint32 = 0x00002000
+	DT_PATH_ELLIPSIS        uint32 = 0x00004000
+	DT_END_ELLIPSIS         uint32 = 0x00008000
+	DT_MODIFYSTRING         uint32 = 0x00010000
+	DT_RTLREADING           uint32 = 0x00020000
+	DT_WORD_ELLIPSIS        uint32 = 0x00040000
+	DT_NOFULLWIDTHCHARBREAK uint32 = 0x00080000
+	DT_HIDEPREFIX           uint32 = 0x00100000
+	DT_PREFIXONLY           uint32 = 0x00200000
+)
+
+// HAlign is the horizontal alignment of DrawText.
+type HAlign int
+
+const (
+	TextLeft HAlign = iota
+	TextCenter
+	TextRight
+)
+
+// VAlign is the vertical alignment of DrawText.
+type VAlign int
+
+const (
+	TextTop VAlign = iota
+	TextMiddle
+	TextBottom
+)
+
+// Ellipsis selects how DrawText shortens text that does not fit.
+type Ellipsis int
+
+const (
+	NoEllipsis Ellipsis = iota
+	// EndEllipsis replaces the end of the text with "...".
+	EndEllipsis
+	// WordEllipsis cuts at a word boundary before adding "...".
+	WordEllipsis
+	// PathEllipsis replaces the middle, keeping the last path element.
+	PathEllipsis
+)
+
+// TextFormat describes the layout of DrawText.
+type TextFormat struct {
+	Align  HAlign
+	VAlign VAlign
+	// SingleLine ignores line breaks. Multi-line text is aligned
+	// vertically by measuring it first, since GDI only aligns single lines.
+	SingleLine bool
+	// Wrap breaks lines between words at the right edge.
+	Wrap       bool
+	Ellipsis   Ellipsis
+	ExpandTabs bool
+	// NoPrefix draws & literally instead of underlining the next character.
+	NoPrefix bool
+	NoClip   bool
+}
+
+// Flags returns the DT_* flags of f. Vertical alignment only applies to
+// single lines, for multi-line text Canvas.DrawText offsets the rectangle.
+func (f TextFormat) Flags() uint32 {
+	var flags uint32
+	switch f.Align {
+	case TextCenter:
+		flags |= DT_CENTER
+	case TextRight:
+		flags |= DT_RIGHT
+	}
+	if f.SingleLine {
+		flags |= DT_SINGLELINE
+		switch f.VAlign {
+		case TextMiddle:
+			flags |= DT_VCENTER
+		case TextBottom:
+			flags |= DT_BOTTOM
+		}
+	} else if f.Wrap {
+		flags |= DT_WORDBREAK
+	}
+	switch f.Ellipsis {
+	case EndEllipsis:
+		flags |= DT_END_ELLIPSIS
+	case WordEllipsis:
+		flags |= DT_WORD_ELLIPSIS
+	case PathEllipsis:
+		flags |= DT_PATH_ELLIPSIS
+	}
+	if f.ExpandTabs {
+		flags |= DT_EXPANDTABS
+	}
+	if f.NoPrefix {
+		flags |= DT_NOPREFIX
+	}
+	if f.NoClip {
+		flags |= DT_NOCLIP
+	}
+	return flags
+}
+
+// SetFontSpec selects the font described by s, converted at the
+// resolution of the device unless s sets one.
+func (c *Canvas) SetFontSpec(s FontSpec) error {
+	if c.b == nil {
+		return ErrClosed
+	}
+	return c.SetFont(s.Font(c.b.DPI()))
+}
+
+// DrawText draws s inside r with the font and text color, laid out as
+// described by f.
+func (c *Canvas) DrawText(s string, r image.Rectangle, f TextFormat) error {
+	if c.b == nil {
+		return ErrClosed
+	}
+	flags := f.Flags()
+	if !f.SingleLine && f.VAlign != TextTop {
+		calc, err := c.b.DrawText(s, image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y), flags|DT_CALCRECT)
+		if err != nil {
+			return errors.Wrap(err, "DrawText")
+		}
+		space := r.Dy() - calc.Dy()
+		if f.VAlign == TextMiddle {
+			space /= 2
+		}
+		if space > 0 {
+			r.Min.Y += space
+		}
+	}
+	_, err := c.b.DrawText(s, r, flags)
+	return err
+}
+
+// MeasureText returns the size of s laid out by f. Wrapped text is broken
+// at width; otherwise width is ignored.
+func (c *Canvas) MeasureText(s string, f TextFormat, width int) (image.Point, error) {
+	if c.b == nil {
+		return image.Point{}, ErrClosed
+	}
+	if !f.Wrap || f.SingleLine {
+		width = 0
+	}
+	calc, err := c.b.DrawText(s, image.Rect(0, 0, width, 0), f.Flags()|DT_CALCRECT)
+	if err != nil {
+		return image.Point{}, errors.Wrap(err, "DrawText")
+	}
+	return calc.Size(), nil
+}
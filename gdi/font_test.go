@@ -0,0 +1,200 @@
+package gdi
+
+import (
+	"image"
+	"testing"
+)
+
+func TestTextFormatFlags(t *testing.T) {
+	tests := []struct {
+		f    TextFormat
+		want uint32
+	}{
+		{TextFormat{}, DT_LEFT | DT_TOP},
+		{TextFormat{Align: TextCenter}, DT_CENTER},
+		{TextFormat{Align: TextRight}, DT_RIGHT},
+		{TextFormat{SingleLine: true}, DT_SINGLELINE},
+		{TextFormat{SingleLine: true, VAlign: TextMiddle}, DT_SINGLELINE | DT_VCENTER},
+		{TextFormat{SingleLine: true, VAlign: TextBottom, Align: TextRight}, DT_SINGLELINE | DT_BOTTOM | DT_RIGHT},
+		// GDI aligns single lines only, Canvas.DrawText handles the rest
+		{TextFormat{VAlign: TextMiddle}, 0},
+		{TextFormat{VAlign: TextBottom, Wrap: true}, DT_WORDBREAK},
+		// single lines never wrap
+		{TextFormat{SingleLine: true, Wrap: true}, DT_SINGLELINE},
+		{TextFormat{Ellipsis: EndEllipsis}, DT_END_ELLIPSIS},
+		{TextFormat{Ellipsis: WordEllipsis}, DT_WORD_ELLIPSIS},
+		{TextFormat{Ellipsis: PathEllipsis}, DT_PATH_ELLIPSIS},
+		{TextFormat{ExpandTabs: true, NoPrefix: true, NoClip: true}, DT_EXPANDTABS | DT_NOPREFIX | DT_NOCLIP},
+		{
+			TextFormat{Align: TextCenter, SingleLine: true, VAlign: TextMiddle, Ellipsis: EndEllipsis, NoPrefix: true},
+			DT_CENTER | DT_SINGLELINE | DT_VCENTER | DT_END_ELLIPSIS | DT_NOPREFIX,
+		},
+	}
+	for _, tt := range tests {
+		got := tt.f.Flags()
+		if got != tt.want {
+			t.Errorf("%+v.Flags() = %#x, want %#x", tt.f, got, tt.want)
+		}
+		if got&(DT_CALCRECT|DT_MODIFYSTRING) != 0 {
+			t.Errorf("%+v.Flags() = %#x sets a flag reserved for measuring", tt.f, got)
+		}
+	}
+}
+
+// the values of winuser.h
+func TestDrawTextFlagValues(t *testing.T) {
+	flags := []uint32{
+		DT_CENTER, DT_RIGHT, DT_VCENTER, DT_BOTTOM, DT_WORDBREAK, DT_SINGLELINE,
+		DT_EXPANDTABS, DT_TABSTOP, DT_NOCLIP, DT_EXTERNALLEADING, DT_CALCRECT,
+		DT_NOPREFIX, DT_INTERNAL, DT_EDITCONTROL, DT_PATH_ELLIPSIS, DT_END_ELLIPSIS,
+		DT_MODIFYSTRING, DT_RTLREADING, DT_WORD_ELLIPSIS, DT_NOFULLWIDTHCHARBREAK,
+		DT_HIDEPREFIX, DT_PREFIXONLY,
+	}
+	for i, f := range flags {
+		if f != 1<<uint(i) {
+			t.Errorf("flag %d = %#x, want %#x", i, f, 1<<uint(i))
+		}
+	}
+}
+
+func TestPointsToPixels(t *testing.T) {
+	tests := []struct {
+		points float64
+		dpi    int
+		want   int
+	}{
+		{0, 96, 0},
+		{9, 96, 12},
+		{10, 96, 13},
+		{10.5, 96, 14},
+		{12, 96, 16},
+		{12, 120, 20},
+		{12, 144, 24},
+		{9, 192, 24},
+		{7.5, 96, 10},
+		// halves round away from zero like MulDiv
+		{0.375, 96, 1},
+		{-0.375, 96, -1},
+		{-9, 96, -12},
+		{1, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := PointsToPixels(tt.points, tt.dpi); got != tt.want {
+			t.Errorf("PointsToPixels(%v, %d) = %d, want %d", tt.points, tt.dpi, got, tt.want)
+		}
+	}
+
+	for _, dpi := range []int{72, 96, 120, 144, 192} {
+		for px := -40; px <= 40; px++ {
+			if got := PointsToPixels(PixelsToPoints(px, dpi), dpi); got != px {
+				t.Errorf("PointsToPixels(PixelsToPoints(%d, %d)) = %d", px, dpi, got)
+			}
+		}
+	}
+	if got := PixelsToPoints(16, 96); got != 12 {
+		t.Errorf("PixelsToPoints(16, 96) = %v", got)
+	}
+	if got := PixelsToPoints(16, 0); got != 0 {
+		t.Errorf("PixelsToPoints at 0 dpi = %v", got)
+	}
+}
+
+func TestFontSpec(t *testing.T) {
+	s := FontSpec{Family: "Segoe UI", Size: 9, Weight: FW_BOLD, Italic: true, Quality: CLEARTYPE_QUALITY}
+	want := Font{Face: "Segoe UI", Height: -12, Weight: FW_BOLD, Italic: true, Quality: CLEARTYPE_QUALITY}
+	if got := s.Font(96); got != want {
+		t.Errorf("Font(96) = %+v, want %+v", got, want)
+	}
+	want.Height = -18
+	if got := s.Font(144); got != want {
+		t.Errorf("Font(144) = %+v, want %+v", got, want)
+	}
+	// zero falls back to the default resolution
+	want.Height = -12
+	if got := s.Font(0); got != want {
+		t.Errorf("Font(0) = %+v, want %+v", got, want)
+	}
+	// a fixed resolution wins over the device
+	s.DPI = 192
+	want.Height = -24
+	if got := s.Font(96); got != want {
+		t.Errorf("Font with DPI 192 = %+v, want %+v", got, want)
+	}
+
+	r := NewRecorder()
+	c, _ := New(r)
+	r.Reset()
+	if err := c.SetFontSpec(FontSpec{Family: "Arial", Size: 12}); err != nil {
+		t.Fatal(err)
+	}
+	checkCalls(t, r, "CreateFont({Arial -16 0 false false false 0}, 4)", "Select(4)")
+	c.Close()
+}
+
+func TestCanvasDrawText(t *testing.T) {
+	r := NewRecorder()
+	c, _ := New(r)
+	r.Reset()
+	box := image.Rect(0, 0, 100, 100)
+
+	// single lines are aligned by GDI
+	c.DrawText("abc", box, TextFormat{SingleLine: true, VAlign: TextBottom})
+	checkCalls(t, r, "DrawText(abc, (0,0)-(100,100), 40)")
+
+	// two lines of 16 pixels leave 68 pixels above them
+	c.DrawText("a\nb", box, TextFormat{VAlign: TextBottom})
+	checkCalls(t, r,
+		"DrawText(a\nb, (0,0)-(100,0), 1024)",
+		"DrawText(a\nb, (0,68)-(100,100), 0)",
+	)
+	c.DrawText("a\nb", box, TextFormat{VAlign: TextMiddle, Align: TextCenter})
+	checkCalls(t, r,
+		"DrawText(a\nb, (0,0)-(100,0), 1025)",
+		"DrawText(a\nb, (0,34)-(100,100), 1)",
+	)
+	// text taller than the box starts at its top
+	c.DrawText("a\nb\nc\nd", image.Rect(0, 0, 100, 40), TextFormat{VAlign: TextBottom})
+	if got := r.Calls[1].Args[1]; got != image.Rect(0, 0, 100, 40) {
+		t.Errorf("overflowing text drawn in %v", got)
+	}
+	r.Reset()
+
+	c.Close()
+	if err := c.DrawText("x", box, TextFormat{}); err != ErrClosed {
+		t.Errorf("DrawText on a closed canvas: %v", err)
+	}
+}
+
+func TestMeasureText(t *testing.T) {
+	r := NewRecorder()
+	c, _ := New(r)
+	tests := []struct {
+		s     string
+		f     TextFormat
+		width int
+		want  image.Point
+	}{
+		{"", TextFormat{}, 0, image.Pt(0, 16)},
+		{"hello", TextFormat{}, 0, image.Pt(40, 16)},
+		{"hello\nworld!", TextFormat{}, 0, image.Pt(48, 32)},
+		{"hello\r\nworld!", TextFormat{SingleLine: true}, 0, image.Pt(96, 16)},
+		// the width only applies to wrapped text
+		{"one two three", TextFormat{}, 40, image.Pt(104, 16)},
+		{"one two three", TextFormat{Wrap: true}, 64, image.Pt(56, 32)},
+		{"one two three", TextFormat{Wrap: true}, 8, image.Pt(40, 48)},
+		{"one two three", TextFormat{Wrap: true, SingleLine: true}, 8, image.Pt(104, 16)},
+	}
+	for _, tt := range tests {
+		got, err := c.MeasureText(tt.s, tt.f, tt.width)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("MeasureText(%q, %+v, %d) = %v, want %v", tt.s, tt.f, tt.width, got, tt.want)
+		}
+	}
+	c.Close()
+	if _, err := c.MeasureText("x", TextFormat{}, 0); err != ErrClosed {
+		t.Errorf("MeasureText on a closed canvas: %v", err)
+	}
+}
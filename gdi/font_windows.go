@@ -0,0 +1,74 @@
+package gdi
+
+import (
+	"sort"
+	"sync"
+	"syscall"
+
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi"
+	"golang.org/x/sys/windows"
+)
+
+// FontFamily is an installed font family.
+type FontFamily struct {
+	Name       string
+	TrueType   bool
+	FixedPitch bool
+}
+
+// enumFonts collects the families passed to enumFontsProc.
+var enumFonts struct {
+	sync.Mutex
+	families map[string]FontFamily
+}
+
+var enumFontsProc = syscall.NewCallback(func(lf *win.LOGFONT, tm uintptr, fontType uint32, lParam uintptr) uintptr {
+	name := windows.UTF16ToString(lf.LfFaceName[:])
+	// families prefixed with @ are the vertical variants for East Asian text
+	if name != "" && name[0] != '@' {
+		enumFonts.families[name] = FontFamily{
+			Name:       name,
+			TrueType:   fontType&winapi.TRUETYPE_FONTTYPE != 0,
+			FixedPitch: lf.LfPitchAndFamily&3 == win.FIXED_PITCH,
+		}
+	}
+	return 1
+})
+
+// Families returns the font families installed for the screen, sorted by name.
+func Families() ([]FontFamily, error) {
+	hdc := win.GetDC(0)
+	if hdc == 0 {
+		return nil, errors.Wrap(windows.GetLastError(), "GetDC")
+	}
+	defer win.ReleaseDC(0, hdc)
+
+	// an empty face name and DEFAULT_CHARSET list every family once per character set
+	lf := win.LOGFONT{LfCharSet: win.DEFAULT_CHARSET}
+	enumFonts.Lock()
+	enumFonts.families = map[string]FontFamily{}
+	winapi.EnumFontFamiliesEx(hdc, &lf, enumFontsProc, 0)
+	found := enumFonts.families
+	enumFonts.families = nil
+	enumFonts.Unlock()
+
+	families := make([]FontFamily, 0, len(found))
+	for _, f := range found {
+		families = append(families, f)
+	}
+	sort.Slice(families, func(i, j int) bool { return families[i].Name < families[j].Name })
+	return families, nil
+}
+
+// ScreenDPI returns the vertical resolution of the screen DC, the DPI the
+// process sees according to its DPI awareness.
+func ScreenDPI() int {
+	hdc := win.GetDC(0)
+	if hdc == 0 {
+		return DefaultDPI
+	}
+	defer win.ReleaseDC(0, hdc)
+	return DC{HDC: hdc}.DPI()
+}
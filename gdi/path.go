@@ -0,0 +1,216 @@
+package gdi
+
+import (
+	"image"
+	"math"
+)
+
+// PolyDraw point types
+const (
+	PT_CLOSEFIGURE byte = 0x01
+	PT_LINETO      byte = 0x02
+	PT_BEZIERTO    byte = 0x04
+	PT_MOVETO      byte = 0x06
+)
+
+// SetPolyFillMode modes
+const (
+	ALTERNATE = 1
+	WINDING   = 2
+)
+
+// Path is a sequence of figures made of lines and cubic Bezier curves, in
+// the form PolyDraw takes. Quadratic curves and arcs are converted to
+// cubic curves as they are added. The zero value is an empty path.
+type Path struct {
+	// Winding fills with the nonzero winding rule instead of alternate.
+	Winding bool
+
+	pts   [][2]float64
+	types []byte
+	cur   [2]float64
+	// start is the index of the MoveTo of the open figure
+	start int
+	open  bool
+}
+
+// point returns the current point.
+func (p *Path) point() (float64, float64) {
+	return p.cur[0], p.cur[1]
+}
+
+func (p *Path) add(t byte, x, y float64) {
+	p.cur = [2]float64{x, y}
+	p.pts = append(p.pts, p.cur)
+	p.types = append(p.types, t)
+}
+
+// ensureFigure starts a figure at the current point when segments are
+// added without a MoveTo or after Close.
+func (p *Path) ensureFigure() {
+	if !p.open {
+		x, y := p.point()
+		p.MoveTo(x, y)
+	}
+}
+
+// MoveTo starts a new figure at (x, y).
+func (p *Path) MoveTo(x, y float64) *Path {
+	if n := len(p.types); p.open && n > 0 && p.start == n-1 {
+		// a figure without segments is replaced
+		p.cur = [2]float64{x, y}
+		p.pts[n-1] = p.cur
+		return p
+	}
+	p.start = len(p.pts)
+	p.open = true
+	p.add(PT_MOVETO, x, y)
+	return p
+}
+
+// LineTo adds a line from the current point to (x, y).
+func (p *Path) LineTo(x, y float64) *Path {
+	if !p.open && len(p.pts) == 0 {
+		return p.MoveTo(x, y)
+	}
+	p.ensureFigure()
+	p.add(PT_LINETO, x, y)
+	return p
+}
+
+// CubicTo adds a cubic Bezier curve with the control points (x1, y1) and
+// (x2, y2) ending at (x, y).
+func (p *Path) CubicTo(x1, y1, x2, y2, x, y float64) *Path {
+	p.ensureFigure()
+	p.add(PT_BEZIERTO, x1, y1)
+	p.add(PT_BEZIERTO, x2, y2)
+	p.add(PT_BEZIERTO, x, y)
+	return p
+}
+
+// QuadTo adds a quadratic Bezier curve with the control point (x1, y1)
+// ending at (x, y), as the equivalent cubic curve.
+func (p *Path) QuadTo(x1, y1, x, y float64) *Path {
+	p.ensureFigure()
+	x0, y0 := p.point()
+	return p.CubicTo(
+		x0+2.0/3*(x1-x0), y0+2.0/3*(y1-y0),
+		x+2.0/3*(x1-x), y+2.0/3*(y1-y),
+		x, y)
+}
+
+// Arc adds the arc of the ellipse centered at (cx, cy) with radii rx and
+// ry from angle start sweeping by sweep, in radians with the y axis
+// pointing down, so positive sweeps run clockwise on screen. A line
+// connects the current point to the start of the arc; without a current
+// figure the arc starts a new one.
+func (p *Path) Arc(cx, cy, rx, ry, start, sweep float64) *Path {
+	x0, y0 := cx+rx*math.Cos(start), cy+ry*math.Sin(start)
+	if p.open {
+		if x, y := p.point(); x != x0 || y != y0 {
+			p.LineTo(x0, y0)
+		}
+	} else {
+		p.MoveTo(x0, y0)
+	}
+
+	// up to a quarter turn per curve keeps the error below 0.03% of the radius
+	n := int(math.Ceil(math.Abs(sweep) / (math.Pi / 2)))
+	if n == 0 {
+		return p
+	}
+	step := sweep / float64(n)
+	k := 4.0 / 3 * math.Tan(step/4)
+	a := start
+	for i := 0; i < n; i++ {
+		cos0, sin0 := math.Cos(a), math.Sin(a)
+		cos1, sin1 := math.Cos(a+step), math.Sin(a+step)
+		p.CubicTo(
+			cx+rx*(cos0-k*sin0), cy+ry*(sin0+k*cos0),
+			cx+rx*(cos1+k*sin1), cy+ry*(sin1-k*cos1),
+			cx+rx*cos1, cy+ry*sin1)
+		a += step
+	}
+	return p
+}
+
+// Close closes the current figure with a line back to its start.
+func (p *Path) Close() *Path {
+	if !p.open || p.start == len(p.pts)-1 {
+		return p
+	}
+	p.types[len(p.types)-1] |= PT_CLOSEFIGURE
+	p.open = false
+	p.cur = p.pts[p.start]
+	return p
+}
+
+// Rect adds r as a closed figure.
+func (p *Path) Rect(r image.Rectangle) *Path {
+	x0, y0, x1, y1 := float64(r.Min.X), float64(r.Min.Y), float64(r.Max.X), float64(r.Max.Y)
+	return p.MoveTo(x0, y0).LineTo(x1, y0).LineTo(x1, y1).LineTo(x0, y1).Close()
+}
+
+// Ellipse adds the ellipse centered at (cx, cy) as a closed figure.
+func (p *Path) Ellipse(cx, cy, rx, ry float64) *Path {
+	return p.MoveTo(cx+rx, cy).Arc(cx, cy, rx, ry, 0, 2*math.Pi).Close()
+}
+
+// Empty reports whether the path has no segments.
+func (p *Path) Empty() bool {
+	for _, t := range p.types {
+		if t != PT_MOVETO {
+			return false
+		}
+	}
+	return true
+}
+
+// Points compiles the path into the point and type arrays of PolyDraw.
+// Coordinates are rounded to the nearest integer and figures without
+// segments are dropped.
+func (p *Path) Points() ([]image.Point, []byte) {
+	pts := make([]image.Point, 0, len(p.pts))
+	types := make([]byte, 0, len(p.types))
+	for i, t := range p.types {
+		if t == PT_MOVETO && (i+1 == len(p.types) || p.types[i+1] == PT_MOVETO) {
+			continue
+		}
+		pts = append(pts, image.Point{X: round(p.pts[i][0]), Y: round(p.pts[i][1])})
+		types = append(types, t)
+	}
+	return pts, types
+}
+
+// Bounds returns the rectangle containing all points and control points.
+func (p *Path) Bounds() image.Rectangle {
+	pts, _ := p.Points()
+	if len(pts) == 0 {
+		return image.Rectangle{}
+	}
+	r := image.Rectangle{Min: pts[0], Max: pts[0]}
+	for _, pt := range pts[1:] {
+		r.Min.X, r.Min.Y = min(r.Min.X, pt.X), min(r.Min.Y, pt.Y)
+		r.Max.X, r.Max.Y = max(r.Max.X, pt.X), max(r.Max.Y, pt.Y)
+	}
+	r.Max = r.Max.Add(image.Point{1, 1})
+	return r
+}
+
+func round(f float64) int {
+	return int(math.Floor(f + 0.5))
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,191 @@
+package gdi
+
+import (
+	"image"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func pt(x, y int) image.Point {
+	return image.Point{X: x, Y: y}
+}
+
+func TestPathPoints(t *testing.T) {
+	tests := []struct {
+		name  string
+		p     *Path
+		pts   []image.Point
+		types []byte
+	}{
+		{"empty", new(Path), []image.Point{}, []byte{}},
+		{"move only", new(Path).MoveTo(1, 1), []image.Point{}, []byte{}},
+		{
+			"polyline",
+			new(Path).MoveTo(1, 2).LineTo(3, 4).LineTo(5, 6),
+			[]image.Point{pt(1, 2), pt(3, 4), pt(5, 6)},
+			[]byte{PT_MOVETO, PT_LINETO, PT_LINETO},
+		},
+		{
+			"closed",
+			new(Path).MoveTo(0, 0).LineTo(10, 0).LineTo(10, 10).Close(),
+			[]image.Point{pt(0, 0), pt(10, 0), pt(10, 10)},
+			[]byte{PT_MOVETO, PT_LINETO, PT_LINETO | PT_CLOSEFIGURE},
+		},
+		{
+			"implicit move",
+			new(Path).LineTo(5, 5).LineTo(6, 7),
+			[]image.Point{pt(5, 5), pt(6, 7)},
+			[]byte{PT_MOVETO, PT_LINETO},
+		},
+		{
+			"repeated move",
+			new(Path).MoveTo(0, 0).MoveTo(1, 1).LineTo(2, 2),
+			[]image.Point{pt(1, 1), pt(2, 2)},
+			[]byte{PT_MOVETO, PT_LINETO},
+		},
+		{
+			"trailing move",
+			new(Path).MoveTo(0, 0).LineTo(1, 1).MoveTo(5, 5),
+			[]image.Point{pt(0, 0), pt(1, 1)},
+			[]byte{PT_MOVETO, PT_LINETO},
+		},
+		{
+			"segment after close",
+			new(Path).MoveTo(0, 0).LineTo(10, 0).LineTo(10, 10).Close().LineTo(0, 10),
+			[]image.Point{pt(0, 0), pt(10, 0), pt(10, 10), pt(0, 0), pt(0, 10)},
+			[]byte{PT_MOVETO, PT_LINETO, PT_LINETO | PT_CLOSEFIGURE, PT_MOVETO, PT_LINETO},
+		},
+		{
+			"close without segments",
+			new(Path).MoveTo(3, 3).Close().LineTo(4, 4),
+			[]image.Point{pt(3, 3), pt(4, 4)},
+			[]byte{PT_MOVETO, PT_LINETO},
+		},
+		{
+			"cubic",
+			new(Path).MoveTo(0, 0).CubicTo(1, 2, 3, 4, 5, 6),
+			[]image.Point{pt(0, 0), pt(1, 2), pt(3, 4), pt(5, 6)},
+			[]byte{PT_MOVETO, PT_BEZIERTO, PT_BEZIERTO, PT_BEZIERTO},
+		},
+		{
+			"quadratic",
+			new(Path).MoveTo(0, 0).QuadTo(3, 3, 6, 0),
+			[]image.Point{pt(0, 0), pt(2, 2), pt(4, 2), pt(6, 0)},
+			[]byte{PT_MOVETO, PT_BEZIERTO, PT_BEZIERTO, PT_BEZIERTO},
+		},
+		{
+			"rounding",
+			new(Path).MoveTo(0.5, -0.5).LineTo(1.49, -1.5).LineTo(2.51, -2.51),
+			[]image.Point{pt(1, 0), pt(1, -1), pt(3, -3)},
+			[]byte{PT_MOVETO, PT_LINETO, PT_LINETO},
+		},
+		{
+			"rect",
+			new(Path).Rect(image.Rect(1, 2, 3, 4)),
+			[]image.Point{pt(1, 2), pt(3, 2), pt(3, 4), pt(1, 4)},
+			[]byte{PT_MOVETO, PT_LINETO, PT_LINETO, PT_LINETO | PT_CLOSEFIGURE},
+		},
+		{
+			"two rects",
+			new(Path).Rect(image.Rect(0, 0, 1, 1)).Rect(image.Rect(5, 5, 6, 6)),
+			[]image.Point{pt(0, 0), pt(1, 0), pt(1, 1), pt(0, 1), pt(5, 5), pt(6, 5), pt(6, 6), pt(5, 6)},
+			[]byte{
+				PT_MOVETO, PT_LINETO, PT_LINETO, PT_LINETO | PT_CLOSEFIGURE,
+				PT_MOVETO, PT_LINETO, PT_LINETO, PT_LINETO | PT_CLOSEFIGURE,
+			},
+		},
+		{
+			"zero sweep arc",
+			new(Path).Arc(0, 0, 10, 10, 0, 0).LineTo(0, 10),
+			[]image.Point{pt(10, 0), pt(0, 10)},
+			[]byte{PT_MOVETO, PT_LINETO},
+		},
+	}
+	for _, tt := range tests {
+		pts, types := tt.p.Points()
+		if !reflect.DeepEqual(pts, tt.pts) || !reflect.DeepEqual(types, tt.types) {
+			t.Errorf("%s: Points = %v, %v, want %v, %v", tt.name, pts, types, tt.pts, tt.types)
+		}
+		if empty := len(tt.pts) == 0; tt.p.Empty() != empty {
+			t.Errorf("%s: Empty = %v", tt.name, !empty)
+		}
+	}
+}
+
+func TestPathArc(t *testing.T) {
+	// a quarter turn from 3 o'clock clockwise on screen ends at 6 o'clock
+	pts, types := new(Path).MoveTo(0, 0).Arc(0, 0, 100, 100, 0, math.Pi/2).Points()
+	wantTypes := []byte{PT_MOVETO, PT_LINETO, PT_BEZIERTO, PT_BEZIERTO, PT_BEZIERTO}
+	if !reflect.DeepEqual(types, wantTypes) {
+		t.Fatalf("types %v, want %v", types, wantTypes)
+	}
+	// k = 4/3 tan(pi/8) puts the control points 55.23 along the tangents
+	wantPts := []image.Point{pt(0, 0), pt(100, 0), pt(100, 55), pt(55, 100), pt(0, 100)}
+	if !reflect.DeepEqual(pts, wantPts) {
+		t.Errorf("points %v, want %v", pts, wantPts)
+	}
+
+	// an arc continuing at the current point adds no line
+	_, types = new(Path).MoveTo(100, 0).Arc(0, 0, 100, 100, 0, -math.Pi/2).Points()
+	if !reflect.DeepEqual(types, []byte{PT_MOVETO, PT_BEZIERTO, PT_BEZIERTO, PT_BEZIERTO}) {
+		t.Errorf("continued arc types %v", types)
+	}
+	pts, _ = new(Path).Arc(0, 0, 100, 100, 0, -math.Pi/2).Points()
+	if pts[len(pts)-1] != pt(0, -100) {
+		t.Errorf("counterclockwise arc ends at %v", pts[len(pts)-1])
+	}
+}
+
+func TestPathEllipse(t *testing.T) {
+	const rx, ry = 1000, 500
+	p := new(Path).Ellipse(0, 0, rx, ry)
+	pts, types := p.Points()
+	if len(pts) != 13 {
+		t.Fatalf("%d points, want a move and four curves", len(pts))
+	}
+	if types[0] != PT_MOVETO || types[12] != PT_BEZIERTO|PT_CLOSEFIGURE {
+		t.Errorf("types %v", types)
+	}
+	for i, want := range []image.Point{pt(rx, 0), pt(0, ry), pt(-rx, 0), pt(0, -ry), pt(rx, 0)} {
+		if pts[3*i] != want {
+			t.Errorf("point %d = %v, want %v", 3*i, pts[3*i], want)
+		}
+	}
+
+	// the curves stay within 0.03% of the ellipse
+	for i := 0; i < 4; i++ {
+		c := p.pts[1+3*i-1 : 1+3*i+3]
+		for s := 0.0; s <= 1; s += 1.0 / 64 {
+			u := 1 - s
+			x := u*u*u*c[0][0] + 3*u*u*s*c[1][0] + 3*u*s*s*c[2][0] + s*s*s*c[3][0]
+			y := u*u*u*c[0][1] + 3*u*u*s*c[1][1] + 3*u*s*s*c[2][1] + s*s*s*c[3][1]
+			if e := math.Abs(math.Hypot(x/rx, y/ry) - 1); e > 0.0003 {
+				t.Fatalf("curve %d at %.3f is off by %.5f", i, s, e)
+			}
+		}
+	}
+
+	if b, want := p.Bounds(), image.Rect(-rx, -ry, rx+1, ry+1); b != want {
+		t.Errorf("Bounds = %v, want %v", b, want)
+	}
+}
+
+func TestPathBounds(t *testing.T) {
+	tests := []struct {
+		p    *Path
+		want image.Rectangle
+	}{
+		{new(Path), image.Rectangle{}},
+		{new(Path).MoveTo(5, 5), image.Rectangle{}},
+		{new(Path).MoveTo(2, 3).LineTo(2, 3), image.Rect(2, 3, 3, 4)},
+		{new(Path).Rect(image.Rect(1, 2, 3, 4)), image.Rect(1, 2, 4, 5)},
+		// control points count
+		{new(Path).MoveTo(0, 0).CubicTo(-5, 20, 5, 20, 10, 0), image.Rect(-5, 0, 11, 21)},
+	}
+	for _, tt := range tests {
+		if got := tt.p.Bounds(); got != tt.want {
+			t.Errorf("Bounds = %v, want %v", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,401 @@
+package gdi
+
+import (
+	"fmt"
+	"image"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Call is a Backend method invoked on a Recorder.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+func (c Call) String() string {
+	args := make([]string, len(c.Args))
+	for i, a := range c.Args {
+		args[i] = fmt.Sprint(a)
+	}
+	return c.Method + "(" + strings.Join(args, ", ") + ")"
+}
+
+// objectKind tells which selection slot an object occupies.
+type objectKind int
+
+const (
+	kindPen objectKind = iota
+	kindBrush
+	kindFont
+	kindRegion
+)
+
+// recorderState is what Save and Restore push and pop.
+type recorderState struct {
+	selected [3]Handle
+	inPath   bool
+	path     bool
+}
+
+// Recorder is a Backend that logs every call instead of drawing. It
+// checks what GDI would reject: selecting or deleting unknown objects,
+// deleting selected objects and restoring unsaved states.
+type Recorder struct {
+	Calls []Call
+
+	// CharWidth and LineHeight are the metrics DrawText measures with,
+	// every character has the same width.
+	CharWidth  int
+	LineHeight int
+
+	next    Handle
+	objects map[Handle]objectKind
+	state   recorderState
+	saved   []recorderState
+}
+
+// Discard is a Backend that draws nothing and records nothing.
+var Discard Backend = discard{}
+
+// NewRecorder returns an empty Recorder. Its stock objects, the ones
+// selected initially, are handles 1 to 3.
+func NewRecorder() *Recorder {
+	r := &Recorder{objects: map[Handle]objectKind{}, CharWidth: 8, LineHeight: 16}
+	for k := kindPen; k <= kindFont; k++ {
+		r.state.selected[k] = r.create(k)
+	}
+	return r
+}
+
+// Strings returns the recorded calls in their String form.
+func (r *Recorder) Strings() []string {
+	s := make([]string, len(r.Calls))
+	for i, c := range r.Calls {
+		s[i] = c.String()
+	}
+	return s
+}
+
+// Live returns the objects created and not deleted, not counting the
+// stock objects.
+func (r *Recorder) Live() []Handle {
+	var live []Handle
+	for h := range r.objects {
+		if h > 3 {
+			live = append(live, h)
+		}
+	}
+	sort.Slice(live, func(i, j int) bool { return live[i] < live[j] })
+	return live
+}
+
+// Reset forgets the recorded calls.
+func (r *Recorder) Reset() {
+	r.Calls = nil
+}
+
+func (r *Recorder) record(method string, args ...interface{}) {
+	r.Calls = append(r.Calls, Call{Method: method, Args: args})
+}
+
+func (r *Recorder) create(k objectKind) Handle {
+	r.next++
+	r.objects[r.next] = k
+	return r.next
+}
+
+func (r *Recorder) CreatePen(p Pen) (Handle, error) {
+	h := r.create(kindPen)
+	r.record("CreatePen", p, h)
+	return h, nil
+}
+
+func (r *Recorder) CreateBrush(b Brush) (Handle, error) {
+	h := r.create(kindBrush)
+	r.record("CreateBrush", b, h)
+	return h, nil
+}
+
+func (r *Recorder) CreateFont(f Font) (Handle, error) {
+	h := r.create(kindFont)
+	r.record("CreateFont", f, h)
+	return h, nil
+}
+
+func (r *Recorder) Select(h Handle) (Handle, error) {
+	r.record("Select", h)
+	k, ok := r.objects[h]
+	if !ok {
+		return 0, errors.Errorf("gdi: select of unknown object %d", h)
+	}
+	if k == kindRegion {
+		return 0, errors.Errorf("gdi: select of region %d", h)
+	}
+	old := r.state.selected[k]
+	r.state.selected[k] = h
+	return old, nil
+}
+
+// selected reports whether h is selected now or in a saved state, where
+// RestoreDC would select it again.
+func (r *Recorder) selected(h Handle) bool {
+	for _, s := range append([]recorderState{r.state}, r.saved...) {
+		for _, sel := range s.selected {
+			if sel == h {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *Recorder) Delete(h Handle) error {
+	r.record("Delete", h)
+	if _, ok := r.objects[h]; !ok {
+		return errors.Errorf("gdi: delete of unknown object %d", h)
+	}
+	if r.selected(h) {
+		return errors.Errorf("gdi: delete of selected object %d", h)
+	}
+	delete(r.objects, h)
+	return nil
+}
+
+func (r *Recorder) Save() (int, error) {
+	r.saved = append(r.saved, r.state)
+	r.record("Save", len(r.saved))
+	return len(r.saved), nil
+}
+
+func (r *Recorder) Restore(level int) error {
+	r.record("Restore", level)
+	if level < 0 {
+		level += len(r.saved) + 1
+	}
+	if level < 1 || level > len(r.saved) {
+		return errors.Errorf("gdi: restore of unsaved level %d", level)
+	}
+	r.state = r.saved[level-1]
+	r.saved = r.saved[:level-1]
+	return nil
+}
+
+func (r *Recorder) Polyline(pts []image.Point) error {
+	r.record("Polyline", append([]image.Point(nil), pts...))
+	return checkPoints("Polyline", len(pts))
+}
+
+func (r *Recorder) Polygon(pts []image.Point) error {
+	r.record("Polygon", append([]image.Point(nil), pts...))
+	return checkPoints("Polygon", len(pts))
+}
+
+func (r *Recorder) PolyBezier(pts []image.Point) error {
+	r.record("PolyBezier", append([]image.Point(nil), pts...))
+	return checkPoints("PolyBezier", len(pts))
+}
+
+func (r *Recorder) Rectangle(rect image.Rectangle) error {
+	r.record("Rectangle", rect)
+	return nil
+}
+
+func (r *Recorder) RoundRect(rect image.Rectangle, corner image.Point) error {
+	r.record("RoundRect", rect, corner)
+	return nil
+}
+
+func (r *Recorder) Ellipse(rect image.Rectangle) error {
+	r.record("Ellipse", rect)
+	return nil
+}
+
+func (r *Recorder) Arc(rect image.Rectangle, start, end image.Point) error {
+	r.record("Arc", rect, start, end)
+	return nil
+}
+
+func (r *Recorder) FillRect(rect image.Rectangle, brush Handle) error {
+	r.record("FillRect", rect, brush)
+	if r.objects[brush] != kindBrush {
+		return errors.Errorf("gdi: %d is not a brush", brush)
+	}
+	return nil
+}
+
+func (r *Recorder) PolyDraw(pts []image.Point, types []byte) error {
+	r.record("PolyDraw", append([]image.Point(nil), pts...), append([]byte(nil), types...))
+	if len(pts) != len(types) {
+		return errors.Errorf("gdi: %d points with %d types", len(pts), len(types))
+	}
+	return nil
+}
+
+func (r *Recorder) SetPolyFillMode(mode int) error {
+	r.record("SetPolyFillMode", mode)
+	return nil
+}
+
+func (r *Recorder) BeginPath() error {
+	r.record("BeginPath")
+	r.state.inPath, r.state.path = true, false
+	return nil
+}
+
+func (r *Recorder) EndPath() error {
+	r.record("EndPath")
+	if !r.state.inPath {
+		return errors.New("gdi: EndPath without BeginPath")
+	}
+	r.state.inPath, r.state.path = false, true
+	return nil
+}
+
+func (r *Recorder) AbortPath() error {
+	r.record("AbortPath")
+	r.state.inPath, r.state.path = false, false
+	return nil
+}
+
+// usePath consumes the path like the functions rendering it do.
+func (r *Recorder) usePath(method string) error {
+	r.record(method)
+	if !r.state.path {
+		return errors.Errorf("gdi: %s without a path", method)
+	}
+	r.state.path = false
+	return nil
+}
+
+func (r *Recorder) StrokePath() error        { return r.usePath("StrokePath") }
+func (r *Recorder) FillPath() error          { return r.usePath("FillPath") }
+func (r *Recorder) StrokeAndFillPath() error { return r.usePath("StrokeAndFillPath") }
+
+func (r *Recorder) PathToRegion() (Handle, error) {
+	if err := r.usePath("PathToRegion"); err != nil {
+		return 0, err
+	}
+	return r.create(kindRegion), nil
+}
+
+func (r *Recorder) SetTextColor(c Color) error {
+	r.record("SetTextColor", c)
+	return nil
+}
+
+func (r *Recorder) SetBkColor(c Color, transparent bool) error {
+	r.record("SetBkColor", c, transparent)
+	return nil
+}
+
+func (r *Recorder) SetTextAlign(a Align) error {
+	r.record("SetTextAlign", a)
+	return nil
+}
+
+func (r *Recorder) TextOut(p image.Point, s string) error {
+	r.record("TextOut", p, s)
+	return nil
+}
+
+func (r *Recorder) DrawText(s string, rect image.Rectangle, flags uint32) (image.Rectangle, error) {
+	r.record("DrawText", s, rect, flags)
+	width := 0
+	if flags&DT_WORDBREAK != 0 && flags&DT_SINGLELINE == 0 {
+		width = rect.Dx()
+	}
+	lines := r.layout(s, flags&DT_SINGLELINE != 0, width)
+	size := image.Point{Y: len(lines) * r.LineHeight}
+	for _, l := range lines {
+		size.X = max(size.X, len([]rune(l))*r.CharWidth)
+	}
+	if flags&DT_CALCRECT != 0 {
+		return image.Rectangle{Min: rect.Min, Max: rect.Min.Add(size)}, nil
+	}
+	return image.Rect(rect.Min.X, rect.Min.Y, rect.Max.X, rect.Min.Y+size.Y), nil
+}
+
+// layout splits s into lines, breaking between words at width when it is
+// positive.
+func (r *Recorder) layout(s string, single bool, width int) []string {
+	if single {
+		return []string{strings.NewReplacer("\r\n", " ", "\n", " ").Replace(s)}
+	}
+	var lines []string
+	for _, para := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			next := word
+			if line != "" {
+				next = line + " " + word
+			}
+			if width > 0 && line != "" && len([]rune(next))*r.CharWidth > width {
+				lines = append(lines, line)
+				next = word
+			}
+			line = next
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (r *Recorder) DPI() int {
+	return DefaultDPI
+}
+
+func (r *Recorder) IntersectClip(rect image.Rectangle) error {
+	r.record("IntersectClip", rect)
+	return nil
+}
+
+func (r *Recorder) ExcludeClip(rect image.Rectangle) error {
+	r.record("ExcludeClip", rect)
+	return nil
+}
+
+func (r *Recorder) ResetClip() error {
+	r.record("ResetClip")
+	return nil
+}
+
+type discard struct{}
+
+func (discard) CreatePen(Pen) (Handle, error)                       { return 1, nil }
+func (discard) CreateBrush(Brush) (Handle, error)                   { return 1, nil }
+func (discard) CreateFont(Font) (Handle, error)                     { return 1, nil }
+func (discard) Select(Handle) (Handle, error)                       { return 1, nil }
+func (discard) Delete(Handle) error                                 { return nil }
+func (discard) Save() (int, error)                                  { return 1, nil }
+func (discard) Restore(int) error                                   { return nil }
+func (discard) Polyline([]image.Point) error                        { return nil }
+func (discard) Polygon([]image.Point) error                         { return nil }
+func (discard) PolyBezier([]image.Point) error                      { return nil }
+func (discard) Rectangle(image.Rectangle) error                     { return nil }
+func (discard) RoundRect(image.Rectangle, image.Point) error        { return nil }
+func (discard) Ellipse(image.Rectangle) error                       { return nil }
+func (discard) Arc(image.Rectangle, image.Point, image.Point) error { return nil }
+func (discard) FillRect(image.Rectangle, Handle) error              { return nil }
+func (discard) PolyDraw([]image.Point, []byte) error                { return nil }
+func (discard) SetPolyFillMode(int) error                           { return nil }
+func (discard) BeginPath() error                                    { return nil }
+func (discard) EndPath() error                                      { return nil }
+func (discard) AbortPath() error                                    { return nil }
+func (discard) StrokePath() error                                   { return nil }
+func (discard) FillPath() error                                     { return nil }
+func (discard) StrokeAndFillPath() error                            { return nil }
+func (discard) PathToRegion() (Handle, error)                       { return 1, nil }
+func (discard) SetTextColor(Color) error                            { return nil }
+func (discard) SetBkColor(Color, bool) error                        { return nil }
+func (discard) SetTextAlign(Align) error                            { return nil }
+func (discard) TextOut(image.Point, string) error                   { return nil }
+func (discard) DrawText(string, image.Rectangle, uint32) (image.Rectangle, error) {
+	return image.Rectangle{}, nil
+}
+func (discard) DPI() int                            { return DefaultDPI }
+func (discard) IntersectClip(image.Rectangle) error { return nil }
+func (discard) ExcludeClip(image.Rectangle) error   { return nil }
+func (discard) ResetClip() error                    { return nil }
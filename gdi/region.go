@@ -0,0 +1,458 @@
+package gdi
+
+import (
+	"encoding/binary"
+	"image"
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// span is the half open range [x0, x1) of a band.
+type span struct {
+	x0, x1 int
+}
+
+// band is the rows [y0, y1) covering the same spans.
+type band struct {
+	y0, y1 int
+	spans  []span
+}
+
+// Region is an area of pixels in the y-x banded form GDI uses: horizontal
+// bands of equal height, each holding sorted, disjoint spans. Adjacent
+// bands with the same spans are merged, so equal areas have equal
+// representations. The zero value is the empty region. Regions are
+// immutable; the operations return new ones.
+type Region struct {
+	bands []band
+}
+
+// RectRegion returns the region covering r.
+func RectRegion(r image.Rectangle) Region {
+	r = r.Canon()
+	if r.Empty() {
+		return Region{}
+	}
+	return Region{bands: []band{{y0: r.Min.Y, y1: r.Max.Y, spans: []span{{r.Min.X, r.Max.X}}}}}
+}
+
+// RectsRegion returns the union of rects.
+func RectsRegion(rects []image.Rectangle) Region {
+	var ys []int
+	for _, r := range rects {
+		if r = r.Canon(); !r.Empty() {
+			ys = append(ys, r.Min.Y, r.Max.Y)
+		}
+	}
+	ys = uniqueInts(ys)
+
+	var b builder
+	for i := 0; i+1 < len(ys); i++ {
+		y0, y1 := ys[i], ys[i+1]
+		var spans []span
+		for _, r := range rects {
+			if r = r.Canon(); !r.Empty() && r.Min.Y <= y0 && y1 <= r.Max.Y {
+				spans = append(spans, span{r.Min.X, r.Max.X})
+			}
+		}
+		b.add(y0, y1, mergeSpans(spans))
+	}
+	return b.region()
+}
+
+// EllipseRegion returns the pixels whose centers lie inside the ellipse
+// bounded by r.
+func EllipseRegion(r image.Rectangle) Region {
+	r = r.Canon()
+	cx, cy := float64(r.Min.X+r.Max.X)/2, float64(r.Min.Y+r.Max.Y)/2
+	rx, ry := float64(r.Dx())/2, float64(r.Dy())/2
+	var b builder
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		dy := (float64(y) + 0.5 - cy) / ry
+		if dy*dy >= 1 {
+			continue
+		}
+		half := rx * math.Sqrt(1-dy*dy)
+		b.add(y, y+1, mergeSpans([]span{{pixelEdge(cx - half), pixelEdge(cx + half)}}))
+	}
+	return b.region()
+}
+
+// PolygonRegion returns the pixels whose centers lie inside the polygon
+// through pts, closed from the last point back to the first. Winding
+// selects the nonzero rule over the alternate (even-odd) rule.
+func PolygonRegion(pts []image.Point, winding bool) Region {
+	if len(pts) < 3 {
+		return Region{}
+	}
+	minY, maxY := pts[0].Y, pts[0].Y
+	for _, p := range pts {
+		minY, maxY = min(minY, p.Y), max(maxY, p.Y)
+	}
+
+	type crossing struct {
+		x   float64
+		dir int
+	}
+	var b builder
+	var xs []crossing
+	for y := minY; y < maxY; y++ {
+		yc := float64(y) + 0.5
+		xs = xs[:0]
+		for i, p := range pts {
+			q := pts[(i+1)%len(pts)]
+			if p.Y == q.Y {
+				continue
+			}
+			dir := 1
+			lo, hi := p, q
+			if p.Y > q.Y {
+				dir, lo, hi = -1, q, p
+			}
+			if yc < float64(lo.Y) || yc >= float64(hi.Y) {
+				continue
+			}
+			x := float64(lo.X) + (yc-float64(lo.Y))*float64(hi.X-lo.X)/float64(hi.Y-lo.Y)
+			xs = append(xs, crossing{x, dir})
+		}
+		sort.Slice(xs, func(i, j int) bool { return xs[i].x < xs[j].x })
+
+		var spans []span
+		wind := 0
+		for i, c := range xs {
+			before := wind
+			if winding {
+				wind += c.dir
+			} else {
+				wind ^= 1
+			}
+			if before == 0 && wind != 0 && i+1 < len(xs) {
+				spans = append(spans, span{x0: pixelEdge(c.x)})
+			} else if before != 0 && wind == 0 && len(spans) > 0 {
+				spans[len(spans)-1].x1 = pixelEdge(c.x)
+			}
+		}
+		b.add(y, y+1, mergeSpans(spans))
+	}
+	return b.region()
+}
+
+// AlphaRegion returns the pixels of img with an alpha of at least threshold,
+// for shaping windows after an image.
+func AlphaRegion(img image.Image, threshold uint8) Region {
+	bounds := img.Bounds()
+	alpha := func(x, y int) uint8 {
+		_, _, _, a := img.At(x, y).RGBA()
+		return uint8(a >> 8)
+	}
+	switch img := img.(type) {
+	case *image.NRGBA:
+		alpha = func(x, y int) uint8 { return img.Pix[img.PixOffset(x, y)+3] }
+	case *image.RGBA:
+		alpha = func(x, y int) uint8 { return img.Pix[img.PixOffset(x, y)+3] }
+	case *image.Alpha:
+		alpha = func(x, y int) uint8 { return img.Pix[img.PixOffset(x, y)] }
+	}
+
+	var b builder
+	var spans []span
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		spans = spans[:0]
+		in := false
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if on := alpha(x, y) >= threshold; on && !in {
+				spans = append(spans, span{x0: x})
+				in = true
+			} else if !on && in {
+				spans[len(spans)-1].x1 = x
+				in = false
+			}
+		}
+		if in {
+			spans[len(spans)-1].x1 = bounds.Max.X
+		}
+		b.add(y, y+1, append([]span(nil), spans...))
+	}
+	return b.region()
+}
+
+// pixelEdge returns the first pixel whose center lies at or right of x.
+func pixelEdge(x float64) int {
+	return int(math.Ceil(x - 0.5))
+}
+
+// Empty reports whether r covers no pixels.
+func (r Region) Empty() bool {
+	return len(r.bands) == 0
+}
+
+// Bounds returns the smallest rectangle containing r.
+func (r Region) Bounds() image.Rectangle {
+	if r.Empty() {
+		return image.Rectangle{}
+	}
+	b := image.Rect(r.bands[0].spans[0].x0, r.bands[0].y0, r.bands[0].spans[0].x1, r.bands[len(r.bands)-1].y1)
+	for _, bd := range r.bands {
+		b.Min.X = min(b.Min.X, bd.spans[0].x0)
+		b.Max.X = max(b.Max.X, bd.spans[len(bd.spans)-1].x1)
+	}
+	return b
+}
+
+// Rects returns the rectangles of r in y-x banded order.
+func (r Region) Rects() []image.Rectangle {
+	var rects []image.Rectangle
+	for _, b := range r.bands {
+		for _, s := range b.spans {
+			rects = append(rects, image.Rect(s.x0, b.y0, s.x1, b.y1))
+		}
+	}
+	return rects
+}
+
+// Contains reports whether the pixel p is in r.
+func (r Region) Contains(p image.Point) bool {
+	i := sort.Search(len(r.bands), func(i int) bool { return r.bands[i].y1 > p.Y })
+	if i == len(r.bands) || r.bands[i].y0 > p.Y {
+		return false
+	}
+	spans := r.bands[i].spans
+	j := sort.Search(len(spans), func(j int) bool { return spans[j].x1 > p.X })
+	return j < len(spans) && spans[j].x0 <= p.X
+}
+
+// Equal reports whether r and o cover the same pixels.
+func (r Region) Equal(o Region) bool {
+	if len(r.bands) != len(o.bands) {
+		return false
+	}
+	for i, b := range r.bands {
+		c := o.bands[i]
+		if b.y0 != c.y0 || b.y1 != c.y1 || !equalSpans(b.spans, c.spans) {
+			return false
+		}
+	}
+	return true
+}
+
+// Translate returns r moved by d.
+func (r Region) Translate(d image.Point) Region {
+	out := Region{bands: make([]band, len(r.bands))}
+	for i, b := range r.bands {
+		spans := make([]span, len(b.spans))
+		for j, s := range b.spans {
+			spans[j] = span{s.x0 + d.X, s.x1 + d.X}
+		}
+		out.bands[i] = band{y0: b.y0 + d.Y, y1: b.y1 + d.Y, spans: spans}
+	}
+	return out
+}
+
+// Union returns the pixels in r or o, like CombineRgn with RGN_OR.
+func (r Region) Union(o Region) Region {
+	return combine(r, o, func(a, b bool) bool { return a || b })
+}
+
+// Intersect returns the pixels in both r and o, like RGN_AND.
+func (r Region) Intersect(o Region) Region {
+	return combine(r, o, func(a, b bool) bool { return a && b })
+}
+
+// Subtract returns the pixels in r but not in o, like RGN_DIFF.
+func (r Region) Subtract(o Region) Region {
+	return combine(r, o, func(a, b bool) bool { return a && !b })
+}
+
+// Xor returns the pixels in exactly one of r and o, like RGN_XOR.
+func (r Region) Xor(o Region) Region {
+	return combine(r, o, func(a, b bool) bool { return a != b })
+}
+
+// combine sweeps over the band edges of both regions and combines the
+// spans of each strip between two consecutive edges.
+func combine(r, o Region, op func(a, b bool) bool) Region {
+	var ys []int
+	for _, b := range r.bands {
+		ys = append(ys, b.y0, b.y1)
+	}
+	for _, b := range o.bands {
+		ys = append(ys, b.y0, b.y1)
+	}
+	ys = uniqueInts(ys)
+
+	var out builder
+	i, j := 0, 0
+	for k := 0; k+1 < len(ys); k++ {
+		y0, y1 := ys[k], ys[k+1]
+		for i < len(r.bands) && r.bands[i].y1 <= y0 {
+			i++
+		}
+		for j < len(o.bands) && o.bands[j].y1 <= y0 {
+			j++
+		}
+		var a, b []span
+		if i < len(r.bands) && r.bands[i].y0 <= y0 {
+			a = r.bands[i].spans
+		}
+		if j < len(o.bands) && o.bands[j].y0 <= y0 {
+			b = o.bands[j].spans
+		}
+		out.add(y0, y1, combineSpans(a, b, op))
+	}
+	return out.region()
+}
+
+// combineSpans applies op to every x range where membership in a and b is constant.
+func combineSpans(a, b []span, op func(a, b bool) bool) []span {
+	var xs []int
+	for _, s := range a {
+		xs = append(xs, s.x0, s.x1)
+	}
+	for _, s := range b {
+		xs = append(xs, s.x0, s.x1)
+	}
+	xs = uniqueInts(xs)
+
+	var out []span
+	i, j := 0, 0
+	for k := 0; k+1 < len(xs); k++ {
+		x0, x1 := xs[k], xs[k+1]
+		for i < len(a) && a[i].x1 <= x0 {
+			i++
+		}
+		for j < len(b) && b[j].x1 <= x0 {
+			j++
+		}
+		inA := i < len(a) && a[i].x0 <= x0
+		inB := j < len(b) && b[j].x0 <= x0
+		if !op(inA, inB) {
+			continue
+		}
+		if n := len(out); n > 0 && out[n-1].x1 == x0 {
+			out[n-1].x1 = x1
+		} else {
+			out = append(out, span{x0, x1})
+		}
+	}
+	return out
+}
+
+// mergeSpans sorts spans and joins the overlapping and touching ones.
+func mergeSpans(spans []span) []span {
+	sort.Slice(spans, func(i, j int) bool { return spans[i].x0 < spans[j].x0 })
+	var out []span
+	for _, s := range spans {
+		if s.x1 <= s.x0 {
+			continue
+		}
+		if n := len(out); n > 0 && s.x0 <= out[n-1].x1 {
+			out[n-1].x1 = max(out[n-1].x1, s.x1)
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func equalSpans(a, b []span) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func uniqueInts(v []int) []int {
+	sort.Ints(v)
+	out := v[:0]
+	for i, x := range v {
+		if i == 0 || x != v[i-1] {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+// builder appends bands from top to bottom, dropping empty ones and
+// merging those that continue the previous band.
+type builder struct {
+	bands []band
+}
+
+func (b *builder) add(y0, y1 int, spans []span) {
+	if len(spans) == 0 || y1 <= y0 {
+		return
+	}
+	if n := len(b.bands); n > 0 && b.bands[n-1].y1 == y0 && equalSpans(b.bands[n-1].spans, spans) {
+		b.bands[n-1].y1 = y1
+		return
+	}
+	b.bands = append(b.bands, band{y0: y0, y1: y1, spans: spans})
+}
+
+func (b *builder) region() Region {
+	return Region{bands: b.bands}
+}
+
+// RGNDATAHEADER
+const (
+	rgnDataHeaderSize = 32
+	RDH_RECTANGLES    = 1
+)
+
+// RegionData encodes r as an RGNDATA structure for ExtCreateRegion.
+func (r Region) RegionData() []byte {
+	rects := r.Rects()
+	b := make([]byte, rgnDataHeaderSize+len(rects)*16)
+	le := binary.LittleEndian
+	le.PutUint32(b, rgnDataHeaderSize)
+	le.PutUint32(b[4:], RDH_RECTANGLES)
+	le.PutUint32(b[8:], uint32(len(rects)))
+	le.PutUint32(b[12:], uint32(len(rects)*16))
+	putRect(b[16:], r.Bounds())
+	for i, rc := range rects {
+		putRect(b[rgnDataHeaderSize+i*16:], rc)
+	}
+	return b
+}
+
+// ParseRegionData decodes an RGNDATA structure as returned by GetRegionData.
+func ParseRegionData(b []byte) (Region, error) {
+	le := binary.LittleEndian
+	if len(b) < rgnDataHeaderSize || le.Uint32(b) != rgnDataHeaderSize {
+		return Region{}, errors.New("gdi: invalid RGNDATAHEADER")
+	}
+	if t := le.Uint32(b[4:]); t != RDH_RECTANGLES {
+		return Region{}, errors.Errorf("gdi: RGNDATA type %d", t)
+	}
+	n := int(le.Uint32(b[8:]))
+	if n < 0 || n > (len(b)-rgnDataHeaderSize)/16 {
+		return Region{}, errors.Errorf("gdi: RGNDATA of %d bytes holds %d rectangles", len(b), n)
+	}
+	rects := make([]image.Rectangle, n)
+	for i := range rects {
+		rects[i] = getRect(b[rgnDataHeaderSize+i*16:])
+	}
+	return RectsRegion(rects), nil
+}
+
+func putRect(b []byte, r image.Rectangle) {
+	le := binary.LittleEndian
+	le.PutUint32(b, uint32(int32(r.Min.X)))
+	le.PutUint32(b[4:], uint32(int32(r.Min.Y)))
+	le.PutUint32(b[8:], uint32(int32(r.Max.X)))
+	le.PutUint32(b[12:], uint32(int32(r.Max.Y)))
+}
+
+func getRect(b []byte) image.Rectangle {
+	le := binary.LittleEndian
+	return image.Rect(
+		int(int32(le.Uint32(b))), int(int32(le.Uint32(b[4:]))),
+		int(int32(le.Uint32(b[8:]))), int(int32(le.Uint32(b[12:]))))
+}
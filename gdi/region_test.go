@@ -0,0 +1,345 @@
+package gdi
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// pixels is the reference model of a region on a small grid.
+type pixels map[image.Point]bool
+
+const gridSize = 12
+
+func regionPixels(r Region) pixels {
+	p := pixels{}
+	for y := -2; y < gridSize+2; y++ {
+		for x := -2; x < gridSize+2; x++ {
+			if r.Contains(image.Pt(x, y)) {
+				p[image.Pt(x, y)] = true
+			}
+		}
+	}
+	return p
+}
+
+func rectsPixels(rects []image.Rectangle) pixels {
+	p := pixels{}
+	for _, r := range rects {
+		r = r.Canon()
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				p[image.Pt(x, y)] = true
+			}
+		}
+	}
+	return p
+}
+
+func randomRects(rnd *rand.Rand) []image.Rectangle {
+	rects := make([]image.Rectangle, rnd.Intn(5))
+	for i := range rects {
+		// some rectangles are empty or not canonical
+		rects[i] = image.Rectangle{
+			Min: image.Pt(rnd.Intn(gridSize), rnd.Intn(gridSize)),
+			Max: image.Pt(rnd.Intn(gridSize), rnd.Intn(gridSize)),
+		}
+	}
+	return rects
+}
+
+// checkCanonical verifies the banding invariants Equal relies on.
+func checkCanonical(t *testing.T, r Region) {
+	t.Helper()
+	for i, b := range r.bands {
+		if b.y1 <= b.y0 || len(b.spans) == 0 {
+			t.Fatalf("band %d is empty: %+v", i, b)
+		}
+		if i > 0 {
+			prev := r.bands[i-1]
+			if b.y0 < prev.y1 {
+				t.Fatalf("band %d overlaps the previous one: %+v", i, r.bands)
+			}
+			if b.y0 == prev.y1 && equalSpans(b.spans, prev.spans) {
+				t.Fatalf("band %d continues the previous one: %+v", i, r.bands)
+			}
+		}
+		for j, s := range b.spans {
+			if s.x1 <= s.x0 || j > 0 && s.x0 <= b.spans[j-1].x1 {
+				t.Fatalf("band %d has unsorted or touching spans: %+v", i, b.spans)
+			}
+		}
+	}
+}
+
+func TestRegionAlgebra(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	ops := []struct {
+		name string
+		op   func(r, o Region) Region
+		in   func(a, b bool) bool
+	}{
+		{"Union", Region.Union, func(a, b bool) bool { return a || b }},
+		{"Intersect", Region.Intersect, func(a, b bool) bool { return a && b }},
+		{"Subtract", Region.Subtract, func(a, b bool) bool { return a && !b }},
+		{"Xor", Region.Xor, func(a, b bool) bool { return a != b }},
+	}
+	for i := 0; i < 500; i++ {
+		ra, rb := randomRects(rnd), randomRects(rnd)
+		a, b := RectsRegion(ra), RectsRegion(rb)
+		pa, pb := rectsPixels(ra), rectsPixels(rb)
+		checkCanonical(t, a)
+		if got := regionPixels(a); !reflect.DeepEqual(got, pa) {
+			t.Fatalf("RectsRegion(%v) covers %d pixels, want %d", ra, len(got), len(pa))
+		}
+
+		for _, op := range ops {
+			got := op.op(a, b)
+			checkCanonical(t, got)
+			want := pixels{}
+			for y := 0; y < gridSize; y++ {
+				for x := 0; x < gridSize; x++ {
+					if p := image.Pt(x, y); op.in(pa[p], pb[p]) {
+						want[p] = true
+					}
+				}
+			}
+			if px := regionPixels(got); !reflect.DeepEqual(px, want) {
+				t.Fatalf("%v %s %v covers %d pixels, want %d", ra, op.name, rb, len(px), len(want))
+			}
+			// the banded form is canonical, equal areas are Equal
+			if !got.Equal(RectsRegion(got.Rects())) {
+				t.Fatalf("%s is not Equal to the region of its rectangles", op.name)
+			}
+		}
+
+		if !a.Union(b).Equal(b.Union(a)) || !a.Xor(b).Equal(a.Union(b).Subtract(a.Intersect(b))) {
+			t.Fatalf("identities fail for %v and %v", ra, rb)
+		}
+		if !a.Subtract(a).Empty() || !a.Xor(a).Empty() || !a.Intersect(Region{}).Empty() {
+			t.Fatalf("%v minus itself is not empty", ra)
+		}
+		if bounds := a.Bounds(); !a.Subtract(RectRegion(bounds)).Empty() ||
+			!a.Empty() && !RectRegion(bounds).Intersect(a).Equal(a) {
+			t.Fatalf("Bounds %v does not contain %v", bounds, ra)
+		}
+	}
+}
+
+func TestRegionBands(t *testing.T) {
+	// two overlapping rectangles become three bands
+	r := RectRegion(image.Rect(0, 0, 10, 10)).Union(RectRegion(image.Rect(5, 5, 15, 15)))
+	want := []image.Rectangle{
+		image.Rect(0, 0, 10, 5),
+		image.Rect(0, 5, 15, 10),
+		image.Rect(5, 10, 15, 15),
+	}
+	if got := r.Rects(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Rects = %v, want %v", got, want)
+	}
+	if b := r.Bounds(); b != image.Rect(0, 0, 15, 15) {
+		t.Errorf("Bounds = %v", b)
+	}
+
+	// a hole splits the middle band into two spans
+	r = RectRegion(image.Rect(0, 0, 9, 9)).Subtract(RectRegion(image.Rect(3, 3, 6, 6)))
+	want = []image.Rectangle{
+		image.Rect(0, 0, 9, 3),
+		image.Rect(0, 3, 3, 6), image.Rect(6, 3, 9, 6),
+		image.Rect(0, 6, 9, 9),
+	}
+	if got := r.Rects(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Rects = %v, want %v", got, want)
+	}
+
+	// touching rectangles merge
+	r = RectsRegion([]image.Rectangle{image.Rect(0, 0, 5, 5), image.Rect(5, 0, 10, 5), image.Rect(0, 5, 10, 8)})
+	if got := r.Rects(); !reflect.DeepEqual(got, []image.Rectangle{image.Rect(0, 0, 10, 8)}) {
+		t.Errorf("touching rectangles = %v", got)
+	}
+
+	if got := r.Translate(image.Pt(-3, 4)).Rects(); !reflect.DeepEqual(got, []image.Rectangle{image.Rect(-3, 4, 7, 12)}) {
+		t.Errorf("Translate = %v", got)
+	}
+	if !RectRegion(image.Rect(5, 5, 0, 0)).Equal(RectRegion(image.Rect(0, 0, 5, 5))) {
+		t.Error("RectRegion does not canonicalize its rectangle")
+	}
+	if !RectRegion(image.Rect(3, 3, 3, 10)).Empty() || !(Region{}).Bounds().Empty() {
+		t.Error("empty rectangle gives a non-empty region")
+	}
+}
+
+func TestEllipseRegion(t *testing.T) {
+	r := EllipseRegion(image.Rect(0, 0, 10, 6))
+	checkCanonical(t, r)
+	if b := r.Bounds(); b != image.Rect(0, 0, 10, 6) {
+		t.Errorf("Bounds = %v", b)
+	}
+	for _, p := range []image.Point{{0, 0}, {9, 0}, {0, 5}, {9, 5}} {
+		if r.Contains(p) {
+			t.Errorf("corner %v is inside", p)
+		}
+	}
+	for _, p := range []image.Point{{5, 0}, {0, 3}, {9, 2}, {4, 5}} {
+		if !r.Contains(p) {
+			t.Errorf("edge pixel %v is outside", p)
+		}
+	}
+	// symmetric about both axes
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 10; x++ {
+			p := r.Contains(image.Pt(x, y))
+			if p != r.Contains(image.Pt(9-x, y)) || p != r.Contains(image.Pt(x, 5-y)) {
+				t.Fatalf("pixel %d,%d breaks the symmetry", x, y)
+			}
+		}
+	}
+}
+
+func TestPolygonRegion(t *testing.T) {
+	square := []image.Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}}
+	for _, winding := range []bool{false, true} {
+		if r := PolygonRegion(square, winding); !r.Equal(RectRegion(image.Rect(0, 0, 10, 10))) {
+			t.Errorf("square with winding %v = %v", winding, r.Rects())
+		}
+	}
+	if !PolygonRegion(square[:2], false).Empty() {
+		t.Error("two points make a region")
+	}
+
+	// a square wound twice is filled by the nonzero rule only
+	double := append(append([]image.Point(nil), square...), square...)
+	if r := PolygonRegion(double, true); !r.Equal(RectRegion(image.Rect(0, 0, 10, 10))) {
+		t.Errorf("double square, winding = %v", r.Rects())
+	}
+
+	// a pentagram leaves its center empty with the alternate rule
+	star := []image.Point{{50, 0}, {79, 90}, {2, 34}, {98, 34}, {21, 90}}
+	alt, wind := PolygonRegion(star, false), PolygonRegion(star, true)
+	center := image.Pt(50, 50)
+	if alt.Contains(center) || !wind.Contains(center) {
+		t.Errorf("center in alternate %v, winding %v", alt.Contains(center), wind.Contains(center))
+	}
+	if !alt.Subtract(wind).Empty() {
+		t.Error("the alternate fill exceeds the winding fill")
+	}
+	if tip := image.Pt(50, 5); !alt.Contains(tip) || !wind.Contains(tip) {
+		t.Error("tip of the star is outside")
+	}
+
+	// a triangle of half the square covers about half the pixels
+	tri := PolygonRegion([]image.Point{{0, 0}, {10, 0}, {0, 10}}, false)
+	n := 0
+	for _, rc := range tri.Rects() {
+		n += rc.Dx() * rc.Dy()
+	}
+	if n < 45 || n > 55 {
+		t.Errorf("triangle covers %d pixels", n)
+	}
+}
+
+func TestAlphaRegion(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(10, 20, 14, 23))
+	img.SetNRGBA(10, 20, color.NRGBA{A: 0xFF})
+	img.SetNRGBA(11, 20, color.NRGBA{A: 0x80})
+	img.SetNRGBA(13, 20, color.NRGBA{A: 0x7F})
+	img.SetNRGBA(13, 22, color.NRGBA{A: 0xFF})
+
+	want := []image.Rectangle{image.Rect(10, 20, 12, 21), image.Rect(13, 22, 14, 23)}
+	gray := image.NewGray16(img.Rect)
+	alpha := image.NewAlpha(img.Rect)
+	for y := 20; y < 23; y++ {
+		for x := 10; x < 14; x++ {
+			alpha.Set(x, y, img.At(x, y))
+		}
+	}
+	for _, src := range []image.Image{img, alpha, toRGBA(img), &generic{img}} {
+		if got := AlphaRegion(src, 0x80).Rects(); !reflect.DeepEqual(got, want) {
+			t.Errorf("AlphaRegion(%T) = %v, want %v", src, got, want)
+		}
+	}
+	if got := AlphaRegion(img, 0x7F).Rects(); len(got) != 3 {
+		t.Errorf("threshold 0x7F = %v", got)
+	}
+	if r := AlphaRegion(gray, 0xFF); !r.Equal(RectRegion(img.Rect)) {
+		t.Errorf("opaque image = %v", r.Rects())
+	}
+}
+
+func toRGBA(src image.Image) *image.RGBA {
+	dst := image.NewRGBA(src.Bounds())
+	for y := dst.Rect.Min.Y; y < dst.Rect.Max.Y; y++ {
+		for x := dst.Rect.Min.X; x < dst.Rect.Max.X; x++ {
+			dst.Set(x, y, src.At(x, y))
+		}
+	}
+	return dst
+}
+
+// generic hides the concrete type of an image.
+type generic struct {
+	image.Image
+}
+
+func TestRegionData(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	regions := []Region{
+		{},
+		RectRegion(image.Rect(-5, -7, 3, 2)),
+		EllipseRegion(image.Rect(0, 0, 31, 17)),
+	}
+	for i := 0; i < 50; i++ {
+		regions = append(regions, RectsRegion(randomRects(rnd)).Translate(image.Pt(-6, -6)))
+	}
+	for _, r := range regions {
+		b := r.RegionData()
+		le := binary.LittleEndian
+		n := len(r.Rects())
+		if le.Uint32(b) != rgnDataHeaderSize || le.Uint32(b[4:]) != RDH_RECTANGLES ||
+			int(le.Uint32(b[8:])) != n || int(le.Uint32(b[12:])) != 16*n || len(b) != rgnDataHeaderSize+16*n {
+			t.Fatalf("RGNDATAHEADER % x for %d rectangles", b[:rgnDataHeaderSize], n)
+		}
+		if got := getRect(b[16:]); got != r.Bounds() {
+			t.Errorf("rcBound = %v, want %v", got, r.Bounds())
+		}
+		got, err := ParseRegionData(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(r) {
+			t.Errorf("ParseRegionData(RegionData(%v)) = %v", r.Rects(), got.Rects())
+		}
+	}
+
+	// GDI may return the rectangles of a band in any order and split them
+	data := make([]byte, rgnDataHeaderSize+3*16)
+	binary.LittleEndian.PutUint32(data, rgnDataHeaderSize)
+	binary.LittleEndian.PutUint32(data[4:], RDH_RECTANGLES)
+	binary.LittleEndian.PutUint32(data[8:], 3)
+	putRect(data[32:], image.Rect(5, 0, 10, 5))
+	putRect(data[48:], image.Rect(0, 0, 5, 5))
+	putRect(data[64:], image.Rect(0, 5, 10, 10))
+	got, err := ParseRegionData(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(RectRegion(image.Rect(0, 0, 10, 10))) {
+		t.Errorf("unsorted RGNDATA = %v", got.Rects())
+	}
+
+	valid := RectRegion(image.Rect(0, 0, 1, 1)).RegionData()
+	for name, b := range map[string][]byte{
+		"empty":       nil,
+		"short":       valid[:rgnDataHeaderSize-1],
+		"header size": append([]byte{16}, valid[1:]...),
+		"type":        append(append([]byte(nil), valid[:4]...), append([]byte{2}, valid[5:]...)...),
+		"count":       valid[:len(valid)-1],
+	} {
+		if _, err := ParseRegionData(b); err == nil {
+			t.Errorf("ParseRegionData accepted %s", name)
+		}
+	}
+}
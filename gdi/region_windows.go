@@ -0,0 +1,54 @@
+package gdi
+
+import (
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi"
+	"github.com/whiteboxsolutions/winapi/gdileak"
+	"golang.org/x/sys/windows"
+)
+
+// HRGN creates a GDI region covering r. The caller deletes it with
+// DeleteObject unless it is handed to SetWindowRgn.
+func (r Region) HRGN() (win.HRGN, error) {
+	if r.Empty() {
+		h := win.CreateRectRgn(0, 0, 0, 0)
+		if h == 0 {
+			return 0, errors.Wrap(windows.GetLastError(), "CreateRectRgn")
+		}
+		winapi.TrackGDIObject(uintptr(h), gdileak.Region)
+		return h, nil
+	}
+	h, err := winapi.ExtCreateRegion(r.RegionData())
+	if err != nil {
+		return 0, errors.Wrap(err, "ExtCreateRegion")
+	}
+	return h, nil
+}
+
+// RegionFromHRGN reads the rectangles of a GDI region.
+func RegionFromHRGN(h win.HRGN) (Region, error) {
+	data, err := winapi.GetRegionData(h)
+	if err != nil {
+		return Region{}, errors.Wrap(err, "GetRegionData")
+	}
+	return ParseRegionData(data)
+}
+
+// SetWindowRegion shapes hwnd after r, in coordinates relative to the top
+// left corner of the window. An empty region removes the shape.
+func SetWindowRegion(hwnd win.HWND, r Region, redraw bool) error {
+	if r.Empty() {
+		return winapi.SetWindowRgn(hwnd, 0, redraw)
+	}
+	h, err := r.HRGN()
+	if err != nil {
+		return err
+	}
+	// the system owns the region once SetWindowRgn succeeds
+	if err := winapi.SetWindowRgn(hwnd, h, redraw); err != nil {
+		winapi.DeleteObject(win.HGDIOBJ(h))
+		return errors.Wrap(err, "SetWindowRgn")
+	}
+	return nil
+}
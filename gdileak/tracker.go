@@ -0,0 +1,296 @@
+// Package gdileak keeps a record of live GDI objects so handle leaks can
+// be traced back to the code that created them. Tracking is off until
+// enabled; the winapi package reports the objects it creates to a
+// Tracker and removes them again on DeleteObject.
+package gdileak
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Object kinds
+const (
+	Bitmap = "bitmap"
+	Brush  = "brush"
+	Font   = "font"
+	Pen    = "pen"
+	Region = "region"
+)
+
+const (
+	// maxDepth limits the recorded creation stack.
+	maxDepth = 32
+	// maxSites limits the stacks written by Report.WriteTo.
+	maxSites = 10
+)
+
+// Object is a live GDI object.
+type Object struct {
+	Handle  uintptr
+	Kind    string
+	Created time.Time
+	// Stack holds the program counters of the creating goroutine,
+	// innermost first.
+	Stack []uintptr
+}
+
+// Age returns how long the object has existed at now.
+func (o Object) Age(now time.Time) time.Duration {
+	return now.Sub(o.Created)
+}
+
+// Frames returns the creation stack as "function file:line" lines.
+func (o Object) Frames() []string {
+	return frames(o.Stack)
+}
+
+func frames(stack []uintptr) []string {
+	if len(stack) == 0 {
+		return nil
+	}
+	var lines []string
+	it := runtime.CallersFrames(stack)
+	for {
+		f, more := it.Next()
+		lines = append(lines, fmt.Sprintf("%s %s:%d", f.Function, f.File, f.Line))
+		if !more {
+			return lines
+		}
+	}
+}
+
+// ProcessCounts are the handle counts of GetGuiResources.
+type ProcessCounts struct {
+	GDI, GDIPeak   uint32
+	User, UserPeak uint32
+}
+
+// Tracker records the GDI objects reported to it. It is safe for
+// concurrent use.
+type Tracker struct {
+	// Now returns the current time, it defaults to time.Now.
+	Now func() time.Time
+	// ProcessCounts, if set, adds the process wide counts to reports.
+	ProcessCounts func() (ProcessCounts, error)
+
+	mu      sync.Mutex
+	enabled bool
+	objects map[uintptr]Object
+	created uint64
+	deleted uint64
+}
+
+// New returns a disabled Tracker.
+func New() *Tracker {
+	return &Tracker{Now: time.Now}
+}
+
+// Enable starts or stops tracking. Stopping forgets all objects.
+func (t *Tracker) Enable(on bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.enabled = on
+	t.objects = nil
+	t.created, t.deleted = 0, 0
+	if on {
+		t.objects = map[uintptr]Object{}
+	}
+}
+
+// Enabled reports whether t is tracking.
+func (t *Tracker) Enabled() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.enabled
+}
+
+// Add records the creation of h. The stack is recorded from the caller of
+// Add's caller, skip drops further frames. A handle that is still recorded
+// was deleted behind the tracker's back and reused by GDI; it is replaced.
+func (t *Tracker) Add(h uintptr, kind string, skip int) {
+	if h == 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.enabled {
+		return
+	}
+	pcs := make([]uintptr, maxDepth)
+	n := runtime.Callers(3+skip, pcs)
+	t.objects[h] = Object{Handle: h, Kind: kind, Created: t.Now(), Stack: pcs[:n:n]}
+	t.created++
+}
+
+// Remove records the deletion of h.
+func (t *Tracker) Remove(h uintptr) {
+	t.Take(h)
+}
+
+// Take records the deletion of h and returns its record. Callers remove
+// an object before deleting it, GDI may reuse the handle right away, and
+// hand the record to Restore when the deletion fails.
+func (t *Tracker) Take(h uintptr) (Object, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	o, ok := t.objects[h]
+	if ok {
+		delete(t.objects, h)
+		t.deleted++
+	}
+	return o, ok
+}
+
+// Restore undoes Take for an object that still exists. It does nothing
+// when tracking is off or the handle was recorded again.
+func (t *Tracker) Restore(o Object) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.enabled {
+		return
+	}
+	if _, ok := t.objects[o.Handle]; ok {
+		return
+	}
+	t.objects[o.Handle] = o
+	if t.deleted > 0 {
+		t.deleted--
+	}
+}
+
+// Live returns the recorded objects, oldest first.
+func (t *Tracker) Live() []Object {
+	t.mu.Lock()
+	live := make([]Object, 0, len(t.objects))
+	for _, o := range t.objects {
+		live = append(live, o)
+	}
+	t.mu.Unlock()
+	sort.Slice(live, func(i, j int) bool {
+		if !live[i].Created.Equal(live[j].Created) {
+			return live[i].Created.Before(live[j].Created)
+		}
+		return live[i].Handle < live[j].Handle
+	})
+	return live
+}
+
+// Site is a creation stack with live objects.
+type Site struct {
+	Kind   string
+	Stack  []uintptr
+	Count  int
+	Oldest time.Time
+}
+
+// Report summarizes the live objects at a point in time.
+type Report struct {
+	Time    time.Time
+	Live    int
+	Created uint64
+	Deleted uint64
+	ByKind  map[string]int
+	// Sites groups the live objects by kind and creation stack, the
+	// sites with the most objects first.
+	Sites []Site
+
+	Process    ProcessCounts
+	ProcessErr error
+}
+
+// Report summarizes the current state of t.
+func (t *Tracker) Report() Report {
+	live := t.Live()
+	t.mu.Lock()
+	r := Report{Time: t.Now(), Live: len(live), Created: t.created, Deleted: t.deleted, ByKind: map[string]int{}}
+	counts := t.ProcessCounts
+	t.mu.Unlock()
+
+	sites := map[string]*Site{}
+	var keys []string
+	for _, o := range live {
+		r.ByKind[o.Kind]++
+		key := fmt.Sprint(o.Kind, o.Stack)
+		s, ok := sites[key]
+		if !ok {
+			s = &Site{Kind: o.Kind, Stack: o.Stack, Oldest: o.Created}
+			sites[key] = s
+			keys = append(keys, key)
+		}
+		s.Count++
+	}
+	for _, k := range keys {
+		r.Sites = append(r.Sites, *sites[k])
+	}
+	sort.SliceStable(r.Sites, func(i, j int) bool { return r.Sites[i].Count > r.Sites[j].Count })
+
+	if counts != nil {
+		r.Process, r.ProcessErr = counts()
+	}
+	return r
+}
+
+// WriteTo writes r in a readable form, with the stacks of the sites with
+// the most objects.
+func (r Report) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d live GDI objects (%d created, %d deleted)\n",
+		r.Time.Format(time.RFC3339), r.Live, r.Created, r.Deleted)
+	kinds := make([]string, 0, len(r.ByKind))
+	for k := range r.ByKind {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	for _, k := range kinds {
+		fmt.Fprintf(&b, "  %-7s %d\n", k, r.ByKind[k])
+	}
+	if r.ProcessErr != nil {
+		fmt.Fprintf(&b, "  process counts: %v\n", r.ProcessErr)
+	} else if r.Process != (ProcessCounts{}) {
+		fmt.Fprintf(&b, "  process: %d GDI (peak %d), %d USER (peak %d)\n",
+			r.Process.GDI, r.Process.GDIPeak, r.Process.User, r.Process.UserPeak)
+	}
+	for i, s := range r.Sites {
+		if i == maxSites {
+			fmt.Fprintf(&b, "  ... %d more sites\n", len(r.Sites)-i)
+			break
+		}
+		fmt.Fprintf(&b, "  %d %s, oldest %s:\n", s.Count, s.Kind, r.Time.Sub(s.Oldest).Round(time.Second))
+		for _, f := range frames(s.Stack) {
+			fmt.Fprintf(&b, "    %s\n", f)
+		}
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func (r Report) String() string {
+	var b strings.Builder
+	r.WriteTo(&b)
+	return b.String()
+}
+
+// Every calls fn with a report every interval until stop is called.
+func (t *Tracker) Every(interval time.Duration, fn func(Report)) (stop func()) {
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fn(t.Report())
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
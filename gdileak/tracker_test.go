@@ -0,0 +1,312 @@
+package gdileak
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// clock is an injectable Now that advances a second per call.
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newClock() *clock {
+	return &clock{now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(time.Second)
+	return c.now
+}
+
+func newTracker() *Tracker {
+	t := New()
+	t.Now = newClock().Now
+	t.Enable(true)
+	return t
+}
+
+// createPen stands for a wrapper like winapi.CreatePen reporting its object.
+func createPen(t *Tracker, h uintptr) {
+	t.Add(h, Pen, 0)
+}
+
+func handles(objects []Object) []uintptr {
+	var h []uintptr
+	for _, o := range objects {
+		h = append(h, o.Handle)
+	}
+	return h
+}
+
+func TestAddRemove(t *testing.T) {
+	tr := New()
+	tr.Add(1, Pen, 0)
+	if tr.Enabled() || len(tr.Live()) != 0 {
+		t.Fatal("a new tracker records objects")
+	}
+
+	tr = newTracker()
+	tr.Add(30, Brush, 0)
+	tr.Add(10, Pen, 0)
+	tr.Add(20, Font, 0)
+	tr.Add(0, Pen, 0)
+	if got := handles(tr.Live()); !reflect.DeepEqual(got, []uintptr{30, 10, 20}) {
+		t.Errorf("Live = %v, want creation order", got)
+	}
+
+	tr.Remove(10)
+	tr.Remove(10)
+	tr.Remove(99)
+	if got := handles(tr.Live()); !reflect.DeepEqual(got, []uintptr{30, 20}) {
+		t.Errorf("Live = %v after Remove", got)
+	}
+	if r := tr.Report(); r.Created != 3 || r.Deleted != 1 {
+		t.Errorf("created %d, deleted %d", r.Created, r.Deleted)
+	}
+
+	// a handle deleted behind the tracker's back and reused is replaced
+	tr.Add(30, Region, 0)
+	live := tr.Live()
+	if got := handles(live); !reflect.DeepEqual(got, []uintptr{20, 30}) || live[1].Kind != Region {
+		t.Errorf("Live = %+v after reuse", live)
+	}
+
+	tr.Enable(false)
+	if tr.Enabled() || len(tr.Live()) != 0 {
+		t.Error("disabling keeps the objects")
+	}
+	tr.Enable(true)
+	if r := tr.Report(); r.Live != 0 || r.Created != 0 || r.Deleted != 0 {
+		t.Errorf("restarted tracker reports %+v", r)
+	}
+}
+
+func TestTakeRestore(t *testing.T) {
+	tr := newTracker()
+	tr.Add(1, Pen, 0)
+	tr.Add(2, Brush, 0)
+
+	o, ok := tr.Take(1)
+	if !ok || o.Handle != 1 || o.Kind != Pen {
+		t.Fatalf("Take = %+v, %v", o, ok)
+	}
+	if _, ok := tr.Take(1); ok {
+		t.Error("second Take found the object")
+	}
+	// the deletion failed, the object is live again with its history
+	tr.Restore(o)
+	live := tr.Live()
+	if !reflect.DeepEqual(live[0], o) {
+		t.Errorf("restored %+v, want %+v", live[0], o)
+	}
+	if r := tr.Report(); r.Created != 2 || r.Deleted != 0 {
+		t.Errorf("created %d, deleted %d after Restore", r.Created, r.Deleted)
+	}
+
+	// a handle recorded again in between is kept
+	o, _ = tr.Take(2)
+	tr.Add(2, Font, 0)
+	tr.Restore(o)
+	if live := tr.Live(); live[len(live)-1].Kind != Font {
+		t.Errorf("Restore replaced the new object: %+v", live)
+	}
+
+	tr.Enable(false)
+	tr.Restore(o)
+	if len(tr.Live()) != 0 {
+		t.Error("Restore records while disabled")
+	}
+}
+
+func TestStack(t *testing.T) {
+	tr := newTracker()
+	createPen(tr, 1)
+	frames := tr.Live()[0].Frames()
+	if len(frames) == 0 || !strings.Contains(frames[0], "gdileak.TestStack ") {
+		t.Fatalf("stack starts at %v, want the caller of createPen", frames)
+	}
+	if !strings.Contains(frames[0], "tracker_test.go:") {
+		t.Errorf("frame %q has no position", frames[0])
+	}
+
+	// skip drops the frames of further wrappers
+	outer := func() {
+		inner := func() { tr.Add(2, Pen, 1) }
+		inner()
+	}
+	outer()
+	if f := tr.Live()[1].Frames(); !strings.Contains(f[0], "gdileak.TestStack ") {
+		t.Errorf("stack with skip starts at %v", f[0])
+	}
+	if (Object{}).Frames() != nil {
+		t.Error("empty stack has frames")
+	}
+}
+
+func TestAge(t *testing.T) {
+	c := newClock()
+	tr := New()
+	tr.Now = c.Now
+	tr.Enable(true)
+	tr.Add(1, Pen, 0)
+	o := tr.Live()[0]
+	if got := o.Age(o.Created.Add(90 * time.Second)); got != 90*time.Second {
+		t.Errorf("Age = %v", got)
+	}
+}
+
+func TestReport(t *testing.T) {
+	tr := newTracker()
+	for h := uintptr(1); h <= 3; h++ {
+		createPen(tr, h)
+	}
+	tr.Add(10, Brush, 0)
+	for h := uintptr(20); h < 32; h++ {
+		tr.Add(h, Font, 0)
+	}
+	tr.Remove(2)
+
+	r := tr.Report()
+	if r.Live != 15 || r.Created != 16 || r.Deleted != 1 {
+		t.Errorf("Live %d, Created %d, Deleted %d", r.Live, r.Created, r.Deleted)
+	}
+	if want := map[string]int{Pen: 2, Brush: 1, Font: 12}; !reflect.DeepEqual(r.ByKind, want) {
+		t.Errorf("ByKind = %v, want %v", r.ByKind, want)
+	}
+	// objects created in a loop share their site
+	if len(r.Sites) != 3 {
+		t.Fatalf("%d sites: %+v", len(r.Sites), r.Sites)
+	}
+	if s := r.Sites[0]; s.Kind != Font || s.Count != 12 {
+		t.Errorf("first site %+v, want the fonts", s)
+	}
+	if s := r.Sites[1]; s.Kind != Pen || s.Count != 2 || !s.Oldest.Equal(tr.Live()[0].Created) {
+		t.Errorf("second site %+v, want the pens", s)
+	}
+	if r.Process != (ProcessCounts{}) || r.ProcessErr != nil {
+		t.Errorf("process counts without a source: %+v, %v", r.Process, r.ProcessErr)
+	}
+
+	text := r.String()
+	for _, want := range []string{
+		"15 live GDI objects (16 created, 1 deleted)",
+		"  brush   1\n  font    12\n  pen     2\n",
+		"  12 font, oldest ",
+		"gdileak.TestReport ",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("report lacks %q:\n%s", want, text)
+		}
+	}
+	var b strings.Builder
+	if n, err := r.WriteTo(&b); err != nil || n != int64(len(text)) || b.String() != text {
+		t.Errorf("WriteTo = %d, %v", n, err)
+	}
+}
+
+func TestReportProcessCounts(t *testing.T) {
+	tr := newTracker()
+	tr.ProcessCounts = func() (ProcessCounts, error) {
+		return ProcessCounts{GDI: 5, GDIPeak: 9, User: 2, UserPeak: 3}, nil
+	}
+	r := tr.Report()
+	if r.Process.GDIPeak != 9 || !strings.Contains(r.String(), "process: 5 GDI (peak 9), 2 USER (peak 3)") {
+		t.Errorf("report %+v:\n%s", r.Process, r)
+	}
+
+	tr.ProcessCounts = func() (ProcessCounts, error) {
+		return ProcessCounts{}, errors.New("access denied")
+	}
+	if r := tr.Report(); !strings.Contains(r.String(), "process counts: access denied") {
+		t.Errorf("report:\n%s", r)
+	}
+}
+
+// addNested records h depth calls deep, every depth is its own site.
+func addNested(tr *Tracker, h uintptr, depth int) {
+	if depth == 0 {
+		tr.Add(h, Pen, 0)
+		return
+	}
+	addNested(tr, h, depth-1)
+}
+
+func TestReportSiteLimit(t *testing.T) {
+	tr := newTracker()
+	for h := uintptr(1); h <= maxSites+2; h++ {
+		addNested(tr, h, int(h))
+	}
+	r := tr.Report()
+	if len(r.Sites) != maxSites+2 {
+		t.Fatalf("%d sites", len(r.Sites))
+	}
+	if !strings.Contains(r.String(), "... 2 more sites") {
+		t.Errorf("report does not limit the sites:\n%s", r)
+	}
+}
+
+func TestEvery(t *testing.T) {
+	tr := newTracker()
+	tr.Add(1, Pen, 0)
+
+	reports := make(chan Report, 10)
+	stop := tr.Every(time.Millisecond, func(r Report) {
+		select {
+		case reports <- r:
+		default:
+		}
+	})
+	for i := 0; i < 3; i++ {
+		select {
+		case r := <-reports:
+			if r.Live != 1 {
+				t.Errorf("report of %d objects", r.Live)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("no report")
+		}
+	}
+	stop()
+	stop()
+
+	// no report arrives once stop returned and the pending one is drained
+	time.Sleep(10 * time.Millisecond)
+	for len(reports) > 0 {
+		<-reports
+	}
+	time.Sleep(10 * time.Millisecond)
+	if len(reports) != 0 {
+		t.Error("reports after stop")
+	}
+}
+
+func TestConcurrent(t *testing.T) {
+	tr := newTracker()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				h := uintptr(g*1000 + i + 1)
+				tr.Add(h, Pen, 0)
+				if i%2 == 0 {
+					tr.Remove(h)
+				}
+				tr.Report()
+			}
+		}(g)
+	}
+	wg.Wait()
+	if r := tr.Report(); r.Live != 400 || r.Created != 800 || r.Deleted != 400 {
+		t.Errorf("Live %d, Created %d, Deleted %d", r.Live, r.Created, r.Deleted)
+	}
+}
@@ -0,0 +1,102 @@
+package winapi
+
+import (
+	"runtime"
+
+	"github.com/lxn/win"
+	"github.com/whiteboxsolutions/winapi/gdileak"
+	"golang.org/x/sys/windows"
+)
+
+// GetGuiResources flags
+const (
+	GR_GDIOBJECTS       uint32 = 0
+	GR_USEROBJECTS      uint32 = 1
+	GR_GDIOBJECTS_PEAK  uint32 = 2
+	GR_USEROBJECTS_PEAK uint32 = 4
+)
+
+// GetGuiResources returns the number of GDI or USER handles process uses.
+func GetGuiResources(process windows.Handle, flags uint32) (uint32, error) {
+	// zero is a valid count, only the last error tells a failure apart
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	setLastError(0)
+	n := getGuiResources(uintptr(process), flags)
+	if n == 0 {
+		if err := windows.GetLastError(); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// GDIObjects records the GDI objects created by this module while
+// tracking is enabled with TrackGDIObjects. Objects are removed when they
+// are deleted with DeleteObject.
+var GDIObjects = gdileak.New()
+
+func init() {
+	GDIObjects.ProcessCounts = processGuiResources
+}
+
+// processGuiResources returns the handle counts of the current process.
+func processGuiResources() (gdileak.ProcessCounts, error) {
+	var c gdileak.ProcessCounts
+	p := windows.CurrentProcess()
+	for _, q := range []struct {
+		flags uint32
+		count *uint32
+	}{
+		{GR_GDIOBJECTS, &c.GDI},
+		{GR_GDIOBJECTS_PEAK, &c.GDIPeak},
+		{GR_USEROBJECTS, &c.User},
+		{GR_USEROBJECTS_PEAK, &c.UserPeak},
+	} {
+		n, err := GetGuiResources(p, q.flags)
+		if err != nil {
+			return c, err
+		}
+		*q.count = n
+	}
+	return c, nil
+}
+
+// TrackGDIObjects turns recording of GDI objects with their creation
+// stacks on or off. Recording is off by default as capturing the stacks
+// slows down object creation.
+func TrackGDIObjects(enable bool) {
+	GDIObjects.Enable(enable)
+}
+
+// LiveObjects returns the recorded objects that were not deleted yet,
+// oldest first.
+func LiveObjects() []gdileak.Object {
+	return GDIObjects.Live()
+}
+
+// TrackGDIObject records h, created by kind, for packages creating GDI
+// objects through other bindings.
+func TrackGDIObject(h uintptr, kind string) {
+	GDIObjects.Add(h, kind, 0)
+}
+
+func trackGDI(h uintptr, kind string) {
+	// skip trackGDI and the creating wrapper
+	GDIObjects.Add(h, kind, 1)
+}
+
+// DeleteObject deletes a pen, brush, font, bitmap, region or palette and
+// removes it from the tracked objects.
+func DeleteObject(h win.HGDIOBJ) bool {
+	// untrack first, GDI may hand out the handle again as soon as it is
+	// deleted and another goroutine's Add must not be undone
+	o, tracked := GDIObjects.Take(uintptr(h))
+	if !win.DeleteObject(h) {
+		if tracked {
+			GDIObjects.Restore(o)
+		}
+		return false
+	}
+	return true
+}
@@ -76,9 +76,7 @@ func EnumChildWindows(hwnd win.HWND) []win.HWND {
 func FindChildWindowsFromWindowText(parentHWND win.HWND, lpszClass *uint16, lpszWindow *uint16, windowText string) win.HWND {
 	var chwnd win.HWND
 	for chwnd = FindWindowEx(parentHWND, chwnd, nil, nil); chwnd != win.HWND(NULL); chwnd = FindWindowEx(parentHWND, chwnd, nil, nil) {
-		var UTF16name = make([]uint16, 1000)
-		GetWindowText(chwnd, UTF16name, 1000)
-		if syscall.UTF16ToString(UTF16name) == windowText {
+		if text, err := WindowText(chwnd); err == nil && text == windowText {
 			return chwnd
 		}
 	}
@@ -89,8 +87,9 @@ func SendMessage(hwnd win.HWND, msg uint32, wParam uintptr, lParam uintptr) uint
 	return win.SendMessage(hwnd, msg, wParam, lParam)
 }
 
+// GetWindowTextString returns the text of hwnd, empty when it cannot be
+// read. Use WindowText to tell errors apart.
 func GetWindowTextString(hwnd win.HWND) string {
-	var name = make([]uint16, 1000)
-	GetWindowText(hwnd, name, 1000)
-	return syscall.UTF16ToString(name)
+	text, _ := WindowText(hwnd)
+	return text
 }
@@ -0,0 +1,23 @@
+package hotkey
+
+import (
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi/input"
+)
+
+// ParseCombo parses a human readable combination such as "Ctrl+Alt+F9".
+// Unlike input.ParseChord a hotkey needs a non-modifier key.
+func ParseCombo(s string) (input.Chord, error) {
+	c, err := input.ParseChord(s)
+	if err != nil {
+		return c, err
+	}
+	switch c.Key {
+	case 0:
+		return c, errors.Errorf("hotkey %q has no key", s)
+	case input.VK_SHIFT, input.VK_CONTROL, input.VK_MENU, input.VK_LWIN, input.VK_RWIN,
+		input.VK_LSHIFT, input.VK_RSHIFT, input.VK_LCONTROL, input.VK_RCONTROL, input.VK_LMENU, input.VK_RMENU:
+		return c, errors.Errorf("hotkey %q uses a modifier as key", s)
+	}
+	return c, nil
+}
@@ -0,0 +1,29 @@
+package hotkey
+
+import (
+	"testing"
+
+	"github.com/whiteboxsolutions/winapi/input"
+)
+
+func TestParseCombo(t *testing.T) {
+	c, err := ParseCombo("Ctrl+Alt+F9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (input.Chord{Modifiers: input.ModCtrl | input.ModAlt, Key: input.VK_F1 + 8}); c != want {
+		t.Errorf("ParseCombo = %+v, want %+v", c, want)
+	}
+	// the modifier bits are the MOD_* flags of RegisterHotKey
+	if uint32(c.Modifiers) != 0x0001|0x0002 {
+		t.Errorf("modifiers = %#x", c.Modifiers)
+	}
+}
+
+func TestParseComboErrors(t *testing.T) {
+	for _, s := range []string{"", "Ctrl+Alt", "Ctrl+LShift", "Shift+RAlt", "Ctrl+Bogus"} {
+		if c, err := ParseCombo(s); err == nil {
+			t.Errorf("ParseCombo(%q) = %+v, want error", s, c)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package hotkey
+
+import (
+	"sync"
+
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi"
+	"github.com/whiteboxsolutions/winapi/input"
+	"golang.org/x/sys/windows"
+)
+
+const windowClass = "winapi.hotkey"
+
+// Hotkey is a registered system-wide key combination.
+type Hotkey struct {
+	ID    int
+	Chord input.Chord
+
+	m *Manager
+}
+
+// Unregister releases the combination.
+func (h *Hotkey) Unregister() error {
+	return h.m.unregister(h.ID)
+}
+
+// Manager owns a hidden window receiving WM_HOTKEY and dispatches to Go callbacks.
+type Manager struct {
+	// Repeat delivers auto-repeat notifications while the keys are held,
+	// by default MOD_NOREPEAT is set.
+	Repeat bool
+
+	w        *winapi.MessageWindow
+	reg      registry
+	mu       sync.Mutex
+	handlers map[int]func()
+}
+
+func NewManager() (*Manager, error) {
+	m := &Manager{handlers: map[int]func(){}}
+	w, err := winapi.NewMessageWindow(windowClass, "", false, m.wndProc)
+	if err != nil {
+		return nil, err
+	}
+	m.w = w
+	return m, nil
+}
+
+func (m *Manager) wndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) (uintptr, bool) {
+	if msg != win.WM_HOTKEY {
+		return 0, false
+	}
+	m.mu.Lock()
+	handler := m.handlers[int(wParam)]
+	m.mu.Unlock()
+	if handler != nil {
+		// keep the message loop responsive while the handler runs
+		go handler()
+	}
+	return 0, true
+}
+
+// Register registers combo, such as "Ctrl+Alt+F9", and calls handler each
+// time it is pressed. ErrConflict is returned when the combination is
+// already registered by this process or another application.
+func (m *Manager) Register(combo string, handler func()) (*Hotkey, error) {
+	c, err := ParseCombo(combo)
+	if err != nil {
+		return nil, err
+	}
+	id, err := m.reg.add(c)
+	if err != nil {
+		return nil, err
+	}
+
+	mods := uint32(c.Modifiers)
+	if !m.Repeat {
+		mods |= winapi.MOD_NOREPEAT
+	}
+	if doErr := m.w.Do(func() {
+		err = winapi.RegisterHotKey(m.w.HWND, id, mods, uint32(c.Key))
+	}); doErr != nil {
+		err = doErr
+	}
+	if err != nil {
+		m.reg.remove(id)
+		if err == windows.ERROR_HOTKEY_ALREADY_REGISTERED {
+			return nil, errors.Wrapf(ErrConflict, "%s is registered by another application", c)
+		}
+		return nil, errors.Wrap(err, "RegisterHotKey")
+	}
+
+	m.mu.Lock()
+	m.handlers[id] = handler
+	m.mu.Unlock()
+	return &Hotkey{ID: id, Chord: c, m: m}, nil
+}
+
+func (m *Manager) unregister(id int) error {
+	if !m.reg.has(id) {
+		return errors.Errorf("hotkey %d is not registered", id)
+	}
+	var err error
+	if doErr := m.w.Do(func() {
+		err = winapi.UnregisterHotKey(m.w.HWND, id)
+	}); doErr != nil {
+		return doErr
+	}
+	if err != nil {
+		// the system still holds the id, keep it allocated
+		return errors.Wrap(err, "UnregisterHotKey")
+	}
+	m.mu.Lock()
+	delete(m.handlers, id)
+	m.mu.Unlock()
+	m.reg.remove(id)
+	return nil
+}
+
+// Close unregisters every hotkey and destroys the window. It returns the
+// first error but always destroys the window.
+func (m *Manager) Close() error {
+	var err error
+	for _, id := range m.reg.ids() {
+		if e := m.unregister(id); e != nil && err == nil {
+			err = e
+		}
+	}
+	if e := m.w.Close(); e != nil && err == nil {
+		err = e
+	}
+	return err
+}
+
+var (
+	defaultManager    *Manager
+	defaultManagerErr error
+	defaultOnce       sync.Once
+)
+
+// RegisterHotkey registers combo on a process wide Manager.
+func RegisterHotkey(combo string, handler func()) (*Hotkey, error) {
+	defaultOnce.Do(func() {
+		defaultManager, defaultManagerErr = NewManager()
+	})
+	if defaultManagerErr != nil {
+		return nil, defaultManagerErr
+	}
+	return defaultManager.Register(combo, handler)
+}
@@ -0,0 +1,86 @@
+package hotkey
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi/input"
+)
+
+// RegisterHotKey accepts application ids in the range 0x0000 through 0xBFFF.
+const maxID = 0xBFFF
+
+var (
+	ErrConflict = errors.New("hotkey already registered")
+	ErrNoIDs    = errors.New("no free hotkey ids")
+)
+
+// registry allocates hotkey ids and rejects combinations registered twice.
+type registry struct {
+	mu      sync.Mutex
+	next    int
+	free    []int
+	byID    map[int]input.Chord
+	byChord map[input.Chord]int
+}
+
+func (r *registry) add(c input.Chord) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.byID == nil {
+		r.byID = map[int]input.Chord{}
+		r.byChord = map[input.Chord]int{}
+	}
+	if _, ok := r.byChord[c]; ok {
+		return 0, errors.Wrap(ErrConflict, c.String())
+	}
+
+	var id int
+	switch {
+	case len(r.free) > 0:
+		id = r.free[len(r.free)-1]
+		r.free = r.free[:len(r.free)-1]
+	case r.next <= maxID:
+		id = r.next
+		r.next++
+	default:
+		return 0, ErrNoIDs
+	}
+	r.byID[id] = c
+	r.byChord[c] = id
+	return id, nil
+}
+
+func (r *registry) remove(id int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.byID[id]
+	if !ok {
+		return false
+	}
+	delete(r.byID, id)
+	delete(r.byChord, c)
+	r.free = append(r.free, id)
+	return true
+}
+
+func (r *registry) has(id int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.byID[id]
+	return ok
+}
+
+func (r *registry) ids() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := make([]int, 0, len(r.byID))
+	for id := range r.byID {
+		ids = append(ids, id)
+	}
+	return ids
+}
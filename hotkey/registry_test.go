@@ -0,0 +1,51 @@
+package hotkey
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi/input"
+)
+
+func TestRegistryIDs(t *testing.T) {
+	var r registry
+	a := input.Chord{Modifiers: input.ModCtrl, Key: 'A'}
+	b := input.Chord{Modifiers: input.ModCtrl, Key: 'B'}
+
+	ida, err := r.add(a)
+	if err != nil || ida != 0 {
+		t.Fatalf("add(a) = %d, %v", ida, err)
+	}
+	idb, err := r.add(b)
+	if err != nil || idb != 1 {
+		t.Fatalf("add(b) = %d, %v", idb, err)
+	}
+	if _, err := r.add(a); errors.Cause(err) != ErrConflict {
+		t.Errorf("duplicate add = %v, want ErrConflict", err)
+	}
+	if !r.has(ida) || r.has(7) {
+		t.Errorf("has = %v, %v", r.has(ida), r.has(7))
+	}
+
+	if !r.remove(ida) || r.remove(ida) {
+		t.Fatal("remove should succeed once")
+	}
+	// a freed id is reused and the chord can be registered again
+	id, err := r.add(a)
+	if err != nil || id != ida {
+		t.Errorf("add after remove = %d, %v, want %d", id, err, ida)
+	}
+	if n := len(r.ids()); n != 2 {
+		t.Errorf("ids = %d entries", n)
+	}
+}
+
+func TestRegistryExhausted(t *testing.T) {
+	r := registry{next: maxID}
+	if id, err := r.add(input.Chord{Key: 'A'}); err != nil || id != maxID {
+		t.Fatalf("add = %d, %v", id, err)
+	}
+	if _, err := r.add(input.Chord{Key: 'B'}); err != ErrNoIDs {
+		t.Errorf("add = %v, want ErrNoIDs", err)
+	}
+}
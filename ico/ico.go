@@ -0,0 +1,247 @@
+// Package ico encodes and decodes .ico and .cur files, which hold an icon
+// or cursor at several sizes and color depths.
+package ico
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+	"image/png"
+
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi/dib"
+)
+
+// ICONDIR idType
+const (
+	TypeIcon   uint16 = 1
+	TypeCursor uint16 = 2
+)
+
+const (
+	dirSize   = 6
+	entrySize = 16
+	// MaxSize is the largest width and height of an entry.
+	MaxSize = 256
+)
+
+var ErrFormat = errors.New("ico: invalid format")
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// Entry is one image of an icon or cursor.
+type Entry struct {
+	Image image.Image
+	// Hotspot is the click point of a cursor relative to the top left
+	// corner of the image.
+	Hotspot image.Point
+	// PNG stores the image PNG compressed instead of as a DIB, which
+	// Windows Vista and later read and which is usual at 256 pixels.
+	PNG bool
+	// BitCount is the color depth the entry was stored with, entries are
+	// always encoded with 32 bpp.
+	BitCount uint16
+}
+
+// File is the contents of an .ico or .cur file.
+type File struct {
+	Type    uint16
+	Entries []Entry
+}
+
+// Nearest returns the entry whose width is closest to size, preferring
+// larger entries that scale down over smaller ones that scale up.
+func (f *File) Nearest(size int) *Entry {
+	var best *Entry
+	for i := range f.Entries {
+		e := &f.Entries[i]
+		if best == nil {
+			best = e
+			continue
+		}
+		w, bw := e.Image.Bounds().Dx(), best.Image.Bounds().Dx()
+		switch {
+		case w == bw:
+			if e.BitCount > best.BitCount {
+				best = e
+			}
+		case bw < size:
+			if w > bw {
+				best = e
+			}
+		case w >= size && w < bw:
+			best = e
+		}
+	}
+	return best
+}
+
+// Decode decodes an .ico or .cur file. DIB entries decode to *image.NRGBA
+// with the AND mask applied to the alpha channel.
+func Decode(b []byte) (*File, error) {
+	le := binary.LittleEndian
+	if len(b) < dirSize || le.Uint16(b) != 0 {
+		return nil, errors.Wrap(ErrFormat, "short header")
+	}
+	f := &File{Type: le.Uint16(b[2:])}
+	if f.Type != TypeIcon && f.Type != TypeCursor {
+		return nil, errors.Wrapf(ErrFormat, "type %d", f.Type)
+	}
+	n := int(le.Uint16(b[4:]))
+	if n == 0 || len(b) < dirSize+n*entrySize {
+		return nil, errors.Wrapf(ErrFormat, "directory of %d entries", n)
+	}
+	f.Entries = make([]Entry, n)
+	for i := range f.Entries {
+		d := b[dirSize+i*entrySize:]
+		size, offset := le.Uint32(d[8:]), le.Uint32(d[12:])
+		if uint64(offset)+uint64(size) > uint64(len(b)) {
+			return nil, errors.Wrapf(ErrFormat, "entry %d out of bounds", i)
+		}
+		e, err := decodeEntry(b[offset : offset+size])
+		if err != nil {
+			return nil, errors.Wrapf(err, "entry %d", i)
+		}
+		if f.Type == TypeCursor {
+			e.Hotspot = image.Point{X: int(le.Uint16(d[4:])), Y: int(le.Uint16(d[6:]))}
+		} else if bc := le.Uint16(d[6:]); bc != 0 && e.BitCount == 0 {
+			e.BitCount = bc
+		}
+		f.Entries[i] = e
+	}
+	return f, nil
+}
+
+func decodeEntry(data []byte) (Entry, error) {
+	if bytes.HasPrefix(data, pngSignature) {
+		img, err := png.Decode(bytes.NewReader(data))
+		return Entry{Image: img, PNG: true, BitCount: 32}, err
+	}
+	h, err := dib.ParseHeader(data)
+	if err != nil {
+		return Entry{}, err
+	}
+	// the height covers the color bitmap followed by the mask
+	height := h.Dy() / 2
+	if height == 0 {
+		return Entry{}, errors.Wrapf(ErrFormat, "height %d", h.Height)
+	}
+	h.Height = int32(height)
+	offset := h.BitsOffset()
+	if offset > len(data) {
+		return Entry{}, errors.Wrap(ErrFormat, "short color table")
+	}
+	palette := dib.DecodePalette(data[offset-h.PaletteLen()*4 : offset])
+	img, err := dib.DecodeBits(&h, palette, data[offset:])
+	if err != nil {
+		return Entry{}, err
+	}
+	nrgba, ok := img.(*image.NRGBA)
+	if !ok {
+		nrgba = image.NewNRGBA(img.Bounds())
+		draw.Draw(nrgba, nrgba.Rect, img, img.Bounds().Min, draw.Src)
+	}
+	// 32 bpp entries may omit the mask, they rely on the alpha channel
+	stride := dib.Stride(h.Dx(), 1)
+	if mask := data[offset+h.Stride()*height:]; len(mask) >= stride*height {
+		for y := 0; y < height; y++ {
+			row := mask[(height-1-y)*stride:]
+			for x := 0; x < h.Dx(); x++ {
+				if row[x/8]&(0x80>>uint(x%8)) != 0 {
+					nrgba.Pix[y*nrgba.Stride+x*4+3] = 0
+				}
+			}
+		}
+	}
+	return Entry{Image: nrgba, BitCount: h.BitCount}, nil
+}
+
+// Encode encodes f as an .ico or .cur file, depending on f.Type.
+func Encode(f *File) ([]byte, error) {
+	typ := f.Type
+	if typ == 0 {
+		typ = TypeIcon
+	}
+	if typ != TypeIcon && typ != TypeCursor {
+		return nil, errors.Errorf("ico: type %d", typ)
+	}
+	if len(f.Entries) == 0 {
+		return nil, errors.New("ico: no images")
+	}
+	le := binary.LittleEndian
+	res := make([][]byte, len(f.Entries))
+	offset := dirSize + entrySize*len(f.Entries)
+	out := make([]byte, offset)
+	le.PutUint16(out[2:], typ)
+	le.PutUint16(out[4:], uint16(len(f.Entries)))
+	for i, e := range f.Entries {
+		b := e.Image.Bounds()
+		if b.Empty() || b.Dx() > MaxSize || b.Dy() > MaxSize {
+			return nil, errors.Errorf("ico: image size %dx%d", b.Dx(), b.Dy())
+		}
+		if e.PNG {
+			var buf bytes.Buffer
+			if err := png.Encode(&buf, e.Image); err != nil {
+				return nil, err
+			}
+			res[i] = buf.Bytes()
+		} else {
+			res[i] = Resource(e.Image)
+		}
+
+		d := out[dirSize+entrySize*i:]
+		d[0], d[1] = byte(b.Dx()), byte(b.Dy()) // 256 is stored as 0
+		if typ == TypeCursor {
+			if !e.Hotspot.In(image.Rect(0, 0, b.Dx(), b.Dy())) {
+				return nil, errors.Errorf("ico: hotspot %v outside %dx%d", e.Hotspot, b.Dx(), b.Dy())
+			}
+			le.PutUint16(d[4:], uint16(e.Hotspot.X))
+			le.PutUint16(d[6:], uint16(e.Hotspot.Y))
+		} else {
+			le.PutUint16(d[4:], 1)  // planes
+			le.PutUint16(d[6:], 32) // bit count
+		}
+		le.PutUint32(d[8:], uint32(len(res[i])))
+		le.PutUint32(d[12:], uint32(offset))
+		offset += len(res[i])
+	}
+	for _, r := range res {
+		out = append(out, r...)
+	}
+	return out, nil
+}
+
+// Resource encodes img as the bits of an RT_ICON resource: a 32 bpp
+// BITMAPINFOHEADER DIB with doubled height, followed by the AND mask.
+// Pixels with zero alpha are set in the mask so the icon stays transparent
+// where alpha blending is not available.
+func Resource(img image.Image) []byte {
+	b := img.Bounds()
+	out := dib.Encode(img)
+	// the height covers both the color bitmap and the mask
+	binary.LittleEndian.PutUint32(out[8:], uint32(2*b.Dy()))
+	return append(out, Mask(img, 4, true)...)
+}
+
+// Mask returns the monochrome AND mask of img, with a bit set for every
+// pixel of zero alpha. Rows are padded to a multiple of align bytes and
+// stored bottom-up when bottomUp is set, as in DIBs; CreateBitmap expects
+// top-down rows aligned to 2 bytes.
+func Mask(img image.Image, align int, bottomUp bool) []byte {
+	b := img.Bounds()
+	stride := (b.Dx() + 8*align - 1) / (8 * align) * align
+	mask := make([]byte, stride*b.Dy())
+	for y := 0; y < b.Dy(); y++ {
+		row := mask[y*stride:]
+		if bottomUp {
+			row = mask[(b.Dy()-1-y)*stride:]
+		}
+		for x := 0; x < b.Dx(); x++ {
+			if _, _, _, a := img.At(b.Min.X+x, b.Min.Y+y).RGBA(); a == 0 {
+				row[x/8] |= 0x80 >> uint(x%8)
+			}
+		}
+	}
+	return mask
+}
@@ -0,0 +1,253 @@
+package ico
+
+import (
+	"bytes"
+	"encoding/binary"
+	"flag"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// testImage returns a w x h image with a transparent border, half
+// transparent pixels and opaque colors inside.
+func testImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 1; y < h-1; y++ {
+		for x := 1; x < w-1; x++ {
+			a := uint8(0xFF)
+			if (x+y)%5 == 0 {
+				a = 0x60
+			}
+			img.SetNRGBA(x, y, color.NRGBA{uint8(x * 255 / w), uint8(y * 255 / h), uint8((x ^ y) * 7), a})
+		}
+	}
+	return img
+}
+
+// checkGolden compares b with the file testdata/name, or writes it with -update.
+func checkGolden(t *testing.T, name string, b []byte) []byte {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, want) {
+		t.Errorf("%s differs from the golden file, run go test -update to accept it", name)
+	}
+	return want
+}
+
+func checkSame(t *testing.T, name string, got, want image.Image) {
+	t.Helper()
+	if got.Bounds() != want.Bounds() {
+		t.Fatalf("%s: bounds %v, want %v", name, got.Bounds(), want.Bounds())
+	}
+	b := want.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			g := color.NRGBAModel.Convert(got.At(x, y))
+			w := color.NRGBAModel.Convert(want.At(x, y))
+			if g != w {
+				t.Fatalf("%s: pixel (%d, %d) = %v, want %v", name, x, y, g, w)
+			}
+		}
+	}
+}
+
+func TestGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		f    *File
+	}{
+		{"icon.ico", &File{Type: TypeIcon, Entries: []Entry{
+			{Image: testImage(16, 16)},
+			{Image: testImage(32, 32)},
+			// widths off the byte boundary exercise the mask padding
+			{Image: testImage(20, 12)},
+		}}},
+		{"cursor.cur", &File{Type: TypeCursor, Entries: []Entry{
+			{Image: testImage(32, 32), Hotspot: image.Pt(3, 5)},
+			{Image: testImage(48, 48), Hotspot: image.Pt(4, 7)},
+		}}},
+	}
+	for _, tt := range tests {
+		b, err := Encode(tt.f)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		f, err := Decode(checkGolden(t, tt.name, b))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if f.Type != tt.f.Type || len(f.Entries) != len(tt.f.Entries) {
+			t.Fatalf("%s: type %d with %d entries", tt.name, f.Type, len(f.Entries))
+		}
+		for i, e := range f.Entries {
+			want := tt.f.Entries[i]
+			if e.Hotspot != want.Hotspot || e.BitCount != 32 || e.PNG {
+				t.Errorf("%s: entry %d has hotspot %v, %d bpp, PNG %v", tt.name, i, e.Hotspot, e.BitCount, e.PNG)
+			}
+			checkSame(t, tt.name, e.Image, want.Image)
+		}
+	}
+}
+
+func TestPNGEntry(t *testing.T) {
+	img := testImage(256, 256)
+	b, err := Encode(&File{Entries: []Entry{{Image: testImage(16, 16)}, {Image: img, PNG: true}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 256 is stored as 0 in the directory
+	if d := b[dirSize+entrySize:]; d[0] != 0 || d[1] != 0 {
+		t.Errorf("directory size %d x %d", d[0], d[1])
+	}
+	f, err := Decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Type != TypeIcon || !f.Entries[1].PNG || f.Entries[1].BitCount != 32 {
+		t.Errorf("type %d, entry %+v", f.Type, f.Entries[1])
+	}
+	checkSame(t, "PNG", f.Entries[1].Image, img)
+}
+
+// legacyIcon returns an icon with a single 4 bpp entry of w x h pixels
+// whose mask hides the first column, as written by old icon editors.
+func legacyIcon(w, h int) []byte {
+	le := binary.LittleEndian
+	stride := (w*4 + 31) / 32 * 4
+	maskStride := (w + 31) / 32 * 4
+	res := make([]byte, 40+16*4+stride*h+maskStride*h)
+	le.PutUint32(res, 40)
+	le.PutUint32(res[4:], uint32(w))
+	le.PutUint32(res[8:], uint32(2*h))
+	le.PutUint16(res[12:], 1)
+	le.PutUint16(res[14:], 4)
+	// palette entry 1 is red
+	res[40+4+2] = 0xFF
+	bits := res[40+16*4:]
+	for i := 0; i < stride*h; i++ {
+		bits[i] = 0x11
+	}
+	mask := bits[stride*h:]
+	for y := 0; y < h; y++ {
+		mask[y*maskStride] = 0x80
+	}
+
+	out := make([]byte, dirSize+entrySize)
+	le.PutUint16(out[2:], TypeIcon)
+	le.PutUint16(out[4:], 1)
+	d := out[dirSize:]
+	d[0], d[1], d[2] = byte(w), byte(h), 16
+	le.PutUint16(d[4:], 1)
+	le.PutUint16(d[6:], 4)
+	le.PutUint32(d[8:], uint32(len(res)))
+	le.PutUint32(d[12:], uint32(len(out)))
+	return append(out, res...)
+}
+
+func TestDecodeLegacy(t *testing.T) {
+	f, err := Decode(legacyIcon(16, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := f.Entries[0]
+	if e.BitCount != 4 {
+		t.Errorf("BitCount = %d", e.BitCount)
+	}
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			want := color.NRGBA{0xFF, 0, 0, 0xFF}
+			if x == 0 {
+				want.A = 0
+			}
+			if got := color.NRGBAModel.Convert(e.Image.At(x, y)); got != want {
+				t.Fatalf("pixel (%d, %d) = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	icon, _ := Encode(&File{Entries: []Entry{{Image: testImage(16, 16)}}})
+	put := func(off int, v uint32) []byte {
+		b := append([]byte(nil), icon...)
+		binary.LittleEndian.PutUint32(b[off:], v)
+		return b
+	}
+	tests := []struct {
+		name string
+		b    []byte
+	}{
+		{"empty", nil},
+		{"reserved", put(0, 1)},
+		{"type", put(2, 3)},
+		{"no entries", put(4, 0)},
+		{"short directory", icon[:dirSize+entrySize-1]},
+		{"entry beyond the file", put(dirSize+8, uint32(len(icon)))},
+		{"zero height", put(dirSize+entrySize+8, 1)},
+	}
+	for _, tt := range tests {
+		if _, err := Decode(tt.b); err == nil {
+			t.Errorf("%s: Decode succeeded", tt.name)
+		}
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		f    *File
+	}{
+		{"no entries", &File{}},
+		{"type", &File{Type: 3, Entries: []Entry{{Image: testImage(16, 16)}}}},
+		{"empty image", &File{Entries: []Entry{{Image: image.NewNRGBA(image.Rect(0, 0, 0, 0))}}}},
+		{"too large", &File{Entries: []Entry{{Image: testImage(257, 16)}}}},
+		{"hotspot", &File{Type: TypeCursor, Entries: []Entry{{Image: testImage(16, 16), Hotspot: image.Pt(16, 0)}}}},
+	}
+	for _, tt := range tests {
+		if _, err := Encode(tt.f); err == nil {
+			t.Errorf("%s: Encode succeeded", tt.name)
+		}
+	}
+}
+
+func TestNearest(t *testing.T) {
+	entry := func(size int, bitCount uint16) Entry {
+		return Entry{Image: image.NewNRGBA(image.Rect(0, 0, size, size)), BitCount: bitCount}
+	}
+	f := &File{Entries: []Entry{entry(16, 32), entry(32, 4), entry(32, 32), entry(48, 32), entry(256, 32)}}
+	tests := []struct {
+		size, want int
+	}{
+		{16, 0},
+		{8, 0},
+		// larger entries scale down, deeper colors win at equal sizes
+		{20, 2},
+		{32, 2},
+		{40, 3},
+		{256, 4},
+		{512, 4},
+	}
+	for _, tt := range tests {
+		if got := f.Nearest(tt.size); got != &f.Entries[tt.want] {
+			t.Errorf("Nearest(%d) = %dx%d at %d bpp, want entry %d",
+				tt.size, got.Image.Bounds().Dx(), got.Image.Bounds().Dy(), got.BitCount, tt.want)
+		}
+	}
+	if (&File{}).Nearest(16) != nil {
+		t.Error("Nearest of an empty file")
+	}
+}
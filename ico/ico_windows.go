@@ -0,0 +1,155 @@
+package ico
+
+import (
+	"image"
+	"unsafe"
+
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi"
+	"github.com/whiteboxsolutions/winapi/gdileak"
+	"golang.org/x/sys/windows"
+)
+
+// HICON creates an icon from the entry of f nearest to size pixels. The
+// caller destroys it with win.DestroyIcon.
+func (f *File) HICON(size int) (win.HICON, error) {
+	e := f.Nearest(size)
+	if e == nil {
+		return 0, errors.New("ico: no images")
+	}
+	return e.handle(true)
+}
+
+// HCURSOR creates a cursor from the entry of f nearest to size pixels,
+// keeping its hotspot. The caller destroys it with win.DestroyCursor.
+func (f *File) HCURSOR(size int) (win.HCURSOR, error) {
+	e := f.Nearest(size)
+	if e == nil {
+		return 0, errors.New("ico: no images")
+	}
+	h, err := e.handle(false)
+	return win.HCURSOR(h), err
+}
+
+// HICON creates an icon from e.
+func (e *Entry) HICON() (win.HICON, error) {
+	return e.handle(true)
+}
+
+// HCURSOR creates a cursor from e with its hotspot.
+func (e *Entry) HCURSOR() (win.HCURSOR, error) {
+	h, err := e.handle(false)
+	return win.HCURSOR(h), err
+}
+
+// handle creates the icon or cursor through CreateIconIndirect from a
+// 32 bpp color bitmap carrying the alpha channel and a monochrome mask.
+func (e *Entry) handle(icon bool) (win.HICON, error) {
+	b := e.Image.Bounds()
+	if b.Empty() {
+		return 0, errors.Errorf("ico: image size %dx%d", b.Dx(), b.Dy())
+	}
+	info := win.ICONINFO{}
+	if icon {
+		info.FIcon = win.TRUE
+	} else {
+		if !e.Hotspot.In(image.Rect(0, 0, b.Dx(), b.Dy())) {
+			return 0, errors.Errorf("ico: hotspot %v outside %dx%d", e.Hotspot, b.Dx(), b.Dy())
+		}
+		info.XHotspot, info.YHotspot = uint32(e.Hotspot.X), uint32(e.Hotspot.Y)
+	}
+
+	color, err := winapi.NewDIB(b.Dx(), b.Dy())
+	if err != nil {
+		return 0, err
+	}
+	defer color.Close()
+	copy(color.Pix, ColorPlane(e.Image))
+
+	mask, err := monoBitmap(b.Dx(), b.Dy(), Mask(e.Image, 2, false))
+	if err != nil {
+		return 0, err
+	}
+	defer winapi.DeleteObject(win.HGDIOBJ(mask))
+
+	info.HbmMask, info.HbmColor = mask, color.HBITMAP
+	return winapi.CreateIconIndirect(&info)
+}
+
+// monoBitmap creates a 1 bpp bitmap from top-down rows aligned to 2 bytes.
+func monoBitmap(width, height int, bits []byte) (win.HBITMAP, error) {
+	hbm := win.CreateBitmap(int32(width), int32(height), 1, 1, unsafe.Pointer(&bits[0]))
+	if hbm == 0 {
+		return 0, errors.Wrap(windows.GetLastError(), "CreateBitmap")
+	}
+	winapi.TrackGDIObject(uintptr(hbm), gdileak.Bitmap)
+	return hbm, nil
+}
+
+// IconFromImage creates an icon from img. The caller destroys it with
+// win.DestroyIcon.
+func IconFromImage(img image.Image) (win.HICON, error) {
+	return (&Entry{Image: img}).handle(true)
+}
+
+// CursorFromImage creates a cursor from img whose click point is hotspot,
+// relative to the top left corner of img. The alpha channel is kept on
+// displays with at least 16 bits per pixel, others get MonochromeCursor.
+// The caller destroys the cursor with win.DestroyCursor.
+func CursorFromImage(img image.Image, hotspot image.Point) (win.HCURSOR, error) {
+	if !colorDisplay() {
+		return MonochromeCursor(img, hotspot)
+	}
+	h, err := (&Entry{Image: img, Hotspot: hotspot}).handle(false)
+	return win.HCURSOR(h), err
+}
+
+// MonochromeCursor creates a black and white cursor from img as described
+// by Monochrome, which is drawn the same on every display.
+func MonochromeCursor(img image.Image, hotspot image.Point) (win.HCURSOR, error) {
+	b := img.Bounds()
+	if b.Empty() {
+		return 0, errors.Errorf("ico: image size %dx%d", b.Dx(), b.Dy())
+	}
+	if !hotspot.In(image.Rect(0, 0, b.Dx(), b.Dy())) {
+		return 0, errors.Errorf("ico: hotspot %v outside %dx%d", hotspot, b.Dx(), b.Dy())
+	}
+	// without a color bitmap the mask holds the AND and XOR masks
+	mask, err := monoBitmap(b.Dx(), 2*b.Dy(), Monochrome(img))
+	if err != nil {
+		return 0, err
+	}
+	defer winapi.DeleteObject(win.HGDIOBJ(mask))
+
+	h, err := winapi.CreateIconIndirect(&win.ICONINFO{
+		XHotspot: uint32(hotspot.X),
+		YHotspot: uint32(hotspot.Y),
+		HbmMask:  mask,
+	})
+	return win.HCURSOR(h), err
+}
+
+// colorDisplay reports whether the screen has at least 16 bits per pixel.
+func colorDisplay() bool {
+	hdc := win.GetDC(0)
+	if hdc == 0 {
+		return true
+	}
+	defer win.ReleaseDC(0, hdc)
+	return win.GetDeviceCaps(hdc, win.BITSPIXEL)*win.GetDeviceCaps(hdc, win.PLANES) >= 16
+}
+
+// Load decodes an .ico or .cur file and creates an icon or cursor of
+// size pixels from it, depending on the file type.
+func Load(b []byte, size int) (win.HICON, error) {
+	f, err := Decode(b)
+	if err != nil {
+		return 0, err
+	}
+	if f.Type == TypeCursor {
+		h, err := f.HCURSOR(size)
+		return win.HICON(h), err
+	}
+	return f.HICON(size)
+}
@@ -0,0 +1,49 @@
+package ico
+
+import (
+	"image"
+	"image/color"
+)
+
+// ColorPlane returns the color bitmap of an icon or cursor as top-down
+// 32 bpp rows of straight alpha BGRA. Fully transparent pixels are black,
+// so XOR drawing through Mask leaves the screen untouched where alpha
+// blending is not available.
+func ColorPlane(img image.Image) []byte {
+	b := img.Bounds()
+	pix := make([]byte, b.Dx()*b.Dy()*4)
+	i := 0
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A != 0 {
+				pix[i+0], pix[i+1], pix[i+2], pix[i+3] = c.B, c.G, c.R, c.A
+			}
+			i += 4
+		}
+	}
+	return pix
+}
+
+// Monochrome returns the single bitmap of a monochrome cursor: the AND
+// mask followed by the XOR mask, twice the height of img in top-down rows
+// aligned to 2 bytes as CreateBitmap expects. Pixels less than half opaque
+// show the screen, the others are black or white by their luminance.
+func Monochrome(img image.Image) []byte {
+	b := img.Bounds()
+	stride := (b.Dx() + 15) / 16 * 2
+	and := make([]byte, 2*stride*b.Dy())
+	xor := and[stride*b.Dy():]
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			c := color.NRGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+			bit := byte(0x80 >> uint(x%8))
+			if c.A < 0x80 {
+				and[y*stride+x/8] |= bit
+			} else if (299*int(c.R)+587*int(c.G)+114*int(c.B))/1000 >= 0x80 {
+				xor[y*stride+x/8] |= bit
+			}
+		}
+	}
+	return and
+}
@@ -0,0 +1,122 @@
+package ico
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// planeImage returns a 10 x 2 image offset from the origin. The first
+// row runs from transparent to opaque, the second holds black, white,
+// gray and colored pixels.
+func planeImage() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(5, 7, 15, 9))
+	for x := 0; x < 10; x++ {
+		img.SetNRGBA(5+x, 7, color.NRGBA{0xFF, 0x80, 0x10, uint8(x * 0x1C)})
+	}
+	row := []color.NRGBA{
+		{0, 0, 0, 0xFF},
+		{0xFF, 0xFF, 0xFF, 0xFF},
+		{0x7F, 0x7F, 0x7F, 0xFF},
+		{0x80, 0x80, 0x80, 0xFF},
+		{0xFF, 0, 0, 0xFF},
+		{0, 0xFF, 0, 0xFF},
+		{0, 0, 0xFF, 0xFF},
+		{0xFF, 0xFF, 0, 0xFF},
+		{0xFF, 0xFF, 0xFF, 0x7F},
+		{0xFF, 0xFF, 0xFF, 0x80},
+	}
+	for x, c := range row {
+		img.SetNRGBA(5+x, 8, c)
+	}
+	return img
+}
+
+func TestColorPlane(t *testing.T) {
+	img := planeImage()
+	pix := ColorPlane(img)
+	if len(pix) != 10*2*4 {
+		t.Fatalf("%d bytes", len(pix))
+	}
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 10; x++ {
+			c := img.NRGBAAt(5+x, 7+y)
+			want := []byte{c.B, c.G, c.R, c.A}
+			// transparent pixels are black
+			if c.A == 0 {
+				want = []byte{0, 0, 0, 0}
+			}
+			if got := pix[(y*10+x)*4:][:4]; !bytes.Equal(got, want) {
+				t.Errorf("pixel (%d, %d) = % x, want % x", x, y, got, want)
+			}
+		}
+	}
+
+	// premultiplied sources are stored with straight alpha, rounded down
+	// as by color.NRGBAModel
+	rgba := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	rgba.SetRGBA(0, 0, color.RGBA{0x40, 0x20, 0x10, 0x80})
+	if got := ColorPlane(rgba); !bytes.Equal(got, []byte{0x1F, 0x3F, 0x7F, 0x80}) {
+		t.Errorf("premultiplied pixel = % x", got)
+	}
+}
+
+func TestMask(t *testing.T) {
+	img := planeImage()
+	tests := []struct {
+		align    int
+		bottomUp bool
+		want     []byte
+	}{
+		// only the first pixel of the first row is transparent
+		{4, false, []byte{0x80, 0, 0, 0, 0, 0, 0, 0}},
+		{4, true, []byte{0, 0, 0, 0, 0x80, 0, 0, 0}},
+		{2, false, []byte{0x80, 0, 0, 0}},
+		{2, true, []byte{0, 0, 0x80, 0}},
+		{1, false, []byte{0x80, 0, 0, 0}},
+	}
+	for _, tt := range tests {
+		if got := Mask(img, tt.align, tt.bottomUp); !bytes.Equal(got, tt.want) {
+			t.Errorf("Mask(align %d, bottom-up %v) = % x, want % x", tt.align, tt.bottomUp, got, tt.want)
+		}
+	}
+
+	// every transparent pixel sets its bit, rows are padded with zeros
+	clear := image.NewNRGBA(image.Rect(0, 0, 9, 3))
+	want := []byte{0xFF, 0x80, 0, 0, 0xFF, 0x80, 0, 0, 0xFF, 0x80, 0, 0}
+	if got := Mask(clear, 4, true); !bytes.Equal(got, want) {
+		t.Errorf("Mask of a transparent image = % x", got)
+	}
+}
+
+func TestMonochrome(t *testing.T) {
+	got := Monochrome(planeImage())
+	want := []byte{
+		// AND mask: pixels less than half opaque show the screen
+		0xF8, 0x00,
+		0x00, 0x80,
+		// XOR mask: opaque pixels are white when their luminance is
+		// at least half
+		0x07, 0xC0,
+		0x55, 0x40,
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Monochrome = % x, want % x", got, want)
+	}
+
+	// rows are aligned to 2 bytes and the planes follow each other
+	img := image.NewNRGBA(image.Rect(0, 0, 17, 2))
+	for x := 0; x < 17; x++ {
+		img.SetNRGBA(x, 1, color.NRGBA{0xFF, 0xFF, 0xFF, 0xFF})
+	}
+	want = []byte{
+		0xFF, 0xFF, 0x80, 0,
+		0, 0, 0, 0,
+		0, 0, 0, 0,
+		0xFF, 0xFF, 0x80, 0,
+	}
+	if got := Monochrome(img); !bytes.Equal(got, want) {
+		t.Errorf("Monochrome of 17 pixels = % x, want % x", got, want)
+	}
+}
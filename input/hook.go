@@ -0,0 +1,227 @@
+package input
+
+import (
+	"context"
+	"time"
+)
+
+// Window messages delivered to low-level hooks
+const (
+	WM_KEYDOWN     uint32 = 0x0100
+	WM_KEYUP       uint32 = 0x0101
+	WM_SYSKEYDOWN  uint32 = 0x0104
+	WM_SYSKEYUP    uint32 = 0x0105
+	WM_MOUSEMOVE   uint32 = 0x0200
+	WM_LBUTTONDOWN uint32 = 0x0201
+	WM_LBUTTONUP   uint32 = 0x0202
+	WM_RBUTTONDOWN uint32 = 0x0204
+	WM_RBUTTONUP   uint32 = 0x0205
+	WM_MBUTTONDOWN uint32 = 0x0207
+	WM_MBUTTONUP   uint32 = 0x0208
+	WM_MOUSEWHEEL  uint32 = 0x020A
+	WM_XBUTTONDOWN uint32 = 0x020B
+	WM_XBUTTONUP   uint32 = 0x020C
+	WM_MOUSEHWHEEL uint32 = 0x020E
+)
+
+// KBDLLHOOKSTRUCT Flags
+const (
+	LLKHF_EXTENDED          uint32 = 0x01
+	LLKHF_LOWER_IL_INJECTED uint32 = 0x02
+	LLKHF_INJECTED          uint32 = 0x10
+	LLKHF_ALTDOWN           uint32 = 0x20
+	LLKHF_UP                uint32 = 0x80
+)
+
+// MSLLHOOKSTRUCT Flags
+const (
+	LLMHF_INJECTED          uint32 = 0x01
+	LLMHF_LOWER_IL_INJECTED uint32 = 0x02
+)
+
+// KBDLLHOOKSTRUCT structure (winuser.h)
+// https://learn.microsoft.com/en-us/windows/win32/api/winuser/ns-winuser-kbdllhookstruct
+type KBDLLHOOKSTRUCT struct {
+	VkCode      uint32
+	ScanCode    uint32
+	Flags       uint32
+	Time        uint32
+	DwExtraInfo uintptr
+}
+
+// MSLLHOOKSTRUCT structure (winuser.h)
+// https://learn.microsoft.com/en-us/windows/win32/api/winuser/ns-winuser-msllhookstruct
+type MSLLHOOKSTRUCT struct {
+	X           int32
+	Y           int32
+	MouseData   uint32
+	Flags       uint32
+	Time        uint32
+	DwExtraInfo uintptr
+}
+
+// KeyboardEvent is a decoded WH_KEYBOARD_LL notification.
+type KeyboardEvent struct {
+	Message   uint32
+	Vk        uint16
+	Scan      uint16
+	Flags     uint32
+	Up        bool
+	Extended  bool
+	AltDown   bool
+	Injected  bool
+	ExtraInfo uintptr
+
+	// Time is the system uptime when the event was posted.
+	Time time.Duration
+}
+
+// DecodeKeyboard decodes the wParam and KBDLLHOOKSTRUCT of a keyboard hook call.
+func DecodeKeyboard(wParam uintptr, h *KBDLLHOOKSTRUCT) KeyboardEvent {
+	return KeyboardEvent{
+		Message:   uint32(wParam),
+		Vk:        uint16(h.VkCode),
+		Scan:      uint16(h.ScanCode),
+		Flags:     h.Flags,
+		Up:        h.Flags&LLKHF_UP != 0,
+		Extended:  h.Flags&LLKHF_EXTENDED != 0,
+		AltDown:   h.Flags&LLKHF_ALTDOWN != 0,
+		Injected:  h.Flags&(LLKHF_INJECTED|LLKHF_LOWER_IL_INJECTED) != 0,
+		ExtraInfo: h.DwExtraInfo,
+		Time:      time.Duration(h.Time) * time.Millisecond,
+	}
+}
+
+// MouseEvent is a decoded WH_MOUSE_LL notification.
+type MouseEvent struct {
+	Message   uint32
+	X         int32
+	Y         int32
+	MouseData uint32
+	Flags     uint32
+	Injected  bool
+	ExtraInfo uintptr
+
+	// Button is the button pressed or released, Down tells which of both.
+	Button Button
+	Down   bool
+
+	// WheelDelta is the signed rotation of WM_MOUSEWHEEL and WM_MOUSEHWHEEL
+	// in multiples of WHEEL_DELTA.
+	WheelDelta int16
+
+	// Time is the system uptime when the event was posted.
+	Time time.Duration
+}
+
+// DecodeMouse decodes the wParam and MSLLHOOKSTRUCT of a mouse hook call.
+func DecodeMouse(wParam uintptr, h *MSLLHOOKSTRUCT) MouseEvent {
+	ev := MouseEvent{
+		Message:   uint32(wParam),
+		X:         h.X,
+		Y:         h.Y,
+		MouseData: h.MouseData,
+		Flags:     h.Flags,
+		Injected:  h.Flags&(LLMHF_INJECTED|LLMHF_LOWER_IL_INJECTED) != 0,
+		ExtraInfo: h.DwExtraInfo,
+		Time:      time.Duration(h.Time) * time.Millisecond,
+	}
+
+	switch ev.Message {
+	case WM_LBUTTONDOWN, WM_LBUTTONUP:
+		ev.Button = LeftButton
+	case WM_RBUTTONDOWN, WM_RBUTTONUP:
+		ev.Button = RightButton
+	case WM_MBUTTONDOWN, WM_MBUTTONUP:
+		ev.Button = MiddleButton
+	case WM_XBUTTONDOWN, WM_XBUTTONUP:
+		ev.Button = XButton1
+		if h.MouseData>>16 == XBUTTON2 {
+			ev.Button = XButton2
+		}
+	case WM_MOUSEWHEEL, WM_MOUSEHWHEEL:
+		ev.WheelDelta = int16(h.MouseData >> 16)
+	}
+
+	switch ev.Message {
+	case WM_LBUTTONDOWN, WM_RBUTTONDOWN, WM_MBUTTONDOWN, WM_XBUTTONDOWN:
+		ev.Down = true
+	}
+	return ev
+}
+
+// Hook configures how low-level hook handlers are called. Windows removes a
+// hook silently when its callback exceeds LowLevelHooksTimeout, so user code
+// should not run unbounded on the hook thread.
+type Hook struct {
+	// Async queues events to a separate goroutine. Async handlers cannot
+	// swallow events, their return value is ignored.
+	Async bool
+
+	// Buffer is the async queue length, events are dropped when it is full.
+	Buffer int
+
+	// Timeout bounds a synchronous handler. An event whose handler does not
+	// return in time is passed on. Zero calls the handler on the hook thread.
+	Timeout time.Duration
+}
+
+// dispatcher decides whether an event is swallowed according to a Hook.
+type dispatcher struct {
+	hook  Hook
+	queue chan func() bool
+}
+
+func newDispatcher(h Hook) *dispatcher {
+	d := &dispatcher{hook: h}
+	if h.Async {
+		n := h.Buffer
+		if n <= 0 {
+			n = 256
+		}
+		d.queue = make(chan func() bool, n)
+	}
+	return d
+}
+
+// dispatch runs call and reports whether the event should be swallowed.
+func (d *dispatcher) dispatch(call func() bool) bool {
+	switch {
+	case d.hook.Async:
+		select {
+		case d.queue <- call:
+		default:
+		}
+		return false
+	case d.hook.Timeout <= 0:
+		return call()
+	}
+
+	res := make(chan bool, 1)
+	go func() {
+		res <- call()
+	}()
+	t := time.NewTimer(d.hook.Timeout)
+	defer t.Stop()
+	select {
+	case swallow := <-res:
+		return swallow
+	case <-t.C:
+		return false
+	}
+}
+
+// run delivers queued async events until ctx is done.
+func (d *dispatcher) run(ctx context.Context) {
+	if d.queue == nil {
+		return
+	}
+	for {
+		select {
+		case call := <-d.queue:
+			call()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package input
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDecodeKeyboard(t *testing.T) {
+	ev := DecodeKeyboard(uintptr(WM_SYSKEYUP), &KBDLLHOOKSTRUCT{
+		VkCode:      uint32(VK_RMENU),
+		ScanCode:    0x38,
+		Flags:       LLKHF_EXTENDED | LLKHF_ALTDOWN | LLKHF_UP | LLKHF_LOWER_IL_INJECTED,
+		Time:        1500,
+		DwExtraInfo: 42,
+	})
+	want := KeyboardEvent{
+		Message:   WM_SYSKEYUP,
+		Vk:        VK_RMENU,
+		Scan:      0x38,
+		Flags:     LLKHF_EXTENDED | LLKHF_ALTDOWN | LLKHF_UP | LLKHF_LOWER_IL_INJECTED,
+		Up:        true,
+		Extended:  true,
+		AltDown:   true,
+		Injected:  true,
+		ExtraInfo: 42,
+		Time:      1500 * time.Millisecond,
+	}
+	if ev != want {
+		t.Errorf("DecodeKeyboard\n got %+v\nwant %+v", ev, want)
+	}
+}
+
+func TestDecodeMouse(t *testing.T) {
+	tests := []struct {
+		msg    uint32
+		data   uint32
+		button Button
+		down   bool
+		wheel  int16
+	}{
+		{WM_LBUTTONDOWN, 0, LeftButton, true, 0},
+		{WM_RBUTTONUP, 0, RightButton, false, 0},
+		{WM_MBUTTONDOWN, 0, MiddleButton, true, 0},
+		{WM_XBUTTONDOWN, XBUTTON1 << 16, XButton1, true, 0},
+		{WM_XBUTTONUP, XBUTTON2 << 16, XButton2, false, 0},
+		{WM_MOUSEWHEEL, 0xFF88 << 16, LeftButton, false, -120},
+		{WM_MOUSEHWHEEL, 240 << 16, LeftButton, false, 240},
+	}
+	for _, tt := range tests {
+		ev := DecodeMouse(uintptr(tt.msg), &MSLLHOOKSTRUCT{X: -5, Y: 7, MouseData: tt.data, Flags: LLMHF_INJECTED})
+		if ev.Button != tt.button || ev.Down != tt.down || ev.WheelDelta != tt.wheel {
+			t.Errorf("message %#x: button %d down %v wheel %d, want %d %v %d",
+				tt.msg, ev.Button, ev.Down, ev.WheelDelta, tt.button, tt.down, tt.wheel)
+		}
+		if ev.X != -5 || ev.Y != 7 || !ev.Injected {
+			t.Errorf("message %#x: %+v", tt.msg, ev)
+		}
+	}
+}
+
+func TestDispatchSync(t *testing.T) {
+	d := newDispatcher(Hook{})
+	if !d.dispatch(func() bool { return true }) {
+		t.Error("synchronous handler should swallow")
+	}
+	if d.dispatch(func() bool { return false }) {
+		t.Error("synchronous handler should pass")
+	}
+}
+
+func TestDispatchTimeout(t *testing.T) {
+	d := newDispatcher(Hook{Timeout: 10 * time.Millisecond})
+	if !d.dispatch(func() bool { return true }) {
+		t.Error("fast handler should swallow")
+	}
+	release := make(chan struct{})
+	defer close(release)
+	if d.dispatch(func() bool { <-release; return true }) {
+		t.Error("late handler should pass the event on")
+	}
+}
+
+func TestDispatchAsync(t *testing.T) {
+	d := newDispatcher(Hook{Async: true, Buffer: 2})
+	ran := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		i := i
+		if d.dispatch(func() bool { ran <- i; return true }) {
+			t.Error("async handler must not swallow")
+		}
+	}
+	// the third event is dropped since nothing drains the queue yet
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.run(ctx)
+		close(done)
+	}()
+	for want := 0; want < 2; want++ {
+		if got := <-ran; got != want {
+			t.Errorf("event %d delivered as %d", want, got)
+		}
+	}
+	cancel()
+	<-done
+	if len(ran) != 0 {
+		t.Errorf("dropped event was delivered")
+	}
+}
@@ -0,0 +1,133 @@
+package input
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi"
+	"golang.org/x/sys/windows"
+)
+
+type hookState struct {
+	hhk      winapi.HHOOK
+	dispatch func(wParam, lParam uintptr) bool
+}
+
+var (
+	hooks        sync.Map // thread id -> *hookState
+	hookProc     uintptr
+	hookProcOnce sync.Once
+)
+
+func lowLevelHookProc(nCode int, wParam, lParam uintptr) uintptr {
+	v, ok := hooks.Load(windows.GetCurrentThreadId())
+	if !ok {
+		return winapi.CallNextHookEx(0, nCode, wParam, lParam)
+	}
+	s := v.(*hookState)
+	if nCode == winapi.HC_ACTION && s.dispatch(wParam, lParam) {
+		return 1
+	}
+	return winapi.CallNextHookEx(s.hhk, nCode, wParam, lParam)
+}
+
+// run installs a low-level hook on a locked thread and pumps messages
+// until ctx is done, then returns ctx.Err().
+func (h Hook) run(ctx context.Context, idHook int, dispatch func(d *dispatcher, wParam, lParam uintptr) bool) error {
+	hookProcOnce.Do(func() {
+		hookProc = syscall.NewCallback(lowLevelHookProc)
+	})
+
+	d := newDispatcher(h)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go d.run(ctx)
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	tid := windows.GetCurrentThreadId()
+	s := &hookState{dispatch: func(wParam, lParam uintptr) bool {
+		return dispatch(d, wParam, lParam)
+	}}
+	hooks.Store(tid, s)
+	defer hooks.Delete(tid)
+
+	// create the message queue so WM_QUIT can be posted from the start
+	var msg win.MSG
+	win.PeekMessage(&msg, 0, 0, 0, win.PM_NOREMOVE)
+
+	hhk, err := winapi.SetWindowsHookEx(idHook, hookProc, win.GetModuleHandle(nil), 0)
+	if err != nil {
+		return errors.Wrap(err, "SetWindowsHookEx")
+	}
+	s.hhk = hhk
+	var unhook sync.Once
+	defer unhook.Do(func() { winapi.UnhookWindowsHookEx(hhk) })
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+			return
+		}
+		for winapi.PostThreadMessage(tid, win.WM_QUIT, 0, 0) != nil {
+			// the queue is full: stop receiving events right away and
+			// keep trying until the loop ends
+			unhook.Do(func() { winapi.UnhookWindowsHookEx(hhk) })
+			select {
+			case <-done:
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}()
+
+	for {
+		switch win.GetMessage(&msg, 0, 0, 0) {
+		case 0:
+			// WM_QUIT posted on cancellation or by the caller
+			return ctx.Err()
+		case -1:
+			return errors.Wrap(windows.GetLastError(), "GetMessage")
+		}
+		win.TranslateMessage(&msg)
+		win.DispatchMessage(&msg)
+	}
+}
+
+// Keyboard installs a WH_KEYBOARD_LL hook and blocks until ctx is done,
+// returning ctx.Err(). fn returns true to swallow the event.
+func (h Hook) Keyboard(ctx context.Context, fn func(KeyboardEvent) bool) error {
+	return h.run(ctx, winapi.WH_KEYBOARD_LL, func(d *dispatcher, wParam, lParam uintptr) bool {
+		ev := DecodeKeyboard(wParam, *(**KBDLLHOOKSTRUCT)(unsafe.Pointer(&lParam)))
+		return d.dispatch(func() bool { return fn(ev) })
+	})
+}
+
+// Mouse installs a WH_MOUSE_LL hook and blocks until ctx is done,
+// returning ctx.Err(). fn returns true to swallow the event.
+func (h Hook) Mouse(ctx context.Context, fn func(MouseEvent) bool) error {
+	return h.run(ctx, winapi.WH_MOUSE_LL, func(d *dispatcher, wParam, lParam uintptr) bool {
+		ev := DecodeMouse(wParam, *(**MSLLHOOKSTRUCT)(unsafe.Pointer(&lParam)))
+		return d.dispatch(func() bool { return fn(ev) })
+	})
+}
+
+// HookKeyboard calls fn synchronously for every keyboard event until ctx is done.
+func HookKeyboard(ctx context.Context, fn func(KeyboardEvent) bool) error {
+	return Hook{}.Keyboard(ctx, fn)
+}
+
+// HookMouse calls fn synchronously for every mouse event until ctx is done.
+func HookMouse(ctx context.Context, fn func(MouseEvent) bool) error {
+	return Hook{}.Mouse(ctx, fn)
+}
@@ -0,0 +1,253 @@
+package input
+
+import (
+	"unicode/utf16"
+)
+
+// INPUT Type
+const (
+	INPUT_MOUSE    uint32 = 0
+	INPUT_KEYBOARD uint32 = 1
+)
+
+// KEYBDINPUT DwFlags
+const (
+	KEYEVENTF_EXTENDEDKEY uint32 = 0x0001
+	KEYEVENTF_KEYUP       uint32 = 0x0002
+	KEYEVENTF_UNICODE     uint32 = 0x0004
+	KEYEVENTF_SCANCODE    uint32 = 0x0008
+)
+
+// MOUSEINPUT DwFlags
+const (
+	MOUSEEVENTF_MOVE            uint32 = 0x0001
+	MOUSEEVENTF_LEFTDOWN        uint32 = 0x0002
+	MOUSEEVENTF_LEFTUP          uint32 = 0x0004
+	MOUSEEVENTF_RIGHTDOWN       uint32 = 0x0008
+	MOUSEEVENTF_RIGHTUP         uint32 = 0x0010
+	MOUSEEVENTF_MIDDLEDOWN      uint32 = 0x0020
+	MOUSEEVENTF_MIDDLEUP        uint32 = 0x0040
+	MOUSEEVENTF_XDOWN           uint32 = 0x0080
+	MOUSEEVENTF_XUP             uint32 = 0x0100
+	MOUSEEVENTF_WHEEL           uint32 = 0x0800
+	MOUSEEVENTF_HWHEEL          uint32 = 0x1000
+	MOUSEEVENTF_MOVE_NOCOALESCE uint32 = 0x2000
+	MOUSEEVENTF_VIRTUALDESK     uint32 = 0x4000
+	MOUSEEVENTF_ABSOLUTE        uint32 = 0x8000
+)
+
+const (
+	WHEEL_DELTA = 120
+	XBUTTON1    = 0x0001
+	XBUTTON2    = 0x0002
+)
+
+// Input is a platform independent description of one INPUT structure.
+type Input struct {
+	Type uint32
+
+	// keyboard events
+	Vk   uint16
+	Scan uint16
+
+	// mouse events
+	DX        int32
+	DY        int32
+	MouseData uint32
+
+	Flags uint32
+}
+
+// Button is a mouse button.
+type Button int
+
+const (
+	LeftButton Button = iota
+	RightButton
+	MiddleButton
+	XButton1
+	XButton2
+)
+
+// Rect is a rectangle in virtual desktop coordinates, right and bottom exclusive.
+type Rect struct {
+	Left, Top, Right, Bottom int32
+}
+
+// Absolute converts a virtual desktop coordinate to the 0..65535 range
+// expected by MOUSEEVENTF_ABSOLUTE|MOUSEEVENTF_VIRTUALDESK.
+func Absolute(x, y int32, desktop Rect) (dx, dy int32) {
+	return normalize(x, desktop.Left, desktop.Right), normalize(y, desktop.Top, desktop.Bottom)
+}
+
+func normalize(v, lo, hi int32) int32 {
+	if hi-lo <= 1 {
+		return 0
+	}
+	if v < lo {
+		v = lo
+	}
+	if v > hi-1 {
+		v = hi - 1
+	}
+	return int32((int64(v-lo)*65535 + int64(hi-lo-1)/2) / int64(hi-lo-1))
+}
+
+// Builder assembles a sequence of inputs for SendInput.
+type Builder struct {
+	// ScanCode maps a virtual key to its scan code, usually via
+	// MapVirtualKey(vk, MAPVK_VK_TO_VSC). A nil ScanCode leaves Scan zero.
+	ScanCode func(vk uint16) uint16
+
+	// UseScanCodes sends KEYEVENTF_SCANCODE key events for applications
+	// reading the hardware scan code instead of the virtual key.
+	UseScanCodes bool
+
+	// Desktop is the virtual desktop used to normalize absolute mouse positions.
+	Desktop Rect
+
+	inputs []Input
+}
+
+// Inputs returns the inputs built so far.
+func (b *Builder) Inputs() []Input {
+	return b.inputs
+}
+
+// Reset discards the inputs built so far.
+func (b *Builder) Reset() {
+	b.inputs = b.inputs[:0]
+}
+
+// Key appends a single key down or up event.
+func (b *Builder) Key(vk uint16, up bool) *Builder {
+	in := Input{Type: INPUT_KEYBOARD, Vk: vk}
+	if b.ScanCode != nil {
+		in.Scan = b.ScanCode(vk)
+	}
+	if b.UseScanCodes && in.Scan != 0 {
+		in.Vk = 0
+		in.Flags |= KEYEVENTF_SCANCODE
+	}
+	if IsExtendedKey(vk) {
+		in.Flags |= KEYEVENTF_EXTENDEDKEY
+	}
+	if up {
+		in.Flags |= KEYEVENTF_KEYUP
+	}
+	b.inputs = append(b.inputs, in)
+	return b
+}
+
+// Tap appends a key press and release.
+func (b *Builder) Tap(vk uint16) *Builder {
+	return b.Key(vk, false).Key(vk, true)
+}
+
+// Chord presses the modifiers, taps the key and releases the modifiers in reverse order.
+func (b *Builder) Chord(c Chord) *Builder {
+	mods := c.ModifierKeys()
+	for _, vk := range mods {
+		b.Key(vk, false)
+	}
+	if c.Key != 0 {
+		b.Tap(c.Key)
+	}
+	for i := len(mods) - 1; i >= 0; i-- {
+		b.Key(mods[i], true)
+	}
+	return b
+}
+
+// Text types s with KEYEVENTF_UNICODE events, one down/up pair per UTF-16 code unit.
+// Line breaks and tabs are sent as VK_RETURN and VK_TAB since many controls ignore
+// them as characters.
+func (b *Builder) Text(s string) *Builder {
+	runes := []rune(s)
+	for i, r := range runes {
+		switch r {
+		case '\r':
+			if i+1 < len(runes) && runes[i+1] == '\n' {
+				continue
+			}
+			b.Tap(VK_RETURN)
+		case '\n':
+			b.Tap(VK_RETURN)
+		case '\t':
+			b.Tap(VK_TAB)
+		default:
+			for _, unit := range utf16.Encode([]rune{r}) {
+				b.inputs = append(b.inputs,
+					Input{Type: INPUT_KEYBOARD, Scan: unit, Flags: KEYEVENTF_UNICODE},
+					Input{Type: INPUT_KEYBOARD, Scan: unit, Flags: KEYEVENTF_UNICODE | KEYEVENTF_KEYUP},
+				)
+			}
+		}
+	}
+	return b
+}
+
+// MoveTo appends an absolute move to (x, y) in virtual desktop coordinates.
+func (b *Builder) MoveTo(x, y int32) *Builder {
+	dx, dy := Absolute(x, y, b.Desktop)
+	b.inputs = append(b.inputs, Input{
+		Type:  INPUT_MOUSE,
+		DX:    dx,
+		DY:    dy,
+		Flags: MOUSEEVENTF_MOVE | MOUSEEVENTF_ABSOLUTE | MOUSEEVENTF_VIRTUALDESK,
+	})
+	return b
+}
+
+// Button appends a button down or up event.
+func (b *Builder) Button(button Button, up bool) *Builder {
+	in := Input{Type: INPUT_MOUSE}
+	switch button {
+	case LeftButton:
+		in.Flags = MOUSEEVENTF_LEFTDOWN
+	case RightButton:
+		in.Flags = MOUSEEVENTF_RIGHTDOWN
+	case MiddleButton:
+		in.Flags = MOUSEEVENTF_MIDDLEDOWN
+	case XButton1:
+		in.Flags, in.MouseData = MOUSEEVENTF_XDOWN, XBUTTON1
+	case XButton2:
+		in.Flags, in.MouseData = MOUSEEVENTF_XDOWN, XBUTTON2
+	}
+	// every *UP flag is the *DOWN flag shifted by one
+	if up {
+		in.Flags <<= 1
+	}
+	b.inputs = append(b.inputs, in)
+	return b
+}
+
+// Click appends a press and release of button.
+func (b *Builder) Click(button Button) *Builder {
+	return b.Button(button, false).Button(button, true)
+}
+
+// Drag presses button at from, moves to to in steps and releases it there.
+func (b *Builder) Drag(button Button, fromX, fromY, toX, toY int32, steps int) *Builder {
+	if steps < 1 {
+		steps = 1
+	}
+	b.MoveTo(fromX, fromY).Button(button, false)
+	for i := 1; i <= steps; i++ {
+		x := fromX + int32(int64(toX-fromX)*int64(i)/int64(steps))
+		y := fromY + int32(int64(toY-fromY)*int64(i)/int64(steps))
+		b.MoveTo(x, y)
+	}
+	return b.Button(button, true)
+}
+
+// Scroll appends a wheel event of clicks notches, positive is away from the user
+// (or to the right when horizontal).
+func (b *Builder) Scroll(clicks int, horizontal bool) *Builder {
+	in := Input{Type: INPUT_MOUSE, MouseData: uint32(int32(clicks * WHEEL_DELTA)), Flags: MOUSEEVENTF_WHEEL}
+	if horizontal {
+		in.Flags = MOUSEEVENTF_HWHEEL
+	}
+	b.inputs = append(b.inputs, in)
+	return b
+}
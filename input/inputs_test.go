@@ -0,0 +1,84 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuilderChord(t *testing.T) {
+	b := &Builder{ScanCode: func(vk uint16) uint16 { return vk + 0x100 }}
+	b.Chord(Chord{ModCtrl | ModShift, VK_DELETE})
+
+	kd := func(vk uint16, flags uint32) Input {
+		return Input{Type: INPUT_KEYBOARD, Vk: vk, Scan: vk + 0x100, Flags: flags}
+	}
+	want := []Input{
+		kd(VK_CONTROL, 0),
+		kd(VK_SHIFT, 0),
+		kd(VK_DELETE, KEYEVENTF_EXTENDEDKEY),
+		kd(VK_DELETE, KEYEVENTF_EXTENDEDKEY|KEYEVENTF_KEYUP),
+		kd(VK_SHIFT, KEYEVENTF_KEYUP),
+		kd(VK_CONTROL, KEYEVENTF_KEYUP),
+	}
+	if got := b.Inputs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Chord inputs\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestBuilderScanCodes(t *testing.T) {
+	b := &Builder{ScanCode: func(vk uint16) uint16 { return 0x1E }, UseScanCodes: true}
+	b.Key('A', false)
+	want := Input{Type: INPUT_KEYBOARD, Scan: 0x1E, Flags: KEYEVENTF_SCANCODE}
+	if got := b.Inputs()[0]; got != want {
+		t.Errorf("Key = %+v, want %+v", got, want)
+	}
+}
+
+func TestBuilderText(t *testing.T) {
+	var b Builder
+	b.Text("a\r\n😀")
+	got := b.Inputs()
+	// one pair for 'a', a tapped VK_RETURN and a surrogate pair
+	if len(got) != 8 {
+		t.Fatalf("got %d inputs: %+v", len(got), got)
+	}
+	if got[0].Scan != 'a' || got[0].Flags != KEYEVENTF_UNICODE || got[1].Flags != KEYEVENTF_UNICODE|KEYEVENTF_KEYUP {
+		t.Errorf("'a' = %+v %+v", got[0], got[1])
+	}
+	if got[2].Vk != VK_RETURN || got[3].Vk != VK_RETURN {
+		t.Errorf("line break = %+v %+v", got[2], got[3])
+	}
+	if got[4].Scan != 0xD83D || got[6].Scan != 0xDE00 {
+		t.Errorf("surrogates = %#x %#x", got[4].Scan, got[6].Scan)
+	}
+}
+
+func TestBuilderMouse(t *testing.T) {
+	b := &Builder{Desktop: Rect{Left: -1920, Top: 0, Right: 1920, Bottom: 1080}}
+	b.MoveTo(-1920, 1079).Click(XButton2).Scroll(-2, false)
+	got := b.Inputs()
+	want := []Input{
+		{Type: INPUT_MOUSE, DX: 0, DY: 65535, Flags: MOUSEEVENTF_MOVE | MOUSEEVENTF_ABSOLUTE | MOUSEEVENTF_VIRTUALDESK},
+		{Type: INPUT_MOUSE, MouseData: XBUTTON2, Flags: MOUSEEVENTF_XDOWN},
+		{Type: INPUT_MOUSE, MouseData: XBUTTON2, Flags: MOUSEEVENTF_XUP},
+		{Type: INPUT_MOUSE, MouseData: uint32(0xFFFFFF10), Flags: MOUSEEVENTF_WHEEL},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mouse inputs\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestAbsolute(t *testing.T) {
+	d := Rect{Left: 0, Top: 0, Right: 1001, Bottom: 2}
+	tests := []struct{ x, y, dx, dy int32 }{
+		{0, 0, 0, 0},
+		{1000, 1, 65535, 65535},
+		{500, 5, 32768, 65535},
+		{-5, -5, 0, 0},
+	}
+	for _, tt := range tests {
+		if dx, dy := Absolute(tt.x, tt.y, d); dx != tt.dx || dy != tt.dy {
+			t.Errorf("Absolute(%d, %d) = %d, %d, want %d, %d", tt.x, tt.y, dx, dy, tt.dx, tt.dy)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Virtual-Key Codes
+// https://learn.microsoft.com/en-us/windows/win32/inputdev/virtual-key-codes
+
+const (
+	VK_LBUTTON          uint16 = 0x01
+	VK_RBUTTON          uint16 = 0x02
+	VK_CANCEL           uint16 = 0x03
+	VK_MBUTTON          uint16 = 0x04
+	VK_XBUTTON1         uint16 = 0x05
+	VK_XBUTTON2         uint16 = 0x06
+	VK_BACK             uint16 = 0x08
+	VK_TAB              uint16 = 0x09
+	VK_CLEAR            uint16 = 0x0C
+	VK_RETURN           uint16 = 0x0D
+	VK_SHIFT            uint16 = 0x10
+	VK_CONTROL          uint16 = 0x11
+	VK_MENU             uint16 = 0x12
+	VK_PAUSE            uint16 = 0x13
+	VK_CAPITAL          uint16 = 0x14
+	VK_ESCAPE           uint16 = 0x1B
+	VK_SPACE            uint16 = 0x20
+	VK_PRIOR            uint16 = 0x21
+	VK_NEXT             uint16 = 0x22
+	VK_END              uint16 = 0x23
+	VK_HOME             uint16 = 0x24
+	VK_LEFT             uint16 = 0x25
+	VK_UP               uint16 = 0x26
+	VK_RIGHT            uint16 = 0x27
+	VK_DOWN             uint16 = 0x28
+	VK_SELECT           uint16 = 0x29
+	VK_PRINT            uint16 = 0x2A
+	VK_EXECUTE          uint16 = 0x2B
+	VK_SNAPSHOT         uint16 = 0x2C
+	VK_INSERT           uint16 = 0x2D
+	VK_DELETE           uint16 = 0x2E
+	VK_HELP             uint16 = 0x2F
+	VK_LWIN             uint16 = 0x5B
+	VK_RWIN             uint16 = 0x5C
+	VK_APPS             uint16 = 0x5D
+	VK_SLEEP            uint16 = 0x5F
+	VK_NUMPAD0          uint16 = 0x60
+	VK_MULTIPLY         uint16 = 0x6A
+	VK_ADD              uint16 = 0x6B
+	VK_SEPARATOR        uint16 = 0x6C
+	VK_SUBTRACT         uint16 = 0x6D
+	VK_DECIMAL          uint16 = 0x6E
+	VK_DIVIDE           uint16 = 0x6F
+	VK_F1               uint16 = 0x70
+	VK_NUMLOCK          uint16 = 0x90
+	VK_SCROLL           uint16 = 0x91
+	VK_LSHIFT           uint16 = 0xA0
+	VK_RSHIFT           uint16 = 0xA1
+	VK_LCONTROL         uint16 = 0xA2
+	VK_RCONTROL         uint16 = 0xA3
+	VK_LMENU            uint16 = 0xA4
+	VK_RMENU            uint16 = 0xA5
+	VK_VOLUME_MUTE      uint16 = 0xAD
+	VK_VOLUME_DOWN      uint16 = 0xAE
+	VK_VOLUME_UP        uint16 = 0xAF
+	VK_MEDIA_NEXT_TRACK uint16 = 0xB0
+	VK_MEDIA_PREV_TRACK uint16 = 0xB1
+	VK_MEDIA_STOP       uint16 = 0xB2
+	VK_MEDIA_PLAY_PAUSE uint16 = 0xB3
+	VK_OEM_1            uint16 = 0xBA
+	VK_OEM_PLUS         uint16 = 0xBB
+	VK_OEM_COMMA        uint16 = 0xBC
+	VK_OEM_MINUS        uint16 = 0xBD
+	VK_OEM_PERIOD       uint16 = 0xBE
+	VK_OEM_2            uint16 = 0xBF
+	VK_OEM_3            uint16 = 0xC0
+	VK_OEM_4            uint16 = 0xDB
+	VK_OEM_5            uint16 = 0xDC
+	VK_OEM_6            uint16 = 0xDD
+	VK_OEM_7            uint16 = 0xDE
+)
+
+// Modifier is a bit set of the modifier keys held for a chord.
+// The values match the MOD_* flags of RegisterHotKey.
+type Modifier uint32
+
+const (
+	ModAlt Modifier = 1 << iota
+	ModCtrl
+	ModShift
+	ModWin
+)
+
+// modifierKeys lists modifiers in the order they are pressed.
+var modifierKeys = []struct {
+	mod Modifier
+	vk  uint16
+}{
+	{ModCtrl, VK_CONTROL},
+	{ModAlt, VK_MENU},
+	{ModShift, VK_SHIFT},
+	{ModWin, VK_LWIN},
+}
+
+var modifierNames = map[string]Modifier{
+	"ctrl":    ModCtrl,
+	"control": ModCtrl,
+	"alt":     ModAlt,
+	"menu":    ModAlt,
+	"shift":   ModShift,
+	"win":     ModWin,
+	"windows": ModWin,
+	"super":   ModWin,
+}
+
+var keyNames = map[string]uint16{
+	"backspace":   VK_BACK,
+	"back":        VK_BACK,
+	"tab":         VK_TAB,
+	"clear":       VK_CLEAR,
+	"enter":       VK_RETURN,
+	"return":      VK_RETURN,
+	"pause":       VK_PAUSE,
+	"break":       VK_PAUSE,
+	"capslock":    VK_CAPITAL,
+	"esc":         VK_ESCAPE,
+	"escape":      VK_ESCAPE,
+	"space":       VK_SPACE,
+	"pageup":      VK_PRIOR,
+	"pgup":        VK_PRIOR,
+	"pagedown":    VK_NEXT,
+	"pgdn":        VK_NEXT,
+	"end":         VK_END,
+	"home":        VK_HOME,
+	"left":        VK_LEFT,
+	"up":          VK_UP,
+	"right":       VK_RIGHT,
+	"down":        VK_DOWN,
+	"select":      VK_SELECT,
+	"print":       VK_PRINT,
+	"execute":     VK_EXECUTE,
+	"printscreen": VK_SNAPSHOT,
+	"prtsc":       VK_SNAPSHOT,
+	"insert":      VK_INSERT,
+	"ins":         VK_INSERT,
+	"delete":      VK_DELETE,
+	"del":         VK_DELETE,
+	"help":        VK_HELP,
+	"lwin":        VK_LWIN,
+	"rwin":        VK_RWIN,
+	"apps":        VK_APPS,
+	"menukey":     VK_APPS,
+	"sleep":       VK_SLEEP,
+	"multiply":    VK_MULTIPLY,
+	"add":         VK_ADD,
+	"separator":   VK_SEPARATOR,
+	"subtract":    VK_SUBTRACT,
+	"decimal":     VK_DECIMAL,
+	"divide":      VK_DIVIDE,
+	"numlock":     VK_NUMLOCK,
+	"scrolllock":  VK_SCROLL,
+	"lshift":      VK_LSHIFT,
+	"rshift":      VK_RSHIFT,
+	"lctrl":       VK_LCONTROL,
+	"rctrl":       VK_RCONTROL,
+	"lalt":        VK_LMENU,
+	"ralt":        VK_RMENU,
+	"volumemute":  VK_VOLUME_MUTE,
+	"volumedown":  VK_VOLUME_DOWN,
+	"volumeup":    VK_VOLUME_UP,
+	"medianext":   VK_MEDIA_NEXT_TRACK,
+	"mediaprev":   VK_MEDIA_PREV_TRACK,
+	"mediastop":   VK_MEDIA_STOP,
+	"mediaplay":   VK_MEDIA_PLAY_PAUSE,
+	";":           VK_OEM_1,
+	"=":           VK_OEM_PLUS,
+	"plus":        VK_OEM_PLUS,
+	",":           VK_OEM_COMMA,
+	"-":           VK_OEM_MINUS,
+	"minus":       VK_OEM_MINUS,
+	".":           VK_OEM_PERIOD,
+	"/":           VK_OEM_2,
+	"`":           VK_OEM_3,
+	"[":           VK_OEM_4,
+	"\\":          VK_OEM_5,
+	"]":           VK_OEM_6,
+	"'":           VK_OEM_7,
+}
+
+// extendedKeys need KEYEVENTF_EXTENDEDKEY when sent by scan code.
+var extendedKeys = map[uint16]bool{
+	VK_RMENU:    true,
+	VK_RCONTROL: true,
+	VK_INSERT:   true,
+	VK_DELETE:   true,
+	VK_HOME:     true,
+	VK_END:      true,
+	VK_PRIOR:    true,
+	VK_NEXT:     true,
+	VK_LEFT:     true,
+	VK_UP:       true,
+	VK_RIGHT:    true,
+	VK_DOWN:     true,
+	VK_NUMLOCK:  true,
+	VK_SNAPSHOT: true,
+	VK_DIVIDE:   true,
+	VK_LWIN:     true,
+	VK_RWIN:     true,
+	VK_APPS:     true,
+}
+
+// IsExtendedKey reports whether vk is on the extended part of the keyboard.
+func IsExtendedKey(vk uint16) bool {
+	return extendedKeys[vk]
+}
+
+// Chord is a key combination such as "Ctrl+Shift+Esc".
+type Chord struct {
+	Modifiers Modifier
+	Key       uint16
+}
+
+// ModifierKeys returns the virtual keys of the chord modifiers in press order.
+func (c Chord) ModifierKeys() []uint16 {
+	var keys []uint16
+	for _, m := range modifierKeys {
+		if c.Modifiers&m.mod != 0 {
+			keys = append(keys, m.vk)
+		}
+	}
+	return keys
+}
+
+func (c Chord) String() string {
+	var parts []string
+	for _, m := range modifierKeys {
+		if c.Modifiers&m.mod != 0 {
+			parts = append(parts, modifierString(m.mod))
+		}
+	}
+	if c.Key != 0 {
+		parts = append(parts, KeyName(c.Key))
+	}
+	return strings.Join(parts, "+")
+}
+
+func modifierString(m Modifier) string {
+	switch m {
+	case ModCtrl:
+		return "Ctrl"
+	case ModAlt:
+		return "Alt"
+	case ModShift:
+		return "Shift"
+	case ModWin:
+		return "Win"
+	}
+	return ""
+}
+
+// KeyName returns a name for vk that ParseKey accepts.
+func KeyName(vk uint16) string {
+	switch {
+	case vk >= '0' && vk <= '9', vk >= 'A' && vk <= 'Z':
+		return string(rune(vk))
+	case vk >= VK_F1 && vk <= VK_F1+23:
+		return fmt.Sprintf("F%d", vk-VK_F1+1)
+	case vk >= VK_NUMPAD0 && vk <= VK_NUMPAD0+9:
+		return fmt.Sprintf("Num%d", vk-VK_NUMPAD0)
+	}
+	var best string
+	for name, code := range keyNames {
+		// prefer the longest alias, it is usually the most readable one
+		if code == vk && (len(name) > len(best) || len(name) == len(best) && name < best) {
+			best = name
+		}
+	}
+	if best != "" {
+		return strings.ToUpper(best[:1]) + best[1:]
+	}
+	return fmt.Sprintf("0x%02X", vk)
+}
+
+// ParseKey resolves a single key name such as "A", "F9", "Num5" or "PageUp".
+func ParseKey(name string) (uint16, error) {
+	n := strings.ToLower(strings.TrimSpace(name))
+	if n == "" {
+		return 0, errors.New("empty key name")
+	}
+	if vk, ok := keyNames[n]; ok {
+		return vk, nil
+	}
+	if len(n) == 1 {
+		c := n[0]
+		switch {
+		case c >= 'a' && c <= 'z':
+			return uint16(c - 'a' + 'A'), nil
+		case c >= '0' && c <= '9':
+			return uint16(c), nil
+		}
+	}
+	var num int
+	if _, err := fmt.Sscanf(n, "f%d", &num); err == nil && fmt.Sprintf("f%d", num) == n && num >= 1 && num <= 24 {
+		return VK_F1 + uint16(num-1), nil
+	}
+	if _, err := fmt.Sscanf(n, "num%d", &num); err == nil && fmt.Sprintf("num%d", num) == n && num >= 0 && num <= 9 {
+		return VK_NUMPAD0 + uint16(num), nil
+	}
+	if _, err := fmt.Sscanf(n, "0x%x", &num); err == nil && num > 0 && num < 0xFF {
+		return uint16(num), nil
+	}
+	return 0, errors.Errorf("unknown key %q", name)
+}
+
+// ParseChord parses a human readable key combination such as "Ctrl+Shift+Esc".
+// Names are case insensitive; a chord may consist of modifiers only.
+func ParseChord(s string) (Chord, error) {
+	var c Chord
+	if strings.TrimSpace(s) == "" {
+		return c, errors.New("empty chord")
+	}
+
+	parts := strings.Split(s, "+")
+	// "Ctrl++" names the plus key
+	if strings.HasSuffix(s, "++") {
+		parts = append(parts[:len(parts)-2], "+")
+	}
+
+	for i, part := range parts {
+		name := strings.ToLower(strings.TrimSpace(part))
+		if mod, ok := modifierNames[name]; ok {
+			if c.Modifiers&mod != 0 {
+				return Chord{}, errors.Errorf("duplicate modifier %q in %q", part, s)
+			}
+			c.Modifiers |= mod
+			continue
+		}
+		if i != len(parts)-1 {
+			return Chord{}, errors.Errorf("key %q must be last in %q", part, s)
+		}
+		if name == "+" {
+			c.Key = VK_OEM_PLUS
+			continue
+		}
+		vk, err := ParseKey(name)
+		if err != nil {
+			return Chord{}, errors.Wrapf(err, "parse chord %q", s)
+		}
+		c.Key = vk
+	}
+	return c, nil
+}
@@ -0,0 +1,71 @@
+package input
+
+import "testing"
+
+func TestParseChord(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Chord
+	}{
+		{"Ctrl+Shift+Esc", Chord{ModCtrl | ModShift, VK_ESCAPE}},
+		{"ctrl + alt + delete", Chord{ModCtrl | ModAlt, VK_DELETE}},
+		{"Win+E", Chord{ModWin, 'E'}},
+		{"F12", Chord{0, VK_F1 + 11}},
+		{"Alt+Num5", Chord{ModAlt, VK_NUMPAD0 + 5}},
+		{"Ctrl++", Chord{ModCtrl, VK_OEM_PLUS}},
+		{"Ctrl+Shift", Chord{ModCtrl | ModShift, 0}},
+		{"Super+0x41", Chord{ModWin, 0x41}},
+	}
+	for _, tt := range tests {
+		got, err := ParseChord(tt.in)
+		if err != nil {
+			t.Errorf("ParseChord(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseChord(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseChordErrors(t *testing.T) {
+	for _, in := range []string{"", "  ", "Ctrl+Ctrl+A", "A+Ctrl", "A+B", "Ctrl+Nope", "F25", "Num10", "f1x"} {
+		if c, err := ParseChord(in); err == nil {
+			t.Errorf("ParseChord(%q) = %+v, want error", in, c)
+		}
+	}
+}
+
+func TestChordStringRoundTrip(t *testing.T) {
+	chords := []Chord{
+		{ModCtrl | ModAlt | ModShift | ModWin, 'Z'},
+		{ModCtrl, VK_PRIOR},
+		{0, VK_F1 + 23},
+		{ModShift, VK_NUMPAD0 + 9},
+		{ModAlt, VK_OEM_4},
+		{ModCtrl, 0xE5},
+	}
+	for _, c := range chords {
+		s := c.String()
+		got, err := ParseChord(s)
+		if err != nil || got != c {
+			t.Errorf("ParseChord(%q) = %+v, %v, want %+v", s, got, err, c)
+		}
+	}
+	if s := (Chord{ModShift | ModCtrl, VK_ESCAPE}).String(); s != "Ctrl+Shift+Escape" {
+		t.Errorf("String = %q", s)
+	}
+}
+
+func TestModifierKeysOrder(t *testing.T) {
+	got := Chord{Modifiers: ModWin | ModShift | ModAlt | ModCtrl}.ModifierKeys()
+	want := []uint16{VK_CONTROL, VK_MENU, VK_SHIFT, VK_LWIN}
+	if len(got) != len(want) {
+		t.Fatalf("ModifierKeys = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("ModifierKeys = %v, want %v", got, want)
+		}
+	}
+}
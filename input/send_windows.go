@@ -0,0 +1,172 @@
+package input
+
+import (
+	"time"
+	"unsafe"
+
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi"
+)
+
+// rawInput has the layout of INPUT, MOUSEINPUT is the largest member of its union.
+type rawInput struct {
+	Type uint32
+	Mi   win.MOUSEINPUT
+}
+
+func (in Input) raw() rawInput {
+	r := rawInput{Type: in.Type}
+	if in.Type == INPUT_KEYBOARD {
+		ki := (*win.KEYBDINPUT)(unsafe.Pointer(&r.Mi))
+		ki.WVk = in.Vk
+		ki.WScan = in.Scan
+		ki.DwFlags = in.Flags
+		return r
+	}
+	r.Mi.Dx = in.DX
+	r.Mi.Dy = in.DY
+	r.Mi.MouseData = in.MouseData
+	r.Mi.DwFlags = in.Flags
+	return r
+}
+
+// VirtualDesktop returns the bounding rectangle of all monitors.
+func VirtualDesktop() Rect {
+	x := win.GetSystemMetrics(win.SM_XVIRTUALSCREEN)
+	y := win.GetSystemMetrics(win.SM_YVIRTUALSCREEN)
+	return Rect{
+		Left:   x,
+		Top:    y,
+		Right:  x + win.GetSystemMetrics(win.SM_CXVIRTUALSCREEN),
+		Bottom: y + win.GetSystemMetrics(win.SM_CYVIRTUALSCREEN),
+	}
+}
+
+// ScanCode maps a virtual key to its scan code with MAPVK_VK_TO_VSC.
+func ScanCode(vk uint16) uint16 {
+	return uint16(winapi.MapVirtualKey(uint32(vk), winapi.MAPVK_VK_TO_VSC))
+}
+
+// SendInput injects inputs in one SendInput call.
+func SendInput(inputs []Input) error {
+	if len(inputs) == 0 {
+		return nil
+	}
+	raw := make([]rawInput, len(inputs))
+	for i, in := range inputs {
+		raw[i] = in.raw()
+	}
+	n := win.SendInput(uint32(len(raw)), unsafe.Pointer(&raw[0]), int32(unsafe.Sizeof(raw[0])))
+	if int(n) != len(raw) {
+		return errors.Errorf("SendInput: %d of %d inputs injected, input may be blocked by UIPI", n, len(raw))
+	}
+	return nil
+}
+
+// Sender synthesizes keyboard and mouse input with SendInput.
+type Sender struct {
+	// UseScanCodes sends keys by scan code, see Builder.UseScanCodes.
+	UseScanCodes bool
+
+	// Delay is slept between consecutive events. Zero sends every
+	// sequence in a single SendInput call.
+	Delay time.Duration
+
+	// DragSteps is the number of intermediate moves of a drag.
+	DragSteps int
+}
+
+// DefaultSender is used by the package level functions.
+var DefaultSender = &Sender{DragSteps: 10}
+
+func (s *Sender) builder() *Builder {
+	return &Builder{
+		ScanCode:     ScanCode,
+		UseScanCodes: s.UseScanCodes,
+		Desktop:      VirtualDesktop(),
+	}
+}
+
+// Send injects inputs, honouring Delay.
+func (s *Sender) Send(inputs []Input) error {
+	if s.Delay <= 0 {
+		return SendInput(inputs)
+	}
+	for i := range inputs {
+		if i > 0 {
+			time.Sleep(s.Delay)
+		}
+		if err := SendInput(inputs[i : i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TypeText types text as Unicode characters.
+func (s *Sender) TypeText(text string) error {
+	return s.Send(s.builder().Text(text).Inputs())
+}
+
+// PressChord presses a key combination such as "Ctrl+Shift+Esc".
+func (s *Sender) PressChord(chord string) error {
+	c, err := ParseChord(chord)
+	if err != nil {
+		return err
+	}
+	return s.Send(s.builder().Chord(c).Inputs())
+}
+
+// MoveTo moves the cursor to (x, y) in virtual desktop coordinates.
+func (s *Sender) MoveTo(x, y int32) error {
+	return s.Send(s.builder().MoveTo(x, y).Inputs())
+}
+
+// Click clicks button at the current cursor position.
+func (s *Sender) Click(button Button) error {
+	return s.Send(s.builder().Click(button).Inputs())
+}
+
+// ClickAt moves the cursor to (x, y) and clicks button.
+func (s *Sender) ClickAt(x, y int32, button Button) error {
+	return s.Send(s.builder().MoveTo(x, y).Click(button).Inputs())
+}
+
+// Drag drags with button held from one point to another.
+func (s *Sender) Drag(button Button, fromX, fromY, toX, toY int32) error {
+	return s.Send(s.builder().Drag(button, fromX, fromY, toX, toY, s.DragSteps).Inputs())
+}
+
+// Scroll turns the wheel by clicks notches.
+func (s *Sender) Scroll(clicks int, horizontal bool) error {
+	return s.Send(s.builder().Scroll(clicks, horizontal).Inputs())
+}
+
+func TypeText(text string) error {
+	return DefaultSender.TypeText(text)
+}
+
+func PressChord(chord string) error {
+	return DefaultSender.PressChord(chord)
+}
+
+func MoveTo(x, y int32) error {
+	return DefaultSender.MoveTo(x, y)
+}
+
+func Click(button Button) error {
+	return DefaultSender.Click(button)
+}
+
+func ClickAt(x, y int32, button Button) error {
+	return DefaultSender.ClickAt(x, y, button)
+}
+
+func Drag(button Button, fromX, fromY, toX, toY int32) error {
+	return DefaultSender.Drag(button, fromX, fromY, toX, toY)
+}
+
+func Scroll(clicks int, horizontal bool) error {
+	return DefaultSender.Scroll(clicks, horizontal)
+}
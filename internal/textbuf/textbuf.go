@@ -0,0 +1,77 @@
+// Package textbuf reads strings that Windows copies into caller supplied
+// UTF-16 buffers, like window text and class names, without guessing a
+// fixed buffer size.
+package textbuf
+
+import (
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+)
+
+var ErrTruncated = errors.New("text kept growing while it was read")
+
+// Source is a string read into a caller supplied buffer.
+type Source interface {
+	// Len returns the expected length in UTF-16 units without the
+	// terminating null, it may be stale by the time Read runs.
+	Len() (int, error)
+	// Read copies the text into buf, null terminated and truncated to fit,
+	// and returns the number of units copied without the null.
+	Read(buf []uint16) (int, error)
+}
+
+const (
+	minTextBuffer    = 64
+	maxTextBufferTry = 8
+)
+
+// Read reads src into a buffer sized from its length. A read filling the
+// whole buffer may have been truncated because the text grew after Len,
+// so the buffer is grown and the read repeated. After maxTextBufferTry
+// reads the truncated text is returned with ErrTruncated.
+func Read(src Source) (string, error) {
+	n, err := src.Len()
+	if err != nil {
+		return "", err
+	}
+	// one unit beyond the null tells a complete read from a truncated one
+	size := n + 2
+	if size < minTextBuffer {
+		size = minTextBuffer
+	}
+
+	var buf []uint16
+	for try := 0; try < maxTextBufferTry; try++ {
+		buf = make([]uint16, size)
+		copied, err := src.Read(buf)
+		if err != nil {
+			return "", err
+		}
+		if copied < size-1 {
+			return decode(buf[:copied]), nil
+		}
+		size = growTextBuffer(size, src)
+	}
+	return decode(buf), ErrTruncated
+}
+
+// growTextBuffer doubles size, or more when the source reports a longer text.
+func growTextBuffer(size int, src Source) int {
+	next := 2 * size
+	if n, err := src.Len(); err == nil && n+2 > next {
+		next = n + 2
+	}
+	return next
+}
+
+// decode decodes s up to its first null.
+func decode(s []uint16) string {
+	for i, c := range s {
+		if c == 0 {
+			s = s[:i]
+			break
+		}
+	}
+	return string(utf16.Decode(s))
+}
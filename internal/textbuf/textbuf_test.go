@@ -0,0 +1,173 @@
+package textbuf
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+)
+
+// fakeSource serves texts[i] to the i-th Read and reports lens[i] from the
+// i-th Len, repeating the last entry of each.
+type fakeSource struct {
+	texts   []string
+	lens    []int
+	lenErr  error
+	readErr error
+
+	reads, lenCalls int
+	sizes           []int
+}
+
+func at(i int, n int) int {
+	if i >= n {
+		return n - 1
+	}
+	return i
+}
+
+func (f *fakeSource) Len() (int, error) {
+	if f.lenErr != nil {
+		return 0, f.lenErr
+	}
+	n := f.lens[at(f.lenCalls, len(f.lens))]
+	f.lenCalls++
+	return n, nil
+}
+
+func (f *fakeSource) Read(buf []uint16) (int, error) {
+	f.sizes = append(f.sizes, len(buf))
+	if f.readErr != nil {
+		return 0, f.readErr
+	}
+	u := utf16.Encode([]rune(f.texts[at(f.reads, len(f.texts))]))
+	f.reads++
+	// copy what fits, null terminated, like GetWindowTextW
+	n := copy(buf[:len(buf)-1], u)
+	buf[n] = 0
+	return n, nil
+}
+
+func TestRead(t *testing.T) {
+	long := strings.Repeat("x", 100)
+	tests := []struct {
+		name  string
+		src   *fakeSource
+		want  string
+		sizes []int
+	}{
+		{
+			"short text",
+			&fakeSource{texts: []string{"hello"}, lens: []int{5}},
+			"hello", []int{minTextBuffer},
+		},
+		{
+			"long text",
+			&fakeSource{texts: []string{long}, lens: []int{100}},
+			long, []int{102},
+		},
+		{
+			// the text grew between Len and Read, the second Len knows by how much
+			"text grows after Len",
+			&fakeSource{texts: []string{long + long}, lens: []int{100, 200}},
+			long + long, []int{102, 204},
+		},
+		{
+			// a read filling the buffer may be truncated, so it is repeated
+			"exact fit",
+			&fakeSource{texts: []string{strings.Repeat("y", 63)}, lens: []int{10}},
+			strings.Repeat("y", 63), []int{64, 128},
+		},
+		{
+			// a stale Len smaller than the doubled buffer does not shrink it
+			"stale length",
+			&fakeSource{texts: []string{strings.Repeat("z", 70)}, lens: []int{3, 3}},
+			strings.Repeat("z", 70), []int{64, 128},
+		},
+		{
+			"surrogate pairs",
+			&fakeSource{texts: []string{"a😀b"}, lens: []int{4}},
+			"a😀b", []int{minTextBuffer},
+		},
+	}
+	for _, tt := range tests {
+		got, err := Read(tt.src)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: Read = %q, want %q", tt.name, got, tt.want)
+		}
+		if !equalInts(tt.src.sizes, tt.sizes) {
+			t.Errorf("%s: buffer sizes %v, want %v", tt.name, tt.src.sizes, tt.sizes)
+		}
+	}
+}
+
+func TestReadTruncated(t *testing.T) {
+	// every read fills the buffer, as for a text growing without end
+	src := &fakeSource{texts: []string{strings.Repeat("g", 1<<16)}, lens: []int{1}}
+	got, err := Read(src)
+	if err != ErrTruncated {
+		t.Fatalf("Read = %v, want ErrTruncated", err)
+	}
+	if src.reads != maxTextBufferTry {
+		t.Errorf("%d reads, want %d", src.reads, maxTextBufferTry)
+	}
+	last := src.sizes[len(src.sizes)-1]
+	if len(got) != last-1 || strings.Trim(got, "g") != "" {
+		t.Errorf("truncated text of %d units from a buffer of %d", len(got), last)
+	}
+	if want := minTextBuffer << (maxTextBufferTry - 1); last != want {
+		t.Errorf("last buffer of %d, want %d", last, want)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	failed := errors.New("access denied")
+	src := &fakeSource{texts: []string{"x"}, lens: []int{1}, readErr: failed}
+	if _, err := Read(src); err != failed {
+		t.Errorf("Read error = %v, want it passed through", err)
+	}
+	src = &fakeSource{lenErr: failed}
+	if _, err := Read(src); err != failed || len(src.sizes) != 0 {
+		t.Errorf("Len error = %v after %d reads", err, len(src.sizes))
+	}
+
+	// a failing Len while growing keeps doubling
+	src = &fakeSource{texts: []string{strings.Repeat("y", 63), "y"}, lens: []int{10}}
+	grown := &lenFailsLater{fakeSource: src, failed: failed}
+	if got, err := Read(grown); err != nil || got != "y" {
+		t.Errorf("Read = %q, %v", got, err)
+	}
+	if !equalInts(src.sizes, []int{64, 128}) {
+		t.Errorf("buffer sizes %v", src.sizes)
+	}
+}
+
+// lenFailsLater fails every Len after the first.
+type lenFailsLater struct {
+	*fakeSource
+	failed error
+}
+
+func (l *lenFailsLater) Len() (int, error) {
+	if l.lenCalls > 0 {
+		return 0, l.failed
+	}
+	return l.fakeSource.Len()
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,241 @@
+// Package layout arranges top-level windows on monitors and saves and
+// restores those arrangements.
+package layout
+
+import (
+	"math"
+
+	"github.com/whiteboxsolutions/winapi/display"
+)
+
+// Side is a snap position within a monitor work area.
+type Side int
+
+const (
+	Left Side = iota
+	Right
+	Top
+	Bottom
+	TopLeft
+	TopRight
+	BottomLeft
+	BottomRight
+	Fill
+)
+
+// SnapRect returns the part of work a window snapped to side covers.
+func SnapRect(work display.Rect, side Side) display.Rect {
+	midX := work.Left + work.Dx()/2
+	midY := work.Top + work.Dy()/2
+	r := work
+	switch side {
+	case Left:
+		r.Right = midX
+	case Right:
+		r.Left = midX
+	case Top:
+		r.Bottom = midY
+	case Bottom:
+		r.Top = midY
+	case TopLeft:
+		r.Right, r.Bottom = midX, midY
+	case TopRight:
+		r.Left, r.Bottom = midX, midY
+	case BottomLeft:
+		r.Right, r.Top = midX, midY
+	case BottomRight:
+		r.Left, r.Top = midX, midY
+	}
+	return r
+}
+
+// Grid splits work into n cells of cols columns separated by gap pixels.
+// cols of zero picks the most square grid. Cells of an incomplete last row
+// share the full width.
+func Grid(work display.Rect, n, cols int, gap int32) []display.Rect {
+	if n <= 0 {
+		return nil
+	}
+	if cols <= 0 {
+		cols = int(math.Ceil(math.Sqrt(float64(n))))
+	}
+	if cols > n {
+		cols = n
+	}
+	rows := (n + cols - 1) / cols
+
+	cells := make([]display.Rect, 0, n)
+	for row := 0; row < rows; row++ {
+		inRow := cols
+		if row == rows-1 {
+			inRow = n - row*cols
+		}
+		top, bottom := split(work.Top, work.Bottom, rows, row, gap)
+		for col := 0; col < inRow; col++ {
+			left, right := split(work.Left, work.Right, inRow, col, gap)
+			cells = append(cells, display.Rect{Left: left, Top: top, Right: right, Bottom: bottom})
+		}
+	}
+	return cells
+}
+
+// split returns the i-th of n spans of [lo, hi) with gap pixels between spans.
+// Rounding leftovers are spread so that the spans cover the range exactly.
+func split(lo, hi int32, n, i int, gap int32) (int32, int32) {
+	avail := int64(hi-lo) - int64(gap)*int64(n-1)
+	if avail < int64(n) {
+		avail, gap = int64(hi-lo), 0
+	}
+	start := lo + int32(avail*int64(i)/int64(n)) + gap*int32(i)
+	end := lo + int32(avail*int64(i+1)/int64(n)) + gap*int32(i)
+	return start, end
+}
+
+// Fit moves r inside bounds, shrinking it when it is larger.
+func Fit(r, bounds display.Rect) display.Rect {
+	if r.Dx() > bounds.Dx() {
+		r.Right = r.Left + bounds.Dx()
+	}
+	if r.Dy() > bounds.Dy() {
+		r.Bottom = r.Top + bounds.Dy()
+	}
+	if r.Right > bounds.Right {
+		r = r.Offset(bounds.Right-r.Right, 0)
+	}
+	if r.Bottom > bounds.Bottom {
+		r = r.Offset(0, bounds.Bottom-r.Bottom)
+	}
+	if r.Left < bounds.Left {
+		r = r.Offset(bounds.Left-r.Left, 0)
+	}
+	if r.Top < bounds.Top {
+		r = r.Offset(0, bounds.Top-r.Top)
+	}
+	return r
+}
+
+// Translate maps r from one monitor to another, keeping its position relative
+// to the monitor and its size in logical units, and fits it into the work area.
+func Translate(r display.Rect, from, to display.Monitor) display.Rect {
+	return Fit(to.FromLogical(from.ToLogical(r)), to.WorkArea)
+}
+
+// WorkspaceToScreen converts the workspace coordinates of
+// WINDOWPLACEMENT.rcNormalPosition to screen coordinates. Workspace
+// coordinates are relative to the work area of the primary monitor.
+func WorkspaceToScreen(r display.Rect, primary display.Monitor) display.Rect {
+	return r.Offset(primary.WorkArea.Left-primary.Bounds.Left, primary.WorkArea.Top-primary.Bounds.Top)
+}
+
+// ScreenToWorkspace is the inverse of WorkspaceToScreen.
+func ScreenToWorkspace(r display.Rect, primary display.Monitor) display.Rect {
+	return r.Offset(primary.Bounds.Left-primary.WorkArea.Left, primary.Bounds.Top-primary.WorkArea.Top)
+}
+
+// State is the show state of a window.
+type State string
+
+const (
+	Normal    State = "normal"
+	Minimized State = "minimized"
+	Maximized State = "maximized"
+)
+
+// Snapshot is the current state of a window as seen by Capture and Plan.
+type Snapshot struct {
+	Handle    uintptr
+	ClassName string
+	Title     string
+
+	// Rect is the restored window rectangle in screen coordinates.
+	Rect  display.Rect
+	State State
+}
+
+// Window is a saved window position. Rect is in 96 DPI logical units
+// relative to the bounds of the monitor named by Monitor.
+type Window struct {
+	ClassName string       `json:"class"`
+	Title     string       `json:"title"`
+	Monitor   string       `json:"monitor"`
+	Rect      display.Rect `json:"rect"`
+	State     State        `json:"state"`
+}
+
+// Layout is a serializable arrangement of windows.
+type Layout struct {
+	Windows []Window `json:"windows"`
+}
+
+// Capture records the position of windows relative to the monitors they are on.
+func Capture(windows []Snapshot, monitors []display.Monitor) Layout {
+	var l Layout
+	for _, w := range windows {
+		m, ok := display.Largest(monitors, w.Rect)
+		if !ok {
+			continue
+		}
+		l.Windows = append(l.Windows, Window{
+			ClassName: w.ClassName,
+			Title:     w.Title,
+			Monitor:   m.DeviceName,
+			Rect:      m.ToLogical(w.Rect),
+			State:     w.State,
+		})
+	}
+	return l
+}
+
+// Move is a window position computed by Plan.
+type Move struct {
+	Handle uintptr
+	Rect   display.Rect
+	State  State
+}
+
+// Plan matches the saved windows to windows by class name and title, then by
+// class name alone, and computes where each has to go on the current monitors.
+// Windows on a monitor that no longer exists go to the primary monitor.
+func (l Layout) Plan(windows []Snapshot, monitors []display.Monitor) []Move {
+	used := make([]bool, len(windows))
+	match := func(saved Window, sameTitle bool) int {
+		for i, w := range windows {
+			if !used[i] && w.ClassName == saved.ClassName && (!sameTitle || w.Title == saved.Title) {
+				used[i] = true
+				return i
+			}
+		}
+		return -1
+	}
+
+	matched := make([]int, len(l.Windows))
+	for i, saved := range l.Windows {
+		matched[i] = match(saved, true)
+	}
+	for i, saved := range l.Windows {
+		if matched[i] < 0 {
+			matched[i] = match(saved, false)
+		}
+	}
+
+	primary, _ := display.Primary(monitors)
+	var moves []Move
+	for i, saved := range l.Windows {
+		if matched[i] < 0 {
+			continue
+		}
+		m := primary
+		for _, candidate := range monitors {
+			if candidate.DeviceName == saved.Monitor {
+				m = candidate
+				break
+			}
+		}
+		moves = append(moves, Move{
+			Handle: windows[matched[i]].Handle,
+			Rect:   Fit(m.FromLogical(saved.Rect), m.WorkArea),
+			State:  saved.State,
+		})
+	}
+	return moves
+}
@@ -0,0 +1,195 @@
+package layout
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/whiteboxsolutions/winapi/display"
+)
+
+type rect = display.Rect
+
+func rc(left, top, right, bottom int32) rect {
+	return rect{Left: left, Top: top, Right: right, Bottom: bottom}
+}
+
+func TestSnapRect(t *testing.T) {
+	work := rc(0, 40, 1920, 1080)
+	tests := []struct {
+		side Side
+		want rect
+	}{
+		{Left, rc(0, 40, 960, 1080)},
+		{Right, rc(960, 40, 1920, 1080)},
+		{Top, rc(0, 40, 1920, 560)},
+		{Bottom, rc(0, 560, 1920, 1080)},
+		{TopLeft, rc(0, 40, 960, 560)},
+		{TopRight, rc(960, 40, 1920, 560)},
+		{BottomLeft, rc(0, 560, 960, 1080)},
+		{BottomRight, rc(960, 560, 1920, 1080)},
+		{Fill, work},
+	}
+	for _, tt := range tests {
+		if got := SnapRect(work, tt.side); got != tt.want {
+			t.Errorf("SnapRect(%d) = %+v, want %+v", tt.side, got, tt.want)
+		}
+	}
+}
+
+func TestGrid(t *testing.T) {
+	tests := []struct {
+		work    rect
+		n, cols int
+		gap     int32
+		want    []rect
+	}{
+		{rc(0, 0, 100, 100), 0, 0, 0, nil},
+		{rc(0, 0, 100, 100), 1, 0, 10, []rect{rc(0, 0, 100, 100)}},
+		{rc(0, 0, 100, 100), 3, 0, 0, []rect{rc(0, 0, 50, 50), rc(50, 0, 100, 50), rc(0, 50, 100, 100)}},
+		{rc(0, 0, 100, 100), 2, 5, 10, []rect{rc(0, 0, 45, 100), rc(55, 0, 100, 100)}},
+		{rc(0, 0, 10, 30), 3, 1, 0, []rect{rc(0, 0, 10, 10), rc(0, 10, 10, 20), rc(0, 20, 10, 30)}},
+		// no room for the gaps
+		{rc(0, 0, 10, 10), 3, 3, 5, []rect{rc(0, 0, 3, 10), rc(3, 0, 6, 10), rc(6, 0, 10, 10)}},
+	}
+	for _, tt := range tests {
+		if got := Grid(tt.work, tt.n, tt.cols, tt.gap); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Grid(%+v, %d, %d, %d) = %+v, want %+v", tt.work, tt.n, tt.cols, tt.gap, got, tt.want)
+		}
+	}
+}
+
+func TestSplitCoversRange(t *testing.T) {
+	for lo := int32(-3); lo < 3; lo++ {
+		for size := int32(0); size < 40; size++ {
+			for n := 1; n < 8; n++ {
+				for gap := int32(0); gap < 5; gap++ {
+					hi := lo + size
+					prev := lo
+					for i := 0; i < n; i++ {
+						start, end := split(lo, hi, n, i, gap)
+						if end < start {
+							t.Fatalf("split(%d, %d, %d, %d, %d) = %d, %d", lo, hi, n, i, gap, start, end)
+						}
+						if i > 0 && start != prev && start != prev+gap {
+							t.Fatalf("split(%d, %d, %d, %d, %d) starts at %d after %d", lo, hi, n, i, gap, start, prev)
+						}
+						prev = end
+					}
+					if prev != hi {
+						t.Fatalf("split(%d, %d, %d, _, %d) ends at %d", lo, hi, n, gap, prev)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestFit(t *testing.T) {
+	bounds := rc(0, 0, 100, 100)
+	tests := []struct{ r, want rect }{
+		{rc(10, 10, 20, 20), rc(10, 10, 20, 20)},
+		{rc(-10, -10, 40, 40), rc(0, 0, 50, 50)},
+		{rc(90, 90, 120, 130), rc(70, 60, 100, 100)},
+		{rc(50, 0, 350, 50), rc(0, 0, 100, 50)},
+		{rc(-500, -500, 500, 500), bounds},
+	}
+	for _, tt := range tests {
+		if got := Fit(tt.r, bounds); got != tt.want {
+			t.Errorf("Fit(%+v) = %+v, want %+v", tt.r, got, tt.want)
+		}
+	}
+}
+
+var (
+	monitorA = display.Monitor{
+		DeviceName: `\\.\DISPLAY1`,
+		Bounds:     rc(0, 0, 1920, 1080),
+		WorkArea:   rc(0, 40, 1920, 1080),
+		Primary:    true,
+		DPIX:       96,
+		DPIY:       96,
+	}
+	monitorB = display.Monitor{
+		DeviceName: `\\.\DISPLAY2`,
+		Bounds:     rc(1920, 0, 4480, 1440),
+		WorkArea:   rc(1920, 0, 4480, 1400),
+		DPIX:       144,
+		DPIY:       144,
+	}
+)
+
+func TestTranslate(t *testing.T) {
+	if got, want := Translate(rc(100, 100, 500, 400), monitorA, monitorB), (rc(2070, 150, 2670, 600)); got != want {
+		t.Errorf("Translate A to B = %+v, want %+v", got, want)
+	}
+	if got, want := Translate(rc(2070, 150, 2670, 600), monitorB, monitorA), (rc(100, 100, 500, 400)); got != want {
+		t.Errorf("Translate B to A = %+v, want %+v", got, want)
+	}
+	// scaled up the bottom of A is beyond the work area of B and gets fitted
+	if got, want := Translate(rc(0, 900, 200, 1080), monitorA, monitorB), (rc(1920, 1130, 2220, 1400)); got != want {
+		t.Errorf("Translate A to B = %+v, want %+v", got, want)
+	}
+}
+
+func TestWorkspace(t *testing.T) {
+	workspace := rc(0, 0, 100, 100)
+	screen := rc(0, 40, 100, 140)
+	if got := WorkspaceToScreen(workspace, monitorA); got != screen {
+		t.Errorf("WorkspaceToScreen = %+v, want %+v", got, screen)
+	}
+	if got := ScreenToWorkspace(screen, monitorA); got != workspace {
+		t.Errorf("ScreenToWorkspace = %+v, want %+v", got, workspace)
+	}
+}
+
+func TestCapturePlan(t *testing.T) {
+	monitors := []display.Monitor{monitorA, monitorB}
+	l := Capture([]Snapshot{
+		{Handle: 1, ClassName: "Notepad", Title: "a.txt", Rect: rc(2070, 150, 2670, 600), State: Maximized},
+		{Handle: 2, ClassName: "CabinetWClass", Title: "Downloads", Rect: rc(100, 100, 900, 700), State: Normal},
+		{Handle: 3, ClassName: "Off", Rect: rc(-5000, 0, -4000, 100), State: Normal},
+	}, monitors)
+
+	want := Layout{Windows: []Window{
+		{ClassName: "Notepad", Title: "a.txt", Monitor: monitorB.DeviceName, Rect: rc(100, 100, 500, 400), State: Maximized},
+		{ClassName: "CabinetWClass", Title: "Downloads", Monitor: monitorA.DeviceName, Rect: rc(100, 100, 900, 700), State: Normal},
+		{ClassName: "Off", Monitor: monitorA.DeviceName, Rect: rc(-5000, 0, -4000, 100), State: Normal},
+	}}
+	if !reflect.DeepEqual(l, want) {
+		t.Fatalf("Capture = %+v, want %+v", l, want)
+	}
+
+	b, err := json.Marshal(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Layout
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, l) {
+		t.Fatalf("JSON round trip = %+v, want %+v", decoded, l)
+	}
+
+	windows := []Snapshot{
+		{Handle: 20, ClassName: "Notepad", Title: "b.txt"},
+		{Handle: 21, ClassName: "Notepad", Title: "a.txt"},
+		{Handle: 22, ClassName: "CabinetWClass", Title: "Documents"},
+	}
+	moves := l.Plan(windows, monitors)
+	wantMoves := []Move{
+		{Handle: 21, Rect: rc(2070, 150, 2670, 600), State: Maximized},
+		{Handle: 22, Rect: rc(100, 100, 900, 700), State: Normal},
+	}
+	if !reflect.DeepEqual(moves, wantMoves) {
+		t.Errorf("Plan = %+v, want %+v", moves, wantMoves)
+	}
+
+	// without monitor B its window goes to the primary monitor
+	moves = l.Plan(windows, monitors[:1])
+	wantMoves[0].Rect = rc(100, 100, 500, 400)
+	if !reflect.DeepEqual(moves, wantMoves) {
+		t.Errorf("Plan on one monitor = %+v, want %+v", moves, wantMoves)
+	}
+}
@@ -0,0 +1,188 @@
+package layout
+
+import (
+	"unsafe"
+
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi"
+	"github.com/whiteboxsolutions/winapi/display"
+	"golang.org/x/sys/windows"
+)
+
+// GetPlacement returns the show state and restored position of hwnd.
+func GetPlacement(hwnd win.HWND) (win.WINDOWPLACEMENT, error) {
+	var wp win.WINDOWPLACEMENT
+	wp.Length = uint32(unsafe.Sizeof(wp))
+	if !win.GetWindowPlacement(hwnd, &wp) {
+		return wp, errors.Wrap(windows.GetLastError(), "GetWindowPlacement")
+	}
+	return wp, nil
+}
+
+// SetPlacement sets the show state and restored position of hwnd.
+func SetPlacement(hwnd win.HWND, wp win.WINDOWPLACEMENT) error {
+	wp.Length = uint32(unsafe.Sizeof(wp))
+	if !win.SetWindowPlacement(hwnd, &wp) {
+		return errors.Wrap(windows.GetLastError(), "SetWindowPlacement")
+	}
+	return nil
+}
+
+// setRect restores hwnd and moves it to r. A window crossing to a monitor of
+// another DPI resizes itself on WM_DPICHANGED, so the final rectangle is set
+// once more after the move.
+func setRect(hwnd win.HWND, r display.Rect) error {
+	if win.IsIconic(hwnd) || win.IsZoomed(hwnd) {
+		win.ShowWindow(hwnd, win.SW_RESTORE)
+	}
+	const flags = win.SWP_NOZORDER | win.SWP_NOACTIVATE
+	for i := 0; i < 2; i++ {
+		if !win.SetWindowPos(hwnd, 0, r.Left, r.Top, r.Dx(), r.Dy(), flags) {
+			return errors.Wrap(windows.GetLastError(), "SetWindowPos")
+		}
+	}
+	return nil
+}
+
+func applyState(hwnd win.HWND, state State) {
+	switch state {
+	case Maximized:
+		win.ShowWindow(hwnd, win.SW_MAXIMIZE)
+	case Minimized:
+		win.ShowWindow(hwnd, win.SW_MINIMIZE)
+	}
+}
+
+// place sets the restored rectangle r and the show state of hwnd in one
+// step, so a window that ends up maximized or minimized is not shown at r
+// first, and maximizes on the monitor r is on.
+func place(hwnd win.HWND, r display.Rect, state State, primary display.Monitor) error {
+	wp, err := GetPlacement(hwnd)
+	if err != nil {
+		return err
+	}
+	wp.RcNormalPosition = ScreenToWorkspace(r, primary).RECT()
+	wp.Flags = 0
+	wp.ShowCmd = win.SW_SHOWNOACTIVATE
+	switch state {
+	case Maximized:
+		wp.ShowCmd = win.SW_SHOWMAXIMIZED
+	case Minimized:
+		wp.ShowCmd = win.SW_SHOWMINNOACTIVE
+	}
+	return SetPlacement(hwnd, wp)
+}
+
+// snapshot returns the restored rectangle and state of hwnd.
+func snapshot(w winapi.WindowInfo, primary display.Monitor) (Snapshot, error) {
+	s := Snapshot{Handle: uintptr(w.HWND), ClassName: w.ClassName, Title: w.Title, Rect: display.Rect(w.Rect), State: Normal}
+	if !w.Minimized && !w.Maximized {
+		return s, nil
+	}
+	wp, err := GetPlacement(w.HWND)
+	if err != nil {
+		return s, err
+	}
+	s.Rect = WorkspaceToScreen(display.Rect(wp.RcNormalPosition), primary)
+	s.State = Maximized
+	if w.Minimized && wp.Flags&win.WPF_RESTORETOMAXIMIZED == 0 {
+		s.State = Minimized
+	}
+	return s, nil
+}
+
+func snapshots(windows []winapi.WindowInfo, monitors []display.Monitor) ([]Snapshot, error) {
+	primary, _ := display.Primary(monitors)
+	res := make([]Snapshot, 0, len(windows))
+	for _, w := range windows {
+		s, err := snapshot(w, primary)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, s)
+	}
+	return res, nil
+}
+
+// MoveToMonitor moves hwnd to m, keeping its relative position and logical size.
+func MoveToMonitor(hwnd win.HWND, m display.Monitor) error {
+	from, err := display.MonitorFromWindow(hwnd)
+	if err != nil {
+		return err
+	}
+	monitors, err := display.ListMonitors()
+	if err != nil {
+		return err
+	}
+	primary, _ := display.Primary(monitors)
+	maximized := win.IsZoomed(hwnd)
+	s, err := snapshot(winapi.GetWindowInfo(hwnd), primary)
+	if err != nil {
+		return err
+	}
+	if err := setRect(hwnd, Translate(s.Rect, from, m)); err != nil {
+		return err
+	}
+	if maximized {
+		applyState(hwnd, Maximized)
+	}
+	return nil
+}
+
+// Snap moves hwnd to side of the work area of its monitor.
+func Snap(hwnd win.HWND, side Side) error {
+	m, err := display.MonitorFromWindow(hwnd)
+	if err != nil {
+		return err
+	}
+	return setRect(hwnd, SnapRect(m.WorkArea, side))
+}
+
+// Tile arranges hwnds in a grid of cols columns on the work area of m.
+func Tile(hwnds []win.HWND, m display.Monitor, cols int, gap int32) error {
+	for i, r := range Grid(m.WorkArea, len(hwnds), cols, gap) {
+		if err := setRect(hwnds[i], r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CaptureLayout records the positions of windows, as returned by winapi.ListWindows.
+func CaptureLayout(windows []winapi.WindowInfo) (Layout, error) {
+	monitors, err := display.ListMonitors()
+	if err != nil {
+		return Layout{}, err
+	}
+	s, err := snapshots(windows, monitors)
+	if err != nil {
+		return Layout{}, err
+	}
+	return Capture(s, monitors), nil
+}
+
+// Apply moves the matching windows back into the saved positions.
+func (l Layout) Apply(windows []winapi.WindowInfo) error {
+	monitors, err := display.ListMonitors()
+	if err != nil {
+		return err
+	}
+	s, err := snapshots(windows, monitors)
+	if err != nil {
+		return err
+	}
+	primary, _ := display.Primary(monitors)
+	for _, mv := range l.Plan(s, monitors) {
+		hwnd := win.HWND(mv.Handle)
+		if mv.State == Maximized || mv.State == Minimized {
+			err = place(hwnd, mv.Rect, mv.State, primary)
+		} else {
+			err = setRect(hwnd, mv.Rect)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
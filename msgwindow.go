@@ -0,0 +1,196 @@
+package winapi
+
+import (
+	"runtime"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"golang.org/x/sys/windows"
+)
+
+// WndProc handles a window message. Returning handled false passes the
+// message on to DefWindowProc.
+type WndProc func(hwnd win.HWND, msg uint32, wParam, lParam uintptr) (result uintptr, handled bool)
+
+// wmInvoke runs the queued functions of a MessageWindow on its thread.
+const wmInvoke = win.WM_APP + 0x3FFF
+
+// MessageWindow is a hidden window with its own message loop running on a
+// locked OS thread. It is the receiver for APIs that deliver notifications
+// as window messages, such as RegisterHotKey or AddClipboardFormatListener.
+type MessageWindow struct {
+	HWND     win.HWND
+	ThreadID uint32
+
+	proc  WndProc
+	mu    sync.Mutex
+	calls []func()
+	done  chan struct{}
+}
+
+var (
+	messageWindows        sync.Map // win.HWND -> *MessageWindow
+	registeredClasses     sync.Map // string -> struct{}
+	messageWindowProc     uintptr
+	messageWindowProcOnce sync.Once
+)
+
+func dispatchMessageWindow(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	v, ok := messageWindows.Load(hwnd)
+	if !ok {
+		return win.DefWindowProc(hwnd, msg, wParam, lParam)
+	}
+	w := v.(*MessageWindow)
+
+	switch msg {
+	case wmInvoke:
+		w.mu.Lock()
+		calls := w.calls
+		w.calls = nil
+		w.mu.Unlock()
+		for _, call := range calls {
+			call()
+		}
+		return 0
+	case win.WM_DESTROY:
+		if w.proc != nil {
+			w.proc(hwnd, msg, wParam, lParam)
+		}
+		messageWindows.Delete(hwnd)
+		win.PostQuitMessage(0)
+		return 0
+	}
+
+	if w.proc != nil {
+		if res, handled := w.proc(hwnd, msg, wParam, lParam); handled {
+			return res
+		}
+	}
+	return win.DefWindowProc(hwnd, msg, wParam, lParam)
+}
+
+func registerMessageWindowClass(className string) error {
+	if _, ok := registeredClasses.Load(className); ok {
+		return nil
+	}
+	messageWindowProcOnce.Do(func() {
+		messageWindowProc = syscall.NewCallback(dispatchMessageWindow)
+	})
+
+	wc := win.WNDCLASSEX{
+		LpfnWndProc:   messageWindowProc,
+		HInstance:     win.GetModuleHandle(nil),
+		LpszClassName: MustUTF16PtrFromString(className),
+	}
+	wc.CbSize = uint32(unsafe.Sizeof(wc))
+	if _, err := RegisterClassEx(&wc); err != nil && err != windows.ERROR_CLASS_ALREADY_EXISTS {
+		return errors.Wrap(err, "RegisterClassEx")
+	}
+	registeredClasses.Store(className, struct{}{})
+	return nil
+}
+
+// NewMessageWindow creates a hidden window of class className and starts its
+// message loop. Message-only windows do not receive broadcasts such as
+// WM_DISPLAYCHANGE or TaskbarCreated, pass topLevel to create an invisible
+// top-level window instead.
+func NewMessageWindow(className, title string, topLevel bool, proc WndProc) (*MessageWindow, error) {
+	w := &MessageWindow{proc: proc, done: make(chan struct{})}
+	created := make(chan error, 1)
+
+	go func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		defer close(w.done)
+
+		if err := registerMessageWindowClass(className); err != nil {
+			created <- err
+			return
+		}
+
+		parent := win.HWND_MESSAGE
+		if topLevel {
+			parent = 0
+		}
+		hwnd := win.CreateWindowEx(0, MustUTF16PtrFromString(className), MustUTF16PtrFromString(title),
+			0, 0, 0, 0, 0, parent, 0, win.GetModuleHandle(nil), nil)
+		if hwnd == 0 {
+			created <- errors.Wrap(windows.GetLastError(), "CreateWindowEx")
+			return
+		}
+		w.HWND = hwnd
+		w.ThreadID = windows.GetCurrentThreadId()
+		messageWindows.Store(hwnd, w)
+		created <- nil
+
+		var msg win.MSG
+		for win.GetMessage(&msg, 0, 0, 0) > 0 {
+			win.TranslateMessage(&msg)
+			win.DispatchMessage(&msg)
+		}
+	}()
+
+	if err := <-created; err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Post queues fn to run on the window thread and returns immediately.
+func (w *MessageWindow) Post(fn func()) error {
+	w.mu.Lock()
+	w.calls = append(w.calls, fn)
+	w.mu.Unlock()
+	if win.PostMessage(w.HWND, wmInvoke, 0, 0) == 0 {
+		return errors.Wrap(windows.GetLastError(), "PostMessage")
+	}
+	return nil
+}
+
+// Do runs fn on the window thread and waits for it to return. Some APIs,
+// like RegisterHotKey, only accept windows created by the calling thread.
+func (w *MessageWindow) Do(fn func()) error {
+	if windows.GetCurrentThreadId() == w.ThreadID {
+		fn()
+		return nil
+	}
+	ran := make(chan struct{})
+	if err := w.Post(func() {
+		defer close(ran)
+		fn()
+	}); err != nil {
+		return err
+	}
+	select {
+	case <-ran:
+		return nil
+	case <-w.done:
+		return errors.New("message window closed")
+	}
+}
+
+// Done is closed once the message loop has exited.
+func (w *MessageWindow) Done() <-chan struct{} {
+	return w.done
+}
+
+// Close destroys the window and waits for its message loop to exit.
+func (w *MessageWindow) Close() error {
+	select {
+	case <-w.done:
+		return nil
+	default:
+	}
+	if windows.GetCurrentThreadId() == w.ThreadID {
+		win.DestroyWindow(w.HWND)
+		return nil
+	}
+	if err := w.Post(func() { win.DestroyWindow(w.HWND) }); err != nil {
+		return err
+	}
+	<-w.done
+	return nil
+}
@@ -0,0 +1,14 @@
+package winapi
+
+import (
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi/internal/textbuf"
+)
+
+var (
+	ErrInvalidWindow = errors.New("invalid window handle")
+	ErrWindowTimeout = errors.New("window did not respond in time")
+	// ErrTextTruncated is returned with the text read so far when a text
+	// kept growing while it was read.
+	ErrTextTruncated = textbuf.ErrTruncated
+)
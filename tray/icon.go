@@ -0,0 +1,23 @@
+package tray
+
+import (
+	"image"
+
+	"github.com/whiteboxsolutions/winapi/ico"
+)
+
+// IconResource encodes img as the bits of an RT_ICON resource: a 32 bpp
+// BITMAPINFOHEADER DIB with doubled height, followed by the AND mask.
+func IconResource(img image.Image) []byte {
+	return ico.Resource(img)
+}
+
+// EncodeICO encodes images as an .ico file with one 32 bpp entry each.
+// Images may be at most 256 pixels wide and high.
+func EncodeICO(images ...image.Image) ([]byte, error) {
+	f := &ico.File{Type: ico.TypeIcon}
+	for _, img := range images {
+		f.Entries = append(f.Entries, ico.Entry{Image: img})
+	}
+	return ico.Encode(f)
+}
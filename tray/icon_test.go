@@ -0,0 +1,65 @@
+package tray
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/whiteboxsolutions/winapi/ico"
+)
+
+func testImage(size int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(x * 255 / size), uint8(y * 255 / size), 0x80, uint8((x + y) * 255 / (2 * size))})
+		}
+	}
+	return img
+}
+
+func TestIconResource(t *testing.T) {
+	img := testImage(16)
+	b := IconResource(img)
+	if !bytes.Equal(b, ico.Resource(img)) {
+		t.Error("IconResource differs from ico.Resource")
+	}
+	// BITMAPINFOHEADER, 16x16 BGRA and a 16 rows mask of 4 bytes
+	if want := 40 + 16*16*4 + 16*4; len(b) != want {
+		t.Errorf("%d bytes, want %d", len(b), want)
+	}
+}
+
+func TestEncodeICO(t *testing.T) {
+	images := []image.Image{testImage(16), testImage(32)}
+	b, err := EncodeICO(images...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := ico.Decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Type != ico.TypeIcon || len(f.Entries) != len(images) {
+		t.Fatalf("decoded type %d with %d entries", f.Type, len(f.Entries))
+	}
+	for i, e := range f.Entries {
+		want := images[i].(*image.NRGBA)
+		if e.Image.Bounds() != want.Bounds() {
+			t.Errorf("entry %d bounds %v, want %v", i, e.Image.Bounds(), want.Bounds())
+			continue
+		}
+		for y := 0; y < want.Bounds().Dy(); y++ {
+			for x := 0; x < want.Bounds().Dx(); x++ {
+				if got := color.NRGBAModel.Convert(e.Image.At(x, y)); got != want.At(x, y) {
+					t.Fatalf("entry %d pixel (%d, %d) = %v, want %v", i, x, y, got, want.At(x, y))
+				}
+			}
+		}
+	}
+
+	if _, err := EncodeICO(image.NewNRGBA(image.Rect(0, 0, 257, 16))); err == nil {
+		t.Error("EncodeICO accepted a 257 pixel wide image")
+	}
+}
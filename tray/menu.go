@@ -0,0 +1,77 @@
+// Package tray shows a notification area icon with a context menu and
+// balloon notifications.
+package tray
+
+// Item is an entry of the context menu. Items with children open a submenu.
+type Item struct {
+	Title     string
+	Disabled  bool
+	Separator bool
+
+	// Checkable items toggle their check mark when clicked and pass the
+	// new state to OnClick. Checked is the state when the menu is set, the
+	// tray does not write it.
+	Checkable bool
+	Checked   bool
+
+	Items   []*Item
+	OnClick func(it *Item, checked bool)
+}
+
+// Separator returns a separator line.
+func Separator() *Item {
+	return &Item{Separator: true}
+}
+
+// Submenu returns an item opening items.
+func Submenu(title string, items ...*Item) *Item {
+	return &Item{Title: title, Items: items}
+}
+
+// menuModel assigns command ids to the items of a menu tree in pre-order,
+// starting at 1 because TrackPopupMenu returns 0 when nothing was chosen.
+// It keeps the check marks itself, it is only used on the window thread
+// while the items may be read anywhere.
+type menuModel struct {
+	items   []*Item
+	byID    map[uint32]*Item
+	ids     map[*Item]uint32
+	checked map[*Item]bool
+}
+
+func newMenuModel(items []*Item) *menuModel {
+	m := &menuModel{items: items, byID: map[uint32]*Item{}, ids: map[*Item]uint32{}, checked: map[*Item]bool{}}
+	var walk func([]*Item)
+	walk = func(items []*Item) {
+		for _, it := range items {
+			if it == nil || it.Separator {
+				continue
+			}
+			if _, ok := m.ids[it]; ok {
+				// the same item listed twice keeps its first id
+				continue
+			}
+			id := uint32(len(m.byID) + 1)
+			m.byID[id] = it
+			m.ids[it] = id
+			m.checked[it] = it.Checked
+			walk(it.Items)
+		}
+	}
+	walk(items)
+	return m
+}
+
+// click resolves a command id and returns the check mark of the item after
+// the click, checkable items are toggled. Submenus, disabled items and
+// unknown ids are not clickable.
+func (m *menuModel) click(id uint32) (it *Item, checked bool, ok bool) {
+	it, ok = m.byID[id]
+	if !ok || it.Disabled || len(it.Items) > 0 {
+		return nil, false, false
+	}
+	if it.Checkable {
+		m.checked[it] = !m.checked[it]
+	}
+	return it, m.checked[it], true
+}
@@ -0,0 +1,58 @@
+package tray
+
+import "testing"
+
+func TestMenuModelIDs(t *testing.T) {
+	open := &Item{Title: "Open"}
+	recent1 := &Item{Title: "a.txt"}
+	recent2 := &Item{Title: "b.txt"}
+	recent := Submenu("Recent", recent1, nil, recent2)
+	quit := &Item{Title: "Quit"}
+	m := newMenuModel([]*Item{open, recent, Separator(), quit, open})
+
+	want := []*Item{open, recent, recent1, recent2, quit}
+	if len(m.byID) != len(want) {
+		t.Fatalf("%d ids, want %d", len(m.byID), len(want))
+	}
+	for i, it := range want {
+		id := uint32(i + 1)
+		if m.byID[id] != it || m.ids[it] != id {
+			t.Errorf("id %d is %q, want %q", id, m.byID[id].Title, it.Title)
+		}
+	}
+}
+
+func TestMenuModelClick(t *testing.T) {
+	plain := &Item{Title: "Plain"}
+	disabled := &Item{Title: "Disabled", Disabled: true}
+	check := &Item{Title: "Check", Checkable: true, Checked: true}
+	sub := Submenu("Sub", &Item{Title: "Child"})
+	m := newMenuModel([]*Item{plain, disabled, check, sub})
+
+	if it, checked, ok := m.click(m.ids[plain]); !ok || it != plain || checked {
+		t.Errorf("click(plain) = %v, %v, %v", it, checked, ok)
+	}
+	for _, id := range []uint32{0, m.ids[disabled], m.ids[sub], 99} {
+		if it, _, ok := m.click(id); ok {
+			t.Errorf("click(%d) = %q, want not clickable", id, it.Title)
+		}
+	}
+
+	for _, want := range []bool{false, true, false} {
+		it, checked, ok := m.click(m.ids[check])
+		if !ok || it != check || checked != want {
+			t.Errorf("click(check) = %v, %v, want checked %v", checked, ok, want)
+		}
+		if m.checked[check] != want {
+			t.Errorf("model keeps %v, want %v", m.checked[check], want)
+		}
+	}
+	if !check.Checked {
+		t.Error("click wrote the Checked field of the item")
+	}
+
+	// a new model starts again from the items
+	if m := newMenuModel([]*Item{check}); !m.checked[check] {
+		t.Error("newMenuModel ignored Checked")
+	}
+}
@@ -0,0 +1,286 @@
+package tray
+
+import (
+	"image"
+	"sync"
+	"unsafe"
+
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi"
+	"github.com/whiteboxsolutions/winapi/ico"
+	"golang.org/x/sys/windows"
+)
+
+// wmNotify is the callback message of the icon.
+const wmNotify = win.WM_APP + 1
+
+// BalloonIcon selects the icon shown in a balloon notification.
+type BalloonIcon uint32
+
+const (
+	BalloonNone    BalloonIcon = win.NIIF_NONE
+	BalloonInfo    BalloonIcon = win.NIIF_INFO
+	BalloonWarning BalloonIcon = win.NIIF_WARNING
+	BalloonError   BalloonIcon = win.NIIF_ERROR
+)
+
+// Options describes a tray icon. The callbacks run on their own goroutine.
+type Options struct {
+	Icon    image.Image
+	Tooltip string
+	Menu    []*Item
+
+	OnClick        func()
+	OnDoubleClick  func()
+	OnBalloonClick func()
+
+	// OnError receives the errors of building the context menu.
+	OnError func(error)
+}
+
+// Tray is an icon in the notification area.
+type Tray struct {
+	opts           Options
+	window         *winapi.MessageWindow
+	taskbarCreated uint32
+
+	mu   sync.Mutex
+	nid  win.NOTIFYICONDATA
+	icon win.HICON
+	menu *menuModel
+}
+
+var taskbarCreated = winapi.MustUTF16PtrFromString("TaskbarCreated")
+
+// New adds an icon to the notification area. The icon is added again when
+// Explorer restarts.
+func New(opts Options) (*Tray, error) {
+	t := &Tray{
+		opts:           opts,
+		menu:           newMenuModel(opts.Menu),
+		taskbarCreated: win.RegisterWindowMessage(taskbarCreated),
+	}
+	// message-only windows do not receive the TaskbarCreated broadcast
+	w, err := winapi.NewMessageWindow("winapi.tray", "", true, t.wndProc)
+	if err != nil {
+		return nil, err
+	}
+	t.window = w
+
+	t.nid.CbSize = uint32(unsafe.Sizeof(t.nid))
+	t.nid.HWnd = w.HWND
+	t.nid.UID = 1
+	t.nid.UFlags = win.NIF_MESSAGE | win.NIF_TIP
+	t.nid.UCallbackMessage = wmNotify
+	copyUTF16(t.nid.SzTip[:], opts.Tooltip)
+	if opts.Icon != nil {
+		if t.icon, err = createIcon(opts.Icon); err != nil {
+			w.Close()
+			return nil, err
+		}
+		t.nid.HIcon = t.icon
+		t.nid.UFlags |= win.NIF_ICON
+	}
+	if !win.Shell_NotifyIcon(win.NIM_ADD, &t.nid) {
+		w.Close()
+		t.destroyIcon()
+		return nil, errors.New("Shell_NotifyIcon: NIM_ADD failed")
+	}
+	return t, nil
+}
+
+func createIcon(img image.Image) (win.HICON, error) {
+	return ico.IconFromImage(img)
+}
+
+func (t *Tray) destroyIcon() {
+	if t.icon != 0 {
+		win.DestroyIcon(t.icon)
+		t.icon = 0
+	}
+}
+
+// copyUTF16 copies s into the fixed size buffer dst, truncating it.
+func copyUTF16(dst []uint16, s string) {
+	u := winapi.MustUTF16FromString(s)
+	if len(u) > len(dst) {
+		u = u[:len(dst)]
+		u[len(u)-1] = 0
+	}
+	copy(dst, u)
+}
+
+func (t *Tray) modify(change func(nid *win.NOTIFYICONDATA)) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	nid := t.nid
+	change(&nid)
+	if !win.Shell_NotifyIcon(win.NIM_MODIFY, &nid) {
+		return errors.New("Shell_NotifyIcon: NIM_MODIFY failed")
+	}
+	// balloons are shown once, not again after Explorer restarts
+	nid.UFlags &^= win.NIF_INFO
+	t.nid = nid
+	return nil
+}
+
+// SetIcon replaces the icon.
+func (t *Tray) SetIcon(img image.Image) error {
+	h, err := createIcon(img)
+	if err != nil {
+		return err
+	}
+	if err := t.modify(func(nid *win.NOTIFYICONDATA) {
+		nid.HIcon = h
+		nid.UFlags |= win.NIF_ICON
+	}); err != nil {
+		win.DestroyIcon(h)
+		return err
+	}
+	t.mu.Lock()
+	t.destroyIcon()
+	t.icon = h
+	t.mu.Unlock()
+	return nil
+}
+
+// SetTooltip replaces the text shown when hovering the icon.
+func (t *Tray) SetTooltip(tooltip string) error {
+	return t.modify(func(nid *win.NOTIFYICONDATA) {
+		copyUTF16(nid.SzTip[:], tooltip)
+	})
+}
+
+// SetMenu replaces the context menu. Call it again after changing items.
+func (t *Tray) SetMenu(items ...*Item) {
+	m := newMenuModel(items)
+	t.mu.Lock()
+	t.menu = m
+	t.mu.Unlock()
+}
+
+// ShowBalloon shows a notification next to the icon.
+func (t *Tray) ShowBalloon(title, text string, icon BalloonIcon) error {
+	return t.modify(func(nid *win.NOTIFYICONDATA) {
+		nid.UFlags |= win.NIF_INFO
+		copyUTF16(nid.SzInfoTitle[:], title)
+		copyUTF16(nid.SzInfo[:], text)
+		nid.DwInfoFlags = uint32(icon)
+	})
+}
+
+// Close removes the icon.
+func (t *Tray) Close() error {
+	t.mu.Lock()
+	win.Shell_NotifyIcon(win.NIM_DELETE, &t.nid)
+	t.destroyIcon()
+	t.mu.Unlock()
+	return t.window.Close()
+}
+
+func (t *Tray) wndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) (uintptr, bool) {
+	switch msg {
+	case t.taskbarCreated:
+		t.mu.Lock()
+		win.Shell_NotifyIcon(win.NIM_ADD, &t.nid)
+		t.mu.Unlock()
+		return 0, true
+	case wmNotify:
+		switch uint32(lParam) {
+		case win.WM_LBUTTONUP:
+			call(t.opts.OnClick)
+		case win.WM_LBUTTONDBLCLK:
+			call(t.opts.OnDoubleClick)
+		case win.NIN_BALLOONUSERCLICK:
+			call(t.opts.OnBalloonClick)
+		case win.WM_RBUTTONUP:
+			t.showMenu(hwnd)
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func call(fn func()) {
+	if fn != nil {
+		go fn()
+	}
+}
+
+func (t *Tray) showMenu(hwnd win.HWND) {
+	t.mu.Lock()
+	m := t.menu
+	t.mu.Unlock()
+	if len(m.items) == 0 {
+		return
+	}
+
+	menu, err := buildMenu(m, m.items)
+	if err != nil {
+		if t.opts.OnError != nil {
+			go t.opts.OnError(err)
+		}
+		return
+	}
+	defer win.DestroyMenu(menu)
+
+	var pt win.POINT
+	win.GetCursorPos(&pt)
+	// the menu only closes on outside clicks when our window is foreground
+	win.SetForegroundWindow(hwnd)
+	id := win.TrackPopupMenu(menu, win.TPM_RIGHTBUTTON|win.TPM_RETURNCMD|win.TPM_NONOTIFY, pt.X, pt.Y, 0, hwnd, nil)
+	win.PostMessage(hwnd, win.WM_NULL, 0, 0)
+
+	if it, checked, ok := m.click(id); ok && it.OnClick != nil {
+		go it.OnClick(it, checked)
+	}
+}
+
+// buildMenu creates the popup menu of items, destroying the menu also
+// destroys its submenus.
+func buildMenu(m *menuModel, items []*Item) (win.HMENU, error) {
+	menu := win.CreatePopupMenu()
+	if menu == 0 {
+		return 0, errors.Wrap(windows.GetLastError(), "CreatePopupMenu")
+	}
+	for i, it := range items {
+		if it == nil {
+			continue
+		}
+		mii := win.MENUITEMINFO{FMask: win.MIIM_FTYPE | win.MIIM_STATE | win.MIIM_ID | win.MIIM_STRING}
+		mii.CbSize = uint32(unsafe.Sizeof(mii))
+		switch {
+		case it.Separator:
+			mii.FMask = win.MIIM_FTYPE
+			mii.FType = win.MFT_SEPARATOR
+		default:
+			mii.WID = m.ids[it]
+			mii.DwTypeData = winapi.MustUTF16PtrFromString(it.Title)
+			if m.checked[it] {
+				mii.FState |= win.MFS_CHECKED
+			}
+			if it.Disabled {
+				mii.FState |= win.MFS_DISABLED
+			}
+			if len(it.Items) > 0 {
+				sub, err := buildMenu(m, it.Items)
+				if err != nil {
+					win.DestroyMenu(menu)
+					return 0, err
+				}
+				mii.FMask |= win.MIIM_SUBMENU
+				mii.HSubMenu = sub
+			}
+		}
+		if !win.InsertMenuItem(menu, uint32(i), true, &mii) {
+			err := errors.Wrapf(windows.GetLastError(), "InsertMenuItem %q", it.Title)
+			if mii.HSubMenu != 0 {
+				win.DestroyMenu(mii.HSubMenu)
+			}
+			win.DestroyMenu(menu)
+			return 0, err
+		}
+	}
+	return menu, nil
+}
@@ -0,0 +1,23 @@
+//go:build 386 || arm
+// +build 386 arm
+
+package uia
+
+import (
+	"unsafe"
+
+	"github.com/lxn/win"
+	"github.com/whiteboxsolutions/go-ole"
+)
+
+// variantArgs passes a VARIANT by value, which 32-bit targets copy word by
+// word into the arguments.
+func variantArgs(v *ole.VARIANT) []uintptr {
+	w := (*[4]uintptr)(unsafe.Pointer(v))
+	return w[:]
+}
+
+// pointArgs passes a POINT by value.
+func pointArgs(pt win.POINT) []uintptr {
+	return []uintptr{uintptr(pt.X), uintptr(pt.Y)}
+}
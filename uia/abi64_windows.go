@@ -0,0 +1,23 @@
+//go:build !386 && !arm
+// +build !386,!arm
+
+package uia
+
+import (
+	"unsafe"
+
+	"github.com/lxn/win"
+	"github.com/whiteboxsolutions/go-ole"
+)
+
+// variantArgs passes a VARIANT by value. 64-bit targets pass structs larger
+// than a register, or two on arm64, as a pointer to a copy; callers keep v
+// alive and do not reuse it after the call.
+func variantArgs(v *ole.VARIANT) []uintptr {
+	return []uintptr{uintptr(unsafe.Pointer(v))}
+}
+
+// pointArgs passes a POINT by value, packed into one register.
+func pointArgs(pt win.POINT) []uintptr {
+	return []uintptr{uintptr(uint32(pt.X)) | uintptr(uint32(pt.Y))<<32}
+}
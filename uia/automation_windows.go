@@ -0,0 +1,302 @@
+package uia
+
+import (
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/go-ole"
+)
+
+// CUIAutomation
+// https://learn.microsoft.com/en-us/windows/win32/api/uiautomationclient/nn-uiautomationclient-iuiautomation
+
+var CLSID_CUIAutomation = ole.NewGUID("{ff48dba4-60ef-4201-aa87-54103eef594e}")
+
+var (
+	ErrNotFound            = errors.New("uia: element not found")
+	ErrPatternNotSupported = errors.New("uia: pattern not supported")
+)
+
+// IUIAutomation
+
+var IUIAutomationID = ole.NewGUID("{30cbe57d-d9d0-452a-ab13-7ac5ac4825ee}")
+
+type IUIAutomation struct {
+	ole.IUnknown
+}
+
+type IUIAutomationVtbl struct {
+	ole.IUnknownVtbl
+	CompareElements                           uintptr
+	CompareRuntimeIds                         uintptr
+	GetRootElement                            uintptr
+	ElementFromHandle                         uintptr
+	ElementFromPoint                          uintptr
+	GetFocusedElement                         uintptr
+	GetRootElementBuildCache                  uintptr
+	ElementFromHandleBuildCache               uintptr
+	ElementFromPointBuildCache                uintptr
+	GetFocusedElementBuildCache               uintptr
+	CreateTreeWalker                          uintptr
+	get_ControlViewWalker                     uintptr
+	get_ContentViewWalker                     uintptr
+	get_RawViewWalker                         uintptr
+	get_RawViewCondition                      uintptr
+	get_ControlViewCondition                  uintptr
+	get_ContentViewCondition                  uintptr
+	CreateCacheRequest                        uintptr
+	CreateTrueCondition                       uintptr
+	CreateFalseCondition                      uintptr
+	CreatePropertyCondition                   uintptr
+	CreatePropertyConditionEx                 uintptr
+	CreateAndCondition                        uintptr
+	CreateAndConditionFromArray               uintptr
+	CreateAndConditionFromNativeArray         uintptr
+	CreateOrCondition                         uintptr
+	CreateOrConditionFromArray                uintptr
+	CreateOrConditionFromNativeArray          uintptr
+	CreateNotCondition                        uintptr
+	AddAutomationEventHandler                 uintptr
+	RemoveAutomationEventHandler              uintptr
+	AddPropertyChangedEventHandlerNativeArray uintptr
+	AddPropertyChangedEventHandler            uintptr
+	RemovePropertyChangedEventHandler         uintptr
+	AddStructureChangedEventHandler           uintptr
+	RemoveStructureChangedEventHandler        uintptr
+	AddFocusChangedEventHandler               uintptr
+	RemoveFocusChangedEventHandler            uintptr
+	RemoveAllEventHandlers                    uintptr
+	IntNativeArrayToSafeArray                 uintptr
+	IntSafeArrayToNativeArray                 uintptr
+	RectToVariant                             uintptr
+	VariantToRect                             uintptr
+	SafeArrayToRectNativeArray                uintptr
+	CreateProxyFactoryEntry                   uintptr
+	get_ProxyFactoryMapping                   uintptr
+	GetPropertyProgrammaticName               uintptr
+	GetPatternProgrammaticName                uintptr
+	PollForPotentialSupportedPatterns         uintptr
+	PollForPotentialSupportedProperties       uintptr
+	CheckNotSupported                         uintptr
+	get_ReservedNotSupportedValue             uintptr
+	get_ReservedMixedAttributeValue           uintptr
+	ElementFromIAccessible                    uintptr
+	ElementFromIAccessibleBuildCache          uintptr
+}
+
+func (v *IUIAutomation) VTable() *IUIAutomationVtbl {
+	return (*IUIAutomationVtbl)(unsafe.Pointer(v.RawVTable))
+}
+
+// NewAutomation creates the UI Automation client. COM must be initialized on
+// the calling thread, UI Automation recommends COINIT_MULTITHREADED.
+func NewAutomation() (*IUIAutomation, error) {
+	unk, err := ole.CreateInstance(CLSID_CUIAutomation, IUIAutomationID)
+	if err != nil {
+		return nil, err
+	}
+	return (*IUIAutomation)(unsafe.Pointer(unk)), nil
+}
+
+func (v *IUIAutomation) getElement(method uintptr, args ...uintptr) (*IUIAutomationElement, error) {
+	var element *IUIAutomationElement
+	args = append([]uintptr{uintptr(unsafe.Pointer(v))}, args...)
+	r1, _, _ := syscall.SyscallN(method, append(args, uintptr(unsafe.Pointer(&element)))...)
+	if r1 != win.S_OK {
+		return nil, ole.NewError(r1)
+	}
+	if element == nil {
+		return nil, ErrNotFound
+	}
+	return element, nil
+}
+
+// GetRootElement returns the desktop.
+func (v *IUIAutomation) GetRootElement() (*IUIAutomationElement, error) {
+	return v.getElement(v.VTable().GetRootElement)
+}
+
+func (v *IUIAutomation) ElementFromHandle(hwnd win.HWND) (*IUIAutomationElement, error) {
+	return v.getElement(v.VTable().ElementFromHandle, uintptr(hwnd))
+}
+
+func (v *IUIAutomation) ElementFromPoint(pt win.POINT) (*IUIAutomationElement, error) {
+	return v.getElement(v.VTable().ElementFromPoint, pointArgs(pt)...)
+}
+
+func (v *IUIAutomation) GetFocusedElement() (*IUIAutomationElement, error) {
+	return v.getElement(v.VTable().GetFocusedElement)
+}
+
+// CompareElements reports whether a and b are the same element.
+func (v *IUIAutomation) CompareElements(a, b *IUIAutomationElement) (bool, error) {
+	var same int32
+	r1, _, _ := syscall.SyscallN(v.VTable().CompareElements, uintptr(unsafe.Pointer(v)), uintptr(unsafe.Pointer(a)), uintptr(unsafe.Pointer(b)), uintptr(unsafe.Pointer(&same)))
+	if r1 != win.S_OK {
+		return false, ole.NewError(r1)
+	}
+	return same != 0, nil
+}
+
+func (v *IUIAutomation) getWalker(method uintptr, args ...uintptr) (*IUIAutomationTreeWalker, error) {
+	var walker *IUIAutomationTreeWalker
+	args = append([]uintptr{uintptr(unsafe.Pointer(v))}, args...)
+	r1, _, _ := syscall.SyscallN(method, append(args, uintptr(unsafe.Pointer(&walker)))...)
+	if r1 != win.S_OK {
+		return nil, ole.NewError(r1)
+	}
+	return walker, nil
+}
+
+// ControlViewWalker walks the elements that are controls.
+func (v *IUIAutomation) ControlViewWalker() (*IUIAutomationTreeWalker, error) {
+	return v.getWalker(v.VTable().get_ControlViewWalker)
+}
+
+// ContentViewWalker walks the elements that carry content for the user.
+func (v *IUIAutomation) ContentViewWalker() (*IUIAutomationTreeWalker, error) {
+	return v.getWalker(v.VTable().get_ContentViewWalker)
+}
+
+// RawViewWalker walks every element.
+func (v *IUIAutomation) RawViewWalker() (*IUIAutomationTreeWalker, error) {
+	return v.getWalker(v.VTable().get_RawViewWalker)
+}
+
+// CreateTreeWalker walks the elements matching c.
+func (v *IUIAutomation) CreateTreeWalker(c Condition) (*IUIAutomationTreeWalker, error) {
+	cond, err := v.CreateCondition(c)
+	if err != nil {
+		return nil, err
+	}
+	defer cond.Release()
+	return v.getWalker(v.VTable().CreateTreeWalker, uintptr(unsafe.Pointer(cond)))
+}
+
+// IUIAutomationCondition
+
+var IUIAutomationConditionID = ole.NewGUID("{352ffba8-0973-437c-a61f-f64cafd81df9}")
+
+type IUIAutomationCondition struct {
+	ole.IUnknown
+}
+
+func (v *IUIAutomation) newCondition(method uintptr, args ...uintptr) (*IUIAutomationCondition, error) {
+	var cond *IUIAutomationCondition
+	args = append([]uintptr{uintptr(unsafe.Pointer(v))}, args...)
+	r1, _, _ := syscall.SyscallN(method, append(args, uintptr(unsafe.Pointer(&cond)))...)
+	if r1 != win.S_OK {
+		return nil, ole.NewError(r1)
+	}
+	return cond, nil
+}
+
+// CreateCondition builds the COM condition for c.
+func (v *IUIAutomation) CreateCondition(c Condition) (*IUIAutomationCondition, error) {
+	switch c.Kind {
+	case TrueCondition:
+		return v.newCondition(v.VTable().CreateTrueCondition)
+	case FalseCondition:
+		return v.newCondition(v.VTable().CreateFalseCondition)
+	case PropertyCondition:
+		val, err := toVariant(c.Value)
+		if err != nil {
+			return nil, errors.Wrap(err, c.Property.String())
+		}
+		defer ole.VariantClear(&val)
+		args := append([]uintptr{uintptr(c.Property)}, variantArgs(&val)...)
+		var cond *IUIAutomationCondition
+		if c.Flags == 0 {
+			cond, err = v.newCondition(v.VTable().CreatePropertyCondition, args...)
+		} else {
+			cond, err = v.newCondition(v.VTable().CreatePropertyConditionEx, append(args, uintptr(c.Flags))...)
+		}
+		runtime.KeepAlive(&val)
+		return cond, err
+	case AndCondition, OrCondition:
+		if len(c.Operands) == 0 {
+			// an empty conjunction holds, an empty disjunction does not
+			if c.Kind == AndCondition {
+				return v.CreateCondition(True())
+			}
+			return v.CreateCondition(False())
+		}
+		ops := make([]*IUIAutomationCondition, 0, len(c.Operands))
+		defer func() {
+			for _, op := range ops {
+				op.Release()
+			}
+		}()
+		for _, o := range c.Operands {
+			op, err := v.CreateCondition(o)
+			if err != nil {
+				return nil, err
+			}
+			ops = append(ops, op)
+		}
+		method := v.VTable().CreateAndConditionFromNativeArray
+		if c.Kind == OrCondition {
+			method = v.VTable().CreateOrConditionFromNativeArray
+		}
+		return v.newCondition(method, uintptr(unsafe.Pointer(&ops[0])), uintptr(len(ops)))
+	case NotCondition:
+		op, err := v.CreateCondition(c.Operands[0])
+		if err != nil {
+			return nil, err
+		}
+		defer op.Release()
+		return v.newCondition(v.VTable().CreateNotCondition, uintptr(unsafe.Pointer(op)))
+	}
+	return nil, errors.Errorf("uia: unknown condition kind %d", c.Kind)
+}
+
+// toVariant converts a property value of a Condition.
+func toVariant(v interface{}) (ole.VARIANT, error) {
+	switch v := v.(type) {
+	case string:
+		return ole.NewVariant(ole.VT_BSTR, int64(uintptr(unsafe.Pointer(ole.SysAllocString(v))))), nil
+	case bool:
+		if v {
+			return ole.NewVariant(ole.VT_BOOL, -1), nil // VARIANT_TRUE
+		}
+		return ole.NewVariant(ole.VT_BOOL, 0), nil
+	case win.HWND:
+		return ole.NewVariant(ole.VT_I4, int64(int32(v))), nil
+	}
+	if i, ok := normalize(v).(int32); ok {
+		return ole.NewVariant(ole.VT_I4, int64(i)), nil
+	}
+	return ole.VARIANT{}, errors.Errorf("unsupported value type %T", v)
+}
+
+// FindFirst returns the first element in scope of root matching c.
+func (v *IUIAutomation) FindFirst(root *IUIAutomationElement, scope TreeScope, c Condition) (*IUIAutomationElement, error) {
+	cond, err := v.CreateCondition(c)
+	if err != nil {
+		return nil, err
+	}
+	defer cond.Release()
+	return root.FindFirst(scope, cond)
+}
+
+// FindAll returns the elements in scope of root matching c.
+func (v *IUIAutomation) FindAll(root *IUIAutomationElement, scope TreeScope, c Condition) ([]*IUIAutomationElement, error) {
+	cond, err := v.CreateCondition(c)
+	if err != nil {
+		return nil, err
+	}
+	defer cond.Release()
+	return root.FindAll(scope, cond)
+}
+
+// RemoveAllEventHandlers removes every handler added through v.
+func (v *IUIAutomation) RemoveAllEventHandlers() error {
+	r1, _, _ := syscall.SyscallN(v.VTable().RemoveAllEventHandlers, uintptr(unsafe.Pointer(v)))
+	if r1 != win.S_OK {
+		return ole.NewError(r1)
+	}
+	return nil
+}
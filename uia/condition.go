@@ -0,0 +1,248 @@
+package uia
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConditionKind is the type of a Condition node.
+type ConditionKind int
+
+const (
+	TrueCondition ConditionKind = iota
+	FalseCondition
+	PropertyCondition
+	AndCondition
+	OrCondition
+	NotCondition
+)
+
+// PropertyConditionFlags, PropertyConditionFlags_* of
+// CreatePropertyConditionEx. They only apply to string properties.
+type PropertyConditionFlags int32
+
+const (
+	MatchIgnoreCase PropertyConditionFlags = 0x1
+	MatchSubstring  PropertyConditionFlags = 0x2
+
+	supportedMatchFlags = MatchIgnoreCase | MatchSubstring
+)
+
+// Condition describes which elements a search returns. It is built in Go
+// and turned into an IUIAutomationCondition only when used.
+type Condition struct {
+	Kind ConditionKind
+
+	// Property conditions compare Property with Value, which is a string,
+	// bool, int32 or ControlType.
+	Property PropertyID
+	Value    interface{}
+	Flags    PropertyConditionFlags
+
+	// Operands of And, Or and Not.
+	Operands []Condition
+}
+
+// True matches every element.
+func True() Condition {
+	return Condition{Kind: TrueCondition}
+}
+
+// False matches no element.
+func False() Condition {
+	return Condition{Kind: FalseCondition}
+}
+
+// Property matches elements whose property p equals v.
+func Property(p PropertyID, v interface{}) Condition {
+	return Condition{Kind: PropertyCondition, Property: p, Value: v}
+}
+
+// PropertyEx is Property with MatchIgnoreCase or MatchSubstring flags.
+func PropertyEx(p PropertyID, v string, flags PropertyConditionFlags) Condition {
+	return Condition{Kind: PropertyCondition, Property: p, Value: v, Flags: flags & supportedMatchFlags}
+}
+
+// Name matches elements named name.
+func Name(name string) Condition {
+	return Property(NameProperty, name)
+}
+
+// NameContains matches elements whose name contains s, ignoring case.
+func NameContains(s string) Condition {
+	return PropertyEx(NameProperty, s, MatchIgnoreCase|MatchSubstring)
+}
+
+// AutomationID matches elements with the given automation id.
+func AutomationID(id string) Condition {
+	return Property(AutomationIdProperty, id)
+}
+
+// ClassName matches elements of the given class.
+func ClassName(class string) Condition {
+	return Property(ClassNameProperty, class)
+}
+
+// Type matches elements of control type t.
+func Type(t ControlType) Condition {
+	return Property(ControlTypeProperty, t)
+}
+
+// And matches elements all conditions match. Without conditions it is True.
+func And(conds ...Condition) Condition {
+	return combine(AndCondition, TrueCondition, FalseCondition, conds)
+}
+
+// Or matches elements any condition matches. Without conditions it is False.
+func Or(conds ...Condition) Condition {
+	return combine(OrCondition, FalseCondition, TrueCondition, conds)
+}
+
+// combine flattens nested operations of the same kind and folds the
+// constants: identity operands are dropped, an absorbing one wins.
+func combine(kind, identity, absorbing ConditionKind, conds []Condition) Condition {
+	var ops []Condition
+	for _, c := range conds {
+		switch c.Kind {
+		case identity:
+			continue
+		case absorbing:
+			return c
+		case kind:
+			ops = append(ops, c.Operands...)
+		default:
+			ops = append(ops, c)
+		}
+	}
+	switch len(ops) {
+	case 0:
+		return Condition{Kind: identity}
+	case 1:
+		return ops[0]
+	}
+	return Condition{Kind: kind, Operands: ops}
+}
+
+// Not inverts c.
+func Not(c Condition) Condition {
+	switch c.Kind {
+	case TrueCondition:
+		return False()
+	case FalseCondition:
+		return True()
+	case NotCondition:
+		return c.Operands[0]
+	}
+	return Condition{Kind: NotCondition, Operands: []Condition{c}}
+}
+
+// Matches evaluates c against the properties returned by get, the way UI
+// Automation would.
+func (c Condition) Matches(get func(PropertyID) interface{}) bool {
+	switch c.Kind {
+	case TrueCondition:
+		return true
+	case FalseCondition:
+		return false
+	case PropertyCondition:
+		return c.matchValue(get(c.Property))
+	case AndCondition:
+		for _, op := range c.Operands {
+			if !op.Matches(get) {
+				return false
+			}
+		}
+		return true
+	case OrCondition:
+		for _, op := range c.Operands {
+			if op.Matches(get) {
+				return true
+			}
+		}
+		return false
+	case NotCondition:
+		return !c.Operands[0].Matches(get)
+	}
+	return false
+}
+
+func (c Condition) matchValue(v interface{}) bool {
+	want, ok := c.Value.(string)
+	if !ok {
+		return normalize(v) == normalize(c.Value)
+	}
+	got, ok := v.(string)
+	if !ok {
+		return false
+	}
+	if c.Flags&MatchIgnoreCase != 0 {
+		want, got = strings.ToLower(want), strings.ToLower(got)
+	}
+	if c.Flags&MatchSubstring != 0 {
+		return strings.Contains(got, want)
+	}
+	return got == want
+}
+
+// normalize maps the integer types a property may be reported as to int32,
+// the VT_I4 UI Automation uses.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case ControlType:
+		return int32(v)
+	case ToggleState:
+		return int32(v)
+	case int:
+		return int32(v)
+	case uint32:
+		return int32(v)
+	case int64:
+		return int32(v)
+	}
+	return v
+}
+
+func (c Condition) String() string {
+	switch c.Kind {
+	case TrueCondition:
+		return "true"
+	case FalseCondition:
+		return "false"
+	case PropertyCondition:
+		op := "="
+		switch c.Flags {
+		case MatchIgnoreCase:
+			op = "=~"
+		case MatchSubstring:
+			op = "*="
+		case MatchIgnoreCase | MatchSubstring:
+			op = "*=~"
+		}
+		return fmt.Sprintf("%s%s%s", c.Property, op, formatValue(c.Property, c.Value))
+	case AndCondition, OrCondition:
+		sep := " AND "
+		if c.Kind == OrCondition {
+			sep = " OR "
+		}
+		ops := make([]string, len(c.Operands))
+		for i, op := range c.Operands {
+			ops[i] = op.String()
+		}
+		return "(" + strings.Join(ops, sep) + ")"
+	case NotCondition:
+		return "NOT " + c.Operands[0].String()
+	}
+	return fmt.Sprintf("Condition(%d)", int(c.Kind))
+}
+
+func formatValue(p PropertyID, v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case int32:
+		if p == ControlTypeProperty {
+			return ControlType(v).String()
+		}
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,146 @@
+package uia
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCombine(t *testing.T) {
+	a, b, c := Name("a"), ClassName("b"), AutomationID("c")
+	tests := []struct {
+		name string
+		got  Condition
+		want Condition
+	}{
+		{"empty And", And(), True()},
+		{"empty Or", Or(), False()},
+		{"single And", And(a), a},
+		{"And drops True", And(True(), a, True()), a},
+		{"And with False", And(a, False(), b), False()},
+		{"Or drops False", Or(False(), a), a},
+		{"Or with True", Or(a, True()), True()},
+		{"And flattens", And(And(a, b), c), Condition{Kind: AndCondition, Operands: []Condition{a, b, c}}},
+		{"Or flattens", Or(a, Or(b, c)), Condition{Kind: OrCondition, Operands: []Condition{a, b, c}}},
+		{"And keeps Or", And(a, Or(b, c)), Condition{Kind: AndCondition, Operands: []Condition{a, {Kind: OrCondition, Operands: []Condition{b, c}}}}},
+		{"Not True", Not(True()), False()},
+		{"Not False", Not(False()), True()},
+		{"double Not", Not(Not(a)), a},
+		{"Not", Not(a), Condition{Kind: NotCondition, Operands: []Condition{a}}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestPropertyEx(t *testing.T) {
+	c := PropertyEx(NameProperty, "x", MatchIgnoreCase|0x40)
+	if c.Flags != MatchIgnoreCase {
+		t.Errorf("Flags = %#x, want unsupported flags dropped", c.Flags)
+	}
+	if c := NameContains("x"); c.Flags != MatchIgnoreCase|MatchSubstring || c.Property != NameProperty {
+		t.Errorf("NameContains = %+v", c)
+	}
+	if c := Type(EditControl); c.Property != ControlTypeProperty || c.Value != EditControl {
+		t.Errorf("Type = %+v", c)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	props := map[PropertyID]interface{}{
+		NameProperty:         "Save As",
+		ClassNameProperty:    "Button",
+		ControlTypeProperty:  int32(ButtonControl),
+		IsEnabledProperty:    true,
+		ProcessIdProperty:    int32(42),
+		AutomationIdProperty: "1",
+	}
+	get := func(p PropertyID) interface{} { return props[p] }
+
+	tests := []struct {
+		c    Condition
+		want bool
+	}{
+		{True(), true},
+		{False(), false},
+		{Name("Save As"), true},
+		{Name("save as"), false},
+		{PropertyEx(NameProperty, "save as", MatchIgnoreCase), true},
+		{PropertyEx(NameProperty, "As", MatchSubstring), true},
+		{PropertyEx(NameProperty, "as", MatchSubstring), false},
+		{NameContains("VE A"), true},
+		{Type(ButtonControl), true},
+		{Type(EditControl), false},
+		{Property(ProcessIdProperty, 42), true},
+		{Property(ProcessIdProperty, uint32(42)), true},
+		{Property(ProcessIdProperty, int64(43)), false},
+		{Property(IsEnabledProperty, true), true},
+		{Property(IsEnabledProperty, false), false},
+		// a string condition never matches a value of another type
+		{Property(ProcessIdProperty, "42"), false},
+		{Name(""), false},
+		// missing properties match nothing
+		{Property(HelpTextProperty, ""), false},
+		{And(ClassName("Button"), Name("Save As")), true},
+		{And(ClassName("Button"), Name("Open")), false},
+		{Or(Name("Open"), Name("Save As")), true},
+		{Or(Name("Open"), Name("Close")), false},
+		{Not(Name("Open")), true},
+		{And(Type(ButtonControl), Not(Or(Name("Open"), Property(IsEnabledProperty, false)))), true},
+	}
+	for _, tt := range tests {
+		if got := tt.c.Matches(get); got != tt.want {
+			t.Errorf("%v matches = %v, want %v", tt.c, got, tt.want)
+		}
+	}
+}
+
+func TestConditionString(t *testing.T) {
+	tests := []struct {
+		c    Condition
+		want string
+	}{
+		{True(), "true"},
+		{False(), "false"},
+		{Name("OK"), `Name="OK"`},
+		{PropertyEx(NameProperty, "ok", MatchIgnoreCase), `Name=~"ok"`},
+		{PropertyEx(NameProperty, "ok", MatchSubstring), `Name*="ok"`},
+		{NameContains("ok"), `Name*=~"ok"`},
+		{Type(ButtonControl), "ControlType=Button"},
+		{Property(ControlTypeProperty, int32(EditControl)), "ControlType=Edit"},
+		{Property(IsEnabledProperty, true), "IsEnabled=true"},
+		{Property(PropertyID(1), 3), "Property(1)=3"},
+		{And(Name("a"), Or(ClassName("b"), Not(AutomationID("c")))), `(Name="a" AND (ClassName="b" OR NOT AutomationId="c"))`},
+		{Condition{Kind: 99}, "Condition(99)"},
+	}
+	for _, tt := range tests {
+		if got := tt.c.String(); got != tt.want {
+			t.Errorf("String() = %s, want %s", got, tt.want)
+		}
+	}
+}
+
+func TestControlTypeNames(t *testing.T) {
+	if len(controlTypeNames) != int(AppBarControl-ButtonControl)+1 {
+		t.Fatalf("%d control type names for %d types", len(controlTypeNames), AppBarControl-ButtonControl+1)
+	}
+	for ct := ButtonControl; ct <= AppBarControl; ct++ {
+		got, ok := ParseControlType(ct.String())
+		if !ok || got != ct {
+			t.Errorf("ParseControlType(%q) = %d, %v, want %d", ct.String(), got, ok, ct)
+		}
+	}
+	if ct, ok := ParseControlType("checkbox"); !ok || ct != CheckBoxControl {
+		t.Errorf("ParseControlType ignores case: %d, %v", ct, ok)
+	}
+	if _, ok := ParseControlType("Widget"); ok {
+		t.Error("ParseControlType accepted an unknown name")
+	}
+	if got := ControlType(1).String(); got != "ControlType(1)" {
+		t.Errorf("String() = %s", got)
+	}
+	if ButtonControl != 50000 || AppBarControl != 50040 || WindowControl != 50032 {
+		t.Errorf("control type ids differ from UIAutomationClient.h")
+	}
+}
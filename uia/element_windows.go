@@ -0,0 +1,387 @@
+package uia
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/lxn/win"
+	"github.com/whiteboxsolutions/go-ole"
+)
+
+// IUIAutomationElement
+// https://learn.microsoft.com/en-us/windows/win32/api/uiautomationclient/nn-uiautomationclient-iuiautomationelement
+
+var IUIAutomationElementID = ole.NewGUID("{d22108aa-8ac5-49a5-837b-37bbb3d7591e}")
+
+type IUIAutomationElement struct {
+	ole.IUnknown
+}
+
+type IUIAutomationElementVtbl struct {
+	ole.IUnknownVtbl
+	SetFocus                        uintptr
+	GetRuntimeId                    uintptr
+	FindFirst                       uintptr
+	FindAll                         uintptr
+	FindFirstBuildCache             uintptr
+	FindAllBuildCache               uintptr
+	BuildUpdatedCache               uintptr
+	GetCurrentPropertyValue         uintptr
+	GetCurrentPropertyValueEx       uintptr
+	GetCachedPropertyValue          uintptr
+	GetCachedPropertyValueEx        uintptr
+	GetCurrentPatternAs             uintptr
+	GetCachedPatternAs              uintptr
+	GetCurrentPattern               uintptr
+	GetCachedPattern                uintptr
+	GetCachedParent                 uintptr
+	GetCachedChildren               uintptr
+	get_CurrentProcessId            uintptr
+	get_CurrentControlType          uintptr
+	get_CurrentLocalizedControlType uintptr
+	get_CurrentName                 uintptr
+	get_CurrentAcceleratorKey       uintptr
+	get_CurrentAccessKey            uintptr
+	get_CurrentHasKeyboardFocus     uintptr
+	get_CurrentIsKeyboardFocusable  uintptr
+	get_CurrentIsEnabled            uintptr
+	get_CurrentAutomationId         uintptr
+	get_CurrentClassName            uintptr
+	get_CurrentHelpText             uintptr
+	get_CurrentCulture              uintptr
+	get_CurrentIsControlElement     uintptr
+	get_CurrentIsContentElement     uintptr
+	get_CurrentIsPassword           uintptr
+	get_CurrentNativeWindowHandle   uintptr
+	get_CurrentItemType             uintptr
+	get_CurrentIsOffscreen          uintptr
+	get_CurrentOrientation          uintptr
+	get_CurrentFrameworkId          uintptr
+	get_CurrentIsRequiredForForm    uintptr
+	get_CurrentItemStatus           uintptr
+	get_CurrentBoundingRectangle    uintptr
+}
+
+func (v *IUIAutomationElement) VTable() *IUIAutomationElementVtbl {
+	return (*IUIAutomationElementVtbl)(unsafe.Pointer(v.RawVTable))
+}
+
+// bstrToString converts and frees a BSTR returned by a call.
+func bstrToString(p *uint16) string {
+	if p == nil {
+		return ""
+	}
+	s := ole.BstrToString(p)
+	ole.SysFreeString((*int16)(unsafe.Pointer(p)))
+	return s
+}
+
+func (v *IUIAutomationElement) SetFocus() error {
+	r1, _, _ := syscall.SyscallN(v.VTable().SetFocus, uintptr(unsafe.Pointer(v)))
+	if r1 != win.S_OK {
+		return ole.NewError(r1)
+	}
+	return nil
+}
+
+// FindFirst returns the first element in scope matching cond, or
+// ErrNotFound.
+func (v *IUIAutomationElement) FindFirst(scope TreeScope, cond *IUIAutomationCondition) (*IUIAutomationElement, error) {
+	var element *IUIAutomationElement
+	r1, _, _ := syscall.SyscallN(v.VTable().FindFirst, uintptr(unsafe.Pointer(v)), uintptr(scope), uintptr(unsafe.Pointer(cond)), uintptr(unsafe.Pointer(&element)))
+	if r1 != win.S_OK {
+		return nil, ole.NewError(r1)
+	}
+	if element == nil {
+		return nil, ErrNotFound
+	}
+	return element, nil
+}
+
+// FindAll returns the elements in scope matching cond.
+func (v *IUIAutomationElement) FindAll(scope TreeScope, cond *IUIAutomationCondition) ([]*IUIAutomationElement, error) {
+	var array *IUIAutomationElementArray
+	r1, _, _ := syscall.SyscallN(v.VTable().FindAll, uintptr(unsafe.Pointer(v)), uintptr(scope), uintptr(unsafe.Pointer(cond)), uintptr(unsafe.Pointer(&array)))
+	if r1 != win.S_OK {
+		return nil, ole.NewError(r1)
+	}
+	return array.elements()
+}
+
+// GetCurrentPropertyValue returns the value of property id as a Go value,
+// see ole.VARIANT.Value.
+func (v *IUIAutomationElement) GetCurrentPropertyValue(id PropertyID) (interface{}, error) {
+	var val ole.VARIANT
+	r1, _, _ := syscall.SyscallN(v.VTable().GetCurrentPropertyValue, uintptr(unsafe.Pointer(v)), uintptr(id), uintptr(unsafe.Pointer(&val)))
+	if r1 != win.S_OK {
+		return nil, ole.NewError(r1)
+	}
+	defer ole.VariantClear(&val)
+	return val.Value(), nil
+}
+
+// Property returns the value of property id or nil, it fits
+// Condition.Matches.
+func (v *IUIAutomationElement) Property(id PropertyID) interface{} {
+	val, _ := v.GetCurrentPropertyValue(id)
+	return val
+}
+
+// GetCurrentPatternAs queries the pattern as the interface riid, the result
+// is nil when the element does not support it.
+func (v *IUIAutomationElement) GetCurrentPatternAs(id PatternID, riid *ole.GUID) (unsafe.Pointer, error) {
+	var pattern unsafe.Pointer
+	r1, _, _ := syscall.SyscallN(v.VTable().GetCurrentPatternAs, uintptr(unsafe.Pointer(v)), uintptr(id), uintptr(unsafe.Pointer(riid)), uintptr(unsafe.Pointer(&pattern)))
+	if r1 != win.S_OK {
+		return nil, ole.NewError(r1)
+	}
+	if pattern == nil {
+		return nil, ErrPatternNotSupported
+	}
+	return pattern, nil
+}
+
+func (v *IUIAutomationElement) getString(method uintptr) (string, error) {
+	var bstr *uint16
+	r1, _, _ := syscall.SyscallN(method, uintptr(unsafe.Pointer(v)), uintptr(unsafe.Pointer(&bstr)))
+	if r1 != win.S_OK {
+		return "", ole.NewError(r1)
+	}
+	return bstrToString(bstr), nil
+}
+
+func (v *IUIAutomationElement) getInt32(method uintptr) (int32, error) {
+	var ret int32
+	r1, _, _ := syscall.SyscallN(method, uintptr(unsafe.Pointer(v)), uintptr(unsafe.Pointer(&ret)))
+	if r1 != win.S_OK {
+		return 0, ole.NewError(r1)
+	}
+	return ret, nil
+}
+
+func (v *IUIAutomationElement) getBool(method uintptr) (bool, error) {
+	ret, err := v.getInt32(method)
+	return ret != 0, err
+}
+
+func (v *IUIAutomationElement) CurrentName() (string, error) {
+	return v.getString(v.VTable().get_CurrentName)
+}
+
+func (v *IUIAutomationElement) CurrentAutomationId() (string, error) {
+	return v.getString(v.VTable().get_CurrentAutomationId)
+}
+
+func (v *IUIAutomationElement) CurrentClassName() (string, error) {
+	return v.getString(v.VTable().get_CurrentClassName)
+}
+
+func (v *IUIAutomationElement) CurrentFrameworkId() (string, error) {
+	return v.getString(v.VTable().get_CurrentFrameworkId)
+}
+
+func (v *IUIAutomationElement) CurrentLocalizedControlType() (string, error) {
+	return v.getString(v.VTable().get_CurrentLocalizedControlType)
+}
+
+func (v *IUIAutomationElement) CurrentHelpText() (string, error) {
+	return v.getString(v.VTable().get_CurrentHelpText)
+}
+
+func (v *IUIAutomationElement) CurrentControlType() (ControlType, error) {
+	t, err := v.getInt32(v.VTable().get_CurrentControlType)
+	return ControlType(t), err
+}
+
+func (v *IUIAutomationElement) CurrentProcessId() (uint32, error) {
+	pid, err := v.getInt32(v.VTable().get_CurrentProcessId)
+	return uint32(pid), err
+}
+
+func (v *IUIAutomationElement) CurrentNativeWindowHandle() (win.HWND, error) {
+	var hwnd win.HWND
+	r1, _, _ := syscall.SyscallN(v.VTable().get_CurrentNativeWindowHandle, uintptr(unsafe.Pointer(v)), uintptr(unsafe.Pointer(&hwnd)))
+	if r1 != win.S_OK {
+		return 0, ole.NewError(r1)
+	}
+	return hwnd, nil
+}
+
+func (v *IUIAutomationElement) CurrentIsEnabled() (bool, error) {
+	return v.getBool(v.VTable().get_CurrentIsEnabled)
+}
+
+func (v *IUIAutomationElement) CurrentIsOffscreen() (bool, error) {
+	return v.getBool(v.VTable().get_CurrentIsOffscreen)
+}
+
+func (v *IUIAutomationElement) CurrentHasKeyboardFocus() (bool, error) {
+	return v.getBool(v.VTable().get_CurrentHasKeyboardFocus)
+}
+
+func (v *IUIAutomationElement) CurrentBoundingRectangle() (win.RECT, error) {
+	var r win.RECT
+	r1, _, _ := syscall.SyscallN(v.VTable().get_CurrentBoundingRectangle, uintptr(unsafe.Pointer(v)), uintptr(unsafe.Pointer(&r)))
+	if r1 != win.S_OK {
+		return r, ole.NewError(r1)
+	}
+	return r, nil
+}
+
+// IUIAutomationElementArray
+
+var IUIAutomationElementArrayID = ole.NewGUID("{14314595-b4bc-4055-95f2-58f2e42c9855}")
+
+type IUIAutomationElementArray struct {
+	ole.IUnknown
+}
+
+type IUIAutomationElementArrayVtbl struct {
+	ole.IUnknownVtbl
+	get_Length uintptr
+	GetElement uintptr
+}
+
+func (v *IUIAutomationElementArray) VTable() *IUIAutomationElementArrayVtbl {
+	return (*IUIAutomationElementArrayVtbl)(unsafe.Pointer(v.RawVTable))
+}
+
+func (v *IUIAutomationElementArray) Length() (int, error) {
+	var n int32
+	r1, _, _ := syscall.SyscallN(v.VTable().get_Length, uintptr(unsafe.Pointer(v)), uintptr(unsafe.Pointer(&n)))
+	if r1 != win.S_OK {
+		return 0, ole.NewError(r1)
+	}
+	return int(n), nil
+}
+
+func (v *IUIAutomationElementArray) GetElement(i int) (*IUIAutomationElement, error) {
+	var element *IUIAutomationElement
+	r1, _, _ := syscall.SyscallN(v.VTable().GetElement, uintptr(unsafe.Pointer(v)), uintptr(i), uintptr(unsafe.Pointer(&element)))
+	if r1 != win.S_OK {
+		return nil, ole.NewError(r1)
+	}
+	return element, nil
+}
+
+// elements copies the array into a slice and releases the array. A nil
+// array is empty.
+func (v *IUIAutomationElementArray) elements() ([]*IUIAutomationElement, error) {
+	if v == nil {
+		return nil, nil
+	}
+	defer v.Release()
+
+	n, err := v.Length()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*IUIAutomationElement, 0, n)
+	for i := 0; i < n; i++ {
+		e, err := v.GetElement(i)
+		if err != nil {
+			for _, e := range res {
+				e.Release()
+			}
+			return nil, err
+		}
+		res = append(res, e)
+	}
+	return res, nil
+}
+
+// IUIAutomationTreeWalker
+// https://learn.microsoft.com/en-us/windows/win32/api/uiautomationclient/nn-uiautomationclient-iuiautomationtreewalker
+
+var IUIAutomationTreeWalkerID = ole.NewGUID("{4042c624-389c-4afc-a630-9df854a541fc}")
+
+type IUIAutomationTreeWalker struct {
+	ole.IUnknown
+}
+
+type IUIAutomationTreeWalkerVtbl struct {
+	ole.IUnknownVtbl
+	GetParentElement                    uintptr
+	GetFirstChildElement                uintptr
+	GetLastChildElement                 uintptr
+	GetNextSiblingElement               uintptr
+	GetPreviousSiblingElement           uintptr
+	NormalizeElement                    uintptr
+	GetParentElementBuildCache          uintptr
+	GetFirstChildElementBuildCache      uintptr
+	GetLastChildElementBuildCache       uintptr
+	GetNextSiblingElementBuildCache     uintptr
+	GetPreviousSiblingElementBuildCache uintptr
+	NormalizeElementBuildCache          uintptr
+	get_Condition                       uintptr
+}
+
+func (v *IUIAutomationTreeWalker) VTable() *IUIAutomationTreeWalkerVtbl {
+	return (*IUIAutomationTreeWalkerVtbl)(unsafe.Pointer(v.RawVTable))
+}
+
+// step returns the related element, nil at the end of the tree.
+func (v *IUIAutomationTreeWalker) step(method uintptr, e *IUIAutomationElement) (*IUIAutomationElement, error) {
+	var element *IUIAutomationElement
+	r1, _, _ := syscall.SyscallN(method, uintptr(unsafe.Pointer(v)), uintptr(unsafe.Pointer(e)), uintptr(unsafe.Pointer(&element)))
+	if r1 != win.S_OK {
+		return nil, ole.NewError(r1)
+	}
+	return element, nil
+}
+
+// GetParentElement returns the parent of e, nil for the root.
+func (v *IUIAutomationTreeWalker) GetParentElement(e *IUIAutomationElement) (*IUIAutomationElement, error) {
+	return v.step(v.VTable().GetParentElement, e)
+}
+
+// GetFirstChildElement returns the first child of e, nil when it has none.
+func (v *IUIAutomationTreeWalker) GetFirstChildElement(e *IUIAutomationElement) (*IUIAutomationElement, error) {
+	return v.step(v.VTable().GetFirstChildElement, e)
+}
+
+// GetLastChildElement returns the last child of e, nil when it has none.
+func (v *IUIAutomationTreeWalker) GetLastChildElement(e *IUIAutomationElement) (*IUIAutomationElement, error) {
+	return v.step(v.VTable().GetLastChildElement, e)
+}
+
+// GetNextSiblingElement returns the next sibling of e, nil for the last.
+func (v *IUIAutomationTreeWalker) GetNextSiblingElement(e *IUIAutomationElement) (*IUIAutomationElement, error) {
+	return v.step(v.VTable().GetNextSiblingElement, e)
+}
+
+// GetPreviousSiblingElement returns the previous sibling of e, nil for the
+// first.
+func (v *IUIAutomationTreeWalker) GetPreviousSiblingElement(e *IUIAutomationElement) (*IUIAutomationElement, error) {
+	return v.step(v.VTable().GetPreviousSiblingElement, e)
+}
+
+// NormalizeElement returns e or its nearest ancestor in the view of v.
+func (v *IUIAutomationTreeWalker) NormalizeElement(e *IUIAutomationElement) (*IUIAutomationElement, error) {
+	return v.step(v.VTable().NormalizeElement, e)
+}
+
+// Walk visits root and its descendants depth first. Returning false from fn
+// skips the children of an element. Elements are released after fn returns,
+// AddRef those kept.
+func (v *IUIAutomationTreeWalker) Walk(root *IUIAutomationElement, fn func(e *IUIAutomationElement, depth int) bool) error {
+	return v.walk(root, 0, fn)
+}
+
+func (v *IUIAutomationTreeWalker) walk(e *IUIAutomationElement, depth int, fn func(e *IUIAutomationElement, depth int) bool) error {
+	if !fn(e, depth) {
+		return nil
+	}
+	child, err := v.GetFirstChildElement(e)
+	for child != nil && err == nil {
+		if err = v.walk(child, depth+1, fn); err == nil {
+			var next *IUIAutomationElement
+			next, err = v.GetNextSiblingElement(child)
+			child.Release()
+			child = next
+			continue
+		}
+		child.Release()
+	}
+	return err
+}
@@ -0,0 +1,160 @@
+package uia
+
+import (
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+
+	"github.com/lxn/win"
+	"github.com/whiteboxsolutions/go-ole"
+	"github.com/whiteboxsolutions/winapi/winrt"
+)
+
+// IUIAutomationEventHandler
+// https://learn.microsoft.com/en-us/windows/win32/api/uiautomationclient/nn-uiautomationclient-iuiautomationeventhandler
+
+var IUIAutomationEventHandlerID = ole.NewGUID("{146c3c17-f12e-4e22-8c27-f894b9b79c69}")
+
+// IUIAutomationFocusChangedEventHandler
+
+var IUIAutomationFocusChangedEventHandlerID = ole.NewGUID("{c270f6b5-5c69-4290-9745-7a7f97169468}")
+
+// eventHandlerVtbl is shared by both handler interfaces, which only add a
+// single Handle method to IUnknown.
+type eventHandlerVtbl struct {
+	ole.IUnknownVtbl
+	Handle uintptr
+}
+
+// eventHandler is a COM object implemented in Go. UI Automation calls it on
+// its own threads.
+type eventHandler struct {
+	vtbl *eventHandlerVtbl
+	refs int32
+	iid  *ole.GUID
+
+	onEvent func(sender *IUIAutomationElement, id EventID)
+	onFocus func(sender *IUIAutomationElement)
+}
+
+var (
+	// liveEventHandlers keeps handlers referenced by COM reachable for the GC.
+	liveEventHandlers sync.Map // *eventHandler -> struct{}
+
+	automationEventHandlerVtbl = &eventHandlerVtbl{
+		IUnknownVtbl: ole.IUnknownVtbl{
+			QueryInterface: syscall.NewCallback(eventHandlerQueryInterface),
+			AddRef:         syscall.NewCallback(eventHandlerAddRef),
+			Release:        syscall.NewCallback(eventHandlerRelease),
+		},
+		Handle: syscall.NewCallback(handleAutomationEvent),
+	}
+	focusChangedEventHandlerVtbl = &eventHandlerVtbl{
+		IUnknownVtbl: automationEventHandlerVtbl.IUnknownVtbl,
+		Handle:       syscall.NewCallback(handleFocusChangedEvent),
+	}
+)
+
+func newEventHandler(vtbl *eventHandlerVtbl, iid *ole.GUID) *eventHandler {
+	h := &eventHandler{vtbl: vtbl, refs: 1, iid: iid}
+	liveEventHandlers.Store(h, struct{}{})
+	return h
+}
+
+func eventHandlerQueryInterface(this *eventHandler, riid *ole.GUID, out *unsafe.Pointer) uintptr {
+	if out == nil {
+		return win.E_INVALIDARG
+	}
+	switch {
+	case ole.IsEqualGUID(riid, ole.IID_IUnknown), ole.IsEqualGUID(riid, this.iid), ole.IsEqualGUID(riid, winrt.IAgileObjectID):
+		eventHandlerAddRef(this)
+		*out = unsafe.Pointer(this)
+		return win.S_OK
+	}
+	*out = nil
+	return win.E_NOINTERFACE
+}
+
+func eventHandlerAddRef(this *eventHandler) uintptr {
+	return uintptr(atomic.AddInt32(&this.refs, 1))
+}
+
+func eventHandlerRelease(this *eventHandler) uintptr {
+	refs := atomic.AddInt32(&this.refs, -1)
+	if refs == 0 {
+		liveEventHandlers.Delete(this)
+	}
+	return uintptr(refs)
+}
+
+func handleAutomationEvent(this *eventHandler, sender *IUIAutomationElement, id uintptr) uintptr {
+	this.onEvent(sender, EventID(int32(id)))
+	return win.S_OK
+}
+
+func handleFocusChangedEvent(this *eventHandler, sender *IUIAutomationElement) uintptr {
+	this.onFocus(sender)
+	return win.S_OK
+}
+
+// EventHandler is a subscription returned by the Add*EventHandler methods.
+type EventHandler struct {
+	handler *eventHandler
+	remove  func() error
+	once    sync.Once
+}
+
+// Remove ends the subscription. UI Automation may still deliver events that
+// were already queued.
+func (h *EventHandler) Remove() (err error) {
+	h.once.Do(func() {
+		err = h.remove()
+		eventHandlerRelease(h.handler)
+	})
+	return err
+}
+
+// AddAutomationEventHandler calls fn for event id raised by element or the
+// elements in scope. sender is only valid during the call, AddRef it to
+// keep it.
+func (v *IUIAutomation) AddAutomationEventHandler(id EventID, element *IUIAutomationElement, scope TreeScope, fn func(sender *IUIAutomationElement, id EventID)) (*EventHandler, error) {
+	h := newEventHandler(automationEventHandlerVtbl, IUIAutomationEventHandlerID)
+	h.onEvent = fn
+	element.AddRef()
+
+	r1, _, _ := syscall.SyscallN(v.VTable().AddAutomationEventHandler, uintptr(unsafe.Pointer(v)), uintptr(id), uintptr(unsafe.Pointer(element)), uintptr(scope), 0, uintptr(unsafe.Pointer(h)))
+	if r1 != win.S_OK {
+		element.Release()
+		eventHandlerRelease(h)
+		return nil, ole.NewError(r1)
+	}
+	return &EventHandler{handler: h, remove: func() error {
+		defer element.Release()
+		r1, _, _ := syscall.SyscallN(v.VTable().RemoveAutomationEventHandler, uintptr(unsafe.Pointer(v)), uintptr(id), uintptr(unsafe.Pointer(element)), uintptr(unsafe.Pointer(h)))
+		if r1 != win.S_OK {
+			return ole.NewError(r1)
+		}
+		return nil
+	}}, nil
+}
+
+// AddFocusChangedEventHandler calls fn whenever the keyboard focus moves.
+// sender is only valid during the call.
+func (v *IUIAutomation) AddFocusChangedEventHandler(fn func(sender *IUIAutomationElement)) (*EventHandler, error) {
+	h := newEventHandler(focusChangedEventHandlerVtbl, IUIAutomationFocusChangedEventHandlerID)
+	h.onFocus = fn
+
+	r1, _, _ := syscall.SyscallN(v.VTable().AddFocusChangedEventHandler, uintptr(unsafe.Pointer(v)), 0, uintptr(unsafe.Pointer(h)))
+	if r1 != win.S_OK {
+		eventHandlerRelease(h)
+		return nil, ole.NewError(r1)
+	}
+	return &EventHandler{handler: h, remove: func() error {
+		r1, _, _ := syscall.SyscallN(v.VTable().RemoveFocusChangedEventHandler, uintptr(unsafe.Pointer(v)), uintptr(unsafe.Pointer(h)))
+		if r1 != win.S_OK {
+			return ole.NewError(r1)
+		}
+		return nil
+	}}, nil
+}
@@ -0,0 +1,253 @@
+// Package uia is a client for Microsoft UI Automation. Unlike window text
+// searches it also reaches controls that are not windows, like those of
+// WPF, UWP and browser content.
+//
+// The ids and the condition builder are plain Go, the COM bindings in
+// *_windows.go follow the IUIAutomation interfaces of UIAutomationClient.h.
+package uia
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PropertyID identifies an element property, UIA_*PropertyId.
+type PropertyID int32
+
+const (
+	RuntimeIdProperty            PropertyID = 30000
+	BoundingRectangleProperty    PropertyID = 30001
+	ProcessIdProperty            PropertyID = 30002
+	ControlTypeProperty          PropertyID = 30003
+	LocalizedControlTypeProperty PropertyID = 30004
+	NameProperty                 PropertyID = 30005
+	AcceleratorKeyProperty       PropertyID = 30006
+	AccessKeyProperty            PropertyID = 30007
+	HasKeyboardFocusProperty     PropertyID = 30008
+	IsKeyboardFocusableProperty  PropertyID = 30009
+	IsEnabledProperty            PropertyID = 30010
+	AutomationIdProperty         PropertyID = 30011
+	ClassNameProperty            PropertyID = 30012
+	HelpTextProperty             PropertyID = 30013
+	ClickablePointProperty       PropertyID = 30014
+	CultureProperty              PropertyID = 30015
+	IsControlElementProperty     PropertyID = 30016
+	IsContentElementProperty     PropertyID = 30017
+	LabeledByProperty            PropertyID = 30018
+	IsPasswordProperty           PropertyID = 30019
+	NativeWindowHandleProperty   PropertyID = 30020
+	ItemTypeProperty             PropertyID = 30021
+	IsOffscreenProperty          PropertyID = 30022
+	OrientationProperty          PropertyID = 30023
+	FrameworkIdProperty          PropertyID = 30024
+	ValueValueProperty           PropertyID = 30045
+	ToggleToggleStateProperty    PropertyID = 30086
+)
+
+var propertyNames = map[PropertyID]string{
+	RuntimeIdProperty:            "RuntimeId",
+	BoundingRectangleProperty:    "BoundingRectangle",
+	ProcessIdProperty:            "ProcessId",
+	ControlTypeProperty:          "ControlType",
+	LocalizedControlTypeProperty: "LocalizedControlType",
+	NameProperty:                 "Name",
+	AcceleratorKeyProperty:       "AcceleratorKey",
+	AccessKeyProperty:            "AccessKey",
+	HasKeyboardFocusProperty:     "HasKeyboardFocus",
+	IsKeyboardFocusableProperty:  "IsKeyboardFocusable",
+	IsEnabledProperty:            "IsEnabled",
+	AutomationIdProperty:         "AutomationId",
+	ClassNameProperty:            "ClassName",
+	HelpTextProperty:             "HelpText",
+	ClickablePointProperty:       "ClickablePoint",
+	CultureProperty:              "Culture",
+	IsControlElementProperty:     "IsControlElement",
+	IsContentElementProperty:     "IsContentElement",
+	LabeledByProperty:            "LabeledBy",
+	IsPasswordProperty:           "IsPassword",
+	NativeWindowHandleProperty:   "NativeWindowHandle",
+	ItemTypeProperty:             "ItemType",
+	IsOffscreenProperty:          "IsOffscreen",
+	OrientationProperty:          "Orientation",
+	FrameworkIdProperty:          "FrameworkId",
+	ValueValueProperty:           "Value.Value",
+	ToggleToggleStateProperty:    "Toggle.ToggleState",
+}
+
+func (p PropertyID) String() string {
+	if s, ok := propertyNames[p]; ok {
+		return s
+	}
+	return fmt.Sprintf("Property(%d)", int32(p))
+}
+
+// ControlType is the UIA_*ControlTypeId of an element.
+type ControlType int32
+
+const (
+	ButtonControl ControlType = 50000 + iota
+	CalendarControl
+	CheckBoxControl
+	ComboBoxControl
+	EditControl
+	HyperlinkControl
+	ImageControl
+	ListItemControl
+	ListControl
+	MenuControl
+	MenuBarControl
+	MenuItemControl
+	ProgressBarControl
+	RadioButtonControl
+	ScrollBarControl
+	SliderControl
+	SpinnerControl
+	StatusBarControl
+	TabControl
+	TabItemControl
+	TextControl
+	ToolBarControl
+	ToolTipControl
+	TreeControl
+	TreeItemControl
+	CustomControl
+	GroupControl
+	ThumbControl
+	DataGridControl
+	DataItemControl
+	DocumentControl
+	SplitButtonControl
+	WindowControl
+	PaneControl
+	HeaderControl
+	HeaderItemControl
+	TableControl
+	TitleBarControl
+	SeparatorControl
+	SemanticZoomControl
+	AppBarControl
+)
+
+var controlTypeNames = [...]string{
+	"Button", "Calendar", "CheckBox", "ComboBox", "Edit", "Hyperlink", "Image",
+	"ListItem", "List", "Menu", "MenuBar", "MenuItem", "ProgressBar",
+	"RadioButton", "ScrollBar", "Slider", "Spinner", "StatusBar", "Tab",
+	"TabItem", "Text", "ToolBar", "ToolTip", "Tree", "TreeItem", "Custom",
+	"Group", "Thumb", "DataGrid", "DataItem", "Document", "SplitButton",
+	"Window", "Pane", "Header", "HeaderItem", "Table", "TitleBar",
+	"Separator", "SemanticZoom", "AppBar",
+}
+
+func (t ControlType) String() string {
+	if i := int(t - ButtonControl); i >= 0 && i < len(controlTypeNames) {
+		return controlTypeNames[i]
+	}
+	return fmt.Sprintf("ControlType(%d)", int32(t))
+}
+
+// ParseControlType returns the control type called name, ignoring case.
+func ParseControlType(name string) (ControlType, bool) {
+	for i, s := range controlTypeNames {
+		if strings.EqualFold(s, name) {
+			return ButtonControl + ControlType(i), true
+		}
+	}
+	return 0, false
+}
+
+// PatternID identifies a control pattern, UIA_*PatternId.
+type PatternID int32
+
+const (
+	InvokePattern PatternID = 10000 + iota
+	SelectionPattern
+	ValuePattern
+	RangeValuePattern
+	ScrollPattern
+	ExpandCollapsePattern
+	GridPattern
+	GridItemPattern
+	MultipleViewPattern
+	WindowPattern
+	SelectionItemPattern
+	DockPattern
+	TablePattern
+	TableItemPattern
+	TextPattern
+	TogglePattern
+	TransformPattern
+	ScrollItemPattern
+	LegacyIAccessiblePattern
+)
+
+// EventID identifies an automation event, UIA_*EventId.
+type EventID int32
+
+const (
+	ToolTipOpenedEvent EventID = 20000 + iota
+	ToolTipClosedEvent
+	StructureChangedEvent
+	MenuOpenedEvent
+	AutomationPropertyChangedEvent
+	AutomationFocusChangedEvent
+	AsyncContentLoadedEvent
+	MenuClosedEvent
+	LayoutInvalidatedEvent
+	InvokeInvokedEvent
+	SelectionItemElementAddedToSelectionEvent
+	SelectionItemElementRemovedFromSelectionEvent
+	SelectionItemElementSelectedEvent
+	SelectionInvalidatedEvent
+	TextTextSelectionChangedEvent
+	TextTextChangedEvent
+	WindowWindowOpenedEvent
+	WindowWindowClosedEvent
+	MenuModeStartEvent
+	MenuModeEndEvent
+)
+
+// TreeScope selects the elements a search or event subscription covers.
+type TreeScope int32
+
+const (
+	ScopeElement     TreeScope = 0x1
+	ScopeChildren    TreeScope = 0x2
+	ScopeDescendants TreeScope = 0x4
+	ScopeParent      TreeScope = 0x8
+	ScopeAncestors   TreeScope = 0x10
+	ScopeSubtree               = ScopeElement | ScopeChildren | ScopeDescendants
+)
+
+// ToggleState of the Toggle pattern.
+type ToggleState int32
+
+const (
+	ToggleOff ToggleState = iota
+	ToggleOn
+	ToggleIndeterminate
+)
+
+func (s ToggleState) String() string {
+	switch s {
+	case ToggleOff:
+		return "Off"
+	case ToggleOn:
+		return "On"
+	case ToggleIndeterminate:
+		return "Indeterminate"
+	}
+	return fmt.Sprintf("ToggleState(%d)", int32(s))
+}
+
+// TextUnit is the unit text ranges are moved and expanded by.
+type TextUnit int32
+
+const (
+	TextUnitCharacter TextUnit = iota
+	TextUnitFormat
+	TextUnitWord
+	TextUnitLine
+	TextUnitParagraph
+	TextUnitPage
+	TextUnitDocument
+)
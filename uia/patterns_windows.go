@@ -0,0 +1,479 @@
+package uia
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/lxn/win"
+	"github.com/whiteboxsolutions/go-ole"
+)
+
+func boolArg(b bool) uintptr {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// IUIAutomationInvokePattern
+
+var IUIAutomationInvokePatternID = ole.NewGUID("{fb377fbe-8ea6-46d5-9c73-6499642d3059}")
+
+type IUIAutomationInvokePattern struct {
+	ole.IUnknown
+}
+
+type IUIAutomationInvokePatternVtbl struct {
+	ole.IUnknownVtbl
+	Invoke uintptr
+}
+
+func (v *IUIAutomationInvokePattern) VTable() *IUIAutomationInvokePatternVtbl {
+	return (*IUIAutomationInvokePatternVtbl)(unsafe.Pointer(v.RawVTable))
+}
+
+// InvokePattern returns the Invoke pattern of buttons, menu items and links.
+func (v *IUIAutomationElement) InvokePattern() (*IUIAutomationInvokePattern, error) {
+	p, err := v.GetCurrentPatternAs(InvokePattern, IUIAutomationInvokePatternID)
+	return (*IUIAutomationInvokePattern)(p), err
+}
+
+func (v *IUIAutomationInvokePattern) Invoke() error {
+	r1, _, _ := syscall.SyscallN(v.VTable().Invoke, uintptr(unsafe.Pointer(v)))
+	if r1 != win.S_OK {
+		return ole.NewError(r1)
+	}
+	return nil
+}
+
+// IUIAutomationValuePattern
+
+var IUIAutomationValuePatternID = ole.NewGUID("{a94cd8b1-0844-4cd6-9d2d-640537ab39e9}")
+
+type IUIAutomationValuePattern struct {
+	ole.IUnknown
+}
+
+type IUIAutomationValuePatternVtbl struct {
+	ole.IUnknownVtbl
+	SetValue              uintptr
+	get_CurrentValue      uintptr
+	get_CurrentIsReadOnly uintptr
+	get_CachedValue       uintptr
+	get_CachedIsReadOnly  uintptr
+}
+
+func (v *IUIAutomationValuePattern) VTable() *IUIAutomationValuePatternVtbl {
+	return (*IUIAutomationValuePatternVtbl)(unsafe.Pointer(v.RawVTable))
+}
+
+// ValuePattern returns the Value pattern of edits and other controls with a
+// string value.
+func (v *IUIAutomationElement) ValuePattern() (*IUIAutomationValuePattern, error) {
+	p, err := v.GetCurrentPatternAs(ValuePattern, IUIAutomationValuePatternID)
+	return (*IUIAutomationValuePattern)(p), err
+}
+
+func (v *IUIAutomationValuePattern) SetValue(value string) error {
+	bstr := ole.SysAllocString(value)
+	defer ole.SysFreeString(bstr)
+	r1, _, _ := syscall.SyscallN(v.VTable().SetValue, uintptr(unsafe.Pointer(v)), uintptr(unsafe.Pointer(bstr)))
+	if r1 != win.S_OK {
+		return ole.NewError(r1)
+	}
+	return nil
+}
+
+func (v *IUIAutomationValuePattern) CurrentValue() (string, error) {
+	var bstr *uint16
+	r1, _, _ := syscall.SyscallN(v.VTable().get_CurrentValue, uintptr(unsafe.Pointer(v)), uintptr(unsafe.Pointer(&bstr)))
+	if r1 != win.S_OK {
+		return "", ole.NewError(r1)
+	}
+	return bstrToString(bstr), nil
+}
+
+func (v *IUIAutomationValuePattern) CurrentIsReadOnly() (bool, error) {
+	var ret int32
+	r1, _, _ := syscall.SyscallN(v.VTable().get_CurrentIsReadOnly, uintptr(unsafe.Pointer(v)), uintptr(unsafe.Pointer(&ret)))
+	if r1 != win.S_OK {
+		return false, ole.NewError(r1)
+	}
+	return ret != 0, nil
+}
+
+// IUIAutomationTogglePattern
+
+var IUIAutomationTogglePatternID = ole.NewGUID("{94cf8058-9b8d-4ab9-8bfd-4cd0a33c8c70}")
+
+type IUIAutomationTogglePattern struct {
+	ole.IUnknown
+}
+
+type IUIAutomationTogglePatternVtbl struct {
+	ole.IUnknownVtbl
+	Toggle                 uintptr
+	get_CurrentToggleState uintptr
+	get_CachedToggleState  uintptr
+}
+
+func (v *IUIAutomationTogglePattern) VTable() *IUIAutomationTogglePatternVtbl {
+	return (*IUIAutomationTogglePatternVtbl)(unsafe.Pointer(v.RawVTable))
+}
+
+// TogglePattern returns the Toggle pattern of check boxes and toggle buttons.
+func (v *IUIAutomationElement) TogglePattern() (*IUIAutomationTogglePattern, error) {
+	p, err := v.GetCurrentPatternAs(TogglePattern, IUIAutomationTogglePatternID)
+	return (*IUIAutomationTogglePattern)(p), err
+}
+
+// Toggle cycles through the toggle states of the control.
+func (v *IUIAutomationTogglePattern) Toggle() error {
+	r1, _, _ := syscall.SyscallN(v.VTable().Toggle, uintptr(unsafe.Pointer(v)))
+	if r1 != win.S_OK {
+		return ole.NewError(r1)
+	}
+	return nil
+}
+
+func (v *IUIAutomationTogglePattern) CurrentToggleState() (ToggleState, error) {
+	var state ToggleState
+	r1, _, _ := syscall.SyscallN(v.VTable().get_CurrentToggleState, uintptr(unsafe.Pointer(v)), uintptr(unsafe.Pointer(&state)))
+	if r1 != win.S_OK {
+		return 0, ole.NewError(r1)
+	}
+	return state, nil
+}
+
+// IUIAutomationSelectionPattern
+
+var IUIAutomationSelectionPatternID = ole.NewGUID("{5ed5202e-b2ac-47a6-b638-4b0bf140d78e}")
+
+type IUIAutomationSelectionPattern struct {
+	ole.IUnknown
+}
+
+type IUIAutomationSelectionPatternVtbl struct {
+	ole.IUnknownVtbl
+	GetCurrentSelection            uintptr
+	get_CurrentCanSelectMultiple   uintptr
+	get_CurrentIsSelectionRequired uintptr
+	GetCachedSelection             uintptr
+	get_CachedCanSelectMultiple    uintptr
+	get_CachedIsSelectionRequired  uintptr
+}
+
+func (v *IUIAutomationSelectionPattern) VTable() *IUIAutomationSelectionPatternVtbl {
+	return (*IUIAutomationSelectionPatternVtbl)(unsafe.Pointer(v.RawVTable))
+}
+
+// SelectionPattern returns the Selection pattern of lists, trees and tabs.
+func (v *IUIAutomationElement) SelectionPattern() (*IUIAutomationSelectionPattern, error) {
+	p, err := v.GetCurrentPatternAs(SelectionPattern, IUIAutomationSelectionPatternID)
+	return (*IUIAutomationSelectionPattern)(p), err
+}
+
+// GetCurrentSelection returns the selected items.
+func (v *IUIAutomationSelectionPattern) GetCurrentSelection() ([]*IUIAutomationElement, error) {
+	var array *IUIAutomationElementArray
+	r1, _, _ := syscall.SyscallN(v.VTable().GetCurrentSelection, uintptr(unsafe.Pointer(v)), uintptr(unsafe.Pointer(&array)))
+	if r1 != win.S_OK {
+		return nil, ole.NewError(r1)
+	}
+	return array.elements()
+}
+
+func (v *IUIAutomationSelectionPattern) CurrentCanSelectMultiple() (bool, error) {
+	var ret int32
+	r1, _, _ := syscall.SyscallN(v.VTable().get_CurrentCanSelectMultiple, uintptr(unsafe.Pointer(v)), uintptr(unsafe.Pointer(&ret)))
+	if r1 != win.S_OK {
+		return false, ole.NewError(r1)
+	}
+	return ret != 0, nil
+}
+
+// IUIAutomationSelectionItemPattern
+
+var IUIAutomationSelectionItemPatternID = ole.NewGUID("{a8efa66a-0fda-421a-9194-38021f3578ea}")
+
+type IUIAutomationSelectionItemPattern struct {
+	ole.IUnknown
+}
+
+type IUIAutomationSelectionItemPatternVtbl struct {
+	ole.IUnknownVtbl
+	Select                        uintptr
+	AddToSelection                uintptr
+	RemoveFromSelection           uintptr
+	get_CurrentIsSelected         uintptr
+	get_CurrentSelectionContainer uintptr
+	get_CachedIsSelected          uintptr
+	get_CachedSelectionContainer  uintptr
+}
+
+func (v *IUIAutomationSelectionItemPattern) VTable() *IUIAutomationSelectionItemPatternVtbl {
+	return (*IUIAutomationSelectionItemPatternVtbl)(unsafe.Pointer(v.RawVTable))
+}
+
+// SelectionItemPattern returns the SelectionItem pattern of list items, tree
+// items and tab items.
+func (v *IUIAutomationElement) SelectionItemPattern() (*IUIAutomationSelectionItemPattern, error) {
+	p, err := v.GetCurrentPatternAs(SelectionItemPattern, IUIAutomationSelectionItemPatternID)
+	return (*IUIAutomationSelectionItemPattern)(p), err
+}
+
+func (v *IUIAutomationSelectionItemPattern) call(method uintptr) error {
+	r1, _, _ := syscall.SyscallN(method, uintptr(unsafe.Pointer(v)))
+	if r1 != win.S_OK {
+		return ole.NewError(r1)
+	}
+	return nil
+}
+
+// Select deselects other items and selects this one.
+func (v *IUIAutomationSelectionItemPattern) Select() error {
+	return v.call(v.VTable().Select)
+}
+
+func (v *IUIAutomationSelectionItemPattern) AddToSelection() error {
+	return v.call(v.VTable().AddToSelection)
+}
+
+func (v *IUIAutomationSelectionItemPattern) RemoveFromSelection() error {
+	return v.call(v.VTable().RemoveFromSelection)
+}
+
+func (v *IUIAutomationSelectionItemPattern) CurrentIsSelected() (bool, error) {
+	var ret int32
+	r1, _, _ := syscall.SyscallN(v.VTable().get_CurrentIsSelected, uintptr(unsafe.Pointer(v)), uintptr(unsafe.Pointer(&ret)))
+	if r1 != win.S_OK {
+		return false, ole.NewError(r1)
+	}
+	return ret != 0, nil
+}
+
+// IUIAutomationTextPattern
+
+var IUIAutomationTextPatternID = ole.NewGUID("{32eba289-3583-42c9-9c59-3b6d9a1e9b6a}")
+
+type IUIAutomationTextPattern struct {
+	ole.IUnknown
+}
+
+type IUIAutomationTextPatternVtbl struct {
+	ole.IUnknownVtbl
+	RangeFromPoint             uintptr
+	RangeFromChild             uintptr
+	GetSelection               uintptr
+	GetVisibleRanges           uintptr
+	get_DocumentRange          uintptr
+	get_SupportedTextSelection uintptr
+}
+
+func (v *IUIAutomationTextPattern) VTable() *IUIAutomationTextPatternVtbl {
+	return (*IUIAutomationTextPatternVtbl)(unsafe.Pointer(v.RawVTable))
+}
+
+// TextPattern returns the Text pattern of documents and rich edits.
+func (v *IUIAutomationElement) TextPattern() (*IUIAutomationTextPattern, error) {
+	p, err := v.GetCurrentPatternAs(TextPattern, IUIAutomationTextPatternID)
+	return (*IUIAutomationTextPattern)(p), err
+}
+
+func (v *IUIAutomationTextPattern) getRange(method uintptr, args ...uintptr) (*IUIAutomationTextRange, error) {
+	var r *IUIAutomationTextRange
+	args = append([]uintptr{uintptr(unsafe.Pointer(v))}, args...)
+	r1, _, _ := syscall.SyscallN(method, append(args, uintptr(unsafe.Pointer(&r)))...)
+	if r1 != win.S_OK {
+		return nil, ole.NewError(r1)
+	}
+	return r, nil
+}
+
+// DocumentRange spans the whole text.
+func (v *IUIAutomationTextPattern) DocumentRange() (*IUIAutomationTextRange, error) {
+	return v.getRange(v.VTable().get_DocumentRange)
+}
+
+// RangeFromPoint returns the degenerate range nearest to pt.
+func (v *IUIAutomationTextPattern) RangeFromPoint(pt win.POINT) (*IUIAutomationTextRange, error) {
+	return v.getRange(v.VTable().RangeFromPoint, pointArgs(pt)...)
+}
+
+func (v *IUIAutomationTextPattern) getRanges(method uintptr) ([]*IUIAutomationTextRange, error) {
+	var array *IUIAutomationTextRangeArray
+	r1, _, _ := syscall.SyscallN(method, uintptr(unsafe.Pointer(v)), uintptr(unsafe.Pointer(&array)))
+	if r1 != win.S_OK {
+		return nil, ole.NewError(r1)
+	}
+	return array.ranges()
+}
+
+// GetSelection returns the selected ranges, the caret is a degenerate one.
+func (v *IUIAutomationTextPattern) GetSelection() ([]*IUIAutomationTextRange, error) {
+	return v.getRanges(v.VTable().GetSelection)
+}
+
+// GetVisibleRanges returns the ranges of the visible lines.
+func (v *IUIAutomationTextPattern) GetVisibleRanges() ([]*IUIAutomationTextRange, error) {
+	return v.getRanges(v.VTable().GetVisibleRanges)
+}
+
+// IUIAutomationTextRange
+
+var IUIAutomationTextRangeID = ole.NewGUID("{a543cc6a-f4ae-494b-8239-c814481187a8}")
+
+type IUIAutomationTextRange struct {
+	ole.IUnknown
+}
+
+type IUIAutomationTextRangeVtbl struct {
+	ole.IUnknownVtbl
+	Clone                 uintptr
+	Compare               uintptr
+	CompareEndpoints      uintptr
+	ExpandToEnclosingUnit uintptr
+	FindAttribute         uintptr
+	FindText              uintptr
+	GetAttributeValue     uintptr
+	GetBoundingRectangles uintptr
+	GetEnclosingElement   uintptr
+	GetText               uintptr
+	Move                  uintptr
+	MoveEndpointByUnit    uintptr
+	MoveEndpointByRange   uintptr
+	Select                uintptr
+	AddToSelection        uintptr
+	RemoveFromSelection   uintptr
+	ScrollIntoView        uintptr
+	GetChildren           uintptr
+}
+
+func (v *IUIAutomationTextRange) VTable() *IUIAutomationTextRangeVtbl {
+	return (*IUIAutomationTextRangeVtbl)(unsafe.Pointer(v.RawVTable))
+}
+
+func (v *IUIAutomationTextRange) Clone() (*IUIAutomationTextRange, error) {
+	var r *IUIAutomationTextRange
+	r1, _, _ := syscall.SyscallN(v.VTable().Clone, uintptr(unsafe.Pointer(v)), uintptr(unsafe.Pointer(&r)))
+	if r1 != win.S_OK {
+		return nil, ole.NewError(r1)
+	}
+	return r, nil
+}
+
+// GetText returns up to maxLength characters of the range, -1 for all.
+func (v *IUIAutomationTextRange) GetText(maxLength int) (string, error) {
+	var bstr *uint16
+	r1, _, _ := syscall.SyscallN(v.VTable().GetText, uintptr(unsafe.Pointer(v)), uintptr(maxLength), uintptr(unsafe.Pointer(&bstr)))
+	if r1 != win.S_OK {
+		return "", ole.NewError(r1)
+	}
+	return bstrToString(bstr), nil
+}
+
+// FindText returns the subrange containing text, or ErrNotFound.
+func (v *IUIAutomationTextRange) FindText(text string, backward, ignoreCase bool) (*IUIAutomationTextRange, error) {
+	bstr := ole.SysAllocString(text)
+	defer ole.SysFreeString(bstr)
+	var r *IUIAutomationTextRange
+	r1, _, _ := syscall.SyscallN(v.VTable().FindText, uintptr(unsafe.Pointer(v)), uintptr(unsafe.Pointer(bstr)), boolArg(backward), boolArg(ignoreCase), uintptr(unsafe.Pointer(&r)))
+	if r1 != win.S_OK {
+		return nil, ole.NewError(r1)
+	}
+	if r == nil {
+		return nil, ErrNotFound
+	}
+	return r, nil
+}
+
+// ExpandToEnclosingUnit grows the range to whole units.
+func (v *IUIAutomationTextRange) ExpandToEnclosingUnit(unit TextUnit) error {
+	r1, _, _ := syscall.SyscallN(v.VTable().ExpandToEnclosingUnit, uintptr(unsafe.Pointer(v)), uintptr(unit))
+	if r1 != win.S_OK {
+		return ole.NewError(r1)
+	}
+	return nil
+}
+
+// Move moves the range by count units, negative counts move backwards. It
+// returns the number of units actually moved.
+func (v *IUIAutomationTextRange) Move(unit TextUnit, count int) (int, error) {
+	var moved int32
+	r1, _, _ := syscall.SyscallN(v.VTable().Move, uintptr(unsafe.Pointer(v)), uintptr(unit), uintptr(count), uintptr(unsafe.Pointer(&moved)))
+	if r1 != win.S_OK {
+		return 0, ole.NewError(r1)
+	}
+	return int(moved), nil
+}
+
+// GetEnclosingElement returns the innermost element containing the range.
+func (v *IUIAutomationTextRange) GetEnclosingElement() (*IUIAutomationElement, error) {
+	var element *IUIAutomationElement
+	r1, _, _ := syscall.SyscallN(v.VTable().GetEnclosingElement, uintptr(unsafe.Pointer(v)), uintptr(unsafe.Pointer(&element)))
+	if r1 != win.S_OK {
+		return nil, ole.NewError(r1)
+	}
+	return element, nil
+}
+
+func (v *IUIAutomationTextRange) Select() error {
+	r1, _, _ := syscall.SyscallN(v.VTable().Select, uintptr(unsafe.Pointer(v)))
+	if r1 != win.S_OK {
+		return ole.NewError(r1)
+	}
+	return nil
+}
+
+func (v *IUIAutomationTextRange) ScrollIntoView(alignToTop bool) error {
+	r1, _, _ := syscall.SyscallN(v.VTable().ScrollIntoView, uintptr(unsafe.Pointer(v)), boolArg(alignToTop))
+	if r1 != win.S_OK {
+		return ole.NewError(r1)
+	}
+	return nil
+}
+
+// IUIAutomationTextRangeArray
+
+var IUIAutomationTextRangeArrayID = ole.NewGUID("{ce4ae76a-e717-4c98-81ea-47371d028eb6}")
+
+type IUIAutomationTextRangeArray struct {
+	ole.IUnknown
+}
+
+type IUIAutomationTextRangeArrayVtbl struct {
+	ole.IUnknownVtbl
+	get_Length uintptr
+	GetElement uintptr
+}
+
+func (v *IUIAutomationTextRangeArray) VTable() *IUIAutomationTextRangeArrayVtbl {
+	return (*IUIAutomationTextRangeArrayVtbl)(unsafe.Pointer(v.RawVTable))
+}
+
+// ranges copies the array into a slice and releases the array.
+func (v *IUIAutomationTextRangeArray) ranges() ([]*IUIAutomationTextRange, error) {
+	if v == nil {
+		return nil, nil
+	}
+	defer v.Release()
+
+	var n int32
+	r1, _, _ := syscall.SyscallN(v.VTable().get_Length, uintptr(unsafe.Pointer(v)), uintptr(unsafe.Pointer(&n)))
+	if r1 != win.S_OK {
+		return nil, ole.NewError(r1)
+	}
+	res := make([]*IUIAutomationTextRange, 0, n)
+	for i := int32(0); i < n; i++ {
+		var r *IUIAutomationTextRange
+		r1, _, _ := syscall.SyscallN(v.VTable().GetElement, uintptr(unsafe.Pointer(v)), uintptr(i), uintptr(unsafe.Pointer(&r)))
+		if r1 != win.S_OK {
+			for _, r := range res {
+				r.Release()
+			}
+			return nil, ole.NewError(r1)
+		}
+		res = append(res, r)
+	}
+	return res, nil
+}
@@ -1,6 +1,7 @@
 package winapi
 
 import (
+	"syscall"
 	"unsafe"
 
 	"github.com/lxn/win"
@@ -19,6 +20,90 @@ const (
 	MAPVK_VK_TO_VSC_EX
 )
 
+// SetSystemCursor ids
+const (
+	OCR_NORMAL      uint32 = 32512
+	OCR_IBEAM       uint32 = 32513
+	OCR_WAIT        uint32 = 32514
+	OCR_CROSS       uint32 = 32515
+	OCR_UP          uint32 = 32516
+	OCR_SIZENWSE    uint32 = 32642
+	OCR_SIZENESW    uint32 = 32643
+	OCR_SIZEWE      uint32 = 32644
+	OCR_SIZENS      uint32 = 32645
+	OCR_SIZEALL     uint32 = 32646
+	OCR_NO          uint32 = 32648
+	OCR_HAND        uint32 = 32649
+	OCR_APPSTARTING uint32 = 32650
+)
+
+const SPI_SETCURSORS uint32 = 0x0057
+
+const (
+	MOD_ALT      uint32 = 0x0001
+	MOD_CONTROL  uint32 = 0x0002
+	MOD_SHIFT    uint32 = 0x0004
+	MOD_WIN      uint32 = 0x0008
+	MOD_NOREPEAT uint32 = 0x4000
+)
+
+type HHOOK win.HANDLE
+
+const (
+	WH_KEYBOARD_LL = 13
+	WH_MOUSE_LL    = 14
+)
+
+const HC_ACTION = 0
+
+const ENUM_CURRENT_SETTINGS uint32 = 0xFFFFFFFF
+
+// DEVMODE dmDisplayOrientation
+const (
+	DMDO_DEFAULT uint32 = iota
+	DMDO_90
+	DMDO_180
+	DMDO_270
+)
+
+const CCHDEVICENAME = 32
+
+// PrintWindow nFlags
+const (
+	PW_CLIENTONLY        uint32 = 0x1
+	PW_RENDERFULLCONTENT uint32 = 0x2
+)
+
+// DPI_AWARENESS_CONTEXT handles
+const (
+	DPI_AWARENESS_CONTEXT_UNAWARE              = ^uintptr(0) // -1
+	DPI_AWARENESS_CONTEXT_SYSTEM_AWARE         = ^uintptr(1) // -2
+	DPI_AWARENESS_CONTEXT_PER_MONITOR_AWARE    = ^uintptr(2) // -3
+	DPI_AWARENESS_CONTEXT_PER_MONITOR_AWARE_V2 = ^uintptr(3) // -4
+	DPI_AWARENESS_CONTEXT_UNAWARE_GDISCALED    = ^uintptr(4) // -5
+)
+
+// SendMessageTimeout fuFlags
+const (
+	SMTO_NORMAL             uint32 = 0x0
+	SMTO_BLOCK              uint32 = 0x1
+	SMTO_ABORTIFHUNG        uint32 = 0x2
+	SMTO_NOTIMEOUTIFNOTHUNG uint32 = 0x8
+	SMTO_ERRORONEXIT        uint32 = 0x20
+)
+
+// COPYDATASTRUCT is passed by pointer in the lParam of WM_COPYDATA.
+type COPYDATASTRUCT struct {
+	DwData uintptr
+	CbData uint32
+	LpData uintptr
+}
+
+type MONITORINFOEX struct {
+	win.MONITORINFO
+	SzDevice [CCHDEVICENAME]uint16
+}
+
 func ClipCursor(rect *win.RECT) (ok int, err error) {
 	if rect == nil {
 		return clipCursor(NULL)
@@ -50,6 +135,14 @@ func GetWindowText(hwnd win.HWND, lpString []uint16, nMax int) (length int) {
 	return getWindowText(uintptr(hwnd), uintptr(unsafe.Pointer(&lpString[0])), nMax)
 }
 
+func GetWindowTextLength(hwnd win.HWND) int {
+	return getWindowTextLength(uintptr(hwnd))
+}
+
+func IsWindow(hwnd win.HWND) bool {
+	return isWindow(uintptr(hwnd))
+}
+
 func InvalidateRect(hwnd win.HWND, rect win.RECT, bErase bool) error {
 	return invalidateRect(uintptr(hwnd), uintptr(unsafe.Pointer(&rect.Left)), bErase)
 }
@@ -58,8 +151,16 @@ func SetLayeredWindowAttributes(hwnd win.HWND, color uint32, bAlpha byte, dwFlag
 	return setLayeredWindowAttributes(uintptr(hwnd), color, bAlpha, dwFlags)
 }
 
+// SetWindowRgn hands hRgn over to the system on success, it must not be deleted.
 func SetWindowRgn(hwnd win.HWND, hRgn win.HRGN, bRedraw bool) error {
-	return setWindowRgn(uintptr(hwnd), uintptr(hRgn), bRedraw)
+	o, tracked := GDIObjects.Take(uintptr(hRgn))
+	if err := setWindowRgn(uintptr(hwnd), uintptr(hRgn), bRedraw); err != nil {
+		if tracked {
+			GDIObjects.Restore(o)
+		}
+		return err
+	}
+	return nil
 }
 
 func SetWindowText(hwnd win.HWND, lpString *uint16) error {
@@ -67,10 +168,144 @@ func SetWindowText(hwnd win.HWND, lpString *uint16) error {
 }
 
 func RegisterClassEx(windowClass *win.WNDCLASSEX) (win.ATOM, error) {
-	a, err := registerClassEx(uintptr(unsafe.Pointer(&windowClass)))
+	a, err := registerClassEx(uintptr(unsafe.Pointer(windowClass)))
 	return win.ATOM(a), err
 }
 
+func RegisterHotKey(hwnd win.HWND, id int, fsModifiers uint32, vk uint32) error {
+	return registerHotKey(uintptr(hwnd), id, fsModifiers, vk)
+}
+
+func UnregisterHotKey(hwnd win.HWND, id int) error {
+	return unregisterHotKey(uintptr(hwnd), id)
+}
+
+func SetWindowsHookEx(idHook int, lpfn uintptr, hmod win.HINSTANCE, dwThreadId uint32) (HHOOK, error) {
+	h, err := setWindowsHookEx(idHook, lpfn, uintptr(hmod), dwThreadId)
+	return HHOOK(h), err
+}
+
+func UnhookWindowsHookEx(hhk HHOOK) error {
+	return unhookWindowsHookEx(uintptr(hhk))
+}
+
+func CallNextHookEx(hhk HHOOK, nCode int, wParam uintptr, lParam uintptr) uintptr {
+	return callNextHookEx(uintptr(hhk), nCode, wParam, lParam)
+}
+
+func PostThreadMessage(idThread uint32, msg uint32, wParam uintptr, lParam uintptr) error {
+	return postThreadMessage(idThread, msg, wParam, lParam)
+}
+
+func RegisterClipboardFormat(format string) (uint32, error) {
+	return registerClipboardFormat(MustUTF16PtrFromString(format))
+}
+
+// EnumClipboardFormats returns the format following format, 0 ends the enumeration.
+func EnumClipboardFormats(format uint32) uint32 {
+	return enumClipboardFormats(format)
+}
+
+func GetClipboardFormatName(format uint32) string {
+	var name = make([]uint16, 256)
+	n := getClipboardFormatName(format, &name[0], len(name))
+	return syscall.UTF16ToString(name[:n])
+}
+
+func GetClipboardSequenceNumber() uint32 {
+	return getClipboardSequenceNumber()
+}
+
+func AddClipboardFormatListener(hwnd win.HWND) error {
+	return addClipboardFormatListener(uintptr(hwnd))
+}
+
+func RemoveClipboardFormatListener(hwnd win.HWND) error {
+	return removeClipboardFormatListener(uintptr(hwnd))
+}
+
+// EnumDisplayMonitors calls lpfnEnum, a callback created by syscall.NewCallback, for each monitor.
+func EnumDisplayMonitors(hdc win.HDC, clip *win.RECT, lpfnEnum uintptr, dwData uintptr) error {
+	return enumDisplayMonitors(uintptr(hdc), uintptr(unsafe.Pointer(clip)), lpfnEnum, dwData)
+}
+
+func MonitorFromRect(rect *win.RECT, dwFlags uint32) win.HMONITOR {
+	return win.HMONITOR(monitorFromRect(uintptr(unsafe.Pointer(&rect.Left)), dwFlags))
+}
+
+// MonitorFromPoint is implemented with MonitorFromRect, POINT is passed by value
+// which differs between 32 and 64 bit calling conventions.
+func MonitorFromPoint(pt win.POINT, dwFlags uint32) win.HMONITOR {
+	rect := win.RECT{Left: pt.X, Top: pt.Y, Right: pt.X + 1, Bottom: pt.Y + 1}
+	return MonitorFromRect(&rect, dwFlags)
+}
+
+func GetMonitorInfo(hMonitor win.HMONITOR, lpmi *MONITORINFOEX) error {
+	lpmi.CbSize = uint32(unsafe.Sizeof(*lpmi))
+	return getMonitorInfo(uintptr(hMonitor), uintptr(unsafe.Pointer(lpmi)))
+}
+
+func EnumDisplaySettings(deviceName string, modeNum uint32, devMode *win.DEVMODE) error {
+	devMode.DmSize = uint16(unsafe.Sizeof(*devMode))
+	return enumDisplaySettings(MustUTF16PtrFromString(deviceName), modeNum, uintptr(unsafe.Pointer(devMode)))
+}
+
+func PrintWindow(hwnd win.HWND, hdcBlt win.HDC, nFlags uint32) error {
+	return printWindow(uintptr(hwnd), uintptr(hdcBlt), nFlags)
+}
+
+// CreateIconFromResourceEx creates an icon or cursor from the bits of a
+// single RT_ICON or RT_CURSOR resource.
+func CreateIconFromResourceEx(bits []byte, icon bool, cxDesired, cyDesired int, flags uint32) (win.HICON, error) {
+	if len(bits) == 0 {
+		return 0, syscall.EINVAL
+	}
+	h, err := createIconFromResourceEx(&bits[0], uint32(len(bits)), icon, 0x00030000, cxDesired, cyDesired, flags)
+	return win.HICON(h), err
+}
+
+// CreateIconIndirect creates an icon or cursor from the bitmaps in info,
+// which are copied and remain owned by the caller.
+func CreateIconIndirect(info *win.ICONINFO) (win.HICON, error) {
+	h, err := createIconIndirect(uintptr(unsafe.Pointer(info)))
+	return win.HICON(h), err
+}
+
+// CopyIcon copies an icon or cursor, which CopyCursor does as well.
+func CopyIcon(h win.HICON) (win.HICON, error) {
+	c, err := copyIcon(uintptr(h))
+	return win.HICON(c), err
+}
+
+// SetSystemCursor replaces the system cursor id, one of OCR_*, with hcur
+// until RestoreSystemCursors or the next logon. The system takes ownership
+// of hcur and destroys it, pass a copy to keep using the cursor.
+func SetSystemCursor(hcur win.HCURSOR, id uint32) error {
+	return setSystemCursor(uintptr(hcur), id)
+}
+
+// RestoreSystemCursors reloads all system cursors from the user's scheme,
+// undoing every SetSystemCursor.
+func RestoreSystemCursors() error {
+	return systemParametersInfo(SPI_SETCURSORS, 0, 0, 0)
+}
+
+// SendMessageTimeout returns the result of the window procedure. err is set
+// when the call failed or timed out.
+func SendMessageTimeout(hwnd win.HWND, msg uint32, wParam, lParam uintptr, flags, timeout uint32) (result uintptr, err error) {
+	_, err = sendMessageTimeout(uintptr(hwnd), msg, wParam, lParam, flags, timeout, &result)
+	return result, err
+}
+
+// SetThreadDpiAwarenessContext is available from Windows 10 1607, earlier
+// versions return 0 like a failed call.
+func SetThreadDpiAwarenessContext(dpiContext uintptr) (old uintptr) {
+	if procSetThreadDpiAwarenessContext.Find() != nil {
+		return 0
+	}
+	return setThreadDpiAwarenessContext(dpiContext)
+}
+
 func ShowCursor(state bool) (counter int) {
 	return showCursor(state)
 }
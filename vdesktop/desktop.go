@@ -0,0 +1,165 @@
+// Package vdesktop tells which virtual desktop windows are on and moves
+// windows between desktops.
+package vdesktop
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrFormat is returned for malformed desktop ids.
+var ErrFormat = errors.New("vdesktop: malformed desktop id")
+
+// ID identifies a virtual desktop. It has the layout of a GUID and converts
+// to ole.GUID.
+type ID struct {
+	Data1 uint32
+	Data2 uint16
+	Data3 uint16
+	Data4 [8]byte
+}
+
+// idSize is the size of an ID in its binary form.
+const idSize = 16
+
+// ParseID parses an id in registry format, with or without braces.
+func ParseID(s string) (ID, error) {
+	t := strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+	if len(t) != 36 || t[8] != '-' || t[13] != '-' || t[18] != '-' || t[23] != '-' {
+		return ID{}, errors.Wrap(ErrFormat, s)
+	}
+	b, err := hex.DecodeString(t[0:8] + t[9:13] + t[14:18] + t[19:23] + t[24:])
+	if err != nil {
+		return ID{}, errors.Wrap(ErrFormat, s)
+	}
+	id := ID{
+		Data1: binary.BigEndian.Uint32(b[0:]),
+		Data2: binary.BigEndian.Uint16(b[4:]),
+		Data3: binary.BigEndian.Uint16(b[6:]),
+	}
+	copy(id.Data4[:], b[8:])
+	return id, nil
+}
+
+// String formats id like the registry, {XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX}.
+func (id ID) String() string {
+	return fmt.Sprintf("{%08X-%04X-%04X-%X-%X}", id.Data1, id.Data2, id.Data3, id.Data4[:2], id.Data4[2:])
+}
+
+// IsZero reports whether id is GUID_NULL, the id of no desktop.
+func (id ID) IsZero() bool {
+	return id == ID{}
+}
+
+// IDFromBytes decodes a GUID in the little-endian binary layout Windows
+// stores in the registry.
+func IDFromBytes(b []byte) (ID, error) {
+	if len(b) != idSize {
+		return ID{}, errors.Wrapf(ErrFormat, "%d bytes", len(b))
+	}
+	id := ID{
+		Data1: binary.LittleEndian.Uint32(b[0:]),
+		Data2: binary.LittleEndian.Uint16(b[4:]),
+		Data3: binary.LittleEndian.Uint16(b[6:]),
+	}
+	copy(id.Data4[:], b[8:])
+	return id, nil
+}
+
+// Bytes encodes id in the layout read by IDFromBytes.
+func (id ID) Bytes() []byte {
+	b := make([]byte, idSize)
+	binary.LittleEndian.PutUint32(b[0:], id.Data1)
+	binary.LittleEndian.PutUint16(b[4:], id.Data2)
+	binary.LittleEndian.PutUint16(b[6:], id.Data3)
+	copy(b[8:], id.Data4[:])
+	return b
+}
+
+// IDsFromBytes decodes a list of ids stored back to back, like the
+// VirtualDesktopIDs registry value, in desktop order.
+func IDsFromBytes(b []byte) ([]ID, error) {
+	if len(b)%idSize != 0 {
+		return nil, errors.Wrapf(ErrFormat, "%d bytes", len(b))
+	}
+	ids := make([]ID, 0, len(b)/idSize)
+	for ; len(b) > 0; b = b[idSize:] {
+		id, _ := IDFromBytes(b[:idSize])
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// Index returns the position of id in ids, -1 when it is missing.
+func Index(ids []ID, id ID) int {
+	for i := range ids {
+		if ids[i] == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Locator reports where windows are. The Windows implementation is
+// IVirtualDesktopManager.
+type Locator interface {
+	IsWindowOnCurrentVirtualDesktop(hwnd uintptr) (bool, error)
+	GetWindowDesktopId(hwnd uintptr) (ID, error)
+}
+
+// Predicate selects windows by handle.
+type Predicate func(hwnd uintptr) bool
+
+// OnCurrentDesktop keeps windows shown on the current desktop. Windows the
+// desktop manager does not track, like tool windows and windows being
+// destroyed, are kept.
+func OnCurrentDesktop(l Locator) Predicate {
+	return func(hwnd uintptr) bool {
+		on, err := l.IsWindowOnCurrentVirtualDesktop(hwnd)
+		return on || err != nil
+	}
+}
+
+// OnDesktop keeps windows placed on desktop id. Windows pinned to all
+// desktops report no desktop and are only kept by OnCurrentDesktop.
+func OnDesktop(l Locator, id ID) Predicate {
+	return func(hwnd uintptr) bool {
+		got, err := l.GetWindowDesktopId(hwnd)
+		return err == nil && got == id
+	}
+}
+
+// And keeps windows all predicates keep.
+func And(preds ...Predicate) Predicate {
+	return func(hwnd uintptr) bool {
+		for _, p := range preds {
+			if !p(hwnd) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or keeps windows any predicate keeps.
+func Or(preds ...Predicate) Predicate {
+	return func(hwnd uintptr) bool {
+		for _, p := range preds {
+			if p(hwnd) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not keeps the windows p drops.
+func Not(p Predicate) Predicate {
+	return func(hwnd uintptr) bool {
+		return !p(hwnd)
+	}
+}
@@ -0,0 +1,156 @@
+package vdesktop
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var testID = ID{
+	Data1: 0xAA509086,
+	Data2: 0x5CA9,
+	Data3: 0x4C25,
+	Data4: [8]byte{0x8F, 0x95, 0x58, 0x9D, 0x3C, 0x07, 0xB4, 0x8A},
+}
+
+// testBytes is testID as stored in the registry
+var testBytes = []byte{0x86, 0x90, 0x50, 0xAA, 0xA9, 0x5C, 0x25, 0x4C, 0x8F, 0x95, 0x58, 0x9D, 0x3C, 0x07, 0xB4, 0x8A}
+
+func TestParseID(t *testing.T) {
+	for _, s := range []string{
+		"{AA509086-5CA9-4C25-8F95-589D3C07B48A}",
+		"AA509086-5CA9-4C25-8F95-589D3C07B48A",
+		"{aa509086-5ca9-4c25-8f95-589d3c07b48a}",
+	} {
+		id, err := ParseID(s)
+		if err != nil {
+			t.Errorf("ParseID(%q): %v", s, err)
+			continue
+		}
+		if id != testID {
+			t.Errorf("ParseID(%q) = %+v, want %+v", s, id, testID)
+		}
+	}
+	if got, want := testID.String(), "{AA509086-5CA9-4C25-8F95-589D3C07B48A}"; got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+}
+
+func TestParseIDErrors(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"{}",
+		"AA509086-5CA9-4C25-8F95-589D3C07B48",
+		"AA509086-5CA9-4C25-8F95-589D3C07B48AB",
+		"AA5090865-CA9-4C25-8F95-589D3C07B48A",
+		"AA509086-5CA9-4C25-8F95-589D3C07B4XA",
+		"{{AA509086-5CA9-4C25-8F95-589D3C07B48A}}",
+	} {
+		if id, err := ParseID(s); !errors.Is(err, ErrFormat) {
+			t.Errorf("ParseID(%q) = %v, %v, want ErrFormat", s, id, err)
+		}
+	}
+}
+
+func TestIDBytes(t *testing.T) {
+	if b := testID.Bytes(); !bytes.Equal(b, testBytes) {
+		t.Errorf("Bytes() = % x, want % x", b, testBytes)
+	}
+	id, err := IDFromBytes(testBytes)
+	if err != nil || id != testID {
+		t.Errorf("IDFromBytes = %+v, %v", id, err)
+	}
+	if _, err := IDFromBytes(testBytes[1:]); !errors.Is(err, ErrFormat) {
+		t.Errorf("IDFromBytes of 15 bytes: %v", err)
+	}
+	if !(ID{}).IsZero() || testID.IsZero() {
+		t.Error("IsZero is wrong")
+	}
+}
+
+func TestIDsFromBytes(t *testing.T) {
+	other := ID{Data1: 1, Data4: [8]byte{7: 2}}
+	b := append(append([]byte(nil), testBytes...), other.Bytes()...)
+	ids, err := IDsFromBytes(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []ID{testID, other}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("IDsFromBytes = %v, want %v", ids, want)
+	}
+	if ids, err := IDsFromBytes(nil); err != nil || len(ids) != 0 {
+		t.Errorf("IDsFromBytes(nil) = %v, %v", ids, err)
+	}
+	if _, err := IDsFromBytes(b[:20]); !errors.Is(err, ErrFormat) {
+		t.Errorf("IDsFromBytes of 20 bytes: %v", err)
+	}
+
+	if i := Index(ids, other); i != 1 {
+		t.Errorf("Index = %d, want 1", i)
+	}
+	if i := Index(ids, ID{}); i != -1 {
+		t.Errorf("Index of a missing id = %d, want -1", i)
+	}
+}
+
+// fakeLocator places windows 1 and 2 on desktop a, 3 on desktop b and
+// knows nothing about other windows. a is the current desktop.
+type fakeLocator struct {
+	a, b ID
+}
+
+func (l fakeLocator) desktop(hwnd uintptr) (ID, error) {
+	switch hwnd {
+	case 1, 2:
+		return l.a, nil
+	case 3:
+		return l.b, nil
+	}
+	return ID{}, errors.New("not tracked")
+}
+
+func (l fakeLocator) IsWindowOnCurrentVirtualDesktop(hwnd uintptr) (bool, error) {
+	id, err := l.desktop(hwnd)
+	return err == nil && id == l.a, err
+}
+
+func (l fakeLocator) GetWindowDesktopId(hwnd uintptr) (ID, error) {
+	return l.desktop(hwnd)
+}
+
+func filter(p Predicate) []uintptr {
+	var kept []uintptr
+	for hwnd := uintptr(1); hwnd <= 4; hwnd++ {
+		if p(hwnd) {
+			kept = append(kept, hwnd)
+		}
+	}
+	return kept
+}
+
+func TestPredicates(t *testing.T) {
+	l := fakeLocator{a: testID, b: ID{Data1: 2}}
+	odd := Predicate(func(hwnd uintptr) bool { return hwnd%2 == 1 })
+
+	tests := []struct {
+		name string
+		p    Predicate
+		want []uintptr
+	}{
+		// untracked windows stay on the current desktop
+		{"OnCurrentDesktop", OnCurrentDesktop(l), []uintptr{1, 2, 4}},
+		{"OnDesktop a", OnDesktop(l, l.a), []uintptr{1, 2}},
+		{"OnDesktop b", OnDesktop(l, l.b), []uintptr{3}},
+		{"And", And(OnCurrentDesktop(l), odd), []uintptr{1}},
+		{"empty And", And(), []uintptr{1, 2, 3, 4}},
+		{"Or", Or(OnDesktop(l, l.b), odd), []uintptr{1, 3}},
+		{"empty Or", Or(), nil},
+		{"Not", Not(OnCurrentDesktop(l)), []uintptr{3}},
+	}
+	for _, tt := range tests {
+		if got := filter(tt.p); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s keeps %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package vdesktop
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/go-ole"
+	"github.com/whiteboxsolutions/winapi"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// VirtualDesktopManager
+// https://learn.microsoft.com/en-us/windows/win32/api/shobjidl_core/nn-shobjidl_core-ivirtualdesktopmanager
+
+var CLSID_VirtualDesktopManager = ole.NewGUID("{aa509086-5ca9-4c25-8f95-589d3c07b48a}")
+
+// IVirtualDesktopManager
+
+var IVirtualDesktopManagerID = ole.NewGUID("{a5cd92ff-29be-454c-8d04-d82879fb3f1b}")
+
+type IVirtualDesktopManager struct {
+	ole.IUnknown
+}
+
+type IVirtualDesktopManagerVtbl struct {
+	ole.IUnknownVtbl
+	IsWindowOnCurrentVirtualDesktop uintptr
+	GetWindowDesktopId              uintptr
+	MoveWindowToDesktop             uintptr
+}
+
+func (v *IVirtualDesktopManager) VTable() *IVirtualDesktopManagerVtbl {
+	return (*IVirtualDesktopManagerVtbl)(unsafe.Pointer(v.RawVTable))
+}
+
+// NewManager creates the desktop manager, COM must be initialized on the
+// calling thread. It satisfies Locator.
+func NewManager() (*IVirtualDesktopManager, error) {
+	unk, err := ole.CreateInstance(CLSID_VirtualDesktopManager, IVirtualDesktopManagerID)
+	if err != nil {
+		return nil, err
+	}
+	return (*IVirtualDesktopManager)(unsafe.Pointer(unk)), nil
+}
+
+func (v *IVirtualDesktopManager) IsWindowOnCurrentVirtualDesktop(hwnd uintptr) (bool, error) {
+	var on int32
+	r1, _, _ := syscall.SyscallN(v.VTable().IsWindowOnCurrentVirtualDesktop, uintptr(unsafe.Pointer(v)), hwnd, uintptr(unsafe.Pointer(&on)))
+	if r1 != win.S_OK {
+		return false, ole.NewError(r1)
+	}
+	return on != 0, nil
+}
+
+// GetWindowDesktopId returns the desktop of a top-level window. Windows
+// pinned to all desktops return the zero ID.
+func (v *IVirtualDesktopManager) GetWindowDesktopId(hwnd uintptr) (ID, error) {
+	var id ole.GUID
+	r1, _, _ := syscall.SyscallN(v.VTable().GetWindowDesktopId, uintptr(unsafe.Pointer(v)), hwnd, uintptr(unsafe.Pointer(&id)))
+	if r1 != win.S_OK {
+		return ID{}, ole.NewError(r1)
+	}
+	return ID(id), nil
+}
+
+// MoveWindowToDesktop moves a top-level window. Windows only allows moving
+// windows of the calling process.
+func (v *IVirtualDesktopManager) MoveWindowToDesktop(hwnd uintptr, desktop ID) error {
+	id := ole.GUID(desktop)
+	r1, _, _ := syscall.SyscallN(v.VTable().MoveWindowToDesktop, uintptr(unsafe.Pointer(v)), hwnd, uintptr(unsafe.Pointer(&id)))
+	if r1 != win.S_OK {
+		return ole.NewError(r1)
+	}
+	return nil
+}
+
+// Filter adapts p for winapi.ListWindows.
+func (p Predicate) Filter() winapi.WindowFilter {
+	return func(w *winapi.WindowInfo) bool {
+		return p(uintptr(w.HWND))
+	}
+}
+
+// CurrentDesktopWindows keeps the windows of the current desktop in
+// winapi.ListWindows.
+func CurrentDesktopWindows(l Locator) winapi.WindowFilter {
+	return OnCurrentDesktop(l).Filter()
+}
+
+// DesktopWindows keeps the windows placed on desktop id in winapi.ListWindows.
+func DesktopWindows(l Locator, id ID) winapi.WindowFilter {
+	return OnDesktop(l, id).Filter()
+}
+
+const virtualDesktopsKey = `Software\Microsoft\Windows\CurrentVersion\Explorer\VirtualDesktops`
+
+// Desktops returns the ids of all desktops in the order of the task view.
+// IVirtualDesktopManager cannot enumerate desktops, Explorer keeps the list
+// in the registry.
+func Desktops() ([]ID, error) {
+	b, err := readDesktopsValue("VirtualDesktopIDs")
+	if err != nil {
+		return nil, err
+	}
+	return IDsFromBytes(b)
+}
+
+// Current returns the id of the current desktop.
+func Current() (ID, error) {
+	b, err := readDesktopsValue("CurrentVirtualDesktop")
+	if err != nil {
+		// Windows 10 keeps the current desktop per logon session
+		var session uint32
+		if windows.ProcessIdToSessionId(windows.GetCurrentProcessId(), &session) != nil {
+			return ID{}, err
+		}
+		key := fmt.Sprintf(`Software\Microsoft\Windows\CurrentVersion\Explorer\SessionInfo\%d\VirtualDesktops`, session)
+		if b, err = readValue(key, "CurrentVirtualDesktop"); err != nil {
+			return ID{}, err
+		}
+	}
+	return IDFromBytes(b)
+}
+
+func readDesktopsValue(name string) ([]byte, error) {
+	return readValue(virtualDesktopsKey, name)
+}
+
+func readValue(key, name string) ([]byte, error) {
+	k, err := registry.OpenKey(registry.CURRENT_USER, key, registry.QUERY_VALUE)
+	if err != nil {
+		return nil, errors.Wrap(err, key)
+	}
+	defer k.Close()
+	b, _, err := k.GetBinaryValue(name)
+	if err != nil {
+		return nil, errors.Wrap(err, name)
+	}
+	return b, nil
+}
@@ -0,0 +1,110 @@
+package winapi
+
+import (
+	"sync"
+	"syscall"
+
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"golang.org/x/sys/windows"
+)
+
+// WindowInfo describes a top-level window.
+type WindowInfo struct {
+	HWND      win.HWND
+	Title     string
+	ClassName string
+	ProcessID uint32
+	ThreadID  uint32
+	Rect      win.RECT
+	Visible   bool
+	Minimized bool
+	Maximized bool
+
+	// Frame is Rect without the invisible resize borders, Cloaked holds
+	// the DWM_CLOAKED_* reasons DWM does not draw the window.
+	Frame   win.RECT
+	Cloaked uint32
+}
+
+// WindowFilter selects windows returned by ListWindows.
+type WindowFilter func(w *WindowInfo) bool
+
+// VisibleWindows keeps windows with the WS_VISIBLE style.
+func VisibleWindows(w *WindowInfo) bool {
+	return w.Visible
+}
+
+// ReallyVisibleWindows keeps windows the user can see: visible, neither
+// minimized nor cloaked, and with a non-empty frame.
+func ReallyVisibleWindows(w *WindowInfo) bool {
+	return w.Visible && !w.Minimized && w.Cloaked == 0 &&
+		w.Frame.Right > w.Frame.Left && w.Frame.Bottom > w.Frame.Top
+}
+
+// IsWindowReallyVisible applies ReallyVisibleWindows to hwnd.
+func IsWindowReallyVisible(hwnd win.HWND) bool {
+	w := GetWindowInfo(hwnd)
+	return ReallyVisibleWindows(&w)
+}
+
+// TitledWindows keeps windows with a non-empty title.
+func TitledWindows(w *WindowInfo) bool {
+	return w.Title != ""
+}
+
+var enumWindows struct {
+	sync.Mutex
+	hwnds []win.HWND
+}
+
+var enumWindowsProc = syscall.NewCallback(func(hwnd win.HWND, lParam uintptr) uintptr {
+	enumWindows.hwnds = append(enumWindows.hwnds, hwnd)
+	return 1
+})
+
+// GetWindowInfo describes hwnd.
+func GetWindowInfo(hwnd win.HWND) WindowInfo {
+	w := WindowInfo{
+		HWND:      hwnd,
+		Visible:   win.IsWindowVisible(hwnd),
+		Minimized: win.IsIconic(hwnd),
+		Maximized: win.IsZoomed(hwnd),
+	}
+	w.Title, _ = WindowText(hwnd)
+	w.ClassName, _ = ClassName(hwnd)
+	w.ThreadID = win.GetWindowThreadProcessId(hwnd, &w.ProcessID)
+	win.GetWindowRect(hwnd, &w.Rect)
+	w.Cloaked, _ = DwmCloaked(hwnd)
+	if frame, err := DwmExtendedFrameBounds(hwnd); err == nil {
+		w.Frame = frame
+	} else {
+		w.Frame = w.Rect
+	}
+	return w
+}
+
+// ListWindows returns the top-level windows in z-order, keeping those all filters accept.
+func ListWindows(filters ...WindowFilter) ([]WindowInfo, error) {
+	enumWindows.Lock()
+	enumWindows.hwnds = nil
+	err := windows.EnumWindows(enumWindowsProc, nil)
+	hwnds := enumWindows.hwnds
+	enumWindows.Unlock()
+	if err != nil {
+		return nil, errors.Wrap(err, "EnumWindows")
+	}
+
+	var res []WindowInfo
+next:
+	for _, hwnd := range hwnds {
+		w := GetWindowInfo(hwnd)
+		for _, filter := range filters {
+			if !filter(&w) {
+				continue next
+			}
+		}
+		res = append(res, w)
+	}
+	return res, nil
+}
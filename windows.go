@@ -13,10 +13,39 @@ const NULL uintptr = 0
 //sys findWindowEx(hwndParent uintptr, hwndChildAfter uintptr, lpszClass *uint16, lpszWindow *uint16) (hwnd uintptr) = user32.FindWindowExW
 //sys getWindowText(hwnd uintptr, lpString uintptr, nMax int) (length int) = user32.GetWindowTextW
 //sys getClassName(hwnd uintptr, lpClassName uintptr, nMax int) (length int) = user32.GetClassNameW
+//sys getWindowTextLength(hwnd uintptr) (length int) = user32.GetWindowTextLengthW
+//sys isWindow(hwnd uintptr) (ok bool) = user32.IsWindow
 //sys setWindowText(hwnd uintptr, lpString *uint16) (err error) = user32.SetWindowTextW
 //sys invalidateRect(hwnd uintptr, rect uintptr, bErase bool) (err error) = user32.InvalidateRect
 //sys mapVirtualKey(uCode uint32, uMapType uint32) (code uint32) = user32.MapVirtualKeyW
 //sys registerClassEx(windowClass uintptr) (atom uint16, err error) = user32.RegisterClassExW
+//sys registerHotKey(hwnd uintptr, id int, fsModifiers uint32, vk uint32) (err error) = user32.RegisterHotKey
+//sys unregisterHotKey(hwnd uintptr, id int) (err error) = user32.UnregisterHotKey
+//sys setWindowsHookEx(idHook int, lpfn uintptr, hmod uintptr, dwThreadId uint32) (hhk uintptr, err error) = user32.SetWindowsHookExW
+//sys unhookWindowsHookEx(hhk uintptr) (err error) = user32.UnhookWindowsHookEx
+//sys callNextHookEx(hhk uintptr, nCode int, wParam uintptr, lParam uintptr) (ret uintptr) = user32.CallNextHookEx
+//sys postThreadMessage(idThread uint32, msg uint32, wParam uintptr, lParam uintptr) (err error) = user32.PostThreadMessageW
+//sys registerClipboardFormat(lpszFormat *uint16) (format uint32, err error) = user32.RegisterClipboardFormatW
+//sys enumClipboardFormats(format uint32) (next uint32) = user32.EnumClipboardFormats
+//sys getClipboardFormatName(format uint32, lpszFormatName *uint16, cchMaxCount int) (length int) = user32.GetClipboardFormatNameW
+//sys getClipboardSequenceNumber() (seq uint32) = user32.GetClipboardSequenceNumber
+//sys addClipboardFormatListener(hwnd uintptr) (err error) = user32.AddClipboardFormatListener
+//sys removeClipboardFormatListener(hwnd uintptr) (err error) = user32.RemoveClipboardFormatListener
+//sys enumDisplayMonitors(hdc uintptr, lprcClip uintptr, lpfnEnum uintptr, dwData uintptr) (err error) = user32.EnumDisplayMonitors
+//sys monitorFromRect(lprc uintptr, dwFlags uint32) (hmonitor uintptr) = user32.MonitorFromRect
+//sys getMonitorInfo(hMonitor uintptr, lpmi uintptr) (err error) = user32.GetMonitorInfoW
+//sys enumDisplaySettings(lpszDeviceName *uint16, iModeNum uint32, lpDevMode uintptr) (err error) = user32.EnumDisplaySettingsW
+//sys printWindow(hwnd uintptr, hdcBlt uintptr, nFlags uint32) (err error) = user32.PrintWindow
+//sys setThreadDpiAwarenessContext(dpiContext uintptr) (old uintptr) = user32.SetThreadDpiAwarenessContext
+//sys dwmGetWindowAttribute(hwnd uintptr, dwAttribute uint32, pvAttribute unsafe.Pointer, cbAttribute uint32) (hresult int32) = Dwmapi.DwmGetWindowAttribute
+//sys dwmSetWindowAttribute(hwnd uintptr, dwAttribute uint32, pvAttribute unsafe.Pointer, cbAttribute uint32) (hresult int32) = Dwmapi.DwmSetWindowAttribute
+//sys createIconFromResourceEx(presbits *byte, dwResSize uint32, fIcon bool, dwVer uint32, cxDesired int, cyDesired int, flags uint32) (hicon uintptr, err error) = user32.CreateIconFromResourceEx
+//sys createIconIndirect(piconinfo uintptr) (hicon uintptr, err error) = user32.CreateIconIndirect
+//sys copyIcon(hicon uintptr) (h uintptr, err error) = user32.CopyIcon
+//sys setSystemCursor(hcur uintptr, id uint32) (err error) = user32.SetSystemCursor
+//sys systemParametersInfo(uiAction uint32, uiParam uint32, pvParam uintptr, fWinIni uint32) (err error) = user32.SystemParametersInfoW
+//sys getGuiResources(process uintptr, flags uint32) (count uint32) = user32.GetGuiResources
+//sys sendMessageTimeout(hwnd uintptr, msg uint32, wParam uintptr, lParam uintptr, fuFlags uint32, uTimeout uint32, lpdwResult *uintptr) (ret uintptr, err error) = user32.SendMessageTimeoutW
 
 //sys createSolidBrush(color uint32) (hbrush uintptr) = Gdi32.CreateSolidBrush
 //sys createPen(iStyle int, cWidth int, color uint32) (hpen uintptr) = Gdi32.CreatePen
@@ -24,6 +53,31 @@ const NULL uintptr = 0
 //sys createRectRgnIndirect(rect uintptr) (rgn uintptr) = Gdi32.CreateRectRgnIndirect
 //sys createDIBSection(hdc uintptr, pbmi uintptr, usage uint, ppvBits uintptr, hSection uintptr, offset uint32) (hBitMap uintptr) = Gdi32.CreateDIBSection
 //sys extFloodFill(hdc uintptr, x int, y int, color uint32, opType uint32) (err error) = Gdi32.ExtFloodFill
+//sys polygon(hdc uintptr, apt uintptr, cpt int) (err error) = Gdi32.Polygon
+//sys polyBezier(hdc uintptr, apt uintptr, cpt uint32) (err error) = Gdi32.PolyBezier
+//sys arc(hdc uintptr, x1 int, y1 int, x2 int, y2 int, x3 int, y3 int, x4 int, y4 int) (err error) = Gdi32.Arc
+//sys setTextAlign(hdc uintptr, align uint32) (old uint32) = Gdi32.SetTextAlign
+//sys selectClipRgn(hdc uintptr, hrgn uintptr) (region int) = Gdi32.SelectClipRgn
+//sys beginPath(hdc uintptr) (err error) = Gdi32.BeginPath
+//sys endPath(hdc uintptr) (err error) = Gdi32.EndPath
+//sys abortPath(hdc uintptr) (err error) = Gdi32.AbortPath
+//sys strokePath(hdc uintptr) (err error) = Gdi32.StrokePath
+//sys fillPath(hdc uintptr) (err error) = Gdi32.FillPath
+//sys strokeAndFillPath(hdc uintptr) (err error) = Gdi32.StrokeAndFillPath
+//sys pathToRegion(hdc uintptr) (rgn uintptr, err error) = Gdi32.PathToRegion
+//sys setPolyFillMode(hdc uintptr, mode int) (old int) = Gdi32.SetPolyFillMode
+//sys extCreateRegion(lpx uintptr, nCount uint32, lpData *byte) (rgn uintptr, err error) = Gdi32.ExtCreateRegion
+//sys getRegionData(hrgn uintptr, nCount uint32, lpRgnData *byte) (n uint32, err error) = Gdi32.GetRegionData
+//sys enumFontFamiliesEx(hdc uintptr, lpLogfont uintptr, lpProc uintptr, lParam uintptr, dwFlags uint32) (ret int32) = Gdi32.EnumFontFamiliesExW
+//sys getEnhMetaFileBits(hemf uintptr, nSize uint32, lpData *byte) (n uint32) = Gdi32.GetEnhMetaFileBits
+//sys setEnhMetaFileBits(nSize uint32, pb *byte) (hemf uintptr, err error) = Gdi32.SetEnhMetaFileBits
+
+//sys globalSize(hMem uintptr) (size uintptr) = kernel32.GlobalSize
+//sys setLastError(code uint32) = kernel32.SetLastError
+//sys virtualAllocEx(process uintptr, address uintptr, size uintptr, allocType uint32, protect uint32) (addr uintptr, err error) = kernel32.VirtualAllocEx
+//sys virtualFreeEx(process uintptr, address uintptr, size uintptr, freeType uint32) (err error) = kernel32.VirtualFreeEx
+
+//sys getDpiForMonitor(hmonitor uintptr, dpiType uint32, dpiX *uint32, dpiY *uint32) (hresult int32) = Shcore.GetDpiForMonitor
 
 //sys activateAudioInterfaceAsync(deviceInterfacePath *uint16, riid uintptr, activationParams uintptr, completionHandler uintptr, createAsync uintptr) (hresult int32) = Mmdevapi.ActivateAudioInterfaceAsync
 
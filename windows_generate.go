@@ -38,43 +38,134 @@ func errnoErr(e syscall.Errno) error {
 }
 
 var (
+	modDwmapi   = windows.NewLazySystemDLL("Dwmapi.dll")
 	modGdi32    = windows.NewLazySystemDLL("Gdi32.dll")
 	modMmdevapi = windows.NewLazySystemDLL("Mmdevapi.dll")
+	modShcore   = windows.NewLazySystemDLL("Shcore.dll")
 	modWtsapi32 = windows.NewLazySystemDLL("Wtsapi32.dll")
+	modkernel32 = windows.NewLazySystemDLL("kernel32.dll")
 	moduser32   = windows.NewLazySystemDLL("user32.dll")
 
-	procCreateDIBSection            = modGdi32.NewProc("CreateDIBSection")
-	procCreatePen                   = modGdi32.NewProc("CreatePen")
-	procCreateRectRgnIndirect       = modGdi32.NewProc("CreateRectRgnIndirect")
-	procCreateSolidBrush            = modGdi32.NewProc("CreateSolidBrush")
-	procExtFloodFill                = modGdi32.NewProc("ExtFloodFill")
-	procPolyDraw                    = modGdi32.NewProc("PolyDraw")
-	procActivateAudioInterfaceAsync = modMmdevapi.NewProc("ActivateAudioInterfaceAsync")
-	procWTSCloseServer              = modWtsapi32.NewProc("WTSCloseServer")
-	procWTSEnumerateSessionsExW     = modWtsapi32.NewProc("WTSEnumerateSessionsExW")
-	procWTSFreeMemoryExW            = modWtsapi32.NewProc("WTSFreeMemoryExW")
-	procWTSOpenServerExW            = modWtsapi32.NewProc("WTSOpenServerExW")
-	procWTSVirtualChannelClose      = modWtsapi32.NewProc("WTSVirtualChannelClose")
-	procWTSVirtualChannelOpen       = modWtsapi32.NewProc("WTSVirtualChannelOpen")
-	procWTSVirtualChannelOpenEx     = modWtsapi32.NewProc("WTSVirtualChannelOpenEx")
-	procWTSVirtualChannelRead       = modWtsapi32.NewProc("WTSVirtualChannelRead")
-	procWTSVirtualChannelWrite      = modWtsapi32.NewProc("WTSVirtualChannelWrite")
-	procClipCursor                  = moduser32.NewProc("ClipCursor")
-	procEnumDesktopWindows          = moduser32.NewProc("EnumDesktopWindows")
-	procFillRect                    = moduser32.NewProc("FillRect")
-	procFindWindowExW               = moduser32.NewProc("FindWindowExW")
-	procGetClassNameW               = moduser32.NewProc("GetClassNameW")
-	procGetWindowTextW              = moduser32.NewProc("GetWindowTextW")
-	procInvalidateRect              = moduser32.NewProc("InvalidateRect")
-	procMapVirtualKeyW              = moduser32.NewProc("MapVirtualKeyW")
-	procRegisterClassExW            = moduser32.NewProc("RegisterClassExW")
-	procSetLayeredWindowAttributes  = moduser32.NewProc("SetLayeredWindowAttributes")
-	procSetWindowRgn                = moduser32.NewProc("SetWindowRgn")
-	procSetWindowTextW              = moduser32.NewProc("SetWindowTextW")
-	procShowCursor                  = moduser32.NewProc("ShowCursor")
-	procUpdateLayeredWindow         = moduser32.NewProc("UpdateLayeredWindow")
+	procDwmGetWindowAttribute         = modDwmapi.NewProc("DwmGetWindowAttribute")
+	procDwmSetWindowAttribute         = modDwmapi.NewProc("DwmSetWindowAttribute")
+	procAbortPath                     = modGdi32.NewProc("AbortPath")
+	procArc                           = modGdi32.NewProc("Arc")
+	procBeginPath                     = modGdi32.NewProc("BeginPath")
+	procCreateDIBSection              = modGdi32.NewProc("CreateDIBSection")
+	procCreatePen                     = modGdi32.NewProc("CreatePen")
+	procCreateRectRgnIndirect         = modGdi32.NewProc("CreateRectRgnIndirect")
+	procCreateSolidBrush              = modGdi32.NewProc("CreateSolidBrush")
+	procEndPath                       = modGdi32.NewProc("EndPath")
+	procEnumFontFamiliesExW           = modGdi32.NewProc("EnumFontFamiliesExW")
+	procExtCreateRegion               = modGdi32.NewProc("ExtCreateRegion")
+	procExtFloodFill                  = modGdi32.NewProc("ExtFloodFill")
+	procFillPath                      = modGdi32.NewProc("FillPath")
+	procGetEnhMetaFileBits            = modGdi32.NewProc("GetEnhMetaFileBits")
+	procGetRegionData                 = modGdi32.NewProc("GetRegionData")
+	procPathToRegion                  = modGdi32.NewProc("PathToRegion")
+	procPolyBezier                    = modGdi32.NewProc("PolyBezier")
+	procPolyDraw                      = modGdi32.NewProc("PolyDraw")
+	procPolygon                       = modGdi32.NewProc("Polygon")
+	procSelectClipRgn                 = modGdi32.NewProc("SelectClipRgn")
+	procSetEnhMetaFileBits            = modGdi32.NewProc("SetEnhMetaFileBits")
+	procSetPolyFillMode               = modGdi32.NewProc("SetPolyFillMode")
+	procSetTextAlign                  = modGdi32.NewProc("SetTextAlign")
+	procStrokeAndFillPath             = modGdi32.NewProc("StrokeAndFillPath")
+	procStrokePath                    = modGdi32.NewProc("StrokePath")
+	procActivateAudioInterfaceAsync   = modMmdevapi.NewProc("ActivateAudioInterfaceAsync")
+	procGetDpiForMonitor              = modShcore.NewProc("GetDpiForMonitor")
+	procWTSCloseServer                = modWtsapi32.NewProc("WTSCloseServer")
+	procWTSEnumerateSessionsExW       = modWtsapi32.NewProc("WTSEnumerateSessionsExW")
+	procWTSFreeMemoryExW              = modWtsapi32.NewProc("WTSFreeMemoryExW")
+	procWTSOpenServerExW              = modWtsapi32.NewProc("WTSOpenServerExW")
+	procWTSVirtualChannelClose        = modWtsapi32.NewProc("WTSVirtualChannelClose")
+	procWTSVirtualChannelOpen         = modWtsapi32.NewProc("WTSVirtualChannelOpen")
+	procWTSVirtualChannelOpenEx       = modWtsapi32.NewProc("WTSVirtualChannelOpenEx")
+	procWTSVirtualChannelRead         = modWtsapi32.NewProc("WTSVirtualChannelRead")
+	procWTSVirtualChannelWrite        = modWtsapi32.NewProc("WTSVirtualChannelWrite")
+	procGlobalSize                    = modkernel32.NewProc("GlobalSize")
+	procSetLastError                  = modkernel32.NewProc("SetLastError")
+	procVirtualAllocEx                = modkernel32.NewProc("VirtualAllocEx")
+	procVirtualFreeEx                 = modkernel32.NewProc("VirtualFreeEx")
+	procAddClipboardFormatListener    = moduser32.NewProc("AddClipboardFormatListener")
+	procCallNextHookEx                = moduser32.NewProc("CallNextHookEx")
+	procClipCursor                    = moduser32.NewProc("ClipCursor")
+	procCopyIcon                      = moduser32.NewProc("CopyIcon")
+	procCreateIconFromResourceEx      = moduser32.NewProc("CreateIconFromResourceEx")
+	procCreateIconIndirect            = moduser32.NewProc("CreateIconIndirect")
+	procEnumClipboardFormats          = moduser32.NewProc("EnumClipboardFormats")
+	procEnumDesktopWindows            = moduser32.NewProc("EnumDesktopWindows")
+	procEnumDisplayMonitors           = moduser32.NewProc("EnumDisplayMonitors")
+	procEnumDisplaySettingsW          = moduser32.NewProc("EnumDisplaySettingsW")
+	procFillRect                      = moduser32.NewProc("FillRect")
+	procFindWindowExW                 = moduser32.NewProc("FindWindowExW")
+	procGetClassNameW                 = moduser32.NewProc("GetClassNameW")
+	procGetClipboardFormatNameW       = moduser32.NewProc("GetClipboardFormatNameW")
+	procGetClipboardSequenceNumber    = moduser32.NewProc("GetClipboardSequenceNumber")
+	procGetGuiResources               = moduser32.NewProc("GetGuiResources")
+	procGetMonitorInfoW               = moduser32.NewProc("GetMonitorInfoW")
+	procGetWindowTextLengthW          = moduser32.NewProc("GetWindowTextLengthW")
+	procGetWindowTextW                = moduser32.NewProc("GetWindowTextW")
+	procInvalidateRect                = moduser32.NewProc("InvalidateRect")
+	procIsWindow                      = moduser32.NewProc("IsWindow")
+	procMapVirtualKeyW                = moduser32.NewProc("MapVirtualKeyW")
+	procMonitorFromRect               = moduser32.NewProc("MonitorFromRect")
+	procPostThreadMessageW            = moduser32.NewProc("PostThreadMessageW")
+	procPrintWindow                   = moduser32.NewProc("PrintWindow")
+	procRegisterClassExW              = moduser32.NewProc("RegisterClassExW")
+	procRegisterClipboardFormatW      = moduser32.NewProc("RegisterClipboardFormatW")
+	procRegisterHotKey                = moduser32.NewProc("RegisterHotKey")
+	procRemoveClipboardFormatListener = moduser32.NewProc("RemoveClipboardFormatListener")
+	procSendMessageTimeoutW           = moduser32.NewProc("SendMessageTimeoutW")
+	procSetLayeredWindowAttributes    = moduser32.NewProc("SetLayeredWindowAttributes")
+	procSetSystemCursor               = moduser32.NewProc("SetSystemCursor")
+	procSetThreadDpiAwarenessContext  = moduser32.NewProc("SetThreadDpiAwarenessContext")
+	procSetWindowRgn                  = moduser32.NewProc("SetWindowRgn")
+	procSetWindowTextW                = moduser32.NewProc("SetWindowTextW")
+	procSetWindowsHookExW             = moduser32.NewProc("SetWindowsHookExW")
+	procShowCursor                    = moduser32.NewProc("ShowCursor")
+	procSystemParametersInfoW         = moduser32.NewProc("SystemParametersInfoW")
+	procUnhookWindowsHookEx           = moduser32.NewProc("UnhookWindowsHookEx")
+	procUnregisterHotKey              = moduser32.NewProc("UnregisterHotKey")
+	procUpdateLayeredWindow           = moduser32.NewProc("UpdateLayeredWindow")
 )
 
+func dwmGetWindowAttribute(hwnd uintptr, dwAttribute uint32, pvAttribute unsafe.Pointer, cbAttribute uint32) (hresult int32) {
+	r0, _, _ := syscall.Syscall6(procDwmGetWindowAttribute.Addr(), 4, uintptr(hwnd), uintptr(dwAttribute), uintptr(pvAttribute), uintptr(cbAttribute), 0, 0)
+	hresult = int32(r0)
+	return
+}
+
+func dwmSetWindowAttribute(hwnd uintptr, dwAttribute uint32, pvAttribute unsafe.Pointer, cbAttribute uint32) (hresult int32) {
+	r0, _, _ := syscall.Syscall6(procDwmSetWindowAttribute.Addr(), 4, uintptr(hwnd), uintptr(dwAttribute), uintptr(pvAttribute), uintptr(cbAttribute), 0, 0)
+	hresult = int32(r0)
+	return
+}
+
+func abortPath(hdc uintptr) (err error) {
+	r1, _, e1 := syscall.Syscall(procAbortPath.Addr(), 1, uintptr(hdc), 0, 0)
+	if r1 == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func arc(hdc uintptr, x1 int, y1 int, x2 int, y2 int, x3 int, y3 int, x4 int, y4 int) (err error) {
+	r1, _, e1 := syscall.Syscall9(procArc.Addr(), 9, uintptr(hdc), uintptr(x1), uintptr(y1), uintptr(x2), uintptr(y2), uintptr(x3), uintptr(y3), uintptr(x4), uintptr(y4))
+	if r1 == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func beginPath(hdc uintptr) (err error) {
+	r1, _, e1 := syscall.Syscall(procBeginPath.Addr(), 1, uintptr(hdc), 0, 0)
+	if r1 == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
 func createDIBSection(hdc uintptr, pbmi uintptr, usage uint, ppvBits uintptr, hSection uintptr, offset uint32) (hBitMap uintptr) {
 	r0, _, _ := syscall.Syscall6(procCreateDIBSection.Addr(), 6, uintptr(hdc), uintptr(pbmi), uintptr(usage), uintptr(ppvBits), uintptr(hSection), uintptr(offset))
 	hBitMap = uintptr(r0)
@@ -99,6 +190,29 @@ func createSolidBrush(color uint32) (hbrush uintptr) {
 	return
 }
 
+func endPath(hdc uintptr) (err error) {
+	r1, _, e1 := syscall.Syscall(procEndPath.Addr(), 1, uintptr(hdc), 0, 0)
+	if r1 == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func enumFontFamiliesEx(hdc uintptr, lpLogfont uintptr, lpProc uintptr, lParam uintptr, dwFlags uint32) (ret int32) {
+	r0, _, _ := syscall.Syscall6(procEnumFontFamiliesExW.Addr(), 5, uintptr(hdc), uintptr(lpLogfont), uintptr(lpProc), uintptr(lParam), uintptr(dwFlags), 0)
+	ret = int32(r0)
+	return
+}
+
+func extCreateRegion(lpx uintptr, nCount uint32, lpData *byte) (rgn uintptr, err error) {
+	r0, _, e1 := syscall.Syscall(procExtCreateRegion.Addr(), 3, uintptr(lpx), uintptr(nCount), uintptr(unsafe.Pointer(lpData)))
+	rgn = uintptr(r0)
+	if rgn == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
 func extFloodFill(hdc uintptr, x int, y int, color uint32, opType uint32) (err error) {
 	r1, _, e1 := syscall.Syscall6(procExtFloodFill.Addr(), 5, uintptr(hdc), uintptr(x), uintptr(y), uintptr(color), uintptr(opType), 0)
 	if r1 == 0 {
@@ -107,6 +221,46 @@ func extFloodFill(hdc uintptr, x int, y int, color uint32, opType uint32) (err e
 	return
 }
 
+func fillPath(hdc uintptr) (err error) {
+	r1, _, e1 := syscall.Syscall(procFillPath.Addr(), 1, uintptr(hdc), 0, 0)
+	if r1 == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func getEnhMetaFileBits(hemf uintptr, nSize uint32, lpData *byte) (n uint32) {
+	r0, _, _ := syscall.Syscall(procGetEnhMetaFileBits.Addr(), 3, uintptr(hemf), uintptr(nSize), uintptr(unsafe.Pointer(lpData)))
+	n = uint32(r0)
+	return
+}
+
+func getRegionData(hrgn uintptr, nCount uint32, lpRgnData *byte) (n uint32, err error) {
+	r0, _, e1 := syscall.Syscall(procGetRegionData.Addr(), 3, uintptr(hrgn), uintptr(nCount), uintptr(unsafe.Pointer(lpRgnData)))
+	n = uint32(r0)
+	if n == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func pathToRegion(hdc uintptr) (rgn uintptr, err error) {
+	r0, _, e1 := syscall.Syscall(procPathToRegion.Addr(), 1, uintptr(hdc), 0, 0)
+	rgn = uintptr(r0)
+	if rgn == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func polyBezier(hdc uintptr, apt uintptr, cpt uint32) (err error) {
+	r1, _, e1 := syscall.Syscall(procPolyBezier.Addr(), 3, uintptr(hdc), uintptr(apt), uintptr(cpt))
+	if r1 == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
 func polyDraw(hdc uintptr, apt uintptr, aj uintptr, cpt int) (err error) {
 	r1, _, e1 := syscall.Syscall6(procPolyDraw.Addr(), 4, uintptr(hdc), uintptr(apt), uintptr(aj), uintptr(cpt), 0, 0)
 	if r1 == 0 {
@@ -115,12 +269,69 @@ func polyDraw(hdc uintptr, apt uintptr, aj uintptr, cpt int) (err error) {
 	return
 }
 
+func polygon(hdc uintptr, apt uintptr, cpt int) (err error) {
+	r1, _, e1 := syscall.Syscall(procPolygon.Addr(), 3, uintptr(hdc), uintptr(apt), uintptr(cpt))
+	if r1 == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func selectClipRgn(hdc uintptr, hrgn uintptr) (region int) {
+	r0, _, _ := syscall.Syscall(procSelectClipRgn.Addr(), 2, uintptr(hdc), uintptr(hrgn), 0)
+	region = int(r0)
+	return
+}
+
+func setEnhMetaFileBits(nSize uint32, pb *byte) (hemf uintptr, err error) {
+	r0, _, e1 := syscall.Syscall(procSetEnhMetaFileBits.Addr(), 2, uintptr(nSize), uintptr(unsafe.Pointer(pb)), 0)
+	hemf = uintptr(r0)
+	if hemf == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func setPolyFillMode(hdc uintptr, mode int) (old int) {
+	r0, _, _ := syscall.Syscall(procSetPolyFillMode.Addr(), 2, uintptr(hdc), uintptr(mode), 0)
+	old = int(r0)
+	return
+}
+
+func setTextAlign(hdc uintptr, align uint32) (old uint32) {
+	r0, _, _ := syscall.Syscall(procSetTextAlign.Addr(), 2, uintptr(hdc), uintptr(align), 0)
+	old = uint32(r0)
+	return
+}
+
+func strokeAndFillPath(hdc uintptr) (err error) {
+	r1, _, e1 := syscall.Syscall(procStrokeAndFillPath.Addr(), 1, uintptr(hdc), 0, 0)
+	if r1 == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func strokePath(hdc uintptr) (err error) {
+	r1, _, e1 := syscall.Syscall(procStrokePath.Addr(), 1, uintptr(hdc), 0, 0)
+	if r1 == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
 func activateAudioInterfaceAsync(deviceInterfacePath *uint16, riid uintptr, activationParams uintptr, completionHandler uintptr, createAsync uintptr) (hresult int32) {
 	r0, _, _ := syscall.Syscall6(procActivateAudioInterfaceAsync.Addr(), 5, uintptr(unsafe.Pointer(deviceInterfacePath)), uintptr(riid), uintptr(activationParams), uintptr(completionHandler), uintptr(createAsync), 0)
 	hresult = int32(r0)
 	return
 }
 
+func getDpiForMonitor(hmonitor uintptr, dpiType uint32, dpiX *uint32, dpiY *uint32) (hresult int32) {
+	r0, _, _ := syscall.Syscall6(procGetDpiForMonitor.Addr(), 4, uintptr(hmonitor), uintptr(dpiType), uintptr(unsafe.Pointer(dpiX)), uintptr(unsafe.Pointer(dpiY)), 0, 0)
+	hresult = int32(r0)
+	return
+}
+
 func wtsCloseServerExW(hServer uintptr) {
 	syscall.Syscall(procWTSCloseServer.Addr(), 1, uintptr(hServer), 0, 0)
 	return
@@ -190,6 +401,48 @@ func wtsVirtualChannelWrite(hChannelHandle uintptr, Buffer uintptr, Length uint3
 	return
 }
 
+func globalSize(hMem uintptr) (size uintptr) {
+	r0, _, _ := syscall.Syscall(procGlobalSize.Addr(), 1, uintptr(hMem), 0, 0)
+	size = uintptr(r0)
+	return
+}
+
+func setLastError(code uint32) {
+	syscall.Syscall(procSetLastError.Addr(), 1, uintptr(code), 0, 0)
+	return
+}
+
+func virtualAllocEx(process uintptr, address uintptr, size uintptr, allocType uint32, protect uint32) (addr uintptr, err error) {
+	r0, _, e1 := syscall.Syscall6(procVirtualAllocEx.Addr(), 5, uintptr(process), uintptr(address), uintptr(size), uintptr(allocType), uintptr(protect), 0)
+	addr = uintptr(r0)
+	if addr == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func virtualFreeEx(process uintptr, address uintptr, size uintptr, freeType uint32) (err error) {
+	r1, _, e1 := syscall.Syscall6(procVirtualFreeEx.Addr(), 4, uintptr(process), uintptr(address), uintptr(size), uintptr(freeType), 0, 0)
+	if r1 == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func addClipboardFormatListener(hwnd uintptr) (err error) {
+	r1, _, e1 := syscall.Syscall(procAddClipboardFormatListener.Addr(), 1, uintptr(hwnd), 0, 0)
+	if r1 == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func callNextHookEx(hhk uintptr, nCode int, wParam uintptr, lParam uintptr) (ret uintptr) {
+	r0, _, _ := syscall.Syscall6(procCallNextHookEx.Addr(), 4, uintptr(hhk), uintptr(nCode), uintptr(wParam), uintptr(lParam), 0, 0)
+	ret = uintptr(r0)
+	return
+}
+
 func clipCursor(rect uintptr) (ok int, err error) {
 	r0, _, e1 := syscall.Syscall(procClipCursor.Addr(), 1, uintptr(rect), 0, 0)
 	ok = int(r0)
@@ -199,6 +452,43 @@ func clipCursor(rect uintptr) (ok int, err error) {
 	return
 }
 
+func copyIcon(hicon uintptr) (h uintptr, err error) {
+	r0, _, e1 := syscall.Syscall(procCopyIcon.Addr(), 1, uintptr(hicon), 0, 0)
+	h = uintptr(r0)
+	if h == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func createIconFromResourceEx(presbits *byte, dwResSize uint32, fIcon bool, dwVer uint32, cxDesired int, cyDesired int, flags uint32) (hicon uintptr, err error) {
+	var _p0 uint32
+	if fIcon {
+		_p0 = 1
+	}
+	r0, _, e1 := syscall.Syscall9(procCreateIconFromResourceEx.Addr(), 7, uintptr(unsafe.Pointer(presbits)), uintptr(dwResSize), uintptr(_p0), uintptr(dwVer), uintptr(cxDesired), uintptr(cyDesired), uintptr(flags), 0, 0)
+	hicon = uintptr(r0)
+	if hicon == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func createIconIndirect(piconinfo uintptr) (hicon uintptr, err error) {
+	r0, _, e1 := syscall.Syscall(procCreateIconIndirect.Addr(), 1, uintptr(piconinfo), 0, 0)
+	hicon = uintptr(r0)
+	if hicon == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func enumClipboardFormats(format uint32) (next uint32) {
+	r0, _, _ := syscall.Syscall(procEnumClipboardFormats.Addr(), 1, uintptr(format), 0, 0)
+	next = uint32(r0)
+	return
+}
+
 func enumDesktopWindows(hDesktop uintptr, lpEnumFunc uintptr, lParam uintptr) (err error) {
 	r1, _, e1 := syscall.Syscall(procEnumDesktopWindows.Addr(), 3, uintptr(hDesktop), uintptr(lpEnumFunc), uintptr(lParam))
 	if r1 == 0 {
@@ -207,6 +497,22 @@ func enumDesktopWindows(hDesktop uintptr, lpEnumFunc uintptr, lParam uintptr) (e
 	return
 }
 
+func enumDisplayMonitors(hdc uintptr, lprcClip uintptr, lpfnEnum uintptr, dwData uintptr) (err error) {
+	r1, _, e1 := syscall.Syscall6(procEnumDisplayMonitors.Addr(), 4, uintptr(hdc), uintptr(lprcClip), uintptr(lpfnEnum), uintptr(dwData), 0, 0)
+	if r1 == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func enumDisplaySettings(lpszDeviceName *uint16, iModeNum uint32, lpDevMode uintptr) (err error) {
+	r1, _, e1 := syscall.Syscall(procEnumDisplaySettingsW.Addr(), 3, uintptr(unsafe.Pointer(lpszDeviceName)), uintptr(iModeNum), uintptr(lpDevMode))
+	if r1 == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
 func fillRect(hdc uintptr, rect uintptr, hbr uintptr) (err error) {
 	r1, _, e1 := syscall.Syscall(procFillRect.Addr(), 3, uintptr(hdc), uintptr(rect), uintptr(hbr))
 	if r1 == 0 {
@@ -227,6 +533,38 @@ func getClassName(hwnd uintptr, lpClassName uintptr, nMax int) (length int) {
 	return
 }
 
+func getClipboardFormatName(format uint32, lpszFormatName *uint16, cchMaxCount int) (length int) {
+	r0, _, _ := syscall.Syscall(procGetClipboardFormatNameW.Addr(), 3, uintptr(format), uintptr(unsafe.Pointer(lpszFormatName)), uintptr(cchMaxCount))
+	length = int(r0)
+	return
+}
+
+func getClipboardSequenceNumber() (seq uint32) {
+	r0, _, _ := syscall.Syscall(procGetClipboardSequenceNumber.Addr(), 0, 0, 0, 0)
+	seq = uint32(r0)
+	return
+}
+
+func getGuiResources(process uintptr, flags uint32) (count uint32) {
+	r0, _, _ := syscall.Syscall(procGetGuiResources.Addr(), 2, uintptr(process), uintptr(flags), 0)
+	count = uint32(r0)
+	return
+}
+
+func getMonitorInfo(hMonitor uintptr, lpmi uintptr) (err error) {
+	r1, _, e1 := syscall.Syscall(procGetMonitorInfoW.Addr(), 2, uintptr(hMonitor), uintptr(lpmi), 0)
+	if r1 == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func getWindowTextLength(hwnd uintptr) (length int) {
+	r0, _, _ := syscall.Syscall(procGetWindowTextLengthW.Addr(), 1, uintptr(hwnd), 0, 0)
+	length = int(r0)
+	return
+}
+
 func getWindowText(hwnd uintptr, lpString uintptr, nMax int) (length int) {
 	r0, _, _ := syscall.Syscall(procGetWindowTextW.Addr(), 3, uintptr(hwnd), uintptr(lpString), uintptr(nMax))
 	length = int(r0)
@@ -245,12 +583,40 @@ func invalidateRect(hwnd uintptr, rect uintptr, bErase bool) (err error) {
 	return
 }
 
+func isWindow(hwnd uintptr) (ok bool) {
+	r0, _, _ := syscall.Syscall(procIsWindow.Addr(), 1, uintptr(hwnd), 0, 0)
+	ok = r0 != 0
+	return
+}
+
 func mapVirtualKey(uCode uint32, uMapType uint32) (code uint32) {
 	r0, _, _ := syscall.Syscall(procMapVirtualKeyW.Addr(), 2, uintptr(uCode), uintptr(uMapType), 0)
 	code = uint32(r0)
 	return
 }
 
+func monitorFromRect(lprc uintptr, dwFlags uint32) (hmonitor uintptr) {
+	r0, _, _ := syscall.Syscall(procMonitorFromRect.Addr(), 2, uintptr(lprc), uintptr(dwFlags), 0)
+	hmonitor = uintptr(r0)
+	return
+}
+
+func postThreadMessage(idThread uint32, msg uint32, wParam uintptr, lParam uintptr) (err error) {
+	r1, _, e1 := syscall.Syscall6(procPostThreadMessageW.Addr(), 4, uintptr(idThread), uintptr(msg), uintptr(wParam), uintptr(lParam), 0, 0)
+	if r1 == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func printWindow(hwnd uintptr, hdcBlt uintptr, nFlags uint32) (err error) {
+	r1, _, e1 := syscall.Syscall(procPrintWindow.Addr(), 3, uintptr(hwnd), uintptr(hdcBlt), uintptr(nFlags))
+	if r1 == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
 func registerClassEx(windowClass uintptr) (atom uint16, err error) {
 	r0, _, e1 := syscall.Syscall(procRegisterClassExW.Addr(), 1, uintptr(windowClass), 0, 0)
 	atom = uint16(r0)
@@ -260,6 +626,40 @@ func registerClassEx(windowClass uintptr) (atom uint16, err error) {
 	return
 }
 
+func registerClipboardFormat(lpszFormat *uint16) (format uint32, err error) {
+	r0, _, e1 := syscall.Syscall(procRegisterClipboardFormatW.Addr(), 1, uintptr(unsafe.Pointer(lpszFormat)), 0, 0)
+	format = uint32(r0)
+	if format == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func registerHotKey(hwnd uintptr, id int, fsModifiers uint32, vk uint32) (err error) {
+	r1, _, e1 := syscall.Syscall6(procRegisterHotKey.Addr(), 4, uintptr(hwnd), uintptr(id), uintptr(fsModifiers), uintptr(vk), 0, 0)
+	if r1 == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func removeClipboardFormatListener(hwnd uintptr) (err error) {
+	r1, _, e1 := syscall.Syscall(procRemoveClipboardFormatListener.Addr(), 1, uintptr(hwnd), 0, 0)
+	if r1 == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func sendMessageTimeout(hwnd uintptr, msg uint32, wParam uintptr, lParam uintptr, fuFlags uint32, uTimeout uint32, lpdwResult *uintptr) (ret uintptr, err error) {
+	r0, _, e1 := syscall.Syscall9(procSendMessageTimeoutW.Addr(), 7, uintptr(hwnd), uintptr(msg), uintptr(wParam), uintptr(lParam), uintptr(fuFlags), uintptr(uTimeout), uintptr(unsafe.Pointer(lpdwResult)), 0, 0)
+	ret = uintptr(r0)
+	if ret == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
 func setLayeredWindowAttributes(hwnd uintptr, color uint32, bAlpha byte, dwFlags uint32) (err error) {
 	r1, _, e1 := syscall.Syscall6(procSetLayeredWindowAttributes.Addr(), 4, uintptr(hwnd), uintptr(color), uintptr(bAlpha), uintptr(dwFlags), 0, 0)
 	if r1 == 0 {
@@ -268,6 +668,20 @@ func setLayeredWindowAttributes(hwnd uintptr, color uint32, bAlpha byte, dwFlags
 	return
 }
 
+func setSystemCursor(hcur uintptr, id uint32) (err error) {
+	r1, _, e1 := syscall.Syscall(procSetSystemCursor.Addr(), 2, uintptr(hcur), uintptr(id), 0)
+	if r1 == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func setThreadDpiAwarenessContext(dpiContext uintptr) (old uintptr) {
+	r0, _, _ := syscall.Syscall(procSetThreadDpiAwarenessContext.Addr(), 1, uintptr(dpiContext), 0, 0)
+	old = uintptr(r0)
+	return
+}
+
 func setWindowRgn(hwnd uintptr, hRgn uintptr, bRedraw bool) (err error) {
 	var _p0 uint32
 	if bRedraw {
@@ -288,6 +702,15 @@ func setWindowText(hwnd uintptr, lpString *uint16) (err error) {
 	return
 }
 
+func setWindowsHookEx(idHook int, lpfn uintptr, hmod uintptr, dwThreadId uint32) (hhk uintptr, err error) {
+	r0, _, e1 := syscall.Syscall6(procSetWindowsHookExW.Addr(), 4, uintptr(idHook), uintptr(lpfn), uintptr(hmod), uintptr(dwThreadId), 0, 0)
+	hhk = uintptr(r0)
+	if hhk == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
 func showCursor(state bool) (counter int) {
 	var _p0 uint32
 	if state {
@@ -298,6 +721,30 @@ func showCursor(state bool) (counter int) {
 	return
 }
 
+func systemParametersInfo(uiAction uint32, uiParam uint32, pvParam uintptr, fWinIni uint32) (err error) {
+	r1, _, e1 := syscall.Syscall6(procSystemParametersInfoW.Addr(), 4, uintptr(uiAction), uintptr(uiParam), uintptr(pvParam), uintptr(fWinIni), 0, 0)
+	if r1 == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func unhookWindowsHookEx(hhk uintptr) (err error) {
+	r1, _, e1 := syscall.Syscall(procUnhookWindowsHookEx.Addr(), 1, uintptr(hhk), 0, 0)
+	if r1 == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func unregisterHotKey(hwnd uintptr, id int) (err error) {
+	r1, _, e1 := syscall.Syscall(procUnregisterHotKey.Addr(), 2, uintptr(hwnd), uintptr(id), 0)
+	if r1 == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
 func updateLayeredWindow(hwnd uintptr, hdcDst uintptr, pptDst uintptr, psize uintptr, hdcSrc uintptr, pptSrc uintptr, crKey uint32, pblend uintptr, dwFlags uint32) (ok bool) {
 	r0, _, _ := syscall.Syscall9(procUpdateLayeredWindow.Addr(), 9, uintptr(hwnd), uintptr(hdcDst), uintptr(pptDst), uintptr(psize), uintptr(hdcSrc), uintptr(pptSrc), uintptr(crKey), uintptr(pblend), uintptr(dwFlags))
 	ok = r0 != 0
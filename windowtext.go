@@ -0,0 +1,125 @@
+package winapi
+
+import (
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/lxn/win"
+	"github.com/pkg/errors"
+	"github.com/whiteboxsolutions/winapi/internal/textbuf"
+	"golang.org/x/sys/windows"
+)
+
+// DefaultWindowTextTimeout bounds how long WindowText waits for a window.
+const DefaultWindowTextTimeout = time.Second
+
+// windowTextSource reads the text of a window with WM_GETTEXT. Unlike
+// GetWindowTextW it also returns the contents of controls in other
+// processes, and SendMessageTimeout keeps hung windows from blocking.
+// It is only used for those controls, see crossProcessControl.
+type windowTextSource struct {
+	hwnd    win.HWND
+	timeout uint32
+}
+
+func (w windowTextSource) Len() (int, error) {
+	n, err := SendMessageTimeout(w.hwnd, win.WM_GETTEXTLENGTH, 0, 0, SMTO_ABORTIFHUNG, w.timeout)
+	if err != nil {
+		return 0, w.err(err)
+	}
+	return int(n), nil
+}
+
+func (w windowTextSource) Read(buf []uint16) (int, error) {
+	n, err := SendMessageTimeout(w.hwnd, win.WM_GETTEXT, uintptr(len(buf)), uintptr(unsafe.Pointer(&buf[0])), SMTO_ABORTIFHUNG, w.timeout)
+	if err != nil {
+		return 0, w.err(err)
+	}
+	if int(n) >= len(buf) {
+		n = uintptr(len(buf) - 1)
+	}
+	return int(n), nil
+}
+
+func (w windowTextSource) err(err error) error {
+	if err == windows.ERROR_TIMEOUT || err == syscall.EINVAL {
+		// aborting for a hung window may leave the last error unset
+		return errors.Wrapf(ErrWindowTimeout, "hwnd %#x", w.hwnd)
+	}
+	if !IsWindow(w.hwnd) {
+		return errors.Wrapf(ErrInvalidWindow, "hwnd %#x", w.hwnd)
+	}
+	return errors.Wrap(err, "SendMessageTimeout")
+}
+
+// getWindowTextSource reads the text of a window with GetWindowTextW,
+// which returns the title of top-level windows without sending messages to
+// other processes, so it cannot hang.
+type getWindowTextSource win.HWND
+
+func (g getWindowTextSource) Len() (int, error) {
+	return GetWindowTextLength(win.HWND(g)), nil
+}
+
+func (g getWindowTextSource) Read(buf []uint16) (int, error) {
+	n := GetWindowText(win.HWND(g), buf, len(buf))
+	if n == 0 && !IsWindow(win.HWND(g)) {
+		return 0, errors.Wrapf(ErrInvalidWindow, "hwnd %#x", g)
+	}
+	return n, nil
+}
+
+// crossProcessControl reports whether hwnd is a child window of another
+// process, for which GetWindowTextW returns nothing and WM_GETTEXT has to
+// be sent.
+func crossProcessControl(hwnd win.HWND) bool {
+	if win.GetWindowLong(hwnd, win.GWL_STYLE)&win.WS_CHILD == 0 {
+		return false
+	}
+	var pid uint32
+	win.GetWindowThreadProcessId(hwnd, &pid)
+	return pid != windows.GetCurrentProcessId()
+}
+
+// WindowText returns the text of hwnd, the title of top-level windows and
+// the contents of controls, in full.
+func WindowText(hwnd win.HWND) (string, error) {
+	return WindowTextTimeout(hwnd, DefaultWindowTextTimeout)
+}
+
+// WindowTextTimeout is WindowText waiting up to timeout for each message
+// sent to controls of other processes. Top-level windows and windows of
+// this process are read with GetWindowTextW.
+func WindowTextTimeout(hwnd win.HWND, timeout time.Duration) (string, error) {
+	if !IsWindow(hwnd) {
+		return "", errors.Wrapf(ErrInvalidWindow, "hwnd %#x", hwnd)
+	}
+	if !crossProcessControl(hwnd) {
+		return textbuf.Read(getWindowTextSource(hwnd))
+	}
+	return textbuf.Read(windowTextSource{hwnd: hwnd, timeout: uint32(timeout / time.Millisecond)})
+}
+
+// classNameSource reads the class name of a window, limited to 256 characters.
+type classNameSource win.HWND
+
+func (c classNameSource) Len() (int, error) {
+	return 256, nil
+}
+
+func (c classNameSource) Read(buf []uint16) (int, error) {
+	n := GetClassName(win.HWND(c), uintptr(unsafe.Pointer(&buf[0])), len(buf))
+	if n == 0 {
+		if !IsWindow(win.HWND(c)) {
+			return 0, errors.Wrapf(ErrInvalidWindow, "hwnd %#x", c)
+		}
+		return 0, errors.Wrap(windows.GetLastError(), "GetClassName")
+	}
+	return n, nil
+}
+
+// ClassName returns the window class name of hwnd.
+func ClassName(hwnd win.HWND) (string, error) {
+	return textbuf.Read(classNameSource(hwnd))
+}